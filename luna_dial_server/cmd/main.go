@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"luna_dial/internal/biz"
 	"luna_dial/internal/config"
 	"luna_dial/internal/data"
 	"luna_dial/internal/server"
+	"os"
 
 	"github.com/spf13/cobra"
 	"gorm.io/driver/postgres"
@@ -15,6 +18,9 @@ import (
 
 var (
 	configFile string
+
+	fsckUserID string
+	fsckFix    bool
 )
 
 var rootCmd = &cobra.Command{
@@ -25,15 +31,31 @@ It provides APIs for user authentication, task management, journaling, and plann
 	Run: runServer,
 }
 
+var treeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Task tree maintenance commands",
+}
+
+var treeFsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Check task trees for orphans, cycles and stale tree fields",
+	Long: `Recompute parent links, depths, root IDs and children counts of all active tasks
+and print the problems found as a JSON report. With --fix all problems are repaired
+in a single transaction. Exits with status 1 when problems are found and not fixed.`,
+	Run: runTreeFsck,
+}
+
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "config file (default is configs/config.ini)")
-}
 
-func runServer(cmd *cobra.Command, args []string) {
-	// Initialize config
-	config.InitConfig(configFile)
+	treeFsckCmd.Flags().StringVarP(&fsckUserID, "user", "u", "", "only check tasks of this user ID (default is all users)")
+	treeFsckCmd.Flags().BoolVar(&fsckFix, "fix", false, "repair the problems found in a transaction")
+	treeCmd.AddCommand(treeFsckCmd)
+	rootCmd.AddCommand(treeCmd)
+}
 
-	// Initialize database
+// openDatabase 按配置文件连接数据库
+func openDatabase() *gorm.DB {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
 		config.Cfg.Database.Host,
 		config.Cfg.Database.User,
@@ -46,6 +68,15 @@ func runServer(cmd *cobra.Command, args []string) {
 	if err != nil {
 		log.Fatalf("failed to connect database: %v", err)
 	}
+	return db
+}
+
+func runServer(cmd *cobra.Command, args []string) {
+	// Initialize config
+	config.InitConfig(configFile)
+
+	// Initialize database
+	db := openDatabase()
 
 	// Initialize data
 	dataInstance, sessionCleanup, err := data.NewData(db)
@@ -82,6 +113,35 @@ func runServer(cmd *cobra.Command, args []string) {
 	server.Start(e, cleanup)
 }
 
+// runTreeFsck 检查（可选修复）任务树，把报告以 JSON 输出到标准输出
+func runTreeFsck(cmd *cobra.Command, args []string) {
+	config.InitConfig(configFile)
+	db := openDatabase()
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	taskUsecase := biz.NewTaskUsecase(data.NewTaskRepo(db), data.NewUserSettingsRepo(db), data.NewChangeHistoryRepo(db))
+	report, err := taskUsecase.CheckTaskTrees(context.Background(), biz.CheckTaskTreesParam{
+		UserID: fsckUserID,
+		Fix:    fsckFix,
+	})
+	if err != nil {
+		log.Fatalf("failed to check task trees: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("failed to write report: %v", err)
+	}
+	if report.IssueCount > 0 && !report.Fixed {
+		os.Exit(1)
+	}
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatal(err)
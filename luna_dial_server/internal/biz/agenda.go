@@ -0,0 +1,113 @@
+package biz
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// agendaPeriodTypes 日程的分组，从年到日
+var agendaPeriodTypes = []PeriodType{PeriodYear, PeriodQuarter, PeriodMonth, PeriodWeek, PeriodDay}
+
+// AgendaTask 日程中的任务，附带从根任务到直接父任务的祖先链
+type AgendaTask struct {
+	*Task
+	ParentChain []*Task `json:"parent_chain"`
+}
+
+// AgendaGroup 日程中同一类型的任务
+type AgendaGroup struct {
+	PeriodType PeriodType    `json:"period_type"`
+	Period     Period        `json:"period"` // 该类型包含日期的标准时间段
+	Tasks      []*AgendaTask `json:"tasks"`
+}
+
+// Agenda 某一天进行中的各层级任务和日志
+type Agenda struct {
+	Date     time.Time      `json:"date"`
+	Groups   []*AgendaGroup `json:"groups"`
+	Journals []*Journal     `json:"journals"`
+}
+
+// 获取日程参数
+type GetAgendaParam struct {
+	UserID string
+	Date   time.Time
+}
+
+// 获取时间段包含指定日期的任务，按类型分组并附带祖先链
+// 只执行固定次数的查询：包含日期的任务一次，祖先所在的任务树一次
+func (uc *TaskUsecase) ListAgendaTasks(ctx context.Context, userID string, date time.Time) ([]*AgendaGroup, error) {
+	if userID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	if date.IsZero() {
+		return nil, ErrInvalidInput
+	}
+
+	tasks, err := uc.repo.ListTasksContainingTime(ctx, userID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	// 一次取出所有相关任务树，祖先链在内存中拼接（已取消的祖先也要返回）
+	var rootIDs []string
+	seenRoots := make(map[string]bool)
+	for _, task := range tasks {
+		rootID := treeRootTaskID(task)
+		if task.ParentID != "" && !seenRoots[rootID] {
+			seenRoots[rootID] = true
+			rootIDs = append(rootIDs, rootID)
+		}
+	}
+	nodes := make(map[string]*Task)
+	if len(rootIDs) > 0 {
+		treeTasks, err := uc.repo.ListTasksByRootIDs(ctx, userID, rootIDs, boardStatuses)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range treeTasks {
+			task.Children = nil
+			nodes[task.ID] = task
+		}
+	}
+
+	groups := make([]*AgendaGroup, len(agendaPeriodTypes))
+	byType := make(map[PeriodType]*AgendaGroup, len(agendaPeriodTypes))
+	for i, periodType := range agendaPeriodTypes {
+		groups[i] = &AgendaGroup{PeriodType: periodType, Period: NewPeriodFromPeriodType(periodType, date), Tasks: []*AgendaTask{}}
+		byType[periodType] = groups[i]
+	}
+	for _, task := range tasks {
+		group, ok := byType[task.TaskType]
+		if !ok {
+			continue
+		}
+		group.Tasks = append(group.Tasks, &AgendaTask{Task: task, ParentChain: parentChainOf(task, nodes)})
+	}
+	for _, group := range groups {
+		sort.SliceStable(group.Tasks, func(i, j int) bool {
+			return group.Tasks[i].TimePeriod.Start.Before(group.Tasks[j].TimePeriod.Start)
+		})
+	}
+	return groups, nil
+}
+
+// parentChainOf 从 nodes 中找出任务的祖先，按从根任务到直接父任务排列
+func parentChainOf(task *Task, nodes map[string]*Task) []*Task {
+	chain := []*Task{}
+	visited := map[string]bool{task.ID: true}
+	for parentID := task.ParentID; parentID != "" && !visited[parentID]; {
+		parent, ok := nodes[parentID]
+		if !ok {
+			break
+		}
+		visited[parentID] = true
+		chain = append(chain, parent)
+		parentID = parent.ParentID
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
@@ -0,0 +1,72 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanUsecase_GetAgenda(t *testing.T) {
+	ctx := context.Background()
+	day := date(2025, 1, 15)
+	newTask := func(id string, periodType PeriodType, parentID, rootID string) *Task {
+		return &Task{ID: id, UserID: "u1", TaskType: periodType, TimePeriod: NewPeriodFromPeriodType(periodType, day), ParentID: parentID, RootTaskID: rootID}
+	}
+	year := newTask("year", PeriodYear, "", "")
+	year.Status = TaskStatusCancelled // 已取消的祖先也出现在祖先链中
+	nextWeek := newTask("next-week", PeriodWeek, "month", "year")
+	nextWeek.TimePeriod = NewPeriodFromPeriodType(PeriodWeek, day.AddDate(0, 0, 7))
+	repo := newMemoryTaskRepo(
+		year,
+		newTask("month", PeriodMonth, "year", "year"),
+		newTask("week", PeriodWeek, "month", "year"),
+		newTask("today", PeriodDay, "week", "year"),
+		nextWeek,
+		newTask("alone", PeriodDay, "", ""),
+	)
+	taskUsecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+	uc := NewPlanUsecase(taskUsecase, NewJournalUsecase(&mockJournalRepo{}, newMockChangeHistoryRepo()))
+
+	agenda, err := uc.GetAgenda(ctx, GetAgendaParam{UserID: "u1", Date: day})
+	require.NoError(t, err)
+	require.Len(t, agenda.Groups, 5)
+	groupIDs := func(periodType PeriodType) []string {
+		for _, group := range agenda.Groups {
+			if group.PeriodType == periodType {
+				ids := []string{}
+				for _, task := range group.Tasks {
+					ids = append(ids, task.ID)
+				}
+				return ids
+			}
+		}
+		return nil
+	}
+	assert.Equal(t, PeriodYear, agenda.Groups[0].PeriodType)
+	assert.Equal(t, []string{"year"}, groupIDs(PeriodYear))
+	assert.Empty(t, groupIDs(PeriodQuarter))
+	assert.Equal(t, []string{"month"}, groupIDs(PeriodMonth))
+	assert.Equal(t, []string{"week"}, groupIDs(PeriodWeek))
+	assert.Equal(t, []string{"alone", "today"}, groupIDs(PeriodDay))
+	assert.Equal(t, NewPeriodFromPeriodType(PeriodWeek, day), agenda.Groups[3].Period)
+
+	// 祖先链从根任务到直接父任务
+	today := agenda.Groups[4].Tasks[1]
+	chain := make([]string, len(today.ParentChain))
+	for i, task := range today.ParentChain {
+		chain[i] = task.ID
+	}
+	assert.Equal(t, []string{"year", "month", "week"}, chain)
+	assert.Empty(t, agenda.Groups[4].Tasks[0].ParentChain)
+	assert.Empty(t, agenda.Groups[0].Tasks[0].ParentChain)
+
+	require.Len(t, agenda.Journals, 1)
+	assert.True(t, agenda.Journals[0].TimePeriod.ContainsTime(day))
+
+	_, err = uc.GetAgenda(ctx, GetAgendaParam{UserID: "u1"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = uc.GetAgenda(ctx, GetAgendaParam{Date: day})
+	assert.ErrorIs(t, err, ErrNoPermission)
+}
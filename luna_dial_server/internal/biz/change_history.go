@@ -0,0 +1,224 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// ChangeEntityType 变更记录所属的实体类型
+type ChangeEntityType string
+
+const (
+	ChangeEntityTask    ChangeEntityType = "task"
+	ChangeEntityJournal ChangeEntityType = "journal"
+)
+
+// ChangeAction 变更动作
+type ChangeAction string
+
+const (
+	ChangeActionCreate  ChangeAction = "create"
+	ChangeActionUpdate  ChangeAction = "update"
+	ChangeActionDelete  ChangeAction = "delete"  // 移入回收站，记录为 deleted_at 字段的变化
+	ChangeActionRestore ChangeAction = "restore" // 从回收站恢复
+)
+
+// 系统触发变更时使用的操作人
+const (
+	SystemActorCron     = "system:cron"     // 周期任务生成器
+	SystemActorRollover = "system:rollover" // 未完成任务自动顺延
+)
+
+// ChangeRecord 实体单个字段的一次变更
+// 创建时记录所有非空字段（旧值为空），删除/恢复记录 deleted_at 的变化
+type ChangeRecord struct {
+	ID         string           `json:"id"`
+	EntityType ChangeEntityType `json:"entity_type"`
+	EntityID   string           `json:"entity_id"`
+	UserID     string           `json:"user_id"`  // 实体所属用户
+	ActorID    string           `json:"actor_id"` // 操作人：用户ID，或 system:* 表示系统触发
+	Action     ChangeAction     `json:"action"`
+	Field      string           `json:"field"`
+	OldValue   string           `json:"old_value"`
+	NewValue   string           `json:"new_value"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// 获取实体变更历史参数
+type ListChangeHistoryParam struct {
+	EntityID string
+	UserID   string
+}
+
+// actorContextKey 操作人在 context 中的键
+type actorContextKey struct{}
+
+// ContextWithActor 指定后续变更记录的操作人，未指定时使用实体所属用户
+func ContextWithActor(ctx context.Context, actorID string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actorID)
+}
+
+func actorFromContext(ctx context.Context, fallback string) string {
+	if actorID, ok := ctx.Value(actorContextKey{}).(string); ok && actorID != "" {
+		return actorID
+	}
+	return fallback
+}
+
+// fieldValue 参与变更记录的字段及其字符串形式
+type fieldValue struct {
+	field string
+	value string
+}
+
+func formatPeriod(period Period) string {
+	if period.Start.IsZero() && period.End.IsZero() {
+		return ""
+	}
+	return period.Start.Format(time.RFC3339) + "/" + period.End.Format(time.RFC3339)
+}
+
+func formatDeletedAt(deletedAt *time.Time) string {
+	if deletedAt == nil {
+		return ""
+	}
+	return deletedAt.Format(time.RFC3339)
+}
+
+// formatTags 标签列表按 JSON 数组记录：标签名允许包含逗号，按分隔符拼接无法区分 ["a,b"] 和 ["a","b"]
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(tags)
+	return string(data)
+}
+
+// taskFieldValues 任务中记录历史的字段，枚举值按 JSON 中的整数记录
+func taskFieldValues(task *Task) []fieldValue {
+	if task == nil {
+		return nil
+	}
+	return []fieldValue{
+		{"title", task.Title},
+		{"description", task.Description},
+		{"task_type", strconv.Itoa(int(task.TaskType))},
+		{"period", formatPeriod(task.TimePeriod)},
+		{"tags", formatTags(task.Tags)},
+		{"icon", task.Icon},
+		{"score", strconv.Itoa(task.Score)},
+		{"status", strconv.Itoa(int(task.Status))},
+		{"cancel_reason", task.CancelReason},
+		{"priority", strconv.Itoa(int(task.Priority))},
+		{"estimate", strconv.Itoa(task.Estimate)},
+		{"parent_id", task.ParentID},
+		{"deleted_at", formatDeletedAt(task.DeletedAt)},
+	}
+}
+
+// journalFieldValues 日志中记录历史的字段
+func journalFieldValues(journal *Journal) []fieldValue {
+	if journal == nil {
+		return nil
+	}
+	return []fieldValue{
+		{"title", journal.Title},
+		{"content", journal.Content},
+		{"journal_type", strconv.Itoa(int(journal.JournalType))},
+		{"period", formatPeriod(journal.TimePeriod)},
+		{"icon", journal.Icon},
+		{"deleted_at", formatDeletedAt(journal.DeletedAt)},
+	}
+}
+
+// buildChangeRecords 比较变更前后的字段，为每个变化的字段生成一条记录
+// before 为空表示创建：只记录非空字段
+func buildChangeRecords(ctx context.Context, entityType ChangeEntityType, entityID, userID string, action ChangeAction, before, after []fieldValue) []*ChangeRecord {
+	oldValues := make(map[string]string, len(before))
+	for _, fv := range before {
+		oldValues[fv.field] = fv.value
+	}
+
+	now := time.Now()
+	actorID := actorFromContext(ctx, userID)
+	records := make([]*ChangeRecord, 0)
+	for _, fv := range after {
+		if oldValues[fv.field] == fv.value {
+			continue
+		}
+		if before == nil && (fv.value == "" || fv.value == "0") {
+			continue // 创建时跳过空值和零值
+		}
+		records = append(records, &ChangeRecord{
+			ID:         generateID(),
+			EntityType: entityType,
+			EntityID:   entityID,
+			UserID:     userID,
+			ActorID:    actorID,
+			Action:     action,
+			Field:      fv.field,
+			OldValue:   oldValues[fv.field],
+			NewValue:   fv.value,
+			CreatedAt:  now,
+		})
+	}
+	return records
+}
+
+// recordTaskChange 记录任务变更，before 为空表示创建
+func (uc *TaskUsecase) recordTaskChange(ctx context.Context, action ChangeAction, before, after *Task) error {
+	records := buildChangeRecords(ctx, ChangeEntityTask, after.ID, after.UserID, action, taskFieldValues(before), taskFieldValues(after))
+	if len(records) == 0 {
+		return nil
+	}
+	return uc.historyRepo.CreateChangeRecords(ctx, records)
+}
+
+// recordJournalChange 记录日志变更，before 为空表示创建
+func (uc *JournalUsecase) recordJournalChange(ctx context.Context, action ChangeAction, before, after *Journal) error {
+	records := buildChangeRecords(ctx, ChangeEntityJournal, after.ID, after.UserID, action, journalFieldValues(before), journalFieldValues(after))
+	if len(records) == 0 {
+		return nil
+	}
+	return uc.historyRepo.CreateChangeRecords(ctx, records)
+}
+
+// 获取任务的变更历史（最近的在前）
+// 任务在回收站中或已被永久删除时，只要还有历史记录仍然返回
+func (uc *TaskUsecase) ListTaskHistory(ctx context.Context, param ListChangeHistoryParam) ([]*ChangeRecord, error) {
+	if param.EntityID == "" || param.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	records, err := uc.historyRepo.ListChangeRecords(ctx, ChangeEntityTask, param.EntityID, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		if err := uc.checkTaskExists(ctx, param.EntityID, param.UserID); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// 获取日志的变更历史（最近的在前）
+// 日志已被永久删除时，只要还有历史记录仍然返回
+func (uc *JournalUsecase) ListJournalHistory(ctx context.Context, param ListChangeHistoryParam) ([]*ChangeRecord, error) {
+	if param.EntityID == "" || param.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	records, err := uc.historyRepo.ListChangeRecords(ctx, ChangeEntityJournal, param.EntityID, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		if _, err := uc.GetJournal(ctx, GetJournalParam{JournalID: param.EntityID, UserID: param.UserID}); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,10 @@
+package biz
+
+import "context"
+
+type ChangeHistoryRepo interface {
+	// 批量写入变更记录，在事务中调用时与实体的修改一起提交
+	CreateChangeRecords(ctx context.Context, records []*ChangeRecord) error
+	// 获取实体的变更记录，最近的在前
+	ListChangeRecords(ctx context.Context, entityType ChangeEntityType, entityID, userID string) ([]*ChangeRecord, error)
+}
@@ -0,0 +1,183 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockChangeHistoryRepo 内存中的变更历史，按写入顺序保存
+type mockChangeHistoryRepo struct {
+	records []*ChangeRecord
+}
+
+func newMockChangeHistoryRepo() *mockChangeHistoryRepo {
+	return &mockChangeHistoryRepo{}
+}
+
+func (m *mockChangeHistoryRepo) CreateChangeRecords(ctx context.Context, records []*ChangeRecord) error {
+	m.records = append(m.records, records...)
+	return nil
+}
+
+func (m *mockChangeHistoryRepo) ListChangeRecords(ctx context.Context, entityType ChangeEntityType, entityID, userID string) ([]*ChangeRecord, error) {
+	result := make([]*ChangeRecord, 0)
+	for i := len(m.records) - 1; i >= 0; i-- {
+		record := m.records[i]
+		if record.EntityType == entityType && record.EntityID == entityID && record.UserID == userID {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+// changedFields 按字段名索引变更记录
+func changedFields(records []*ChangeRecord) map[string]*ChangeRecord {
+	fields := make(map[string]*ChangeRecord, len(records))
+	for _, record := range records {
+		fields[record.Field] = record
+	}
+	return fields
+}
+
+func TestTaskUsecase_ChangeHistory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("创建时记录非空字段", func(t *testing.T) {
+		historyRepo := newMockChangeHistoryRepo()
+		usecase := NewTaskUsecase(newMemoryTaskRepo(), newMockUserSettingsRepo(), historyRepo)
+
+		task, err := usecase.CreateTask(ctx, CreateTaskParam{
+			UserID: "user-123", Title: "写周报", Type: PeriodDay,
+			Period: NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 6)),
+		})
+		require.NoError(t, err)
+
+		records, err := usecase.ListTaskHistory(ctx, ListChangeHistoryParam{EntityID: task.ID, UserID: "user-123"})
+		require.NoError(t, err)
+		fields := changedFields(records)
+		require.Contains(t, fields, "title")
+		assert.Equal(t, ChangeActionCreate, fields["title"].Action)
+		assert.Equal(t, "", fields["title"].OldValue)
+		assert.Equal(t, "写周报", fields["title"].NewValue)
+		assert.Equal(t, "user-123", fields["title"].ActorID)
+		assert.Contains(t, fields, "period")
+		assert.NotContains(t, fields, "icon", "empty fields are skipped on create")
+		assert.NotContains(t, fields, "deleted_at")
+	})
+
+	t.Run("更新时只记录变化的字段", func(t *testing.T) {
+		historyRepo := newMockChangeHistoryRepo()
+		repo := newMemoryTaskRepo(&Task{ID: "task", UserID: "user-123", Title: "旧标题", TaskType: PeriodDay, RootTaskID: "task"})
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), historyRepo)
+		title := "新标题"
+		unchanged := TaskPriorityLow
+
+		_, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "task", UserID: "user-123", Title: &title, Priority: &unchanged})
+		require.NoError(t, err)
+
+		require.Len(t, historyRepo.records, 1)
+		record := historyRepo.records[0]
+		assert.Equal(t, ChangeActionUpdate, record.Action)
+		assert.Equal(t, "title", record.Field)
+		assert.Equal(t, "旧标题", record.OldValue)
+		assert.Equal(t, "新标题", record.NewValue)
+	})
+
+	t.Run("标签名中的逗号不影响标签记录", func(t *testing.T) {
+		historyRepo := newMockChangeHistoryRepo()
+		repo := newMemoryTaskRepo(&Task{ID: "task", UserID: "user-123", TaskType: PeriodDay, Tags: []string{"a", "b"}, RootTaskID: "task"})
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), historyRepo)
+		tags := []string{"a,b"}
+
+		_, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "task", UserID: "user-123", Tags: &tags})
+		require.NoError(t, err)
+
+		require.Len(t, historyRepo.records, 1)
+		assert.Equal(t, "tags", historyRepo.records[0].Field)
+		assert.Equal(t, `["a","b"]`, historyRepo.records[0].OldValue)
+		assert.Equal(t, `["a,b"]`, historyRepo.records[0].NewValue)
+	})
+
+	t.Run("删除和恢复记录 deleted_at", func(t *testing.T) {
+		historyRepo := newMockChangeHistoryRepo()
+		repo := newMemoryTaskRepo(&Task{ID: "task", UserID: "user-123", TaskType: PeriodDay, RootTaskID: "task"})
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), historyRepo)
+
+		require.NoError(t, usecase.DeleteTask(ctx, DeleteTaskParam{TaskID: "task", UserID: "user-123"}))
+		_, err := usecase.RestoreTask(ctx, RestoreTaskParam{TaskID: "task", UserID: "user-123"})
+		require.NoError(t, err)
+
+		records, err := usecase.ListTaskHistory(ctx, ListChangeHistoryParam{EntityID: "task", UserID: "user-123"})
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, ChangeActionRestore, records[0].Action, "most recent first")
+		assert.Equal(t, "", records[0].NewValue)
+		assert.Equal(t, ChangeActionDelete, records[1].Action)
+		assert.Equal(t, "deleted_at", records[1].Field)
+		assert.Equal(t, records[1].NewValue, records[0].OldValue)
+	})
+
+	t.Run("级联删除为每个后代记录删除", func(t *testing.T) {
+		historyRepo := newMockChangeHistoryRepo()
+		repo := newMemoryTaskRepo(
+			&Task{ID: "root", UserID: "user-123", TaskType: PeriodMonth, RootTaskID: "root"},
+			&Task{ID: "child", UserID: "user-123", TaskType: PeriodWeek, ParentID: "root", RootTaskID: "root", TreeDepth: 1},
+			&Task{ID: "grandchild", UserID: "user-123", TaskType: PeriodDay, ParentID: "child", RootTaskID: "root", TreeDepth: 2},
+		)
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), historyRepo)
+
+		require.NoError(t, usecase.DeleteTask(ctx, DeleteTaskParam{TaskID: "root", UserID: "user-123", Mode: TaskDeleteModeCascade}))
+
+		deleted := make([]string, 0)
+		for _, record := range historyRepo.records {
+			if record.Action == ChangeActionDelete {
+				deleted = append(deleted, record.EntityID)
+			}
+		}
+		assert.Equal(t, []string{"root", "child", "grandchild"}, deleted)
+	})
+
+	t.Run("使用 context 中的操作人", func(t *testing.T) {
+		historyRepo := newMockChangeHistoryRepo()
+		repo := newMemoryTaskRepo(&Task{ID: "task", UserID: "user-123", TaskType: PeriodDay, RootTaskID: "task"})
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), historyRepo)
+
+		_, err := usecase.SetTaskIcon(ContextWithActor(ctx, SystemActorCron), SetTaskIconParam{TaskID: "task", UserID: "user-123", Icon: "📝"})
+		require.NoError(t, err)
+
+		require.Len(t, historyRepo.records, 1)
+		assert.Equal(t, SystemActorCron, historyRepo.records[0].ActorID)
+		assert.Equal(t, "user-123", historyRepo.records[0].UserID)
+	})
+
+	t.Run("任务不存在", func(t *testing.T) {
+		usecase := NewTaskUsecase(newMemoryTaskRepo(), newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+		_, err := usecase.ListTaskHistory(ctx, ListChangeHistoryParam{EntityID: "non-existent", UserID: "user-123"})
+		assert.Equal(t, ErrTaskNotFound, err)
+	})
+}
+
+func TestJournalUsecase_ChangeHistory(t *testing.T) {
+	ctx := context.Background()
+	historyRepo := newMockChangeHistoryRepo()
+	usecase := NewJournalUsecase(&mockJournalRepo{}, historyRepo)
+	content := "更新后的内容"
+
+	_, err := usecase.UpdateJournal(ctx, UpdateJournalParam{JournalID: TestJournalID123, UserID: TestUserID123, Content: &content})
+	require.NoError(t, err)
+	require.NoError(t, usecase.DeleteJournal(ctx, DeleteJournalParam{JournalID: TestJournalID123, UserID: TestUserID123}))
+
+	records, err := usecase.ListJournalHistory(ctx, ListChangeHistoryParam{EntityID: TestJournalID123, UserID: TestUserID123})
+	require.NoError(t, err)
+	fields := changedFields(records)
+	require.Contains(t, fields, "content")
+	assert.Equal(t, "更新后的内容", fields["content"].NewValue)
+	require.Contains(t, fields, "deleted_at")
+	assert.Equal(t, ChangeActionDelete, fields["deleted_at"].Action)
+
+	_, err = usecase.ListJournalHistory(ctx, ListChangeHistoryParam{EntityID: TestJournalIDNonExistent, UserID: TestUserID123})
+	assert.Equal(t, ErrJournalNotFound, err)
+}
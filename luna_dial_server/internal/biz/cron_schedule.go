@@ -0,0 +1,185 @@
+package biz
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronExpr 解析后的标准5段 cron 表达式：分 时 日 月 周
+// 周期任务以"天"为粒度生成，所以分、时两段只做语法校验，匹配时只看日、月、周
+type cronExpr struct {
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+
+	// 日、周两段是否都做了限制：都限制时按 cron 惯例取并集
+	domRestricted bool
+	dowRestricted bool
+}
+
+// cronField 每段的取值范围
+type cronField struct {
+	min, max int
+}
+
+var cronFields = []cronField{
+	{0, 59}, // 分
+	{0, 23}, // 时
+	{1, 31}, // 日
+	{1, 12}, // 月
+	{0, 7},  // 周（0和7都表示周日）
+}
+
+// parseCronExpr 解析 cron 表达式，支持 *、数字、范围(a-b)、列表(a,b)、步长(*/n, a-b/n, a/n)
+func parseCronExpr(expr string) (*cronExpr, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, ErrCronExprInvalid
+	}
+
+	sets := make([]map[int]bool, len(parts))
+	for i, part := range parts {
+		set, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+
+	// 周日统一用 0 表示
+	if sets[4][7] {
+		sets[4][0] = true
+		delete(sets[4], 7)
+	}
+
+	return &cronExpr{
+		daysOfMonth:   sets[2],
+		months:        sets[3],
+		daysOfWeek:    sets[4],
+		domRestricted: parts[2] != "*",
+		dowRestricted: parts[4] != "*",
+	}, nil
+}
+
+func parseCronField(field string, bounds cronField) (map[int]bool, error) {
+	set := make(map[int]bool)
+	for _, item := range strings.Split(field, ",") {
+		step := 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			n, err := strconv.Atoi(item[idx+1:])
+			if err != nil || n <= 0 {
+				return nil, ErrCronExprInvalid
+			}
+			step = n
+			item = item[:idx]
+		}
+
+		lo, hi := bounds.min, bounds.max
+		switch {
+		case item == "*":
+		case strings.Contains(item, "-"):
+			rangeParts := strings.SplitN(item, "-", 2)
+			a, errA := strconv.Atoi(rangeParts[0])
+			b, errB := strconv.Atoi(rangeParts[1])
+			if errA != nil || errB != nil || a > b {
+				return nil, ErrCronExprInvalid
+			}
+			lo, hi = a, b
+		default:
+			n, err := strconv.Atoi(item)
+			if err != nil {
+				return nil, ErrCronExprInvalid
+			}
+			lo, hi = n, n
+			if step > 1 {
+				hi = bounds.max // a/n 表示从 a 开始到最大值，每 n 个取一个
+			}
+		}
+
+		if lo < bounds.min || hi > bounds.max || lo > hi {
+			return nil, ErrCronExprInvalid
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// matchesDate 判断某一天是否命中表达式
+func (c *cronExpr) matchesDate(date time.Time) bool {
+	if !c.months[int(date.Month())] {
+		return false
+	}
+
+	domMatch := c.daysOfMonth[date.Day()]
+	dowMatch := c.daysOfWeek[int(date.Weekday())]
+	if c.domRestricted && c.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// parseSchedule 解析 cron 类型规则的表达式，其他类型返回 nil
+// 生成任务时每条规则只解析一次，结果传给 occurrencesBetween
+func (ct *CronTask) parseSchedule() (*cronExpr, error) {
+	if ct.Frequency != CronFrequencyCron {
+		return nil, nil
+	}
+	return parseCronExpr(ct.CronExpr)
+}
+
+// matchesDate 判断规则在某一天是否需要生成任务
+// date 需要是某天的零点，规则的开始/结束日期在调用方处理；expr 为 parseSchedule 的结果
+func (ct *CronTask) matchesDate(date time.Time, expr *cronExpr) bool {
+	switch ct.Frequency {
+	case CronFrequencyDaily:
+		return true
+
+	case CronFrequencyWeekly:
+		for _, weekday := range ct.Weekdays {
+			if time.Weekday(weekday) == date.Weekday() {
+				return true
+			}
+		}
+		return false
+
+	case CronFrequencyMonthly:
+		day := ct.MonthDay
+		if day <= 0 {
+			day = ct.StartDate.Day()
+		}
+		// 当月没有这一天时（如31号），在当月最后一天生成
+		lastDay := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, date.Location()).Day()
+		if day > lastDay {
+			day = lastDay
+		}
+		return date.Day() == day
+
+	case CronFrequencyWorkdays:
+		return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+
+	case CronFrequencyCron:
+		return expr != nil && expr.matchesDate(date)
+
+	default:
+		return false
+	}
+}
+
+// occurrencesBetween 返回 [from, to] 闭区间内（按天）规则命中的日期
+func (ct *CronTask) occurrencesBetween(from, to time.Time, expr *cronExpr) []time.Time {
+	dates := make([]time.Time, 0)
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if ct.matchesDate(date, expr) {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+// truncateToDate 取时间所在的日历日，统一为 UTC 零点，与接口解析出的日期保持一致
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
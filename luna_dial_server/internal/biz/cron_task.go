@@ -0,0 +1,435 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// CronFrequency 周期任务的重复频率
+type CronFrequency int
+
+const (
+	CronFrequencyDaily    CronFrequency = iota // 每天
+	CronFrequencyWeekly                        // 每周指定的星期几
+	CronFrequencyMonthly                       // 每月指定日期
+	CronFrequencyWorkdays                      // 工作日（周一至周五）
+	CronFrequencyCron                          // cron 表达式
+)
+
+// 默认提前生成未来多少天内的任务
+const DefaultCronTaskLookaheadDays = 7
+
+// CronTask 周期任务规则：按频率在每个命中的日期生成一条普通任务
+type CronTask struct {
+	ID        string        `json:"id"`
+	UserID    string        `json:"user_id"`
+	Title     string        `json:"title"`
+	Frequency CronFrequency `json:"frequency"`
+	Weekdays  []int         `json:"weekdays"`  // 每周模式：0=周日 ... 6=周六
+	MonthDay  int           `json:"month_day"` // 每月模式：1-31，为0时取开始日期的日
+	CronExpr  string        `json:"cron_expr"` // cron 模式：标准5段表达式，只按天匹配
+
+	StartDate time.Time  `json:"start_date"`
+	EndDate   *time.Time `json:"end_date,omitempty"` // 为空表示一直重复
+
+	// 生成任务的模板
+	TaskType PeriodType   `json:"task_type"`
+	Tags     []string     `json:"tags"`
+	Icon     string       `json:"icon"`
+	Priority TaskPriority `json:"priority"`
+	ParentID string       `json:"parent_id"`
+
+	// 最后一次生成任务对应的日期，重启后从这里继续，避免重复生成
+	LastGeneratedDate *time.Time `json:"last_generated_date,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// 创建周期任务参数
+type CreateCronTaskParam struct {
+	UserID    string
+	Title     string
+	Frequency CronFrequency
+	Weekdays  []int
+	MonthDay  int
+	CronExpr  string
+	StartDate time.Time
+	EndDate   *time.Time
+	TaskType  PeriodType
+	Tags      []string
+	Icon      string
+	Priority  TaskPriority
+	ParentID  string
+}
+
+// 编辑周期任务参数
+type UpdateCronTaskParam struct {
+	CronTaskID string
+	UserID     string
+	Title      *string
+	Frequency  *CronFrequency
+	Weekdays   *[]int
+	MonthDay   *int
+	CronExpr   *string
+	StartDate  *time.Time
+	EndDate    *time.Time
+	ClearEnd   bool // 清空结束日期，改为一直重复
+	TaskType   *PeriodType
+	Tags       *[]string
+	Icon       *string
+	Priority   *TaskPriority
+	ParentID   *string
+}
+
+// 删除周期任务参数
+type DeleteCronTaskParam struct {
+	CronTaskID string
+	UserID     string
+}
+
+// 获取周期任务参数
+type GetCronTaskParam struct {
+	CronTaskID string
+	UserID     string
+}
+
+type CronTaskUsecase struct {
+	repo          CronTaskRepo
+	taskUsecase   *TaskUsecase
+	lookaheadDays int
+}
+
+func NewCronTaskUsecase(repo CronTaskRepo, taskUsecase *TaskUsecase) *CronTaskUsecase {
+	return &CronTaskUsecase{
+		repo:          repo,
+		taskUsecase:   taskUsecase,
+		lookaheadDays: DefaultCronTaskLookaheadDays,
+	}
+}
+
+// 创建周期任务
+func (uc *CronTaskUsecase) CreateCronTask(ctx context.Context, param CreateCronTaskParam) (*CronTask, error) {
+	if param.UserID == "" || param.Title == "" {
+		return nil, ErrInvalidInput
+	}
+
+	tags := []string{}
+	if param.Tags != nil {
+		tags = param.Tags
+	}
+
+	cronTask := &CronTask{
+		ID:        generateID(),
+		UserID:    param.UserID,
+		Title:     param.Title,
+		Frequency: param.Frequency,
+		Weekdays:  param.Weekdays,
+		MonthDay:  param.MonthDay,
+		CronExpr:  param.CronExpr,
+		StartDate: truncateToDate(param.StartDate),
+		TaskType:  param.TaskType,
+		Tags:      tags,
+		Icon:      param.Icon,
+		Priority:  param.Priority,
+		ParentID:  param.ParentID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if param.EndDate != nil {
+		endDate := truncateToDate(*param.EndDate)
+		cronTask.EndDate = &endDate
+	}
+
+	if err := uc.validateCronTask(ctx, cronTask); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.CreateCronTask(ctx, cronTask); err != nil {
+		return nil, err
+	}
+	return cronTask, nil
+}
+
+// 编辑周期任务
+// 已经生成的任务不受影响，修改规则后从 last_generated_date 之后按新规则继续生成
+func (uc *CronTaskUsecase) UpdateCronTask(ctx context.Context, param UpdateCronTaskParam) (*CronTask, error) {
+	if param.CronTaskID == "" || param.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+	if param.Title != nil && *param.Title == "" {
+		return nil, ErrInvalidInput
+	}
+
+	cronTask, err := uc.repo.GetCronTask(ctx, param.CronTaskID, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if cronTask == nil {
+		return nil, ErrCronTaskNotFound
+	}
+
+	if param.Title != nil {
+		cronTask.Title = *param.Title
+	}
+	if param.Frequency != nil {
+		cronTask.Frequency = *param.Frequency
+	}
+	if param.Weekdays != nil {
+		cronTask.Weekdays = *param.Weekdays
+	}
+	if param.MonthDay != nil {
+		cronTask.MonthDay = *param.MonthDay
+	}
+	if param.CronExpr != nil {
+		cronTask.CronExpr = *param.CronExpr
+	}
+	if param.StartDate != nil {
+		cronTask.StartDate = truncateToDate(*param.StartDate)
+	}
+	if param.ClearEnd {
+		cronTask.EndDate = nil
+	} else if param.EndDate != nil {
+		endDate := truncateToDate(*param.EndDate)
+		cronTask.EndDate = &endDate
+	}
+	if param.TaskType != nil {
+		cronTask.TaskType = *param.TaskType
+	}
+	if param.Tags != nil {
+		cronTask.Tags = *param.Tags
+	}
+	if param.Icon != nil {
+		cronTask.Icon = *param.Icon
+	}
+	if param.Priority != nil {
+		cronTask.Priority = *param.Priority
+	}
+	if param.ParentID != nil {
+		cronTask.ParentID = *param.ParentID
+	}
+
+	if err := uc.validateCronTask(ctx, cronTask); err != nil {
+		return nil, err
+	}
+
+	cronTask.UpdatedAt = time.Now()
+	if err := uc.repo.UpdateCronTask(ctx, cronTask); err != nil {
+		return nil, err
+	}
+	return cronTask, nil
+}
+
+// 删除周期任务，已经生成的任务保留
+func (uc *CronTaskUsecase) DeleteCronTask(ctx context.Context, param DeleteCronTaskParam) error {
+	if param.CronTaskID == "" || param.UserID == "" {
+		return ErrInvalidInput
+	}
+
+	cronTask, err := uc.repo.GetCronTask(ctx, param.CronTaskID, param.UserID)
+	if err != nil {
+		return err
+	}
+	if cronTask == nil {
+		return ErrCronTaskNotFound
+	}
+
+	return uc.repo.DeleteCronTask(ctx, param.CronTaskID, param.UserID)
+}
+
+// 获取周期任务详情
+func (uc *CronTaskUsecase) GetCronTask(ctx context.Context, param GetCronTaskParam) (*CronTask, error) {
+	if param.CronTaskID == "" || param.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	cronTask, err := uc.repo.GetCronTask(ctx, param.CronTaskID, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if cronTask == nil {
+		return nil, ErrCronTaskNotFound
+	}
+	return cronTask, nil
+}
+
+// 获取用户的全部周期任务
+func (uc *CronTaskUsecase) ListCronTasks(ctx context.Context, userID string) ([]*CronTask, error) {
+	if userID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	return uc.repo.ListCronTasks(ctx, userID)
+}
+
+// GenerateDueTasks 为所有仍然有效的周期任务生成未来 lookaheadDays 天内的任务
+// 返回本次新生成的任务数量；单条规则失败不影响其他规则
+func (uc *CronTaskUsecase) GenerateDueTasks(ctx context.Context, now time.Time) (int, error) {
+	today := truncateToDate(now)
+
+	cronTasks, err := uc.repo.ListActiveCronTasks(ctx, today)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, cronTask := range cronTasks {
+		created, err := uc.generateForCronTask(ctx, cronTask, today)
+		if err != nil {
+			log.Errorf("Failed to generate tasks for cron task %s: %v", cronTask.ID, err)
+			continue
+		}
+		total += created
+	}
+	return total, nil
+}
+
+// generateForCronTask 在一个事务中为单条规则生成任务并推进 last_generated_date
+// 不补生成今天之前错过的日期；同一个任务周期只生成一次（如每天重复但生成周任务）
+func (uc *CronTaskUsecase) generateForCronTask(ctx context.Context, cronTask *CronTask, today time.Time) (int, error) {
+	from := cronTask.StartDate
+	if from.Before(today) {
+		from = today
+	}
+	if cronTask.LastGeneratedDate != nil && !cronTask.LastGeneratedDate.Before(from) {
+		from = cronTask.LastGeneratedDate.AddDate(0, 0, 1)
+	}
+	to := today.AddDate(0, 0, uc.lookaheadDays)
+	if cronTask.EndDate != nil && cronTask.EndDate.Before(to) {
+		to = *cronTask.EndDate
+	}
+	if from.After(to) {
+		return 0, nil
+	}
+	expr, err := cronTask.parseSchedule()
+	if err != nil {
+		return 0, err
+	}
+
+	var lastPeriod *Period
+	if cronTask.LastGeneratedDate != nil {
+		period := NewPeriodFromPeriodType(cronTask.TaskType, *cronTask.LastGeneratedDate)
+		lastPeriod = &period
+	}
+
+	created := 0
+	err = uc.taskUsecase.repo.Transaction(ctx, func(ctx context.Context) error {
+		advanced := false
+		for _, date := range cronTask.occurrencesBetween(from, to, expr) {
+			period := NewPeriodFromPeriodType(cronTask.TaskType, date)
+			if lastPeriod != nil && period.Start.Equal(lastPeriod.Start) {
+				continue
+			}
+
+			if err := uc.createTaskFromCronTask(ctx, cronTask, period); err != nil {
+				// 父任务已删除或周期已结束：跳过这一天，避免每轮都重复报错
+				if !errors.Is(err, ErrTaskNotFound) && !errors.Is(err, ErrSubTaskPeriodInvalid) && !errors.Is(err, ErrSubTaskTypeInvalid) {
+					return err
+				}
+				log.Warnf("Skip generating task for cron task %s on %s: %v", cronTask.ID, date.Format("2006-01-02"), err)
+			} else {
+				created++
+			}
+
+			generatedDate := date
+			cronTask.LastGeneratedDate = &generatedDate
+			lastPeriod = &period
+			advanced = true
+		}
+
+		if !advanced {
+			return nil
+		}
+		cronTask.UpdatedAt = time.Now()
+		return uc.repo.UpdateCronTask(ctx, cronTask)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
+// createTaskFromCronTask 按规则模板创建一条任务，有父任务时走子任务的校验逻辑
+func (uc *CronTaskUsecase) createTaskFromCronTask(ctx context.Context, cronTask *CronTask, period Period) error {
+	tags := append([]string{}, cronTask.Tags...)
+
+	if cronTask.ParentID != "" {
+		_, err := uc.taskUsecase.CreateSubTask(ctx, CreateSubTaskParam{
+			ParentID: cronTask.ParentID,
+			UserID:   cronTask.UserID,
+			Title:    cronTask.Title,
+			Type:     cronTask.TaskType,
+			Period:   period,
+			Priority: cronTask.Priority,
+			Tags:     tags,
+			Icon:     cronTask.Icon,
+		})
+		return err
+	}
+
+	_, err := uc.taskUsecase.CreateTask(ctx, CreateTaskParam{
+		UserID:   cronTask.UserID,
+		Title:    cronTask.Title,
+		Type:     cronTask.TaskType,
+		Period:   period,
+		Tags:     tags,
+		Icon:     cronTask.Icon,
+		Priority: cronTask.Priority,
+	})
+	return err
+}
+
+// validateCronTask 校验规则本身以及模板中的父任务
+func (uc *CronTaskUsecase) validateCronTask(ctx context.Context, cronTask *CronTask) error {
+	if cronTask.StartDate.IsZero() {
+		return ErrInvalidInput
+	}
+	if cronTask.EndDate != nil && cronTask.EndDate.Before(cronTask.StartDate) {
+		return ErrInvalidPeriod
+	}
+	if cronTask.TaskType < PeriodDay || cronTask.TaskType > PeriodYear {
+		return ErrInvalidInput
+	}
+	if cronTask.Priority < TaskPriorityLow || cronTask.Priority > TaskPriorityUrgent {
+		return ErrInvalidInput
+	}
+
+	switch cronTask.Frequency {
+	case CronFrequencyDaily, CronFrequencyWorkdays:
+	case CronFrequencyWeekly:
+		if len(cronTask.Weekdays) == 0 {
+			return ErrCronTaskRuleInvalid
+		}
+		for _, weekday := range cronTask.Weekdays {
+			if weekday < 0 || weekday > 6 {
+				return ErrCronTaskRuleInvalid
+			}
+		}
+	case CronFrequencyMonthly:
+		if cronTask.MonthDay < 0 || cronTask.MonthDay > 31 {
+			return ErrCronTaskRuleInvalid
+		}
+	case CronFrequencyCron:
+		if _, err := parseCronExpr(cronTask.CronExpr); err != nil {
+			return err
+		}
+	default:
+		return ErrCronTaskRuleInvalid
+	}
+
+	if cronTask.ParentID != "" {
+		parentTask, err := uc.taskUsecase.repo.GetTask(ctx, cronTask.ParentID, cronTask.UserID)
+		if err != nil {
+			return err
+		}
+		if parentTask == nil {
+			return ErrTaskNotFound
+		}
+		if cronTask.TaskType > parentTask.TaskType {
+			return ErrSubTaskTypeInvalid
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package biz
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// CronTaskGenerator 后台定时根据周期任务规则生成任务
+type CronTaskGenerator struct {
+	usecase  *CronTaskUsecase
+	interval time.Duration
+
+	// 生成协程控制
+	stopGenerate chan bool
+	generateDone chan bool
+}
+
+// NewCronTaskGenerator 创建周期任务生成器
+func NewCronTaskGenerator(usecase *CronTaskUsecase, interval time.Duration) *CronTaskGenerator {
+	return &CronTaskGenerator{
+		usecase:      usecase,
+		interval:     interval,
+		stopGenerate: make(chan bool),
+		generateDone: make(chan bool),
+	}
+}
+
+// Start 启动生成协程，启动时立即执行一次
+func (g *CronTaskGenerator) Start() {
+	go g.generateWorker()
+}
+
+// Stop 停止生成协程，等待当前这一轮执行完成
+func (g *CronTaskGenerator) Stop() {
+	g.stopGenerate <- true
+	<-g.generateDone
+}
+
+// generateWorker 生成工作协程
+func (g *CronTaskGenerator) generateWorker() {
+	g.runOnce()
+
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			g.runOnce()
+		case <-g.stopGenerate:
+			g.generateDone <- true
+			return
+		}
+	}
+}
+
+func (g *CronTaskGenerator) runOnce() {
+	// 生成的任务在变更历史中记为系统操作
+	ctx := ContextWithActor(context.Background(), SystemActorCron)
+	created, err := g.usecase.GenerateDueTasks(ctx, time.Now())
+	if err != nil {
+		log.Errorf("Failed to generate tasks from cron tasks: %v", err)
+		return
+	}
+	if created > 0 {
+		log.Infof("Generated %d tasks from cron tasks", created)
+	}
+}
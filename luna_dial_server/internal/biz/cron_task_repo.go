@@ -0,0 +1,16 @@
+package biz
+
+import (
+	"context"
+	"time"
+)
+
+type CronTaskRepo interface {
+	CreateCronTask(ctx context.Context, cronTask *CronTask) error
+	UpdateCronTask(ctx context.Context, cronTask *CronTask) error
+	DeleteCronTask(ctx context.Context, cronTaskID, userID string) error
+	GetCronTask(ctx context.Context, cronTaskID, userID string) (*CronTask, error)
+	ListCronTasks(ctx context.Context, userID string) ([]*CronTask, error)
+	// 获取所有用户在指定日期仍然有效（未结束）的周期任务，供后台生成任务使用
+	ListActiveCronTasks(ctx context.Context, date time.Time) ([]*CronTask, error)
+}
@@ -0,0 +1,384 @@
+package biz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock CronTaskRepo 实现（内存存储）
+type mockCronTaskRepo struct {
+	cronTasks map[string]*CronTask
+}
+
+func newMockCronTaskRepo() *mockCronTaskRepo {
+	return &mockCronTaskRepo{cronTasks: make(map[string]*CronTask)}
+}
+
+func (m *mockCronTaskRepo) CreateCronTask(ctx context.Context, cronTask *CronTask) error {
+	copied := *cronTask
+	m.cronTasks[cronTask.ID] = &copied
+	return nil
+}
+
+func (m *mockCronTaskRepo) UpdateCronTask(ctx context.Context, cronTask *CronTask) error {
+	copied := *cronTask
+	m.cronTasks[cronTask.ID] = &copied
+	return nil
+}
+
+func (m *mockCronTaskRepo) DeleteCronTask(ctx context.Context, cronTaskID, userID string) error {
+	delete(m.cronTasks, cronTaskID)
+	return nil
+}
+
+func (m *mockCronTaskRepo) GetCronTask(ctx context.Context, cronTaskID, userID string) (*CronTask, error) {
+	cronTask, ok := m.cronTasks[cronTaskID]
+	if !ok || cronTask.UserID != userID {
+		return nil, nil
+	}
+	copied := *cronTask
+	return &copied, nil
+}
+
+func (m *mockCronTaskRepo) ListCronTasks(ctx context.Context, userID string) ([]*CronTask, error) {
+	cronTasks := make([]*CronTask, 0)
+	for _, cronTask := range m.cronTasks {
+		if cronTask.UserID == userID {
+			copied := *cronTask
+			cronTasks = append(cronTasks, &copied)
+		}
+	}
+	return cronTasks, nil
+}
+
+func (m *mockCronTaskRepo) ListActiveCronTasks(ctx context.Context, date time.Time) ([]*CronTask, error) {
+	cronTasks := make([]*CronTask, 0)
+	for _, cronTask := range m.cronTasks {
+		if cronTask.EndDate == nil || !cronTask.EndDate.Before(date) {
+			copied := *cronTask
+			cronTasks = append(cronTasks, &copied)
+		}
+	}
+	return cronTasks, nil
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseCronExpr(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{"每天", "0 9 * * *", false},
+		{"工作日", "0 9 * * 1-5", false},
+		{"每月1号和15号", "0 0 1,15 * *", false},
+		{"步长", "*/30 */2 */3 * *", false},
+		{"周日写作7", "0 0 * * 7", false},
+		{"段数不足", "0 9 * *", true},
+		{"非数字", "a b c d e", true},
+		{"日超出范围", "0 0 32 * *", true},
+		{"周超出范围", "0 0 * * 8", true},
+		{"反向范围", "0 0 * * 5-1", true},
+		{"步长为0", "0 0 */0 * *", true},
+		{"单值加步长", "0 0 1/7 * *", false},
+		{"单值加步长超出范围", "0 0 32/7 * *", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCronExpr(tt.expr)
+			if tt.wantErr {
+				assert.Equal(t, ErrCronExprInvalid, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("单值加步长从该值取到最大值", func(t *testing.T) {
+		expr, err := parseCronExpr("0 0 1/7 * *")
+		require.NoError(t, err)
+		assert.Equal(t, map[int]bool{1: true, 8: true, 15: true, 22: true, 29: true}, expr.daysOfMonth)
+	})
+}
+
+func TestCronTask_MatchesDate(t *testing.T) {
+	// 2025-01-06 是周一
+	tests := []struct {
+		name     string
+		cronTask CronTask
+		date     time.Time
+		expected bool
+	}{
+		{"每天", CronTask{Frequency: CronFrequencyDaily}, date(2025, 1, 11), true},
+		{"每周命中", CronTask{Frequency: CronFrequencyWeekly, Weekdays: []int{1, 3}}, date(2025, 1, 8), true},
+		{"每周未命中", CronTask{Frequency: CronFrequencyWeekly, Weekdays: []int{1, 3}}, date(2025, 1, 9), false},
+		{"每月指定日期", CronTask{Frequency: CronFrequencyMonthly, MonthDay: 15}, date(2025, 1, 15), true},
+		{"每月默认取开始日期", CronTask{Frequency: CronFrequencyMonthly, StartDate: date(2025, 1, 20)}, date(2025, 2, 20), true},
+		{"每月31号在小月取最后一天", CronTask{Frequency: CronFrequencyMonthly, MonthDay: 31}, date(2025, 2, 28), true},
+		{"工作日周五", CronTask{Frequency: CronFrequencyWorkdays}, date(2025, 1, 10), true},
+		{"工作日周六", CronTask{Frequency: CronFrequencyWorkdays}, date(2025, 1, 11), false},
+		{"cron 工作日", CronTask{Frequency: CronFrequencyCron, CronExpr: "0 9 * * 1-5"}, date(2025, 1, 6), true},
+		{"cron 日和周同时限制取并集", CronTask{Frequency: CronFrequencyCron, CronExpr: "0 0 1 * 0"}, date(2025, 1, 12), true},
+		{"cron 指定月份", CronTask{Frequency: CronFrequencyCron, CronExpr: "0 0 1 3 *"}, date(2025, 1, 1), false},
+		{"cron 表达式非法", CronTask{Frequency: CronFrequencyCron, CronExpr: "bad"}, date(2025, 1, 1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, _ := tt.cronTask.parseSchedule()
+			assert.Equal(t, tt.expected, tt.cronTask.matchesDate(tt.date, expr))
+		})
+	}
+}
+
+func TestCronTaskUsecase_CreateCronTask(t *testing.T) {
+	ctx := context.Background()
+	usecase := NewCronTaskUsecase(newMockCronTaskRepo(), createTestTaskUsecase())
+
+	validParam := func() CreateCronTaskParam {
+		return CreateCronTaskParam{
+			UserID:    "user-123",
+			Title:     "晨跑",
+			Frequency: CronFrequencyDaily,
+			StartDate: time.Date(2025, 1, 6, 15, 30, 0, 0, time.UTC),
+			TaskType:  PeriodDay,
+		}
+	}
+
+	t.Run("成功创建", func(t *testing.T) {
+		cronTask, err := usecase.CreateCronTask(ctx, validParam())
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, cronTask.ID)
+		assert.Equal(t, date(2025, 1, 6), cronTask.StartDate, "start date should be truncated to day")
+		assert.Nil(t, cronTask.LastGeneratedDate)
+		assert.NotNil(t, cronTask.Tags)
+	})
+
+	t.Run("标题为空", func(t *testing.T) {
+		param := validParam()
+		param.Title = ""
+		_, err := usecase.CreateCronTask(ctx, param)
+		assert.Equal(t, ErrInvalidInput, err)
+	})
+
+	t.Run("每周规则缺少星期", func(t *testing.T) {
+		param := validParam()
+		param.Frequency = CronFrequencyWeekly
+		_, err := usecase.CreateCronTask(ctx, param)
+		assert.Equal(t, ErrCronTaskRuleInvalid, err)
+	})
+
+	t.Run("cron 表达式非法", func(t *testing.T) {
+		param := validParam()
+		param.Frequency = CronFrequencyCron
+		param.CronExpr = "* * *"
+		_, err := usecase.CreateCronTask(ctx, param)
+		assert.Equal(t, ErrCronExprInvalid, err)
+	})
+
+	t.Run("结束日期早于开始日期", func(t *testing.T) {
+		param := validParam()
+		endDate := date(2025, 1, 1)
+		param.EndDate = &endDate
+		_, err := usecase.CreateCronTask(ctx, param)
+		assert.Equal(t, ErrInvalidPeriod, err)
+	})
+
+	t.Run("父任务不存在", func(t *testing.T) {
+		param := validParam()
+		param.ParentID = "non-existent"
+		_, err := usecase.CreateCronTask(ctx, param)
+		assert.Equal(t, ErrTaskNotFound, err)
+	})
+
+	t.Run("生成的任务类型大于父任务类型", func(t *testing.T) {
+		param := validParam()
+		param.ParentID = "task-123" // mock 中为日任务
+		param.TaskType = PeriodWeek
+		_, err := usecase.CreateCronTask(ctx, param)
+		assert.Equal(t, ErrSubTaskTypeInvalid, err)
+	})
+}
+
+func TestCronTaskUsecase_UpdateCronTask(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockCronTaskRepo()
+	usecase := NewCronTaskUsecase(repo, createTestTaskUsecase())
+
+	endDate := date(2025, 3, 1)
+	cronTask, err := usecase.CreateCronTask(ctx, CreateCronTaskParam{
+		UserID:    "user-123",
+		Title:     "周报",
+		Frequency: CronFrequencyWeekly,
+		Weekdays:  []int{5},
+		StartDate: date(2025, 1, 1),
+		EndDate:   &endDate,
+		TaskType:  PeriodDay,
+	})
+	require.NoError(t, err)
+
+	t.Run("清空结束日期", func(t *testing.T) {
+		updated, err := usecase.UpdateCronTask(ctx, UpdateCronTaskParam{
+			CronTaskID: cronTask.ID,
+			UserID:     "user-123",
+			ClearEnd:   true,
+		})
+
+		require.NoError(t, err)
+		assert.Nil(t, updated.EndDate)
+		assert.Nil(t, repo.cronTasks[cronTask.ID].EndDate)
+	})
+
+	t.Run("改为每天", func(t *testing.T) {
+		frequency := CronFrequencyDaily
+		updated, err := usecase.UpdateCronTask(ctx, UpdateCronTaskParam{
+			CronTaskID: cronTask.ID,
+			UserID:     "user-123",
+			Frequency:  &frequency,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, CronFrequencyDaily, updated.Frequency)
+	})
+
+	t.Run("其他用户的周期任务", func(t *testing.T) {
+		title := "新标题"
+		_, err := usecase.UpdateCronTask(ctx, UpdateCronTaskParam{
+			CronTaskID: cronTask.ID,
+			UserID:     "user-456",
+			Title:      &title,
+		})
+		assert.Equal(t, ErrCronTaskNotFound, err)
+	})
+}
+
+func TestCronTaskUsecase_DeleteCronTask(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockCronTaskRepo()
+	usecase := NewCronTaskUsecase(repo, createTestTaskUsecase())
+	repo.cronTasks["cron-1"] = &CronTask{ID: "cron-1", UserID: "user-123"}
+
+	assert.Equal(t, ErrCronTaskNotFound, usecase.DeleteCronTask(ctx, DeleteCronTaskParam{CronTaskID: "cron-1", UserID: "user-456"}))
+	require.NoError(t, usecase.DeleteCronTask(ctx, DeleteCronTaskParam{CronTaskID: "cron-1", UserID: "user-123"}))
+	assert.Empty(t, repo.cronTasks)
+}
+
+func TestCronTaskUsecase_GenerateDueTasks(t *testing.T) {
+	ctx := context.Background()
+	monday := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+
+	newUsecase := func(tasks ...*Task) (*CronTaskUsecase, *mockCronTaskRepo, *memoryTaskRepo) {
+		cronRepo := newMockCronTaskRepo()
+		taskRepo := newMemoryTaskRepo(tasks...)
+		return NewCronTaskUsecase(cronRepo, NewTaskUsecase(taskRepo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())), cronRepo, taskRepo
+	}
+
+	t.Run("生成未来一周的每日任务，重复执行不会重复生成", func(t *testing.T) {
+		usecase, cronRepo, taskRepo := newUsecase()
+		cronRepo.cronTasks["cron-1"] = &CronTask{
+			ID: "cron-1", UserID: "user-123", Title: "晨跑",
+			Frequency: CronFrequencyDaily, StartDate: date(2025, 1, 1), TaskType: PeriodDay,
+		}
+
+		created, err := usecase.GenerateDueTasks(ctx, monday)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultCronTaskLookaheadDays+1, created, "today plus lookahead days")
+		assert.Len(t, taskRepo.tasks, created)
+		require.NotNil(t, cronRepo.cronTasks["cron-1"].LastGeneratedDate)
+		assert.Equal(t, date(2025, 1, 13), *cronRepo.cronTasks["cron-1"].LastGeneratedDate)
+
+		// 模拟重启后再次执行
+		created, err = usecase.GenerateDueTasks(ctx, monday.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, created)
+
+		// 第二天只补上新进入窗口的一天
+		created, err = usecase.GenerateDueTasks(ctx, monday.AddDate(0, 0, 1))
+		require.NoError(t, err)
+		assert.Equal(t, 1, created)
+	})
+
+	t.Run("同一个任务周期只生成一次", func(t *testing.T) {
+		usecase, cronRepo, taskRepo := newUsecase()
+		cronRepo.cronTasks["cron-1"] = &CronTask{
+			ID: "cron-1", UserID: "user-123", Title: "周计划",
+			Frequency: CronFrequencyWorkdays, StartDate: date(2025, 1, 1), TaskType: PeriodWeek,
+		}
+
+		created, err := usecase.GenerateDueTasks(ctx, monday)
+		require.NoError(t, err)
+		assert.Equal(t, 2, created, "this week and next week")
+		for _, task := range taskRepo.tasks {
+			assert.True(t, task.TimePeriod.MatchesPeriodType(PeriodWeek))
+		}
+
+		created, err = usecase.GenerateDueTasks(ctx, monday.AddDate(0, 0, 1))
+		require.NoError(t, err)
+		assert.Equal(t, 0, created)
+	})
+
+	t.Run("不生成结束日期之后和开始日期之前的任务", func(t *testing.T) {
+		usecase, cronRepo, _ := newUsecase()
+		endDate := date(2025, 1, 8)
+		cronRepo.cronTasks["cron-1"] = &CronTask{
+			ID: "cron-1", UserID: "user-123", Title: "短期",
+			Frequency: CronFrequencyDaily, StartDate: date(2025, 1, 7), EndDate: &endDate, TaskType: PeriodDay,
+		}
+
+		created, err := usecase.GenerateDueTasks(ctx, monday)
+		require.NoError(t, err)
+		assert.Equal(t, 2, created)
+	})
+
+	t.Run("有父任务时生成子任务", func(t *testing.T) {
+		parent := &Task{
+			ID: "month-goal", UserID: "user-123", TaskType: PeriodMonth,
+			TimePeriod: NewPeriodFromPeriodType(PeriodMonth, date(2025, 1, 1)),
+		}
+		usecase, cronRepo, taskRepo := newUsecase(parent)
+		cronRepo.cronTasks["cron-1"] = &CronTask{
+			ID: "cron-1", UserID: "user-123", Title: "练琴",
+			Frequency: CronFrequencyWeekly, Weekdays: []int{1}, StartDate: date(2025, 1, 1),
+			TaskType: PeriodDay, ParentID: "month-goal", Tags: []string{"音乐"},
+		}
+
+		created, err := usecase.GenerateDueTasks(ctx, monday)
+		require.NoError(t, err)
+		assert.Equal(t, 2, created, "Jan 6 and Jan 13")
+		for _, task := range taskRepo.tasks {
+			if task.ID == "month-goal" {
+				continue
+			}
+			assert.Equal(t, "month-goal", task.ParentID)
+			assert.Equal(t, []string{"音乐"}, task.Tags)
+		}
+	})
+
+	t.Run("父任务周期已结束时跳过并推进游标", func(t *testing.T) {
+		parent := &Task{
+			ID: "dec-goal", UserID: "user-123", TaskType: PeriodMonth,
+			TimePeriod: NewPeriodFromPeriodType(PeriodMonth, date(2024, 12, 1)),
+		}
+		usecase, cronRepo, taskRepo := newUsecase(parent)
+		cronRepo.cronTasks["cron-1"] = &CronTask{
+			ID: "cron-1", UserID: "user-123", Title: "复盘",
+			Frequency: CronFrequencyDaily, StartDate: date(2024, 12, 1),
+			TaskType: PeriodDay, ParentID: "dec-goal",
+		}
+
+		created, err := usecase.GenerateDueTasks(ctx, monday)
+		require.NoError(t, err)
+		assert.Equal(t, 0, created)
+		assert.Len(t, taskRepo.tasks, 1)
+		require.NotNil(t, cronRepo.cronTasks["cron-1"].LastGeneratedDate)
+	})
+}
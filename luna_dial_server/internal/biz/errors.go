@@ -12,13 +12,58 @@ var (
 
 // 任务相关错误
 var (
-	ErrInvalidPeriod        = errors.New("invalid period range")              // 时间区间非法
-	ErrTitleEmpty           = errors.New("title is required")                 // 标题不能为空
-	ErrOnlyDayTaskCanScore  = errors.New("only day type task can set score")  // 仅日类型任务可设置分数
-	ErrUserIDNotMatchParent = errors.New("userID does not match parent task") // userID与父任务不一致
-	ErrTaskNotFound         = errors.New("task not found")                    // 任务不存在
-	ErrTaskAlreadyCompleted = errors.New("task already completed")            // 任务已完成
-	ErrDuplicateTitle       = errors.New("duplicate title")                   // 标题重复
+	ErrInvalidPeriod          = errors.New("invalid period range")                             // 时间区间非法
+	ErrTitleEmpty             = errors.New("title is required")                                // 标题不能为空
+	ErrOnlyDayTaskCanScore    = errors.New("only day type task can set score")                 // 仅日类型任务可设置分数
+	ErrUserIDNotMatchParent   = errors.New("userID does not match parent task")                // userID与父任务不一致
+	ErrTaskNotFound           = errors.New("task not found")                                   // 任务不存在
+	ErrTaskAlreadyCompleted   = errors.New("task already completed")                           // 任务已完成
+	ErrDuplicateTitle         = errors.New("duplicate title")                                  // 标题重复
+	ErrSubTaskTypeInvalid     = errors.New("subtask type cannot be larger than parent type")   // 子任务类型不能大于父任务类型
+	ErrSubTaskPeriodInvalid   = errors.New("subtask must start within parent period")          // 子任务开始时间必须在父任务时间范围内
+	ErrTaskMoveCycle          = errors.New("cannot move task under itself or its descendants") // 不能移动到自身或后代之下
+	ErrTaskBlocked            = errors.New("task is blocked by unfinished tasks")              // 任务仍被未完成的任务阻塞
+	ErrTaskDependencyCycle    = errors.New("task dependency would create a cycle")             // 依赖会形成环
+	ErrTaskDependencyExists   = errors.New("task dependency already exists")                   // 依赖已存在
+	ErrTaskDependencyNotFound = errors.New("task dependency not found")                        // 依赖不存在
+	ErrChecklistItemNotFound  = errors.New("checklist item not found")                         // 清单项不存在
+	ErrChecklistItemTextEmpty = errors.New("checklist item text is required")                  // 清单项文本不能为空
+	ErrTimerAlreadyRunning    = errors.New("another timer is already running")                 // 已有计时器在运行
+	ErrNoRunningTimer         = errors.New("no running timer")                                 // 没有正在运行的计时器
+	ErrTimeEntryNotFound      = errors.New("time entry not found")                             // 工时记录不存在
+	ErrTimeEntryInvalid       = errors.New("time entry must end after it starts")              // 工时记录时间不合法
+	ErrRolloverModeInvalid    = errors.New("invalid rollover mode")                            // 顺延方式不合法
+	ErrTaskReorderInvalid     = errors.New("task ids must be distinct children of the parent") // 排序的任务必须是同一父任务下互不重复的子任务
+	ErrTaskChildrenConflict   = errors.New("children no longer fit the task")                  // 修改类型或时间段后子任务不再满足父子规则
+	ErrKeyResultInvalid       = errors.New("invalid key result target or unit")                // 关键结果设置不合法：目标值与起始值相同或单位过长
+	ErrTaskNotKeyResult       = errors.New("task is not a key result")                         // 任务不是关键结果
+	ErrCheckInInvalid         = errors.New("invalid check-in value or time")                   // 打卡的值不是有限数或打卡时间晚于当前时间
+	ErrTaskStatusTransition   = errors.New("task status transition is not allowed")            // 不允许的状态变化，具体的前后状态见 TaskStatusTransitionError
+	ErrTaskNotOnBoard         = errors.New("task is not on the board")                         // 任务不在看板范围内
+	ErrWIPLimitExceeded       = errors.New("board column has reached its wip limit")           // 看板目标列已达到在制品上限
+	ErrTaskTreeTooDeep        = errors.New("task tree exceeds the maximum depth")              // 子任务的深度超过配置的任务树最大深度
+)
+
+// 周期任务相关错误
+var (
+	ErrCronTaskNotFound    = errors.New("cron task not found")     // 周期任务不存在
+	ErrCronTaskRuleInvalid = errors.New("invalid recurring rule")  // 重复规则不合法
+	ErrCronExprInvalid     = errors.New("invalid cron expression") // cron 表达式不合法
+)
+
+// 任务模板相关错误
+var (
+	ErrTaskTemplateNotFound = errors.New("task template not found")    // 任务模板不存在
+	ErrTaskTemplateInvalid  = errors.New("invalid task template tree") // 模板节点不合法
+)
+
+// 标签相关错误
+var (
+	ErrTagNotFound     = errors.New("tag not found")                                     // 标签不存在
+	ErrTagNameInvalid  = errors.New("tag name must be 1-50 characters without newlines") // 标签名不合法
+	ErrTagNameExists   = errors.New("tag name already exists")                           // 标签名已存在
+	ErrTagColorInvalid = errors.New("tag color must be in #RRGGBB format")               // 标签颜色不合法
+	ErrTagExprInvalid  = errors.New("invalid tag expression")                            // 标签表达式不合法
 )
 
 // 日志相关错误
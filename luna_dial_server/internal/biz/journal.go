@@ -18,6 +18,7 @@ type Journal struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	UserID      string     `json:"user_id"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"` // 进入回收站的时间，只在回收站接口中出现
 }
 
 // 创建日志参数
@@ -66,7 +67,8 @@ type ListAllJournalsParam struct {
 }
 
 type JournalUsecase struct {
-	repo JournalRepo
+	repo        JournalRepo
+	historyRepo ChangeHistoryRepo
 	// log  *log.Helper
 }
 
@@ -75,6 +77,7 @@ type ListJournalByPeriodParam struct {
 	UserID  string
 	Period  Period
 	GroupBy PeriodType
+	Match   PeriodMatch // 日志时间段与 Period 的匹配方式
 }
 
 // 第四阶段新增：分页查询日志参数
@@ -85,10 +88,13 @@ type ListJournalsWithPaginationParam struct {
 	JournalType *int       // 可选：日志类型过滤
 	PeriodStart *time.Time // 可选：开始时间过滤
 	PeriodEnd   *time.Time // 可选：结束时间过滤
+
+	// 日志时间段与时间范围的匹配方式
+	Match PeriodMatch
 }
 
-func NewJournalUsecase(repo JournalRepo) *JournalUsecase {
-	return &JournalUsecase{repo: repo}
+func NewJournalUsecase(repo JournalRepo, historyRepo ChangeHistoryRepo) *JournalUsecase {
+	return &JournalUsecase{repo: repo, historyRepo: historyRepo}
 }
 
 // 创建日志
@@ -121,7 +127,13 @@ func (uc *JournalUsecase) CreateJournal(ctx context.Context, param CreateJournal
 		UpdatedAt:   time.Now(),
 		UserID:      param.UserID,
 	}
-	if err := uc.repo.CreateJournal(ctx, journal); err != nil {
+	err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.CreateJournal(ctx, journal); err != nil {
+			return err
+		}
+		return uc.recordJournalChange(ctx, ChangeActionCreate, nil, journal)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -154,6 +166,7 @@ func (uc *JournalUsecase) UpdateJournal(ctx context.Context, param UpdateJournal
 	if oldJournal == nil {
 		return nil, ErrJournalNotFound
 	}
+	before := *oldJournal
 	// 更新数据
 	if param.Content != nil {
 		oldJournal.Content = *param.Content
@@ -168,7 +181,13 @@ func (uc *JournalUsecase) UpdateJournal(ctx context.Context, param UpdateJournal
 		oldJournal.Icon = *param.Icon
 	}
 
-	if err := uc.repo.UpdateJournal(ctx, oldJournal); err != nil {
+	err = uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.UpdateJournal(ctx, oldJournal); err != nil {
+			return err
+		}
+		return uc.recordJournalChange(ctx, ChangeActionUpdate, &before, oldJournal)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return oldJournal, nil
@@ -181,15 +200,25 @@ func (uc *JournalUsecase) DeleteJournal(ctx context.Context, param DeleteJournal
 		return ErrInvalidInput
 	}
 
-	err := uc.repo.DeleteJournalWithAuth(ctx, param.JournalID, param.UserID)
-	if err != nil {
-		if errors.Is(err, model.ErrRecordNotFound) {
-			return ErrJournalNotFound
+	return uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		journal, err := uc.GetJournal(ctx, GetJournalParam(param))
+		if err != nil {
+			return err
+		}
+
+		err = uc.repo.DeleteJournalWithAuth(ctx, param.JournalID, param.UserID)
+		if err != nil {
+			if errors.Is(err, model.ErrRecordNotFound) {
+				return ErrJournalNotFound
+			}
+			return err
 		}
-		return err
-	}
 
-	return nil
+		deleted := *journal
+		deletedAt := time.Now()
+		deleted.DeletedAt = &deletedAt
+		return uc.recordJournalChange(ctx, ChangeActionDelete, journal, &deleted)
+	})
 }
 
 // 获取日志详情
@@ -225,9 +254,12 @@ func (uc *JournalUsecase) ListJournalByPeriod(ctx context.Context, param ListJou
 	if !param.Period.MatchesPeriodType(param.GroupBy) {
 		return nil, ErrJournalTypeInvalid
 	}
+	if !param.Match.IsValid() {
+		return nil, ErrInvalidInput
+	}
 
 	groupBy := int(param.GroupBy)
-	journals, err := uc.repo.ListJournals(ctx, param.UserID, param.Period.Start, param.Period.End, groupBy)
+	journals, err := uc.repo.ListJournals(ctx, param.UserID, param.Period.Start, param.Period.End, groupBy, param.Match)
 	if err != nil {
 		if errors.Is(err, model.ErrRecordNotFound) {
 			return nil, ErrJournalNotFound
@@ -238,6 +270,17 @@ func (uc *JournalUsecase) ListJournalByPeriod(ctx context.Context, param ListJou
 	return journals, nil
 }
 
+// 获取时间段包含指定日期的所有类型的日志
+func (uc *JournalUsecase) ListJournalsOnDate(ctx context.Context, userID string, date time.Time) ([]*Journal, error) {
+	if userID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	if date.IsZero() {
+		return nil, ErrInvalidInput
+	}
+	return uc.repo.ListJournalsContainingTime(ctx, userID, date)
+}
+
 // 获取全部日志列表，带分页
 func (uc *JournalUsecase) ListAllJournals(ctx context.Context, param ListAllJournalsParam) ([]*Journal, error) {
 	if param.UserID == "" {
@@ -275,6 +318,9 @@ func (uc *JournalUsecase) ListJournalsWithPagination(ctx context.Context, param
 	if param.PageSize <= 0 || param.PageSize > 100 {
 		param.PageSize = 20 // 默认每页20条
 	}
+	if !param.Match.IsValid() {
+		return nil, 0, ErrInvalidInput
+	}
 
 	// 调用仓库层进行分页查询
 	journals, total, err := uc.repo.ListJournalsWithPagination(
@@ -285,6 +331,7 @@ func (uc *JournalUsecase) ListJournalsWithPagination(ctx context.Context, param
 		param.JournalType,
 		param.PeriodStart,
 		param.PeriodEnd,
+		param.Match,
 	)
 	if err != nil {
 		return nil, 0, err
@@ -6,11 +6,25 @@ import (
 )
 
 type JournalRepo interface {
+	// Transaction 在同一个数据库事务中执行 fn
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
 	CreateJournal(ctx context.Context, journal *Journal) error
 	UpdateJournal(ctx context.Context, journal *Journal) error
 	DeleteJournalWithAuth(ctx context.Context, journalID, userID string) error
 	GetJournalWithAuth(ctx context.Context, journalID, userID string) (*Journal, error)
-	ListJournals(ctx context.Context, userID string, periodStart, periodEnd time.Time, journalType int) ([]*Journal, error)
+	// 获取指定类型、时间段按 match 方式命中 [periodStart, periodEnd) 的日志
+	ListJournals(ctx context.Context, userID string, periodStart, periodEnd time.Time, journalType int, match PeriodMatch) ([]*Journal, error)
+	// 获取时间段包含 t 的所有类型的日志（period_start <= t < period_end）
+	ListJournalsContainingTime(ctx context.Context, userID string, t time.Time) ([]*Journal, error)
 	ListAllJournals(ctx context.Context, userID string, offset, limit int) ([]*Journal, error)
-	ListJournalsWithPagination(ctx context.Context, userID string, page, pageSize int, journalType *int, periodStart, periodEnd *time.Time) ([]*Journal, int64, error)
+	// periodStart / periodEnd 为空时不限制对应的一侧
+	ListJournalsWithPagination(ctx context.Context, userID string, page, pageSize int, journalType *int, periodStart, periodEnd *time.Time, match PeriodMatch) ([]*Journal, int64, error)
+	// 回收站：DeleteJournalWithAuth 为软删除，以下方法操作已删除的日志
+	ListTrashedJournals(ctx context.Context, userID string) ([]*Journal, error)
+	// 获取回收站中的日志，不存在时返回 model.ErrRecordNotFound
+	GetTrashedJournal(ctx context.Context, journalID, userID string) (*Journal, error)
+	// 恢复日志，回收站中不存在时返回 model.ErrRecordNotFound
+	RestoreJournal(ctx context.Context, journalID, userID string) error
+	// 永久删除在 before 之前进入回收站的日志（所有用户），返回删除数量
+	PurgeTrashedJournals(ctx context.Context, before time.Time) (int64, error)
 }
@@ -25,6 +25,10 @@ const (
 // Mock JournalRepo 实现用于测试
 type mockJournalRepo struct{}
 
+func (m *mockJournalRepo) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func (m *mockJournalRepo) CreateJournal(ctx context.Context, journal *Journal) error {
 	return nil
 }
@@ -74,7 +78,7 @@ func (m *mockJournalRepo) GetJournalWithAuth(ctx context.Context, journalID, use
 	}, nil
 }
 
-func (m *mockJournalRepo) ListJournals(ctx context.Context, userID string, periodStart, periodEnd time.Time, journalType int) ([]*Journal, error) {
+func (m *mockJournalRepo) ListJournals(ctx context.Context, userID string, periodStart, periodEnd time.Time, journalType int, match PeriodMatch) ([]*Journal, error) {
 	if userID == TestUserIDWithNoJournals {
 		return []*Journal{}, nil
 	}
@@ -96,6 +100,22 @@ func (m *mockJournalRepo) ListJournals(ctx context.Context, userID string, perio
 	}, nil
 }
 
+func (m *mockJournalRepo) ListJournalsContainingTime(ctx context.Context, userID string, t time.Time) ([]*Journal, error) {
+	if userID == TestUserIDWithNoJournals {
+		return []*Journal{}, nil
+	}
+	return []*Journal{
+		{
+			ID:          TestJournalID1,
+			Title:       "日志1",
+			Content:     "内容1",
+			JournalType: PeriodWeek,
+			UserID:      userID,
+			TimePeriod:  NewPeriodFromPeriodType(PeriodWeek, t),
+		},
+	}, nil
+}
+
 func (m *mockJournalRepo) ListAllJournals(ctx context.Context, userID string, offset, limit int) ([]*Journal, error) {
 	if userID == TestUserIDWithNoJournals {
 		return []*Journal{}, nil
@@ -144,7 +164,7 @@ func (m *mockJournalRepo) ListAllJournals(ctx context.Context, userID string, of
 	return allJournals[start:end], nil
 }
 
-func (m *mockJournalRepo) ListJournalsWithPagination(ctx context.Context, userID string, page, pageSize int, journalType *int, periodStart, periodEnd *time.Time) ([]*Journal, int64, error) {
+func (m *mockJournalRepo) ListJournalsWithPagination(ctx context.Context, userID string, page, pageSize int, journalType *int, periodStart, periodEnd *time.Time, match PeriodMatch) ([]*Journal, int64, error) {
 	if userID == TestUserIDWithNoJournals {
 		return []*Journal{}, 0, nil
 	}
@@ -227,16 +247,39 @@ func (m *mockJournalRepo) ListJournalsWithPagination(ctx context.Context, userID
 	return filteredJournals[offset:end], total, nil
 }
 
+func (m *mockJournalRepo) ListTrashedJournals(ctx context.Context, userID string) ([]*Journal, error) {
+	return []*Journal{}, nil
+}
+
+func (m *mockJournalRepo) GetTrashedJournal(ctx context.Context, journalID, userID string) (*Journal, error) {
+	if journalID == TestJournalIDNonExistent || journalID == "non-existent" {
+		return nil, model.ErrRecordNotFound
+	}
+	deletedAt := time.Now()
+	return &Journal{ID: journalID, UserID: userID, DeletedAt: &deletedAt}, nil
+}
+
+func (m *mockJournalRepo) RestoreJournal(ctx context.Context, journalID, userID string) error {
+	if journalID == TestJournalIDNonExistent || journalID == "non-existent" {
+		return model.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (m *mockJournalRepo) PurgeTrashedJournals(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
 // 创建测试用的 JournalUsecase 实例
 func createTestJournalUsecase() *JournalUsecase {
 	repo := &mockJournalRepo{}
-	return NewJournalUsecase(repo)
+	return NewJournalUsecase(repo, newMockChangeHistoryRepo())
 }
 
 // 测试 NewJournalUsecase 构造函数
 func TestNewJournalUsecase(t *testing.T) {
 	repo := &mockJournalRepo{}
-	usecase := NewJournalUsecase(repo)
+	usecase := NewJournalUsecase(repo, newMockChangeHistoryRepo())
 
 	require.NotNil(t, usecase, "NewJournalUsecase should not return nil")
 	assert.Equal(t, repo, usecase.repo, "repo should be set correctly")
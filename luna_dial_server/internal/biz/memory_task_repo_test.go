@@ -0,0 +1,635 @@
+package biz
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// memoryTaskRepo 带内存状态的任务仓库，用于需要观察写入结果的用例（完成传播、周期任务生成等）
+type memoryTaskRepo struct {
+	mockTaskRepo
+	tasks        map[string]*Task
+	dependencies []*TaskDependency
+	checklists   map[string][]*ChecklistItem
+	timeEntries  map[string]*TimeEntry
+	trashed      map[string]*Task
+	checkIns     []*KeyResultCheckIn
+}
+
+func newMemoryTaskRepo(tasks ...*Task) *memoryTaskRepo {
+	repo := &memoryTaskRepo{tasks: make(map[string]*Task), checklists: make(map[string][]*ChecklistItem), timeEntries: make(map[string]*TimeEntry), trashed: make(map[string]*Task)}
+	for _, task := range tasks {
+		repo.tasks[task.ID] = task
+	}
+	return repo
+}
+
+// Transaction 失败时恢复任务和回收站，模拟事务回滚（嵌套调用相当于保存点）
+func (r *memoryTaskRepo) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	copyTasks := func(tasks map[string]*Task) map[string]*Task {
+		copied := make(map[string]*Task, len(tasks))
+		for id, task := range tasks {
+			t := *task
+			copied[id] = &t
+		}
+		return copied
+	}
+	tasks, trashed := copyTasks(r.tasks), copyTasks(r.trashed)
+	if err := fn(ctx); err != nil {
+		r.tasks, r.trashed = tasks, trashed
+		return err
+	}
+	return nil
+}
+
+func (r *memoryTaskRepo) LockUserTasks(ctx context.Context, userID string) error {
+	return nil
+}
+
+func (r *memoryTaskRepo) GetTask(ctx context.Context, taskID, userID string) (*Task, error) {
+	task, ok := r.tasks[taskID]
+	if !ok || task.UserID != userID {
+		return nil, nil
+	}
+	copied := *task
+	return &copied, nil
+}
+
+func (r *memoryTaskRepo) CreateTask(ctx context.Context, task *Task) error {
+	copied := *task
+	r.tasks[task.ID] = &copied
+	return nil
+}
+
+// UpdateTask 与数据层一致，不覆盖汇总分数、进度和整树估算
+func (r *memoryTaskRepo) UpdateTask(ctx context.Context, task *Task) error {
+	copied := *task
+	if existing, ok := r.tasks[task.ID]; ok {
+		copied.RollupScore, copied.Progress = existing.RollupScore, existing.Progress
+		copied.TreeEstimate = existing.TreeEstimate
+	}
+	r.tasks[task.ID] = &copied
+	return nil
+}
+
+func (r *memoryTaskRepo) UpdateTaskRollup(ctx context.Context, taskID, userID string, rollup TaskRollup) error {
+	if task, ok := r.tasks[taskID]; ok && task.UserID == userID {
+		task.RollupScore, task.Progress = rollup.Score, rollup.Progress
+	}
+	return nil
+}
+
+func (r *memoryTaskRepo) GetSiblingSortRankRange(ctx context.Context, userID, parentID string) (int64, int64, error) {
+	var minRank, maxRank int64
+	found := false
+	for _, task := range r.tasks {
+		if task.ParentID != parentID || task.UserID != userID {
+			continue
+		}
+		if !found || task.SortRank < minRank {
+			minRank = task.SortRank
+		}
+		if !found || task.SortRank > maxRank {
+			maxRank = task.SortRank
+		}
+		found = true
+	}
+	return minRank, maxRank, nil
+}
+
+func (r *memoryTaskRepo) UpdateTaskSortRanks(ctx context.Context, userID string, ranks map[string]int64) error {
+	for taskID, rank := range ranks {
+		if task, ok := r.tasks[taskID]; ok && task.UserID == userID {
+			task.SortRank = rank
+		}
+	}
+	return nil
+}
+
+func (r *memoryTaskRepo) UpdateTaskBoardRanks(ctx context.Context, userID string, ranks map[string]int64) error {
+	for taskID, rank := range ranks {
+		if task, ok := r.tasks[taskID]; ok && task.UserID == userID {
+			task.BoardRank = rank
+		}
+	}
+	return nil
+}
+
+func (r *memoryTaskRepo) ListChildTasks(ctx context.Context, parentID, userID string) ([]*Task, error) {
+	children := make([]*Task, 0)
+	for _, task := range r.tasks {
+		if task.ParentID == parentID && task.UserID == userID {
+			copied := *task
+			children = append(children, &copied)
+		}
+	}
+	return children, nil
+}
+
+func (r *memoryTaskRepo) GetTaskParentChain(ctx context.Context, taskID, userID string) ([]*Task, error) {
+	chain := make([]*Task, 0)
+	for id := taskID; id != ""; {
+		task, ok := r.tasks[id]
+		if !ok {
+			break
+		}
+		copied := *task
+		chain = append([]*Task{&copied}, chain...)
+		id = task.ParentID
+	}
+	return chain, nil
+}
+
+func (r *memoryTaskRepo) CreateTaskDependency(ctx context.Context, dependency *TaskDependency) error {
+	copied := *dependency
+	r.dependencies = append(r.dependencies, &copied)
+	return nil
+}
+
+func (r *memoryTaskRepo) DeleteTaskDependency(ctx context.Context, taskID, blockedByTaskID, userID string) error {
+	kept := make([]*TaskDependency, 0, len(r.dependencies))
+	for _, dep := range r.dependencies {
+		if dep.TaskID == taskID && dep.BlockedByTaskID == blockedByTaskID && dep.UserID == userID {
+			continue
+		}
+		kept = append(kept, dep)
+	}
+	r.dependencies = kept
+	return nil
+}
+
+func (r *memoryTaskRepo) ListTaskDependencies(ctx context.Context, userID string, includeTrashed bool) ([]*TaskDependency, error) {
+	dependencies := make([]*TaskDependency, 0)
+	for _, dep := range r.dependencies {
+		trashed := r.trashed[dep.TaskID] != nil || r.trashed[dep.BlockedByTaskID] != nil
+		if dep.UserID == userID && (includeTrashed || !trashed) {
+			dependencies = append(dependencies, dep)
+		}
+	}
+	return dependencies, nil
+}
+
+func (r *memoryTaskRepo) ListTaskBlockers(ctx context.Context, taskID, userID string) ([]*Task, error) {
+	blockers := make([]*Task, 0)
+	for _, dep := range r.dependencies {
+		if dep.TaskID == taskID && dep.UserID == userID {
+			if task, ok := r.tasks[dep.BlockedByTaskID]; ok {
+				copied := *task
+				blockers = append(blockers, &copied)
+			}
+		}
+	}
+	return blockers, nil
+}
+
+func (r *memoryTaskRepo) ListTaskDependents(ctx context.Context, taskID, userID string) ([]*Task, error) {
+	dependents := make([]*Task, 0)
+	for _, dep := range r.dependencies {
+		if dep.BlockedByTaskID == taskID && dep.UserID == userID {
+			if task, ok := r.tasks[dep.TaskID]; ok {
+				copied := *task
+				dependents = append(dependents, &copied)
+			}
+		}
+	}
+	return dependents, nil
+}
+
+func (r *memoryTaskRepo) ListTasks(ctx context.Context, userID string, periodStart, periodEnd time.Time, taskType int, match PeriodMatch) ([]*Task, error) {
+	tasks := make([]*Task, 0)
+	for _, task := range r.tasks {
+		if task.UserID == userID && int(task.TaskType) == taskType &&
+			match.Matches(task.TimePeriod, Period{Start: periodStart, End: periodEnd}) {
+			copied := *task
+			tasks = append(tasks, &copied)
+		}
+	}
+	return tasks, nil
+}
+
+func (r *memoryTaskRepo) ListTasksInPeriod(ctx context.Context, userID string, periodStart, periodEnd time.Time) ([]*Task, error) {
+	tasks := make([]*Task, 0)
+	for _, task := range r.tasks {
+		if task.UserID == userID && !task.TimePeriod.Start.Before(periodStart) && !task.TimePeriod.End.After(periodEnd) {
+			copied := *task
+			tasks = append(tasks, &copied)
+		}
+	}
+	return tasks, nil
+}
+
+func (r *memoryTaskRepo) ListTasksContainingTime(ctx context.Context, userID string, t time.Time) ([]*Task, error) {
+	tasks := make([]*Task, 0)
+	for _, task := range r.tasks {
+		if task.UserID == userID && task.TimePeriod.ContainsTime(t) {
+			copied := *task
+			tasks = append(tasks, &copied)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	return tasks, nil
+}
+
+func (r *memoryTaskRepo) ListTasksByRootIDs(ctx context.Context, userID string, rootTaskIDs []string, includeStatus []TaskStatus) ([]*Task, error) {
+	tasks := make([]*Task, 0)
+	for _, task := range r.tasks {
+		for _, rootID := range rootTaskIDs {
+			if task.UserID == userID && treeRootTaskID(task) == rootID {
+				copied := *task
+				tasks = append(tasks, &copied)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+func (r *memoryTaskRepo) GetCompleteTaskTree(ctx context.Context, taskID, userID string, includeStatus []TaskStatus) ([]*Task, error) {
+	task, ok := r.tasks[taskID]
+	if !ok || task.UserID != userID {
+		return nil, nil
+	}
+	rootID := task.RootTaskID
+	if rootID == "" {
+		rootID = task.ID
+	}
+	nodes := make(map[string]*Task)
+	ids := make([]string, 0)
+	for _, t := range r.tasks {
+		if t.UserID == userID && (t.ID == rootID || t.RootTaskID == rootID) {
+			copied := *t
+			copied.Children = make([]*Task, 0)
+			nodes[t.ID] = &copied
+			ids = append(ids, t.ID)
+		}
+	}
+	sort.Strings(ids)
+	roots := make([]*Task, 0)
+	for _, id := range ids {
+		node := nodes[id]
+		if parent, ok := nodes[node.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return roots, nil
+}
+
+func (r *memoryTaskRepo) ListSubtreeTasks(ctx context.Context, taskID, userID string, includeStatus []TaskStatus) ([]*Task, error) {
+	task, ok := r.tasks[taskID]
+	if !ok || task.UserID != userID {
+		return []*Task{}, nil
+	}
+	root := *task
+	root.Children = make([]*Task, 0)
+	nodes := map[string]*Task{taskID: &root}
+	for queue := []*Task{&root}; len(queue) > 0; queue = queue[1:] {
+		ids := make([]string, 0)
+		for _, t := range r.tasks {
+			if t.UserID == userID && t.ParentID == queue[0].ID && nodes[t.ID] == nil {
+				ids = append(ids, t.ID)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			t := r.tasks[id]
+			if len(includeStatus) > 0 && !containsTaskStatus(includeStatus, t.Status) {
+				continue
+			}
+			copied := *t
+			copied.Children = make([]*Task, 0)
+			nodes[id] = &copied
+			queue[0].Children = append(queue[0].Children, &copied)
+			queue = append(queue, &copied)
+		}
+	}
+	return []*Task{&root}, nil
+}
+
+func (r *memoryTaskRepo) ListTaskUserIDs(ctx context.Context) ([]string, error) {
+	seen := make(map[string]bool)
+	userIDs := make([]string, 0)
+	for _, task := range r.tasks {
+		if !seen[task.UserID] {
+			seen[task.UserID] = true
+			userIDs = append(userIDs, task.UserID)
+		}
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
+func (r *memoryTaskRepo) ListUserTasks(ctx context.Context, userID string) ([]*Task, error) {
+	tasks := make([]*Task, 0)
+	for _, task := range r.tasks {
+		if task.UserID == userID {
+			copied := *task
+			tasks = append(tasks, &copied)
+		}
+	}
+	return tasks, nil
+}
+
+func (r *memoryTaskRepo) UpdateTaskTreeFields(ctx context.Context, task *Task) error {
+	stored, ok := r.tasks[task.ID]
+	if !ok {
+		return nil
+	}
+	stored.ParentID = task.ParentID
+	stored.RootTaskID = task.RootTaskID
+	stored.TreeDepth = task.TreeDepth
+	stored.ChildrenCount = task.ChildrenCount
+	stored.HasChildren = task.HasChildren
+	return nil
+}
+
+func containsTaskStatus(statuses []TaskStatus, status TaskStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryTaskRepo) ListChecklistItems(ctx context.Context, taskID, userID string) ([]*ChecklistItem, error) {
+	items := make([]*ChecklistItem, 0)
+	for _, item := range r.checklists[taskID] {
+		copied := *item
+		items = append(items, &copied)
+	}
+	return items, nil
+}
+
+func (r *memoryTaskRepo) ReplaceChecklistItems(ctx context.Context, taskID, userID string, items []*ChecklistItem) error {
+	stored := make([]*ChecklistItem, len(items))
+	for i, item := range items {
+		copied := *item
+		stored[i] = &copied
+	}
+	r.checklists[taskID] = stored
+	return nil
+}
+
+func (r *memoryTaskRepo) CreateTimeEntry(ctx context.Context, entry *TimeEntry) error {
+	copied := *entry
+	r.timeEntries[entry.ID] = &copied
+	return nil
+}
+
+func (r *memoryTaskRepo) UpdateTimeEntry(ctx context.Context, entry *TimeEntry) error {
+	return r.CreateTimeEntry(ctx, entry)
+}
+
+func (r *memoryTaskRepo) DeleteTimeEntry(ctx context.Context, entryID, userID string) error {
+	delete(r.timeEntries, entryID)
+	return nil
+}
+
+func (r *memoryTaskRepo) GetTimeEntry(ctx context.Context, entryID, userID string) (*TimeEntry, error) {
+	entry, ok := r.timeEntries[entryID]
+	if !ok || entry.UserID != userID {
+		return nil, nil
+	}
+	copied := *entry
+	return &copied, nil
+}
+
+func (r *memoryTaskRepo) GetRunningTimeEntry(ctx context.Context, userID string) (*TimeEntry, error) {
+	for _, entry := range r.timeEntries {
+		if entry.UserID == userID && entry.IsRunning() {
+			copied := *entry
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryTaskRepo) ListTimeEntries(ctx context.Context, taskID, userID string) ([]*TimeEntry, error) {
+	entries := make([]*TimeEntry, 0)
+	for _, entry := range r.timeEntries {
+		if entry.TaskID == taskID && entry.UserID == userID {
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+	}
+	return entries, nil
+}
+
+func (r *memoryTaskRepo) CreateKeyResultCheckIn(ctx context.Context, checkIn *KeyResultCheckIn) error {
+	copied := *checkIn
+	r.checkIns = append(r.checkIns, &copied)
+	return nil
+}
+
+func (r *memoryTaskRepo) ListKeyResultCheckIns(ctx context.Context, taskID, userID string) ([]*KeyResultCheckIn, error) {
+	checkIns := make([]*KeyResultCheckIn, 0)
+	for _, checkIn := range r.checkIns {
+		if checkIn.TaskID == taskID && checkIn.UserID == userID {
+			copied := *checkIn
+			checkIns = append(checkIns, &copied)
+		}
+	}
+	sort.SliceStable(checkIns, func(i, j int) bool { return checkIns[i].CheckedAt.After(checkIns[j].CheckedAt) })
+	return checkIns, nil
+}
+
+func (r *memoryTaskRepo) GetLatestKeyResultCheckIn(ctx context.Context, taskID, userID string) (*KeyResultCheckIn, error) {
+	checkIns, _ := r.ListKeyResultCheckIns(ctx, taskID, userID)
+	if len(checkIns) == 0 {
+		return nil, nil
+	}
+	return checkIns[0], nil
+}
+
+func (r *memoryTaskRepo) ListTimeEntriesInRange(ctx context.Context, userID string, start, end time.Time) ([]*TimeEntry, error) {
+	entries := make([]*TimeEntry, 0)
+	for _, entry := range r.timeEntries {
+		if entry.UserID == userID && !entry.IsRunning() && !entry.StartedAt.Before(start) && entry.StartedAt.Before(end) {
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+	}
+	return entries, nil
+}
+
+func (r *memoryTaskRepo) RefreshTaskTimeSpent(ctx context.Context, taskID, userID string) error {
+	task, ok := r.tasks[taskID]
+	if !ok {
+		return nil
+	}
+	task.TimeSpent = 0
+	for _, entry := range r.timeEntries {
+		if entry.TaskID == taskID && !entry.IsRunning() {
+			task.TimeSpent += entry.Duration
+		}
+	}
+	rootTaskID := task.RootTaskID
+	if rootTaskID == "" {
+		rootTaskID = taskID
+	}
+	return r.RefreshTreeTimeSpent(ctx, rootTaskID, userID)
+}
+
+func (r *memoryTaskRepo) RefreshTreeTimeSpent(ctx context.Context, rootTaskID, userID string) error {
+	root, ok := r.tasks[rootTaskID]
+	if !ok {
+		return nil
+	}
+	root.TreeTimeSpent = 0
+	for _, task := range r.tasks {
+		if task.ID == rootTaskID || task.RootTaskID == rootTaskID {
+			root.TreeTimeSpent += task.TimeSpent
+		}
+	}
+	return nil
+}
+
+func (r *memoryTaskRepo) RefreshTreeEstimate(ctx context.Context, rootTaskID, userID string) error {
+	root, ok := r.tasks[rootTaskID]
+	if !ok {
+		return nil
+	}
+	root.TreeEstimate = 0
+	for _, task := range r.tasks {
+		if (task.ID == rootTaskID || task.RootTaskID == rootTaskID) && task.Status != TaskStatusCancelled {
+			root.TreeEstimate += task.Estimate
+		}
+	}
+	return nil
+}
+
+func (r *memoryTaskRepo) DeleteTask(ctx context.Context, taskID, userID string) error {
+	return r.trash([]string{taskID})
+}
+
+func (r *memoryTaskRepo) DeleteTaskSubtree(ctx context.Context, taskID, userID string) error {
+	ids := []string{taskID}
+	for i := 0; i < len(ids); i++ {
+		for _, task := range r.tasks {
+			if task.ParentID == ids[i] {
+				ids = append(ids, task.ID)
+			}
+		}
+	}
+	return r.trash(ids)
+}
+
+// trash 软删除：同一次删除的任务 DeletedAt 相同
+func (r *memoryTaskRepo) trash(ids []string) error {
+	now := time.Now()
+	for _, id := range ids {
+		if task, ok := r.tasks[id]; ok {
+			task.DeletedAt = &now
+			r.trashed[id] = task
+			delete(r.tasks, id)
+		}
+	}
+	return nil
+}
+
+func (r *memoryTaskRepo) ListTrashedTasks(ctx context.Context, userID string) ([]*Task, error) {
+	tasks := make([]*Task, 0)
+	for _, task := range r.trashed {
+		if task.UserID == userID {
+			copied := *task
+			tasks = append(tasks, &copied)
+		}
+	}
+	return tasks, nil
+}
+
+func (r *memoryTaskRepo) GetTrashedTask(ctx context.Context, taskID, userID string) (*Task, error) {
+	task, ok := r.trashed[taskID]
+	if !ok || task.UserID != userID {
+		return nil, nil
+	}
+	copied := *task
+	return &copied, nil
+}
+
+func (r *memoryTaskRepo) RestoreTaskSubtree(ctx context.Context, taskID, userID string) error {
+	root, ok := r.trashed[taskID]
+	if !ok {
+		return nil
+	}
+	deletedAt := *root.DeletedAt
+	ids := []string{taskID}
+	for i := 0; i < len(ids); i++ {
+		for _, task := range r.trashed {
+			if task.ParentID == ids[i] && task.DeletedAt.Equal(deletedAt) {
+				ids = append(ids, task.ID)
+			}
+		}
+	}
+	for _, id := range ids {
+		task := r.trashed[id]
+		task.DeletedAt = nil
+		r.tasks[id] = task
+		delete(r.trashed, id)
+	}
+	return nil
+}
+
+func (r *memoryTaskRepo) PurgeTrashedTasks(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	for id, task := range r.trashed {
+		if task.DeletedAt.Before(before) {
+			delete(r.trashed, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *memoryTaskRepo) UpdateTreeOptimizationFields(ctx context.Context, taskID, userID string) error {
+	task, ok := r.tasks[taskID]
+	if !ok {
+		return nil
+	}
+	task.TreeDepth = 0
+	task.RootTaskID = task.ID
+	for parent := r.tasks[task.ParentID]; parent != nil; parent = r.tasks[parent.ParentID] {
+		task.TreeDepth++
+		task.RootTaskID = parent.ID
+	}
+	task.ChildrenCount = 0
+	for _, child := range r.tasks {
+		if child.ParentID == taskID {
+			task.ChildrenCount++
+		}
+	}
+	task.HasChildren = task.ChildrenCount > 0
+	return nil
+}
+
+func (r *memoryTaskRepo) RebuildSubtreeOptimizationFields(ctx context.Context, taskID, userID string) error {
+	ids := []string{taskID}
+	for i := 0; i < len(ids); i++ {
+		if err := r.UpdateTreeOptimizationFields(ctx, ids[i], userID); err != nil {
+			return err
+		}
+		for _, task := range r.tasks {
+			if task.ParentID == ids[i] {
+				ids = append(ids, task.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// newTestTask 用户 user-123 在 day 所在周期的任务，ID 兼作标题
+// parent 不为空时挂在 parent 下并同步双方的树字段，否则为根任务
+func newTestTask(id string, periodType PeriodType, day time.Time, status TaskStatus, parent *Task) *Task {
+	task := &Task{
+		ID: id, UserID: "user-123", Title: id, TaskType: periodType, Status: status,
+		TimePeriod: NewPeriodFromPeriodType(periodType, day), RootTaskID: id,
+	}
+	if parent != nil {
+		task.ParentID, task.RootTaskID, task.TreeDepth = parent.ID, parent.RootTaskID, parent.TreeDepth+1
+		parent.HasChildren = true
+		parent.ChildrenCount++
+	}
+	return task
+}
@@ -15,6 +15,15 @@ const (
 	PeriodYear
 )
 
+// PeriodMatch 按时间段查询时，记录的时间段与查询时间段的匹配方式
+type PeriodMatch int
+
+const (
+	PeriodMatchContained      PeriodMatch = iota // 完全落在查询时间段内（默认）
+	PeriodMatchIntersecting                      // 与查询时间段有交集，例如跨月的周出现在两个月中
+	PeriodMatchStartingWithin                    // 开始时间落在查询时间段内
+)
+
 type Period struct {
 	Start time.Time `json:"start"`
 	End   time.Time `json:"end"`
@@ -104,6 +113,31 @@ func (p Period) ContainsTime(t time.Time) bool {
 	return (t.Equal(p.Start) || t.After(p.Start)) && t.Before(p.End)
 }
 
+// 判断当前Period是否与另一个Period有交集（左闭右开，首尾相接不算）
+func (p Period) Intersects(ref Period) bool {
+	if !p.IsValid() || !ref.IsValid() {
+		return false
+	}
+	return p.Start.Before(ref.End) && p.End.After(ref.Start)
+}
+
+// IsValid 是否为支持的匹配方式
+func (m PeriodMatch) IsValid() bool {
+	return m >= PeriodMatchContained && m <= PeriodMatchStartingWithin
+}
+
+// Matches 时间段 p 是否按匹配方式命中查询时间段 ref
+func (m PeriodMatch) Matches(p, ref Period) bool {
+	switch m {
+	case PeriodMatchIntersecting:
+		return p.Intersects(ref)
+	case PeriodMatchStartingWithin:
+		return ref.ContainsTime(p.Start)
+	default:
+		return p.IsWithin(ref)
+	}
+}
+
 // 自动检测当前Period的周期类型
 func (p Period) DetectType() PeriodType {
 	if !p.IsValid() {
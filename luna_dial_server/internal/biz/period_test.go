@@ -741,6 +741,46 @@ func TestPeriod_ContainsTime(t *testing.T) {
 	}
 }
 
+// 测试 PeriodMatch.Matches 方法：跨月的周与月份的三种匹配方式
+func TestPeriodMatch_Matches(t *testing.T) {
+	utc := time.UTC
+
+	january := NewPeriodFromPeriodType(PeriodMonth, time.Date(2025, 1, 1, 0, 0, 0, 0, utc))
+	february := NewPeriodFromPeriodType(PeriodMonth, time.Date(2025, 2, 1, 0, 0, 0, 0, utc))
+	straddling := NewPeriodFromPeriodType(PeriodWeek, time.Date(2025, 1, 29, 0, 0, 0, 0, utc)) // 2025-01-27 ~ 2025-02-03
+	inside := NewPeriodFromPeriodType(PeriodWeek, time.Date(2025, 1, 15, 0, 0, 0, 0, utc))
+	adjacent := Period{Start: january.End, End: january.End.AddDate(0, 0, 1)} // 首尾相接
+
+	tests := []struct {
+		name   string
+		match  PeriodMatch
+		period Period
+		ref    Period
+		want   bool
+	}{
+		{name: "contained - 月内的周", match: PeriodMatchContained, period: inside, ref: january, want: true},
+		{name: "contained - 跨月的周不属于任何一个月", match: PeriodMatchContained, period: straddling, ref: january, want: false},
+		{name: "intersecting - 跨月的周属于一月", match: PeriodMatchIntersecting, period: straddling, ref: january, want: true},
+		{name: "intersecting - 跨月的周属于二月", match: PeriodMatchIntersecting, period: straddling, ref: february, want: true},
+		{name: "intersecting - 首尾相接不算交集", match: PeriodMatchIntersecting, period: adjacent, ref: january, want: false},
+		{name: "starting_within - 跨月的周只属于开始的月份", match: PeriodMatchStartingWithin, period: straddling, ref: january, want: true},
+		{name: "starting_within - 开始时间不在二月", match: PeriodMatchStartingWithin, period: straddling, ref: february, want: false},
+		{name: "starting_within - 开始时间等于结束时间（右开）", match: PeriodMatchStartingWithin, period: adjacent, ref: january, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.match.Matches(tt.period, tt.ref); got != tt.want {
+				t.Errorf("PeriodMatch.Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if PeriodMatch(3).IsValid() {
+		t.Error("PeriodMatch(3).IsValid() = true, want false")
+	}
+}
+
 // 测试 Period.DetectType 方法
 func TestPeriod_DetectType(t *testing.T) {
 	utc := time.UTC
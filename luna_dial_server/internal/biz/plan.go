@@ -10,13 +10,25 @@ type Plan struct {
 	PlanType      PeriodType  `json:"plan_type"`
 	PlanPeriod    Period      `json:"plan_period"`
 	ScoreTotal    int         `json:"score_total"`
+	TimeSpent     int64       `json:"time_spent"`     // 计划时间段内记录的总工时（秒）
+	CarriedOver   int         `json:"carried_over"`   // 计划时间段内从之前时间段顺延来的日任务数
+	Completed     int         `json:"completed"`      // 计划时间段内已完成的日任务数
+	CompletedLate int         `json:"completed_late"` // 其中在任务时间段结束之后才完成的数量
 	GroupStats    []GroupStat `json:"group_stats"`
+
+	// 关键结果：计划中关键结果的数量，以及它们进度（达成率截断到 0-100）的平均值
+	KeyResults        int     `json:"key_results"`
+	KeyResultProgress float64 `json:"key_result_progress"`
 }
 
 type GroupStat struct {
-	GroupKey   string `json:"group_key"`   // 分组键：日(2025-01-15)、周(2025-W03)、月(2025-01)、季度(2025-Q1)、年(2025)
-	TaskCount  int    `json:"task_count"`  // 该分组内的任务总数
-	ScoreTotal int    `json:"score_total"` // 该分组内的分数总和
+	GroupKey      string `json:"group_key"`      // 分组键：日(2025-01-15)、周(2025-W03)、月(2025-01)、季度(2025-Q1)、年(2025)
+	TaskCount     int    `json:"task_count"`     // 该分组内的任务总数
+	ScoreTotal    int    `json:"score_total"`    // 该分组内的分数总和
+	TimeSpent     int64  `json:"time_spent"`     // 该分组内记录的工时总和（秒），按工时记录的开始时间分组
+	CarriedOver   int    `json:"carried_over"`   // 该分组内从之前时间段顺延来的任务数
+	Completed     int    `json:"completed"`      // 该分组内已完成的任务数
+	CompletedLate int    `json:"completed_late"` // 该分组内在时间段结束之后才完成的任务数（按 completed_at 判断）
 }
 
 // 获取指定时间的计划参数
@@ -24,6 +36,7 @@ type GetPlanByPeriodParam struct {
 	UserID  string
 	Period  Period
 	GroupBy PeriodType
+	Match   PeriodMatch // 任务和日志时间段与 Period 的匹配方式，接口默认为 PeriodMatchIntersecting
 }
 
 type GetPlanStatsParam struct {
@@ -67,15 +80,21 @@ func (uc *PlanUsecase) GetPlanByPeriod(ctx context.Context, param GetPlanByPerio
 	}
 
 	// 获取统计信息
-	groupStats, err := uc.GetPlanStats(ctx, GetPlanStatsParam(param))
+	groupStats, err := uc.GetPlanStats(ctx, GetPlanStatsParam{UserID: param.UserID, Period: param.Period, GroupBy: param.GroupBy})
 	if err != nil {
 		return nil, err
 	}
 
 	// 计算总分数
 	var scoreTotal int
+	var timeSpent int64
+	var carriedOver, completed, completedLate int
 	for _, stat := range groupStats {
 		scoreTotal += stat.ScoreTotal
+		timeSpent += stat.TimeSpent
+		carriedOver += stat.CarriedOver
+		completed += stat.Completed
+		completedLate += stat.CompletedLate
 	}
 
 	plan := &Plan{
@@ -86,8 +105,13 @@ func (uc *PlanUsecase) GetPlanByPeriod(ctx context.Context, param GetPlanByPerio
 		PlanType:      param.GroupBy,
 		PlanPeriod:    param.Period,
 		ScoreTotal:    scoreTotal,
+		TimeSpent:     timeSpent,
+		CarriedOver:   carriedOver,
+		Completed:     completed,
+		CompletedLate: completedLate,
 		GroupStats:    groupStats,
 	}
+	plan.KeyResults, plan.KeyResultProgress = keyResultSummary(taskPointers)
 
 	return plan, nil
 }
@@ -104,3 +128,48 @@ func (uc *PlanUsecase) GetPlanStats(ctx context.Context, param GetPlanStatsParam
 	// 直接调用TaskUsecase的GetTaskStats方法
 	return uc.taskUsecase.GetTaskStats(ctx, GetTaskStatsParam(param))
 }
+
+// 获取指定时间的计划与完成工作量对比
+func (uc *PlanUsecase) GetPlanEffort(ctx context.Context, param GetTaskEffortParam) ([]EffortStat, error) {
+	if param.UserID == "" {
+		return nil, ErrNoPermission
+	}
+	if !param.Period.IsValid() {
+		return nil, ErrPlanPeriodInvalid
+	}
+	return uc.taskUsecase.GetTaskEffort(ctx, param)
+}
+
+// 获取指定日期的日程：时间段包含该日期的各层级任务（附带祖先链）和日志
+func (uc *PlanUsecase) GetAgenda(ctx context.Context, param GetAgendaParam) (*Agenda, error) {
+	if param.UserID == "" {
+		return nil, ErrNoPermission
+	}
+
+	groups, err := uc.taskUsecase.ListAgendaTasks(ctx, param.UserID, param.Date)
+	if err != nil {
+		return nil, err
+	}
+	journals, err := uc.journalUsecase.ListJournalsOnDate(ctx, param.UserID, param.Date)
+	if err != nil {
+		return nil, err
+	}
+	return &Agenda{Date: param.Date, Groups: groups, Journals: journals}, nil
+}
+
+// keyResultSummary 统计任务中的关键结果数量和平均进度
+func keyResultSummary(tasks []*Task) (int, float64) {
+	count := 0
+	var progress float64
+	for _, task := range tasks {
+		if task.KeyResult == nil {
+			continue
+		}
+		count++
+		progress += task.KeyResult.Progress()
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return count, roundRollup(progress / float64(count))
+}
@@ -15,8 +15,8 @@ func createTestPlanUsecase() *PlanUsecase {
 	taskRepo := &mockTaskRepo{}
 	journalRepo := &mockJournalRepo{}
 
-	taskUsecase := NewTaskUsecase(taskRepo)
-	journalUsecase := NewJournalUsecase(journalRepo)
+	taskUsecase := NewTaskUsecase(taskRepo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+	journalUsecase := NewJournalUsecase(journalRepo, newMockChangeHistoryRepo())
 
 	return NewPlanUsecase(taskUsecase, journalUsecase)
 }
@@ -33,6 +33,9 @@ func (m *mockTaskRepo) UpdateTask(ctx context.Context, task *Task) error {
 func (m *mockTaskRepo) DeleteTask(ctx context.Context, taskID, userID string) error {
 	return nil
 }
+func (m *mockTaskRepo) DeleteTaskSubtree(ctx context.Context, taskID, userID string) error {
+	return nil
+}
 func (m *mockTaskRepo) GetTask(ctx context.Context, taskID, userID string) (*Task, error) {
 	// 模拟一些测试数据
 	if taskID == "task-123" && userID == "user-123" {
@@ -92,7 +95,23 @@ func (m *mockTaskRepo) GetTask(ctx context.Context, taskID, userID string) (*Tas
 	}
 	return nil, ErrTaskNotFound
 }
-func (m *mockTaskRepo) ListTasks(ctx context.Context, userID string, periodStart, periodEnd time.Time, taskType int) ([]*Task, error) {
+func (m *mockTaskRepo) ListChildTasks(ctx context.Context, parentID, userID string) ([]*Task, error) {
+	// 模拟返回直接子任务
+	if parentID == "parent-task-123" && userID == "user-123" {
+		return []*Task{
+			{
+				ID:       "child-task-123",
+				Title:    "子任务",
+				UserID:   userID,
+				TaskType: PeriodDay,
+				ParentID: "parent-task-123",
+				Score:    30,
+			},
+		}, nil
+	}
+	return []*Task{}, nil
+}
+func (m *mockTaskRepo) ListTasks(ctx context.Context, userID string, periodStart, periodEnd time.Time, taskType int, match PeriodMatch) ([]*Task, error) {
 	// 模拟返回一些测试任务
 	if userID == "user-123" {
 		return []*Task{
@@ -217,6 +236,22 @@ func (m *mockTaskRepo) ListTasksByRootIDs(ctx context.Context, userID string, ro
 	return []*Task{}, nil
 }
 
+func (m *mockTaskRepo) ListTaskUserIDs(ctx context.Context) ([]string, error) {
+	return []string{}, nil
+}
+
+func (m *mockTaskRepo) ListUserTasks(ctx context.Context, userID string) ([]*Task, error) {
+	return []*Task{}, nil
+}
+
+func (m *mockTaskRepo) UpdateTaskTreeFields(ctx context.Context, task *Task) error {
+	return nil
+}
+
+func (m *mockTaskRepo) ListSubtreeTasks(ctx context.Context, taskID, userID string, includeStatus []TaskStatus) ([]*Task, error) {
+	return []*Task{}, nil
+}
+
 func (m *mockTaskRepo) GetCompleteTaskTree(ctx context.Context, taskID, userID string, includeStatus []TaskStatus) ([]*Task, error) {
 	// 模拟获取完整任务树
 	if taskID == "root-task-1" && userID == "user-123" {
@@ -231,6 +266,12 @@ func (m *mockTaskRepo) GetCompleteTaskTree(ctx context.Context, taskID, userID s
 
 func (m *mockTaskRepo) GetTaskParentChain(ctx context.Context, taskID, userID string) ([]*Task, error) {
 	// 模拟获取父任务链
+	if taskID == "child-task-123" && userID == "user-123" {
+		return []*Task{
+			{ID: "parent-task-123", Title: "父任务", TreeDepth: 0},
+			{ID: "child-task-123", Title: "子任务", TreeDepth: 1, ParentID: "parent-task-123"},
+		}, nil
+	}
 	if taskID == "child-2" && userID == "user-123" {
 		return []*Task{
 			{ID: "root-task-1", Title: "2024年度目标", TreeDepth: 0},
@@ -245,13 +286,119 @@ func (m *mockTaskRepo) UpdateTreeOptimizationFields(ctx context.Context, taskID,
 	return nil
 }
 
+func (m *mockTaskRepo) RebuildSubtreeOptimizationFields(ctx context.Context, taskID, userID string) error {
+	// 模拟重建子树优化字段
+	return nil
+}
+
+func (m *mockTaskRepo) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// 模拟事务：直接执行
+	return fn(ctx)
+}
+
+func (m *mockTaskRepo) LockUserTasks(ctx context.Context, userID string) error {
+	return nil
+}
+
+func (m *mockTaskRepo) CreateTaskDependency(ctx context.Context, dependency *TaskDependency) error {
+	return nil
+}
+
+func (m *mockTaskRepo) DeleteTaskDependency(ctx context.Context, taskID, blockedByTaskID, userID string) error {
+	return nil
+}
+
+func (m *mockTaskRepo) ListTaskDependencies(ctx context.Context, userID string, includeTrashed bool) ([]*TaskDependency, error) {
+	return []*TaskDependency{}, nil
+}
+
+func (m *mockTaskRepo) ListTaskBlockers(ctx context.Context, taskID, userID string) ([]*Task, error) {
+	return []*Task{}, nil
+}
+
+func (m *mockTaskRepo) ListTaskDependents(ctx context.Context, taskID, userID string) ([]*Task, error) {
+	return []*Task{}, nil
+}
+
+func (m *mockTaskRepo) ListChecklistItems(ctx context.Context, taskID, userID string) ([]*ChecklistItem, error) {
+	return []*ChecklistItem{}, nil
+}
+
+func (m *mockTaskRepo) ReplaceChecklistItems(ctx context.Context, taskID, userID string, items []*ChecklistItem) error {
+	return nil
+}
+
+func (m *mockTaskRepo) CreateTimeEntry(ctx context.Context, entry *TimeEntry) error {
+	return nil
+}
+
+func (m *mockTaskRepo) UpdateTimeEntry(ctx context.Context, entry *TimeEntry) error {
+	return nil
+}
+
+func (m *mockTaskRepo) DeleteTimeEntry(ctx context.Context, entryID, userID string) error {
+	return nil
+}
+
+func (m *mockTaskRepo) GetTimeEntry(ctx context.Context, entryID, userID string) (*TimeEntry, error) {
+	return nil, nil
+}
+
+func (m *mockTaskRepo) GetRunningTimeEntry(ctx context.Context, userID string) (*TimeEntry, error) {
+	return nil, nil
+}
+
+func (m *mockTaskRepo) ListTimeEntries(ctx context.Context, taskID, userID string) ([]*TimeEntry, error) {
+	return []*TimeEntry{}, nil
+}
+
+func (m *mockTaskRepo) ListTimeEntriesInRange(ctx context.Context, userID string, start, end time.Time) ([]*TimeEntry, error) {
+	return []*TimeEntry{}, nil
+}
+
+func (m *mockTaskRepo) RefreshTaskTimeSpent(ctx context.Context, taskID, userID string) error {
+	return nil
+}
+
+func (m *mockTaskRepo) RefreshTreeTimeSpent(ctx context.Context, rootTaskID, userID string) error {
+	return nil
+}
+
+func (m *mockTaskRepo) RefreshTreeEstimate(ctx context.Context, rootTaskID, userID string) error {
+	return nil
+}
+
+func (m *mockTaskRepo) ListTrashedTasks(ctx context.Context, userID string) ([]*Task, error) {
+	return []*Task{}, nil
+}
+
+func (m *mockTaskRepo) GetTrashedTask(ctx context.Context, taskID, userID string) (*Task, error) {
+	return nil, nil
+}
+
+func (m *mockTaskRepo) RestoreTaskSubtree(ctx context.Context, taskID, userID string) error {
+	return nil
+}
+
+func (m *mockTaskRepo) PurgeTrashedTasks(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockTaskRepo) ListTasksInPeriod(ctx context.Context, userID string, periodStart, periodEnd time.Time) ([]*Task, error) {
+	return []*Task{}, nil
+}
+
+func (m *mockTaskRepo) ListTasksContainingTime(ctx context.Context, userID string, t time.Time) ([]*Task, error) {
+	return []*Task{}, nil
+}
+
 // 测试 NewPlanUsecase 构造函数
 func TestNewPlanUsecase(t *testing.T) {
 	taskRepo := &mockTaskRepo{}
 	journalRepo := &mockJournalRepo{}
 
-	taskUsecase := NewTaskUsecase(taskRepo)
-	journalUsecase := NewJournalUsecase(journalRepo)
+	taskUsecase := NewTaskUsecase(taskRepo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+	journalUsecase := NewJournalUsecase(journalRepo, newMockChangeHistoryRepo())
 
 	planUsecase := NewPlanUsecase(taskUsecase, journalUsecase)
 
@@ -679,3 +826,31 @@ func TestPlanUsecase_EdgeCases(t *testing.T) {
 		// 可能是 ErrPeriodTooLarge 或类似错误
 	})
 }
+
+func (m *mockTaskRepo) GetSiblingSortRankRange(ctx context.Context, userID, parentID string) (int64, int64, error) {
+	return 0, 0, nil
+}
+
+func (m *mockTaskRepo) UpdateTaskSortRanks(ctx context.Context, userID string, ranks map[string]int64) error {
+	return nil
+}
+
+func (m *mockTaskRepo) UpdateTaskBoardRanks(ctx context.Context, userID string, ranks map[string]int64) error {
+	return nil
+}
+
+func (m *mockTaskRepo) UpdateTaskRollup(ctx context.Context, taskID, userID string, rollup TaskRollup) error {
+	return nil
+}
+
+func (m *mockTaskRepo) CreateKeyResultCheckIn(ctx context.Context, checkIn *KeyResultCheckIn) error {
+	return nil
+}
+
+func (m *mockTaskRepo) ListKeyResultCheckIns(ctx context.Context, taskID, userID string) ([]*KeyResultCheckIn, error) {
+	return []*KeyResultCheckIn{}, nil
+}
+
+func (m *mockTaskRepo) GetLatestKeyResultCheckIn(ctx context.Context, taskID, userID string) (*KeyResultCheckIn, error) {
+	return nil, nil
+}
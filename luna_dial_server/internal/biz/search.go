@@ -0,0 +1,305 @@
+package biz
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SearchEntityKind 搜索结果的实体类型
+type SearchEntityKind string
+
+const (
+	SearchEntityTask    SearchEntityKind = "task"
+	SearchEntityJournal SearchEntityKind = "journal"
+)
+
+const (
+	searchMaxTerms      = 10  // 查询最多使用的关键词数
+	searchSnippetRadius = 30  // 摘要中关键词前后保留的字符数
+	searchSnippetMax    = 120 // 没有命中关键词时摘要的最大字符数
+	searchMaxPageSize   = 100
+)
+
+// 摘要中关键词的高亮标记
+const (
+	SearchHighlightStart = "<mark>"
+	SearchHighlightEnd   = "</mark>"
+)
+
+// 搜索参数
+type SearchParam struct {
+	UserID     string
+	Query      string
+	Kinds      []SearchEntityKind // 为空时搜索任务和日志
+	PeriodType *PeriodType        // 任务类型或日志类型
+	StartDate  *time.Time         // 时间段开始时间在 [StartDate, EndDate) 内
+	EndDate    *time.Time
+	Statuses   []TaskStatus // 只作用于任务，指定后不返回日志
+	Page       int
+	PageSize   int
+}
+
+// SearchQuery 仓库层的搜索条件
+type SearchQuery struct {
+	UserID     string
+	Query      string   // 原始查询，用于全文检索
+	Terms      []string // 拆分后的关键词，用于中文等无法分词内容的子串匹配
+	PeriodType *PeriodType
+	StartDate  *time.Time
+	EndDate    *time.Time
+	Statuses   []TaskStatus
+	Limit      int
+}
+
+// SearchHit 一条搜索结果，Task 和 Journal 只有一个不为空
+type SearchHit struct {
+	Kind    SearchEntityKind `json:"kind"`
+	Rank    float64          `json:"rank"`
+	Snippet string           `json:"snippet"` // 命中的关键词用 <mark></mark> 包裹，其余内容已做 HTML 转义
+	Task    *Task            `json:"task,omitempty"`
+	Journal *Journal         `json:"journal,omitempty"`
+}
+
+type SearchUsecase struct {
+	repo SearchRepo
+}
+
+func NewSearchUsecase(repo SearchRepo) *SearchUsecase {
+	return &SearchUsecase{repo: repo}
+}
+
+// searchTerms 按空白拆分查询并去重，忽略大小写
+func searchTerms(query string) []string {
+	terms := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, field := range strings.Fields(query) {
+		key := strings.ToLower(field)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		terms = append(terms, field)
+		if len(terms) == searchMaxTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// 搜索任务标题、标签以及日志标题、内容，结果按相关度降序分页
+// 任务和日志分别查询前 Page*PageSize 条后合并排序，总数为两者之和
+func (uc *SearchUsecase) Search(ctx context.Context, param SearchParam) ([]*SearchHit, int64, error) {
+	if param.UserID == "" {
+		return nil, 0, ErrUserIDEmpty
+	}
+	terms := searchTerms(param.Query)
+	if len(terms) == 0 {
+		return nil, 0, ErrInvalidInput
+	}
+	if param.StartDate != nil && param.EndDate != nil && !param.StartDate.Before(*param.EndDate) {
+		return nil, 0, ErrInvalidPeriod
+	}
+	if param.Page <= 0 {
+		param.Page = 1
+	}
+	if param.PageSize <= 0 || param.PageSize > searchMaxPageSize {
+		param.PageSize = 20
+	}
+
+	searchTask, searchJournal := len(param.Kinds) == 0, len(param.Kinds) == 0
+	for _, kind := range param.Kinds {
+		switch kind {
+		case SearchEntityTask:
+			searchTask = true
+		case SearchEntityJournal:
+			searchJournal = true
+		default:
+			return nil, 0, ErrInvalidInput
+		}
+	}
+	if len(param.Statuses) > 0 {
+		searchJournal = false
+	}
+
+	query := SearchQuery{
+		UserID:     param.UserID,
+		Query:      strings.Join(terms, " "),
+		Terms:      terms,
+		PeriodType: param.PeriodType,
+		StartDate:  param.StartDate,
+		EndDate:    param.EndDate,
+		Statuses:   param.Statuses,
+		Limit:      param.Page * param.PageSize,
+	}
+
+	hits := make([]*SearchHit, 0)
+	var total int64
+	if searchTask {
+		taskHits, count, err := uc.repo.SearchTasks(ctx, query)
+		if err != nil {
+			return nil, 0, err
+		}
+		hits = append(hits, taskHits...)
+		total += count
+	}
+	if searchJournal {
+		journalHits, count, err := uc.repo.SearchJournals(ctx, query)
+		if err != nil {
+			return nil, 0, err
+		}
+		hits = append(hits, journalHits...)
+		total += count
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Rank > hits[j].Rank
+	})
+	start := (param.Page - 1) * param.PageSize
+	if start >= len(hits) {
+		return []*SearchHit{}, total, nil
+	}
+	end := start + param.PageSize
+	if end > len(hits) {
+		end = len(hits)
+	}
+	page := hits[start:end]
+	for _, hit := range page {
+		hit.Snippet = hitSnippet(hit, terms)
+	}
+	return page, total, nil
+}
+
+// hitSnippet 任务取标题和标签，日志优先取命中关键词的内容，内容未命中时取标题
+func hitSnippet(hit *SearchHit, terms []string) string {
+	if hit.Task != nil {
+		text := hit.Task.Title
+		if len(hit.Task.Tags) > 0 {
+			text += " #" + strings.Join(hit.Task.Tags, " #")
+		}
+		return BuildSnippet(text, terms)
+	}
+	if hit.Journal != nil {
+		if indexOfAnyTerm(hit.Journal.Content, terms) >= 0 || indexOfAnyTerm(hit.Journal.Title, terms) < 0 {
+			return BuildSnippet(hit.Journal.Content, terms)
+		}
+		return BuildSnippet(hit.Journal.Title, terms)
+	}
+	return ""
+}
+
+// indexOfAnyTerm 第一个命中的关键词在文本中的字符（rune）位置，没有命中返回 -1
+func indexOfAnyTerm(text string, terms []string) int {
+	lower := []rune(strings.ToLower(text))
+	best := -1
+	for _, term := range terms {
+		if i := runeIndex(lower, []rune(strings.ToLower(term))); i >= 0 && (best < 0 || i < best) {
+			best = i
+		}
+	}
+	return best
+}
+
+func runeIndex(text, sub []rune) int {
+	if len(sub) == 0 {
+		return -1
+	}
+	for i := 0; i+len(sub) <= len(text); i++ {
+		match := true
+		for j := range sub {
+			if text[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// BuildSnippet 截取第一个命中关键词附近的文本，并高亮其中所有命中的关键词（忽略大小写）
+// 按字符截取，不会截断中文；截断处用省略号表示
+func BuildSnippet(text string, terms []string) string {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return ""
+	}
+
+	start, end := 0, len(runes)
+	if first := indexOfAnyTerm(text, terms); first >= 0 {
+		start = first - searchSnippetRadius
+		end = first + searchSnippetRadius*3
+	} else {
+		end = searchSnippetMax
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	window := runes[start:end]
+	lower := []rune(strings.ToLower(string(window)))
+	// 大小写转换可能改变字符数（极少见），此时不高亮，避免位置错乱
+	highlight := make([]bool, len(window))
+	if len(lower) == len(window) {
+		for _, term := range terms {
+			termRunes := []rune(strings.ToLower(term))
+			for i := 0; i+len(termRunes) <= len(lower); {
+				j := runeIndex(lower[i:], termRunes)
+				if j < 0 {
+					break
+				}
+				for k := i + j; k < i+j+len(termRunes); k++ {
+					highlight[k] = true
+				}
+				i += j + len(termRunes)
+			}
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i, r := range window {
+		if highlight[i] && (i == 0 || !highlight[i-1]) {
+			b.WriteString(SearchHighlightStart)
+		}
+		b.WriteString(escapeSnippetRune(r))
+		if highlight[i] && (i == len(window)-1 || !highlight[i+1]) {
+			b.WriteString(SearchHighlightEnd)
+		}
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// escapeSnippetRune 摘要中包含高亮标签，其余内容需要做 HTML 转义
+func escapeSnippetRune(r rune) string {
+	switch r {
+	case '<':
+		return "&lt;"
+	case '>':
+		return "&gt;"
+	case '&':
+		return "&amp;"
+	case '"':
+		return "&#34;"
+	case '\'':
+		return "&#39;"
+	case '\n', '\r', '\t':
+		return " "
+	}
+	if r == utf8.RuneError {
+		return ""
+	}
+	return string(r)
+}
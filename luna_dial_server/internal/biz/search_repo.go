@@ -0,0 +1,10 @@
+package biz
+
+import "context"
+
+type SearchRepo interface {
+	// SearchTasks 按相关度降序返回匹配的任务（最多 Limit 条）和匹配总数
+	SearchTasks(ctx context.Context, query SearchQuery) ([]*SearchHit, int64, error)
+	// SearchJournals 按相关度降序返回匹配的日志（最多 Limit 条）和匹配总数
+	SearchJournals(ctx context.Context, query SearchQuery) ([]*SearchHit, int64, error)
+}
@@ -0,0 +1,134 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSearchRepo 返回固定的结果，并记录收到的查询条件
+type mockSearchRepo struct {
+	taskHits    []*SearchHit
+	journalHits []*SearchHit
+	queries     map[SearchEntityKind]SearchQuery
+}
+
+func newMockSearchRepo() *mockSearchRepo {
+	return &mockSearchRepo{queries: make(map[SearchEntityKind]SearchQuery)}
+}
+
+func (m *mockSearchRepo) SearchTasks(ctx context.Context, query SearchQuery) ([]*SearchHit, int64, error) {
+	m.queries[SearchEntityTask] = query
+	return m.taskHits, int64(len(m.taskHits)), nil
+}
+
+func (m *mockSearchRepo) SearchJournals(ctx context.Context, query SearchQuery) ([]*SearchHit, int64, error) {
+	m.queries[SearchEntityJournal] = query
+	return m.journalHits, int64(len(m.journalHits)), nil
+}
+
+func TestSearchUsecase_Search(t *testing.T) {
+	ctx := context.Background()
+	newRepo := func() *mockSearchRepo {
+		repo := newMockSearchRepo()
+		repo.taskHits = []*SearchHit{
+			{Kind: SearchEntityTask, Rank: 0.9, Task: &Task{ID: "t1", Title: "整理项目文档", Tags: []string{"工作"}}},
+			{Kind: SearchEntityTask, Rank: 0.2, Task: &Task{ID: "t2", Title: "项目复盘"}},
+		}
+		repo.journalHits = []*SearchHit{
+			{Kind: SearchEntityJournal, Rank: 0.5, Journal: &Journal{ID: "j1", Title: "周记", Content: "这周主要在推进项目上线"}},
+		}
+		return repo
+	}
+
+	t.Run("合并任务和日志并按相关度分页", func(t *testing.T) {
+		repo := newRepo()
+		usecase := NewSearchUsecase(repo)
+
+		hits, total, err := usecase.Search(ctx, SearchParam{UserID: "user-123", Query: " 项目  项目 ", Page: 1, PageSize: 2})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		require.Len(t, hits, 2)
+		assert.Equal(t, "t1", hits[0].Task.ID)
+		assert.Equal(t, "j1", hits[1].Journal.ID)
+		assert.Equal(t, "整理<mark>项目</mark>文档 #工作", hits[0].Snippet)
+		assert.Equal(t, "这周主要在推进<mark>项目</mark>上线", hits[1].Snippet)
+		assert.Equal(t, []string{"项目"}, repo.queries[SearchEntityTask].Terms, "terms are deduplicated")
+		assert.Equal(t, 2, repo.queries[SearchEntityTask].Limit)
+
+		hits, _, err = usecase.Search(ctx, SearchParam{UserID: "user-123", Query: "项目", Page: 2, PageSize: 2})
+		require.NoError(t, err)
+		require.Len(t, hits, 1)
+		assert.Equal(t, "t2", hits[0].Task.ID)
+	})
+
+	t.Run("按状态过滤时不返回日志", func(t *testing.T) {
+		repo := newRepo()
+		usecase := NewSearchUsecase(repo)
+
+		hits, total, err := usecase.Search(ctx, SearchParam{UserID: "user-123", Query: "项目", Statuses: []TaskStatus{TaskStatusCompleted}})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Len(t, hits, 2)
+		assert.NotContains(t, repo.queries, SearchEntityJournal)
+	})
+
+	t.Run("只搜索日志", func(t *testing.T) {
+		repo := newRepo()
+		usecase := NewSearchUsecase(repo)
+
+		hits, _, err := usecase.Search(ctx, SearchParam{UserID: "user-123", Query: "项目", Kinds: []SearchEntityKind{SearchEntityJournal}})
+
+		require.NoError(t, err)
+		require.Len(t, hits, 1)
+		assert.NotContains(t, repo.queries, SearchEntityTask)
+	})
+
+	t.Run("参数不合法", func(t *testing.T) {
+		usecase := NewSearchUsecase(newRepo())
+
+		_, _, err := usecase.Search(ctx, SearchParam{UserID: "user-123", Query: "   "})
+		assert.Equal(t, ErrInvalidInput, err)
+
+		_, _, err = usecase.Search(ctx, SearchParam{UserID: "user-123", Query: "项目", Kinds: []SearchEntityKind{"user"}})
+		assert.Equal(t, ErrInvalidInput, err)
+
+		start, end := date(2025, 2, 1), date(2025, 1, 1)
+		_, _, err = usecase.Search(ctx, SearchParam{UserID: "user-123", Query: "项目", StartDate: &start, EndDate: &end})
+		assert.Equal(t, ErrInvalidPeriod, err)
+	})
+}
+
+func TestBuildSnippet(t *testing.T) {
+	t.Run("高亮所有命中并忽略大小写", func(t *testing.T) {
+		assert.Equal(t, "<mark>Go</mark> 和 <mark>go</mark>lang", BuildSnippet("Go 和 golang", []string{"GO"}))
+	})
+
+	t.Run("截取命中位置附近的内容", func(t *testing.T) {
+		text := ""
+		for i := 0; i < 50; i++ {
+			text += "前"
+		}
+		text += "关键词"
+		for i := 0; i < 200; i++ {
+			text += "后"
+		}
+		snippet := BuildSnippet(text, []string{"关键词"})
+		assert.Contains(t, snippet, "<mark>关键词</mark>")
+		assert.True(t, len([]rune(snippet)) < 150)
+		assert.Equal(t, "…", string([]rune(snippet)[0]))
+		assert.Equal(t, "…", string([]rune(snippet)[len([]rune(snippet))-1]))
+	})
+
+	t.Run("转义其余的 HTML", func(t *testing.T) {
+		assert.Equal(t, "&lt;b&gt;<mark>a</mark>&lt;/b&gt;", BuildSnippet("<b>a</b>", []string{"a"}))
+	})
+
+	t.Run("没有命中时取开头", func(t *testing.T) {
+		assert.Equal(t, "没有命中", BuildSnippet("没有命中", []string{"项目"}))
+	})
+}
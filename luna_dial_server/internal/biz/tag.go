@@ -0,0 +1,325 @@
+package biz
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 标签名的最大字符数
+const MaxTagNameLength = 50
+
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Tag 标签，任务通过 task_tags 关联表引用标签
+// 任务创建或修改时按名称自动创建不存在的标签，颜色和描述可以之后再设置
+type Tag struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Name        string    `json:"name"`
+	Color       string    `json:"color"` // #RRGGBB，为空表示使用默认颜色
+	Description string    `json:"description"`
+	TaskCount   int       `json:"task_count"` // 使用该标签的任务数（不含回收站），只在列表中返回
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// 创建标签参数
+type CreateTagParam struct {
+	UserID      string
+	Name        string
+	Color       string
+	Description string
+}
+
+// 更新标签参数，只修改传递了的字段
+type UpdateTagParam struct {
+	TagID       string
+	UserID      string
+	Name        *string // 重命名，所有使用该标签的任务一起更新
+	Color       *string
+	Description *string
+}
+
+// 获取/删除标签参数
+type GetTagParam struct {
+	TagID  string
+	UserID string
+}
+
+// 合并标签参数
+type MergeTagsParam struct {
+	UserID   string
+	SourceID string // 合并后删除
+	TargetID string
+}
+
+// 按标签表达式查询任务参数
+type ListTasksByTagsParam struct {
+	UserID   string
+	Expr     string
+	Page     int
+	PageSize int
+}
+
+type TagUsecase struct {
+	repo        TagRepo
+	taskUsecase *TaskUsecase
+}
+
+func NewTagUsecase(repo TagRepo, taskUsecase *TaskUsecase) *TagUsecase {
+	return &TagUsecase{repo: repo, taskUsecase: taskUsecase}
+}
+
+// normalizeTagName 去除首尾空白并校验长度，标签名可以包含逗号，但不能包含换行
+func normalizeTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxTagNameLength || strings.ContainsAny(name, "\r\n") {
+		return "", ErrTagNameInvalid
+	}
+	return name, nil
+}
+
+// normalizeTags 规范化任务的标签列表：去除空白和空标签，按首次出现的顺序去重
+// 子任务继承父任务标签时，重复的标签只保留一个
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			continue
+		}
+		name, err := normalizeTagName(tag)
+		if err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized, nil
+}
+
+func validateTagColor(color string) error {
+	if color != "" && !tagColorPattern.MatchString(color) {
+		return ErrTagColorInvalid
+	}
+	return nil
+}
+
+// 获取用户的全部标签及使用次数
+func (uc *TagUsecase) ListTags(ctx context.Context, userID string) ([]*Tag, error) {
+	if userID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	return uc.repo.ListTags(ctx, userID)
+}
+
+// 创建标签，同名标签已存在时返回 ErrTagNameExists
+func (uc *TagUsecase) CreateTag(ctx context.Context, param CreateTagParam) (*Tag, error) {
+	if param.UserID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	name, err := normalizeTagName(param.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTagColor(param.Color); err != nil {
+		return nil, err
+	}
+	existing, err := uc.repo.GetTagByName(ctx, param.UserID, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrTagNameExists
+	}
+
+	now := time.Now()
+	tag := &Tag{
+		ID:          generateID(),
+		UserID:      param.UserID,
+		Name:        name,
+		Color:       param.Color,
+		Description: param.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := uc.repo.CreateTag(ctx, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// getTag 获取标签，不存在时返回 ErrTagNotFound
+func (uc *TagUsecase) getTag(ctx context.Context, tagID, userID string) (*Tag, error) {
+	if tagID == "" || userID == "" {
+		return nil, ErrInvalidInput
+	}
+	tag, err := uc.repo.GetTag(ctx, tagID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, ErrTagNotFound
+	}
+	return tag, nil
+}
+
+// 更新标签；重命名时新名称不能与其他标签重复（需要合并时使用 MergeTags）
+// 使用该标签的任务在同一个事务中更新，并记录任务的变更历史
+func (uc *TagUsecase) UpdateTag(ctx context.Context, param UpdateTagParam) (*Tag, error) {
+	tag, err := uc.getTag(ctx, param.TagID, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	oldName := tag.Name
+	if param.Name != nil {
+		name, err := normalizeTagName(*param.Name)
+		if err != nil {
+			return nil, err
+		}
+		if name != tag.Name {
+			existing, err := uc.repo.GetTagByName(ctx, param.UserID, name)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				return nil, ErrTagNameExists
+			}
+		}
+		tag.Name = name
+	}
+	if param.Color != nil {
+		if err := validateTagColor(*param.Color); err != nil {
+			return nil, err
+		}
+		tag.Color = *param.Color
+	}
+	if param.Description != nil {
+		tag.Description = *param.Description
+	}
+	tag.UpdatedAt = time.Now()
+
+	if oldName == tag.Name {
+		if err := uc.repo.UpdateTag(ctx, tag); err != nil {
+			return nil, err
+		}
+		return tag, nil
+	}
+
+	err = uc.taskUsecase.repo.Transaction(ctx, func(ctx context.Context) error {
+		return uc.withTagHistory(ctx, param.UserID, oldName, func(tags []string) []string {
+			return replaceTag(tags, oldName, tag.Name)
+		}, func(ctx context.Context) error {
+			return uc.repo.UpdateTag(ctx, tag)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// 把源标签合并到目标标签：使用源标签的任务改为使用目标标签，然后删除源标签
+func (uc *TagUsecase) MergeTags(ctx context.Context, param MergeTagsParam) (*Tag, error) {
+	if param.SourceID == param.TargetID {
+		return nil, ErrInvalidInput
+	}
+	source, err := uc.getTag(ctx, param.SourceID, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := uc.getTag(ctx, param.TargetID, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.taskUsecase.repo.Transaction(ctx, func(ctx context.Context) error {
+		return uc.withTagHistory(ctx, param.UserID, source.Name, func(tags []string) []string {
+			return replaceTag(tags, source.Name, target.Name)
+		}, func(ctx context.Context) error {
+			return uc.repo.MergeTags(ctx, param.UserID, source.ID, target.ID)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// 删除标签，同时从所有任务上移除
+func (uc *TagUsecase) DeleteTag(ctx context.Context, param GetTagParam) error {
+	tag, err := uc.getTag(ctx, param.TagID, param.UserID)
+	if err != nil {
+		return err
+	}
+
+	return uc.taskUsecase.repo.Transaction(ctx, func(ctx context.Context) error {
+		return uc.withTagHistory(ctx, param.UserID, tag.Name, func(tags []string) []string {
+			return replaceTag(tags, tag.Name, "")
+		}, func(ctx context.Context) error {
+			return uc.repo.DeleteTag(ctx, tag.ID, param.UserID)
+		})
+	})
+}
+
+// withTagHistory 执行标签操作，并为受影响的任务记录 tags 字段的变更，需要在事务中调用
+// 回收站中的任务由仓库层同步，不记录历史
+func (uc *TagUsecase) withTagHistory(ctx context.Context, userID, name string, change func([]string) []string, apply func(ctx context.Context) error) error {
+	affected, _, err := uc.repo.ListTasksByTagExpr(ctx, userID, &TagExpr{Op: TagExprName, Name: name}, 0, 0)
+	if err != nil {
+		return err
+	}
+	if err := apply(ctx); err != nil {
+		return err
+	}
+	for _, task := range affected {
+		after := *task
+		after.Tags = change(task.Tags)
+		if err := uc.taskUsecase.recordTaskChange(ctx, ChangeActionUpdate, task, &after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceTag 把标签列表中的 oldName 替换为 newName（为空表示移除），保持顺序并去重
+func replaceTag(tags []string, oldName, newName string) []string {
+	replaced := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if tag == oldName {
+			tag = newName
+		}
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		replaced = append(replaced, tag)
+	}
+	return replaced
+}
+
+// 按标签表达式分页查询任务
+func (uc *TagUsecase) ListTasksByTags(ctx context.Context, param ListTasksByTagsParam) ([]*Task, int64, error) {
+	if param.UserID == "" {
+		return nil, 0, ErrUserIDEmpty
+	}
+	expr, err := ParseTagExpr(param.Expr)
+	if err != nil {
+		return nil, 0, err
+	}
+	if param.Page <= 0 {
+		param.Page = 1
+	}
+	if param.PageSize <= 0 {
+		param.PageSize = 20
+	}
+	return uc.repo.ListTasksByTagExpr(ctx, param.UserID, expr, param.PageSize, (param.Page-1)*param.PageSize)
+}
@@ -0,0 +1,231 @@
+package biz
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 标签表达式的规模限制
+const (
+	maxTagExprNames = 20
+	maxTagExprDepth = 10
+)
+
+// TagExprOp 标签表达式节点类型
+type TagExprOp int
+
+const (
+	TagExprName TagExprOp = iota // 任务带有标签 Name
+	TagExprAnd
+	TagExprOr
+	TagExprNot
+)
+
+// TagExpr 标签表达式，例如 `工作 AND (紧急 OR 重要) AND NOT "已归档"`
+// 关键字 AND、OR、NOT 不区分大小写，相邻的两个标签之间省略 AND；
+// 包含空格、括号或与关键字同名的标签需要用双引号包裹
+type TagExpr struct {
+	Op       TagExprOp
+	Name     string
+	Children []*TagExpr
+}
+
+// Matches 判断标签列表是否满足表达式
+func (e *TagExpr) Matches(tags []string) bool {
+	switch e.Op {
+	case TagExprAnd:
+		for _, child := range e.Children {
+			if !child.Matches(tags) {
+				return false
+			}
+		}
+		return true
+	case TagExprOr:
+		for _, child := range e.Children {
+			if child.Matches(tags) {
+				return true
+			}
+		}
+		return false
+	case TagExprNot:
+		return !e.Children[0].Matches(tags)
+	default:
+		for _, tag := range tags {
+			if tag == e.Name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+type tagExprToken struct {
+	text   string
+	quoted bool
+}
+
+type tagExprParser struct {
+	tokens []tagExprToken
+	pos    int
+	names  int
+}
+
+// ParseTagExpr 解析标签表达式，单个标签名也是合法的表达式
+func ParseTagExpr(s string) (*TagExpr, error) {
+	tokens, err := tokenizeTagExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, ErrTagExprInvalid
+	}
+	p := &tagExprParser{tokens: tokens}
+	expr, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, ErrTagExprInvalid
+	}
+	return expr, nil
+}
+
+func tokenizeTagExpr(s string) ([]tagExprToken, error) {
+	tokens := make([]tagExprToken, 0)
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, tagExprToken{text: string(r)})
+			i++
+		case r == '"':
+			var b strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					b.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, ErrTagExprInvalid
+			}
+			tokens = append(tokens, tagExprToken{text: b.String(), quoted: true})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, tagExprToken{text: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
+
+func (p *tagExprParser) peekKeyword(keyword string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+	token := p.tokens[p.pos]
+	return !token.quoted && strings.EqualFold(token.text, keyword)
+}
+
+func (p *tagExprParser) parseOr(depth int) (*TagExpr, error) {
+	if depth > maxTagExprDepth {
+		return nil, ErrTagExprInvalid
+	}
+	first, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	children := []*TagExpr{first}
+	for p.peekKeyword("OR") {
+		p.pos++
+		next, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &TagExpr{Op: TagExprOr, Children: children}, nil
+}
+
+func (p *tagExprParser) parseAnd(depth int) (*TagExpr, error) {
+	first, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	children := []*TagExpr{first}
+	for p.pos < len(p.tokens) && !p.peekKeyword("OR") && !(p.tokens[p.pos].text == ")" && !p.tokens[p.pos].quoted) {
+		if p.peekKeyword("AND") {
+			p.pos++
+		}
+		next, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &TagExpr{Op: TagExprAnd, Children: children}, nil
+}
+
+func (p *tagExprParser) parseUnary(depth int) (*TagExpr, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, ErrTagExprInvalid
+	}
+	token := p.tokens[p.pos]
+	switch {
+	case p.peekKeyword("NOT"):
+		p.pos++
+		if depth+1 > maxTagExprDepth {
+			return nil, ErrTagExprInvalid
+		}
+		child, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &TagExpr{Op: TagExprNot, Children: []*TagExpr{child}}, nil
+	case !token.quoted && token.text == "(":
+		p.pos++
+		expr, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].quoted || p.tokens[p.pos].text != ")" {
+			return nil, ErrTagExprInvalid
+		}
+		p.pos++
+		return expr, nil
+	case !token.quoted && (token.text == ")" || p.peekKeyword("AND") || p.peekKeyword("OR")):
+		return nil, ErrTagExprInvalid
+	}
+
+	p.pos++
+	name, err := normalizeTagName(token.text)
+	if err != nil {
+		return nil, ErrTagExprInvalid
+	}
+	p.names++
+	if p.names > maxTagExprNames {
+		return nil, ErrTagExprInvalid
+	}
+	return &TagExpr{Op: TagExprName, Name: name}, nil
+}
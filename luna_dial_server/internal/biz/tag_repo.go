@@ -0,0 +1,21 @@
+package biz
+
+import "context"
+
+type TagRepo interface {
+	// ListTags 获取用户的全部标签，TaskCount 只统计未删除的任务
+	ListTags(ctx context.Context, userID string) ([]*Tag, error)
+	// GetTag 获取标签，不存在时返回 nil, nil
+	GetTag(ctx context.Context, tagID, userID string) (*Tag, error)
+	// GetTagByName 按名称获取标签，不存在时返回 nil, nil
+	GetTagByName(ctx context.Context, userID, name string) (*Tag, error)
+	CreateTag(ctx context.Context, tag *Tag) error
+	// UpdateTag 更新名称、颜色和描述，名称变化时同步任务上冗余的标签文本（包括回收站中的任务）
+	UpdateTag(ctx context.Context, tag *Tag) error
+	// MergeTags 把源标签的任务关联转到目标标签并删除源标签，同步任务上冗余的标签文本
+	MergeTags(ctx context.Context, userID, sourceID, targetID string) error
+	// DeleteTag 删除标签及其任务关联，同步任务上冗余的标签文本
+	DeleteTag(ctx context.Context, tagID, userID string) error
+	// ListTasksByTagExpr 按标签表达式分页查询未删除的任务，limit 为 0 时返回全部
+	ListTasksByTagExpr(ctx context.Context, userID string, expr *TagExpr, limit, offset int) ([]*Task, int64, error)
+}
@@ -0,0 +1,293 @@
+package biz
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTagRepo 在 memoryTaskRepo 的任务上维护标签，模拟 task_tags 关联和冗余文本的同步
+type memoryTagRepo struct {
+	taskRepo *memoryTaskRepo
+	tags     map[string]*Tag
+}
+
+func newMemoryTagRepo(taskRepo *memoryTaskRepo, tags ...*Tag) *memoryTagRepo {
+	repo := &memoryTagRepo{taskRepo: taskRepo, tags: make(map[string]*Tag)}
+	for _, tag := range tags {
+		repo.tags[tag.ID] = tag
+	}
+	return repo
+}
+
+func (r *memoryTagRepo) ListTags(ctx context.Context, userID string) ([]*Tag, error) {
+	result := make([]*Tag, 0)
+	for _, tag := range r.tags {
+		if tag.UserID != userID {
+			continue
+		}
+		copied := *tag
+		for _, task := range r.taskRepo.tasks {
+			if task.UserID == userID && (&TagExpr{Name: tag.Name}).Matches(task.Tags) {
+				copied.TaskCount++
+			}
+		}
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (r *memoryTagRepo) GetTag(ctx context.Context, tagID, userID string) (*Tag, error) {
+	tag, ok := r.tags[tagID]
+	if !ok || tag.UserID != userID {
+		return nil, nil
+	}
+	copied := *tag
+	return &copied, nil
+}
+
+func (r *memoryTagRepo) GetTagByName(ctx context.Context, userID, name string) (*Tag, error) {
+	for _, tag := range r.tags {
+		if tag.UserID == userID && tag.Name == name {
+			copied := *tag
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryTagRepo) CreateTag(ctx context.Context, tag *Tag) error {
+	copied := *tag
+	r.tags[tag.ID] = &copied
+	return nil
+}
+
+// replaceOnTasks 修改所有使用 oldName 的任务（含回收站）
+func (r *memoryTagRepo) replaceOnTasks(userID, oldName, newName string) {
+	for _, tasks := range []map[string]*Task{r.taskRepo.tasks, r.taskRepo.trashed} {
+		for _, task := range tasks {
+			if task.UserID == userID {
+				task.Tags = replaceTag(task.Tags, oldName, newName)
+			}
+		}
+	}
+}
+
+func (r *memoryTagRepo) UpdateTag(ctx context.Context, tag *Tag) error {
+	old := r.tags[tag.ID]
+	r.replaceOnTasks(tag.UserID, old.Name, tag.Name)
+	copied := *tag
+	r.tags[tag.ID] = &copied
+	return nil
+}
+
+func (r *memoryTagRepo) MergeTags(ctx context.Context, userID, sourceID, targetID string) error {
+	r.replaceOnTasks(userID, r.tags[sourceID].Name, r.tags[targetID].Name)
+	delete(r.tags, sourceID)
+	return nil
+}
+
+func (r *memoryTagRepo) DeleteTag(ctx context.Context, tagID, userID string) error {
+	r.replaceOnTasks(userID, r.tags[tagID].Name, "")
+	delete(r.tags, tagID)
+	return nil
+}
+
+func (r *memoryTagRepo) ListTasksByTagExpr(ctx context.Context, userID string, expr *TagExpr, limit, offset int) ([]*Task, int64, error) {
+	matched := make([]*Task, 0)
+	for _, task := range r.taskRepo.tasks {
+		if task.UserID == userID && expr.Matches(task.Tags) {
+			copied := *task
+			matched = append(matched, &copied)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	total := int64(len(matched))
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	matched = matched[offset:]
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+func TestParseTagExpr(t *testing.T) {
+	t.Run("优先级与隐式 AND", func(t *testing.T) {
+		expr, err := ParseTagExpr(`工作 紧急 OR 学习 AND NOT "已 归档"`)
+		require.NoError(t, err)
+
+		assert.True(t, expr.Matches([]string{"工作", "紧急"}))
+		assert.True(t, expr.Matches([]string{"学习"}))
+		assert.False(t, expr.Matches([]string{"学习", "已 归档"}))
+		assert.False(t, expr.Matches([]string{"工作"}))
+	})
+
+	t.Run("括号和关键字大小写", func(t *testing.T) {
+		expr, err := ParseTagExpr(`工作 and (紧急 or 重要) and not 归档`)
+		require.NoError(t, err)
+
+		assert.True(t, expr.Matches([]string{"工作", "重要"}))
+		assert.False(t, expr.Matches([]string{"工作", "重要", "归档"}))
+		assert.False(t, expr.Matches([]string{"紧急"}))
+	})
+
+	t.Run("引号中的关键字作为标签名", func(t *testing.T) {
+		expr, err := ParseTagExpr(`"OR" "a\"b,c"`)
+		require.NoError(t, err)
+
+		assert.True(t, expr.Matches([]string{"OR", `a"b,c`}))
+		assert.False(t, expr.Matches([]string{"OR"}))
+	})
+
+	t.Run("非法表达式", func(t *testing.T) {
+		for _, s := range []string{"", "   ", "工作 AND", "(工作", "工作)", "OR 工作", `"未闭合`, "NOT"} {
+			_, err := ParseTagExpr(s)
+			assert.ErrorIs(t, err, ErrTagExprInvalid, s)
+		}
+	})
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{" 工作 ", "", "重要", "工作", "a,b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"工作", "重要", "a,b"}, tags)
+
+	_, err = normalizeTags([]string{"第一行\n第二行"})
+	assert.ErrorIs(t, err, ErrTagNameInvalid)
+}
+
+func TestTaskUsecase_CreateSubTask_DedupesInheritedTags(t *testing.T) {
+	ctx := context.Background()
+	parent := &Task{
+		ID: "parent", UserID: "user-123", Title: "季度目标", TaskType: PeriodMonth,
+		TimePeriod: NewPeriodFromPeriodType(PeriodMonth, date(2025, 1, 1)), Tags: []string{"工作", "重要"}, RootTaskID: "parent",
+	}
+	usecase := NewTaskUsecase(newMemoryTaskRepo(parent), newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+	child, err := usecase.CreateSubTask(ctx, CreateSubTaskParam{
+		ParentID: "parent", UserID: "user-123", Title: "周计划", Type: PeriodDay,
+		Period: NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 6)), Tags: []string{"重要", " 紧急 "},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"工作", "重要", "紧急"}, child.Tags)
+	assert.Len(t, child.Tags, 3)
+}
+
+func TestTagUsecase(t *testing.T) {
+	ctx := context.Background()
+	setup := func() (*TagUsecase, *memoryTaskRepo, *mockChangeHistoryRepo) {
+		taskRepo := newMemoryTaskRepo(
+			&Task{ID: "t1", UserID: "user-123", Title: "周报", Tags: []string{"工作", "紧急"}},
+			&Task{ID: "t2", UserID: "user-123", Title: "读书", Tags: []string{"学习"}},
+			&Task{ID: "t3", UserID: "user-123", Title: "复盘", Tags: []string{"紧急", "工作项"}},
+		)
+		tagRepo := newMemoryTagRepo(taskRepo,
+			&Tag{ID: "work", UserID: "user-123", Name: "工作"},
+			&Tag{ID: "urgent", UserID: "user-123", Name: "紧急"},
+			&Tag{ID: "study", UserID: "user-123", Name: "学习"},
+			&Tag{ID: "work-item", UserID: "user-123", Name: "工作项"},
+		)
+		historyRepo := newMockChangeHistoryRepo()
+		taskUsecase := NewTaskUsecase(taskRepo, newMockUserSettingsRepo(), historyRepo)
+		return NewTagUsecase(tagRepo, taskUsecase), taskRepo, historyRepo
+	}
+
+	t.Run("创建时校验名称和颜色", func(t *testing.T) {
+		usecase, _, _ := setup()
+
+		_, err := usecase.CreateTag(ctx, CreateTagParam{UserID: "user-123", Name: " 工作 "})
+		assert.ErrorIs(t, err, ErrTagNameExists)
+		_, err = usecase.CreateTag(ctx, CreateTagParam{UserID: "user-123", Name: "生活", Color: "red"})
+		assert.ErrorIs(t, err, ErrTagColorInvalid)
+
+		tag, err := usecase.CreateTag(ctx, CreateTagParam{UserID: "user-123", Name: "生活", Color: "#00aaFF"})
+		require.NoError(t, err)
+		assert.Equal(t, "生活", tag.Name)
+	})
+
+	t.Run("重命名更新任务并记录历史", func(t *testing.T) {
+		usecase, taskRepo, historyRepo := setup()
+		name := "项目"
+
+		tag, err := usecase.UpdateTag(ctx, UpdateTagParam{TagID: "work", UserID: "user-123", Name: &name})
+		require.NoError(t, err)
+		assert.Equal(t, "项目", tag.Name)
+		assert.Equal(t, []string{"项目", "紧急"}, taskRepo.tasks["t1"].Tags)
+		assert.Equal(t, []string{"紧急", "工作项"}, taskRepo.tasks["t3"].Tags, "only exact names are renamed")
+
+		require.Len(t, historyRepo.records, 1)
+		assert.Equal(t, "t1", historyRepo.records[0].EntityID)
+		assert.Equal(t, "tags", historyRepo.records[0].Field)
+		assert.Equal(t, `["项目","紧急"]`, historyRepo.records[0].NewValue)
+	})
+
+	t.Run("重命名为已有标签时报冲突", func(t *testing.T) {
+		usecase, _, _ := setup()
+		name := "紧急"
+
+		_, err := usecase.UpdateTag(ctx, UpdateTagParam{TagID: "work", UserID: "user-123", Name: &name})
+		assert.ErrorIs(t, err, ErrTagNameExists)
+	})
+
+	t.Run("只改颜色不记录任务历史", func(t *testing.T) {
+		usecase, _, historyRepo := setup()
+		color := "#ff0000"
+
+		tag, err := usecase.UpdateTag(ctx, UpdateTagParam{TagID: "work", UserID: "user-123", Color: &color})
+		require.NoError(t, err)
+		assert.Equal(t, "#ff0000", tag.Color)
+		assert.Empty(t, historyRepo.records)
+	})
+
+	t.Run("合并后任务上的标签去重", func(t *testing.T) {
+		usecase, taskRepo, _ := setup()
+
+		target, err := usecase.MergeTags(ctx, MergeTagsParam{UserID: "user-123", SourceID: "urgent", TargetID: "work"})
+		require.NoError(t, err)
+		assert.Equal(t, "work", target.ID)
+		assert.Equal(t, []string{"工作"}, taskRepo.tasks["t1"].Tags)
+		assert.Equal(t, []string{"工作", "工作项"}, taskRepo.tasks["t3"].Tags)
+
+		_, err = usecase.getTag(ctx, "urgent", "user-123")
+		assert.ErrorIs(t, err, ErrTagNotFound)
+
+		_, err = usecase.MergeTags(ctx, MergeTagsParam{UserID: "user-123", SourceID: "work", TargetID: "work"})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("删除后从任务上移除", func(t *testing.T) {
+		usecase, taskRepo, historyRepo := setup()
+
+		require.NoError(t, usecase.DeleteTag(ctx, GetTagParam{TagID: "urgent", UserID: "user-123"}))
+		assert.Equal(t, []string{"工作"}, taskRepo.tasks["t1"].Tags)
+		assert.Equal(t, []string{"工作项"}, taskRepo.tasks["t3"].Tags)
+		assert.Len(t, historyRepo.records, 2)
+
+		err := usecase.DeleteTag(ctx, GetTagParam{TagID: "urgent", UserID: "user-123"})
+		assert.ErrorIs(t, err, ErrTagNotFound)
+	})
+
+	t.Run("按表达式分页查询", func(t *testing.T) {
+		usecase, _, _ := setup()
+
+		tasks, total, err := usecase.ListTasksByTags(ctx, ListTasksByTagsParam{UserID: "user-123", Expr: "紧急 OR 学习", PageSize: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		require.Len(t, tasks, 2)
+		assert.Equal(t, "t1", tasks[0].ID)
+
+		tasks, total, err = usecase.ListTasksByTags(ctx, ListTasksByTagsParam{UserID: "user-123", Expr: "紧急 NOT 工作"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, "t3", tasks[0].ID)
+
+		_, _, err = usecase.ListTasksByTags(ctx, ListTasksByTagsParam{UserID: "user-123", Expr: "(紧急"})
+		assert.ErrorIs(t, err, ErrTagExprInvalid)
+	})
+}
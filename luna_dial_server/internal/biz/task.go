@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/labstack/gommon/log"
@@ -31,19 +32,51 @@ const (
 )
 
 type Task struct {
-	ID         string       `json:"id"`
-	Title      string       `json:"title"`
-	TaskType   PeriodType   `json:"task_type"`
-	TimePeriod Period       `json:"period"`
-	Tags       []string     `json:"tags"`
-	Icon       string       `json:"icon"`
-	Score      int          `json:"score"`
-	Status     TaskStatus   `json:"status"`
-	Priority   TaskPriority `json:"priority"`
-	ParentID   string       `json:"parent_id"`
-	UserID     string       `json:"user_id"`
-	CreatedAt  time.Time    `json:"created_at"`
-	UpdatedAt  time.Time    `json:"updated_at"`
+	ID          string       `json:"id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"` // markdown 格式，用于记录验收标准、备注等
+	TaskType    PeriodType   `json:"task_type"`
+	TimePeriod  Period       `json:"period"`
+	Tags        []string     `json:"tags"`
+	Icon        string       `json:"icon"`
+	Score       int          `json:"score"`
+	Status      TaskStatus   `json:"status"`
+	Priority    TaskPriority `json:"priority"`
+	ParentID    string       `json:"parent_id"`
+	UserID      string       `json:"user_id"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	DeletedAt   *time.Time   `json:"deleted_at,omitempty"` // 进入回收站的时间，只在回收站接口中出现
+
+	// 状态时间：第一次开始、最近一次完成、取消的时间，以及可选的取消原因，状态变化时自动维护
+	StartedAt    *time.Time `json:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	CancelledAt  *time.Time `json:"cancelled_at"`
+	CancelReason string     `json:"cancel_reason,omitempty"`
+
+	// 所有未取消的子任务都已完成，等待用户确认完成（用户设置为仅标记时使用）
+	ReadyToComplete bool `json:"ready_to_complete"`
+
+	// 清单统计（与数据库字段对应），列表接口无需加载清单项即可显示进度
+	ChecklistTotal int     `json:"checklist_total"`
+	ChecklistDone  int     `json:"checklist_done"`
+	ChecklistRatio float64 `json:"checklist_ratio"` // 已完成清单项占比 0-1，没有清单项时为0
+	// 清单项，只在创建/更新任务和清单相关接口中返回（不存储在任务表）
+	Checklist []*ChecklistItem `json:"checklist,omitempty"`
+
+	// 顺延：CarriedOverFromID 为复制模式下的原任务，CarryOverCount 为任务被顺延的次数
+	CarriedOverFromID string `json:"carried_over_from_id,omitempty"`
+	CarryOverCount    int    `json:"carry_over_count"`
+
+	// 工时统计（秒）：TimeSpent 为任务自身已结束的工时记录之和
+	// TreeTimeSpent 只在根任务上维护，为整棵任务树（root_task_id 相同的任务）的耗时之和
+	TimeSpent     int64 `json:"time_spent"`
+	TreeTimeSpent int64 `json:"tree_time_spent"`
+
+	// 工作量估算（分钟或点数，单位见用户设置）：Estimate 为任务自身的估算
+	// TreeEstimate 只在根任务上维护，为整棵任务树中未取消任务的估算之和
+	Estimate     int `json:"estimate"`
+	TreeEstimate int `json:"tree_estimate"`
 
 	// 新增：树结构优化字段（与数据库字段对应）
 	HasChildren   bool   `json:"has_children"`   // 是否有子任务：前端可据此判断是否显示展开按钮
@@ -51,43 +84,97 @@ type Task struct {
 	RootTaskID    string `json:"root_task_id"`   // 根任务ID：用于批量查询和任务树重组
 	TreeDepth     int    `json:"tree_depth"`     // 树深度：前端渲染缩进层级
 
+	// 同级任务中的排序位置，升序排列；按间隔分配，调整顺序时通常只需改动被移动的任务
+	SortRank int64 `json:"sort_rank"`
+	// 看板列中的排序位置，升序排列，由移动看板卡片维护
+	BoardRank int64 `json:"board_rank"`
+
+	// 分数汇总：ScoreRollup、RollupCompletedOnly 决定如何由子任务汇总，Weight 为任务在父任务加权平均中的权重
+	// RollupScore、Progress 为汇总结果，子任务变化时自动重算（叶子任务为自身分数和完成情况）
+	ScoreRollup         ScoreRollupMode `json:"score_rollup"`
+	RollupCompletedOnly bool            `json:"rollup_completed_only"`
+	Weight              float64         `json:"weight"`
+	RollupScore         float64         `json:"rollup_score"`
+	Progress            float64         `json:"progress"` // 0-100
+
+	// 关键结果：不为空时任务是可量化的关键结果，进度由达成率决定
+	KeyResult *KeyResult `json:"key_result,omitempty"`
+
 	// 新增：内存构建的子任务列表（不存储到数据库）
 	// 设计说明：通过 root_task_id 批量查询获取所有相关任务后，在内存中构建这个树结构
 	// 优势：避免 N+1 查询问题，一次数据库查询 + 内存构建完整树
 	Children []*Task `json:"children,omitempty"`
+
+	// 本次状态变化向上传播时，状态或"可完成"标记被改变的祖先任务（不存储到数据库）
+	ChangedAncestors []*Task `json:"changed_ancestors,omitempty"`
+
+	// 创建或更新后任务所在的日、周计划工作量超过用户容量时的提醒（不存储到数据库）
+	CapacityWarnings []CapacityWarning `json:"capacity_warnings,omitempty"`
 }
 
 // 创建任务参数
 type CreateTaskParam struct {
-	UserID   string
-	Title    string
-	Type     PeriodType
-	Period   Period
-	Tags     []string
-	Icon     string
-	Score    int
-	Priority TaskPriority
-	ParentID string
+	UserID      string
+	Title       string
+	Description string
+	Type        PeriodType
+	Period      Period
+	Tags        []string
+	Icon        string
+	Score       int
+	Priority    TaskPriority
+	ParentID    string
+	Checklist   []ChecklistItemInput
+	KeyResult   *KeyResultInput // 不为空时创建为关键结果
+	Estimate    int             // 工作量估算，不能为负数
 }
 
 // 编辑任务参数
 type UpdateTaskParam struct {
-	TaskID string
-	UserID string
-	Title  *string
-	// Type        *PeriodType 暂时不运行修改任务类型吧
+	TaskID   string
+	UserID   string
+	Title    *string
+	Type     *PeriodType // 修改任务类型，时间段按新类型规范化
 	Period   *Period
 	Tags     *[]string
 	Icon     *string
 	Score    *int
-	Status   *TaskStatus
+	Status   *TaskStatus // 只允许 taskStatusTransitions 中的状态变化
 	Priority *TaskPriority
+	Estimate *int // 工作量估算，不能为负数
+
+	// 取消原因：任务更新后处于已取消状态时才能设置
+	CancelReason *string
+
+	// 分数汇总设置
+	ScoreRollup         *ScoreRollupMode
+	RollupCompletedOnly *bool
+	Weight              *float64
+
+	// 关键结果：KeyResult 不为空时设置为关键结果（已是关键结果时保留当前值），RemoveKeyResult 改回普通任务
+	KeyResult       *KeyResultInput
+	RemoveKeyResult bool
+
+	Description *string
+	Checklist   *[]ChecklistItemInput // 整体替换清单
+
+	// 忽略阻塞依赖，强制把被阻塞的任务改为进行中或已完成
+	OverrideBlockers bool
 }
 
+// TaskDeleteMode 删除任务时子任务的处理方式
+type TaskDeleteMode int
+
+const (
+	TaskDeleteModeCascade TaskDeleteMode = iota // 级联删除整棵子树（默认）
+	TaskDeleteModePromote                       // 子任务上移，挂到祖父任务下（没有祖父任务时成为根任务）
+)
+
 // 删除任务参数
 type DeleteTaskParam struct {
 	TaskID string
 	UserID string
+	Mode   TaskDeleteMode
 }
 
 // 设置任务分数参数
@@ -99,15 +186,19 @@ type SetTaskScoreParam struct {
 
 // 创建子任务参数
 type CreateSubTaskParam struct {
-	ParentID string
-	UserID   string
-	Title    string
-	Type     PeriodType
-	Period   Period
-	Priority TaskPriority
-	Tags     []string
-	Icon     string
-	Score    int
+	ParentID    string
+	UserID      string
+	Title       string
+	Description string
+	Type        PeriodType
+	Period      Period
+	Priority    TaskPriority
+	Tags        []string
+	Icon        string
+	Score       int
+	Checklist   []ChecklistItemInput
+	KeyResult   *KeyResultInput // 不为空时创建为关键结果
+	Estimate    int             // 工作量估算，不能为负数
 }
 
 // 修改标签参数
@@ -124,11 +215,19 @@ type SetTaskIconParam struct {
 	Icon   string
 }
 
+// 移动任务参数
+type MoveTaskParam struct {
+	TaskID      string
+	UserID      string
+	NewParentID string // 新父任务ID，为空表示移动到根级别
+}
+
 // 获取指定时间的指定类型的任务列表参数
 type ListTaskByPeriodParam struct {
 	UserID  string
 	Period  Period
 	GroupBy PeriodType
+	Match   PeriodMatch // 任务时间段与 Period 的匹配方式
 }
 
 // 获取某个任务的父任务树列表参数
@@ -173,12 +272,15 @@ type GetTaskParentChainParam struct {
 }
 
 type TaskUsecase struct {
-	repo TaskRepo
+	repo         TaskRepo
+	settingsRepo UserSettingsRepo
+	historyRepo  ChangeHistoryRepo
+	maxTreeDepth int // 子任务的最大深度，0 表示使用 DefaultMaxTreeDepth
 	// log *log.Helper
 }
 
-func NewTaskUsecase(repo TaskRepo) *TaskUsecase {
-	return &TaskUsecase{repo: repo}
+func NewTaskUsecase(repo TaskRepo, settingsRepo UserSettingsRepo, historyRepo ChangeHistoryRepo) *TaskUsecase {
+	return &TaskUsecase{repo: repo, settingsRepo: settingsRepo, historyRepo: historyRepo}
 }
 
 // 创建任务
@@ -188,13 +290,16 @@ func (uc *TaskUsecase) CreateTask(ctx context.Context, param CreateTaskParam) (*
 		return nil, ErrInvalidInput // 参数不合法
 	}
 
-	tags := []string{}
-	if param.Tags != nil {
-		tags = param.Tags
+	if param.Estimate < 0 {
+		return nil, ErrInvalidInput // 估算不能为负数
 	}
+
+	tags := append([]string{}, param.Tags...)
+	var parentTask *Task
 	if param.ParentID != "" {
 		// 检查父任务是否存在
-		parentTask, err := uc.repo.GetTask(ctx, param.ParentID, param.UserID)
+		var err error
+		parentTask, err = uc.repo.GetTask(ctx, param.ParentID, param.UserID)
 		if err != nil {
 			return nil, err // 返回仓库层的错误
 		}
@@ -205,6 +310,15 @@ func (uc *TaskUsecase) CreateTask(ctx context.Context, param CreateTaskParam) (*
 			tags = append(tags, parentTask.Tags...) // 继承父任务的标签
 		}
 	}
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if param.KeyResult != nil {
+		if err := param.KeyResult.validate(); err != nil {
+			return nil, err
+		}
+	}
 	if !param.Period.IsValid() {
 		return nil, ErrInvalidInput // 时间段不合法
 	}
@@ -218,22 +332,33 @@ func (uc *TaskUsecase) CreateTask(ctx context.Context, param CreateTaskParam) (*
 	}
 
 	task := &Task{
-		ID:         generateID(), // 假设有一个生成ID的函数
-		Title:      param.Title,
-		TaskType:   param.Type,
-		TimePeriod: param.Period,
-		Tags:       tags,
-		Icon:       param.Icon,
-		Score:      param.Score,
-		Status:     TaskStatusNotStarted, // 默认状态为未开始
-		Priority:   param.Priority,       // 使用传入的优先级，如果为0则默认为低优先级
-		UserID:     param.UserID,
-		ParentID:   param.ParentID,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-	}
-
-	err := uc.repo.CreateTask(ctx, task)
+		ID:          generateID(), // 假设有一个生成ID的函数
+		Title:       param.Title,
+		Description: param.Description,
+		TaskType:    param.Type,
+		TimePeriod:  param.Period,
+		Tags:        tags,
+		Icon:        param.Icon,
+		Score:       param.Score,
+		Status:      TaskStatusNotStarted, // 默认状态为未开始
+		Priority:    param.Priority,       // 使用传入的优先级，如果为0则默认为低优先级
+		Estimate:    param.Estimate,
+		UserID:      param.UserID,
+		ParentID:    param.ParentID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if parentTask != nil {
+		task.placeUnderParent(parentTask)
+		if err := uc.checkTreeDepth(task.TreeDepth); err != nil {
+			return nil, err
+		}
+	}
+	if param.KeyResult != nil {
+		task.applyKeyResult(*param.KeyResult)
+	}
+
+	err = uc.createTaskWithChecklist(ctx, task, param.Checklist)
 	if err != nil {
 		return nil, err // 返回仓库层的错误
 	}
@@ -247,14 +372,15 @@ func (uc *TaskUsecase) CreateTask(ctx context.Context, param CreateTaskParam) (*
 			log.Warnf("Failed to update tree optimization for parent %s: %v", task.ParentID, err)
 		}
 	}
+	uc.attachCapacityWarnings(ctx, task)
 
 	return task, nil
 }
 
 // 更新任务
-// 禁止修改 period type，其实不难做，但是先不放开
 // 如果传递了标题，必须不为空
 // 如果传递了时间段，必须不为空且合法
+// 修改类型或时间段时，时间段按类型规范化，并且必须仍满足与父任务、子任务之间的规则（同 CreateSubTask）
 func (uc *TaskUsecase) UpdateTask(ctx context.Context, param UpdateTaskParam) (*Task, error) {
 	if param.TaskID == "" || param.UserID == "" {
 		return nil, ErrInvalidInput // 参数不合法
@@ -271,6 +397,26 @@ func (uc *TaskUsecase) UpdateTask(ctx context.Context, param UpdateTaskParam) (*
 	if param.Period != nil && !param.Period.IsValid() {
 		return nil, ErrInvalidInput // 时间段不合法
 	}
+	if param.ScoreRollup != nil && !param.ScoreRollup.IsValid() {
+		return nil, ErrInvalidInput // 汇总方式不合法
+	}
+	if param.Weight != nil && !isValidTaskWeight(*param.Weight) {
+		return nil, ErrInvalidInput // 权重不能为负数
+	}
+	if param.Estimate != nil && *param.Estimate < 0 {
+		return nil, ErrInvalidInput // 估算不能为负数
+	}
+	if param.CancelReason != nil && utf8.RuneCountInString(*param.CancelReason) > maxCancelReasonLength {
+		return nil, ErrInvalidInput // 取消原因过长
+	}
+	if param.KeyResult != nil {
+		if param.RemoveKeyResult {
+			return nil, ErrInvalidInput // 不能同时设置和移除关键结果
+		}
+		if err := param.KeyResult.validate(); err != nil {
+			return nil, err
+		}
+	}
 
 	task, err := uc.repo.GetTask(ctx, param.TaskID, param.UserID)
 	if err != nil {
@@ -280,15 +426,32 @@ func (uc *TaskUsecase) UpdateTask(ctx context.Context, param UpdateTaskParam) (*
 		return nil, ErrTaskNotFound
 	}
 
+	oldStatus := task.Status
+	before := *task
+
+	// 被阻塞的任务不能开始或完成，除非显式忽略阻塞
+	if param.Status != nil && *param.Status != oldStatus && !param.OverrideBlockers &&
+		(*param.Status == TaskStatusInProgress || *param.Status == TaskStatusCompleted) {
+		if err := uc.checkTaskBlockers(ctx, task); err != nil {
+			return nil, err
+		}
+	}
+
 	task.UpdatedAt = time.Now()
 	if param.Title != nil {
 		task.Title = *param.Title
 	}
-	if param.Period != nil {
-		task.TimePeriod = *param.Period
+	if param.Type != nil || param.Period != nil {
+		if err := uc.applyTaskPlacement(ctx, task, param.Type, param.Period); err != nil {
+			return nil, err
+		}
 	}
 	if param.Tags != nil {
-		task.Tags = *param.Tags
+		tags, err := normalizeTags(*param.Tags)
+		if err != nil {
+			return nil, err
+		}
+		task.Tags = tags
 	}
 	if param.Icon != nil {
 		task.Icon = *param.Icon
@@ -297,24 +460,99 @@ func (uc *TaskUsecase) UpdateTask(ctx context.Context, param UpdateTaskParam) (*
 		task.Score = *param.Score
 	}
 	if param.Status != nil {
-		task.Status = *param.Status
+		if err := task.transitionTo(*param.Status, task.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+	if param.CancelReason != nil {
+		if task.Status != TaskStatusCancelled {
+			return nil, ErrInvalidInput // 只有已取消的任务可以设置取消原因
+		}
+		task.CancelReason = *param.CancelReason
 	}
 	if param.Priority != nil {
 		task.Priority = *param.Priority
 	}
-	err = uc.repo.UpdateTask(ctx, task)
+	if param.Estimate != nil {
+		task.Estimate = *param.Estimate
+	}
+	if param.ScoreRollup != nil {
+		task.ScoreRollup = *param.ScoreRollup
+	}
+	if param.RollupCompletedOnly != nil {
+		task.RollupCompletedOnly = *param.RollupCompletedOnly
+	}
+	if param.Weight != nil {
+		task.Weight = *param.Weight
+	}
+	if param.KeyResult != nil {
+		task.applyKeyResult(*param.KeyResult)
+	}
+	if param.RemoveKeyResult {
+		task.KeyResult = nil
+	}
+	if param.Description != nil {
+		task.Description = *param.Description
+	}
+	var checklist []*ChecklistItem
+	if param.Checklist != nil {
+		checklist, err = newChecklistItems(task, *param.Checklist)
+		if err != nil {
+			return nil, err
+		}
+		task.applyChecklist(checklist)
+	}
+
+	// 任务更新、清单替换和状态变化时向上的完成状态传播在同一事务中完成
+	err = uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.UpdateTask(ctx, task); err != nil {
+			return err
+		}
+		if err := uc.recordTaskChange(ctx, ChangeActionUpdate, &before, task); err != nil {
+			return err
+		}
+		if param.Checklist != nil {
+			if err := uc.repo.ReplaceChecklistItems(ctx, task.ID, task.UserID, checklist); err != nil {
+				return err
+			}
+		}
+		if task.Status != oldStatus {
+			changed, err := uc.propagateCompletion(ctx, task, oldStatus)
+			if err != nil {
+				return err
+			}
+			task.ChangedAncestors = changed
+		}
+		// 估算或状态变化后重算整树估算（已取消的任务不计入）
+		if task.Estimate != before.Estimate || task.Status != oldStatus {
+			if err := uc.repo.RefreshTreeEstimate(ctx, treeRootTaskID(task), task.UserID); err != nil {
+				return err
+			}
+		}
+		// 分数、状态、权重或汇总方式变化后重算自身和祖先的汇总分数
+		return uc.refreshRollup(ctx, task)
+	})
 	if err != nil {
 		return nil, err // 返回仓库层的错误
 	}
+	if param.Estimate != nil || param.Type != nil || param.Period != nil || param.Status != nil {
+		uc.attachCapacityWarnings(ctx, task)
+	}
 	return task, nil
 }
 
 // 根据ID删除任务
 // 检查USERID
+// 删除为软删除：任务进入回收站，可以通过 RestoreTask 恢复，超过保留期后由 TrashPurger 永久删除
+// 根据 Mode 处理子任务：级联删除整棵子树，或将子任务上移到祖父任务下
+// 删除和树优化字段的维护在同一个事务中完成
 func (uc *TaskUsecase) DeleteTask(ctx context.Context, param DeleteTaskParam) error {
 	if param.TaskID == "" || param.UserID == "" {
 		return ErrInvalidInput // 参数不合法
 	}
+	if param.Mode != TaskDeleteModeCascade && param.Mode != TaskDeleteModePromote {
+		return ErrInvalidInput // 删除模式不合法
+	}
 
 	// 检查任务是否存在并且属于该用户
 	task, err := uc.repo.GetTask(ctx, param.TaskID, param.UserID)
@@ -328,20 +566,110 @@ func (uc *TaskUsecase) DeleteTask(ctx context.Context, param DeleteTaskParam) er
 	// 保存父任务ID,用于后续更新树优化字段
 	parentID := task.ParentID
 
-	// 删除任务
-	err = uc.repo.DeleteTask(ctx, param.TaskID, param.UserID)
-	if err != nil {
-		return err // 返回仓库层的错误
-	}
+	return uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		var promotedIDs []string
+		deletedTasks := []*Task{task}
+		switch param.Mode {
+		case TaskDeleteModePromote:
+			children, err := uc.repo.ListChildTasks(ctx, task.ID, param.UserID)
+			if err != nil {
+				return err
+			}
+
+			// 上移后子任务需要满足祖父任务的子任务规则，有不满足的子任务时不删除，列出所有冲突
+			if parentID != "" {
+				grandparent, err := uc.repo.GetTask(ctx, parentID, param.UserID)
+				if err != nil {
+					return err
+				}
+				if grandparent == nil {
+					return ErrTaskNotFound
+				}
+				if conflicts := childPlacementConflicts(grandparent, children); len(conflicts) > 0 {
+					return &TaskChildrenConflictError{Conflicts: conflicts}
+				}
+			}
+
+			// 子任务按原顺序挂到祖父任务下（没有祖父任务时成为根任务）
+			// 根任务依次排到最前，因此倒序处理以保持原顺序
+			sortSiblingTasks(children)
+			if parentID == "" {
+				for i, j := 0, len(children)-1; i < j; i, j = i+1, j-1 {
+					children[i], children[j] = children[j], children[i]
+				}
+			}
+			now := time.Now()
+			for _, child := range children {
+				childBefore := *child
+				child.ParentID = parentID
+				child.UpdatedAt = now
+				if err := uc.assignSortRank(ctx, child); err != nil {
+					return err
+				}
+				if err := uc.repo.UpdateTask(ctx, child); err != nil {
+					return err
+				}
+				if err := uc.recordTaskChange(ctx, ChangeActionUpdate, &childBefore, child); err != nil {
+					return err
+				}
+			}
+
+			if err := uc.repo.DeleteTask(ctx, task.ID, param.UserID); err != nil {
+				return err
+			}
 
-	// 删除后维护父任务的树优化字段
-	if parentID != "" {
-		if err := uc.repo.UpdateTreeOptimizationFields(ctx, parentID, param.UserID); err != nil {
-			log.Warnf("Failed to update tree optimization for parent %s after deletion: %v", parentID, err)
+			// 被上移的子树整体重算 root_task_id 和 tree_depth
+			for _, child := range children {
+				if err := uc.repo.RebuildSubtreeOptimizationFields(ctx, child.ID, param.UserID); err != nil {
+					return err
+				}
+				promotedIDs = append(promotedIDs, child.ID)
+			}
+		default:
+			// 删除前读取整棵子树，为级联删除的每个后代记录历史
+			roots, err := uc.repo.ListSubtreeTasks(ctx, task.ID, param.UserID, nil)
+			if err != nil {
+				return err
+			}
+			if len(roots) > 0 {
+				deletedTasks = deletedTasks[:0]
+				for queue := roots; len(queue) > 0; queue = queue[1:] {
+					deletedTasks = append(deletedTasks, queue[0])
+					queue = append(queue, queue[0].Children...)
+				}
+			}
+			if err := uc.repo.DeleteTaskSubtree(ctx, task.ID, param.UserID); err != nil {
+				return err
+			}
 		}
-	}
 
-	return nil
+		deletedAt := time.Now()
+		for _, t := range deletedTasks {
+			deleted := *t
+			deleted.DeletedAt = &deletedAt
+			if err := uc.recordTaskChange(ctx, ChangeActionDelete, t, &deleted); err != nil {
+				return err
+			}
+		}
+
+		// 删除后维护父任务的树优化字段和汇总分数
+		if parentID != "" {
+			if err := uc.repo.UpdateTreeOptimizationFields(ctx, parentID, param.UserID); err != nil {
+				return err
+			}
+			if err := uc.refreshRollupFrom(ctx, parentID, param.UserID); err != nil {
+				return err
+			}
+		}
+
+		// 重算受影响的根任务耗时和估算：原根任务（未被删除时），以及上移后可能成为根任务的子任务
+		rootTaskIDs := make([]string, 0, len(promotedIDs)+1)
+		if task.RootTaskID != task.ID {
+			rootTaskIDs = append(rootTaskIDs, task.RootTaskID)
+		}
+		rootTaskIDs = append(rootTaskIDs, promotedIDs...)
+		return uc.refreshTreeTotals(ctx, param.UserID, rootTaskIDs...)
+	})
 }
 
 // 根据ID更新任务分数
@@ -365,10 +693,16 @@ func (uc *TaskUsecase) SetTaskScore(ctx context.Context, param SetTaskScoreParam
 	}
 
 	// 更新分数
+	before := *task
 	task.Score = param.Score
 	task.UpdatedAt = time.Now()
 
-	err = uc.repo.UpdateTask(ctx, task)
+	err = uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.updateTaskWithHistory(ctx, &before, task); err != nil {
+			return err
+		}
+		return uc.refreshRollup(ctx, task)
+	})
 	if err != nil {
 		return nil, err // 返回仓库层的错误
 	}
@@ -393,46 +727,55 @@ func (uc *TaskUsecase) CreateSubTask(ctx context.Context, param CreateSubTaskPar
 		return nil, ErrTaskNotFound // 父任务不存在
 	}
 
-	// 验证子任务的类型必须小于等于父任务类型（粒度更小或相等）
-	// 例如：周任务的子任务可以是日或周；月任务的子任务可以是日、周或月
-	if param.Type > parentTask.TaskType {
-		return nil, ErrInvalidInput // 子任务类型不能大于父任务类型
-	}
-
 	if !param.Period.IsValid() {
 		return nil, ErrInvalidInput // 时间段不合法
 	}
+	if param.Estimate < 0 {
+		return nil, ErrInvalidInput // 估算不能为负数
+	}
 
-	// 子任务的开始时间必须在父任务的时间范围内
-	// 注意：不检查结束时间，以支持边界情况（如1月任务的最后一周可能横跨到2月）
-	if param.Period.Start.Before(parentTask.TimePeriod.Start) || param.Period.Start.After(parentTask.TimePeriod.End) {
-		return nil, ErrInvalidInput // 子任务的开始时间必须在父任务时间范围内
+	if err := validateSubTaskPlacement(parentTask, param.Type, param.Period); err != nil {
+		return nil, err
 	}
 
-	// 继承父任务的标签
-	tags := param.Tags
-	if len(parentTask.Tags) > 0 {
-		tags = append(tags, parentTask.Tags...)
+	// 继承父任务的标签，重复的标签只保留一个
+	tags, err := normalizeTags(append(append([]string{}, param.Tags...), parentTask.Tags...))
+	if err != nil {
+		return nil, err
+	}
+	if param.KeyResult != nil {
+		if err := param.KeyResult.validate(); err != nil {
+			return nil, err
+		}
 	}
 
 	// 创建子任务
 	task := &Task{
-		ID:         generateID(),
-		Title:      param.Title,
-		TaskType:   param.Type,
-		TimePeriod: param.Period,
-		Tags:       tags,
-		Icon:       param.Icon,
-		Score:      param.Score,
-		Status:     TaskStatusNotStarted, // 子任务默认状态为未开始
-		Priority:   param.Priority,       // 使用传入的优先级，由前端逻辑定义它与父任务等级一致
-		UserID:     param.UserID,
-		ParentID:   param.ParentID,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-	}
-
-	err = uc.repo.CreateTask(ctx, task)
+		ID:          generateID(),
+		Title:       param.Title,
+		Description: param.Description,
+		TaskType:    param.Type,
+		TimePeriod:  param.Period,
+		Tags:        tags,
+		Icon:        param.Icon,
+		Score:       param.Score,
+		Status:      TaskStatusNotStarted, // 子任务默认状态为未开始
+		Priority:    param.Priority,       // 使用传入的优先级，由前端逻辑定义它与父任务等级一致
+		Estimate:    param.Estimate,
+		UserID:      param.UserID,
+		ParentID:    param.ParentID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	task.placeUnderParent(parentTask)
+	if err := uc.checkTreeDepth(task.TreeDepth); err != nil {
+		return nil, err
+	}
+	if param.KeyResult != nil {
+		task.applyKeyResult(*param.KeyResult)
+	}
+
+	err = uc.createTaskWithChecklist(ctx, task, param.Checklist)
 	if err != nil {
 		return nil, err // 返回仓库层的错误
 	}
@@ -444,10 +787,242 @@ func (uc *TaskUsecase) CreateSubTask(ctx context.Context, param CreateSubTaskPar
 	if err := uc.repo.UpdateTreeOptimizationFields(ctx, task.ParentID, task.UserID); err != nil {
 		log.Warnf("Failed to update tree optimization for parent %s: %v", task.ParentID, err)
 	}
+	uc.attachCapacityWarnings(ctx, task)
 
 	return task, nil
 }
 
+// validateSubTaskPlacement 校验子任务能否挂在指定父任务下
+// 创建子任务和移动任务共用同一套规则
+func validateSubTaskPlacement(parentTask *Task, childType PeriodType, childPeriod Period) error {
+	// 验证子任务的类型必须小于等于父任务类型（粒度更小或相等）
+	// 例如：周任务的子任务可以是日或周；月任务的子任务可以是日、周或月
+	if childType > parentTask.TaskType {
+		return ErrSubTaskTypeInvalid
+	}
+
+	// 子任务的开始时间必须在父任务的时间范围内
+	// 注意：不检查结束时间，以支持边界情况（如1月任务的最后一周可能横跨到2月）
+	if childPeriod.Start.Before(parentTask.TimePeriod.Start) || childPeriod.Start.After(parentTask.TimePeriod.End) {
+		return ErrSubTaskPeriodInvalid
+	}
+
+	return nil
+}
+
+// TaskChildConflict 任务修改类型或时间段后（或删除任务时上移到祖父任务下），不再满足父子规则的子任务
+type TaskChildConflict struct {
+	TaskID   string     `json:"task_id"`
+	Title    string     `json:"title"`
+	TaskType PeriodType `json:"task_type"`
+	Period   Period     `json:"period"`
+	Reason   string     `json:"reason"` // 违反的规则，与 ErrSubTaskTypeInvalid、ErrSubTaskPeriodInvalid 的描述一致
+}
+
+// TaskChildrenConflictError 列出所有冲突的子任务，errors.Is 可匹配 ErrTaskChildrenConflict
+type TaskChildrenConflictError struct {
+	Conflicts []TaskChildConflict
+}
+
+func (e *TaskChildrenConflictError) Error() string {
+	ids := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		ids[i] = conflict.TaskID
+	}
+	return fmt.Sprintf("%s: %s", ErrTaskChildrenConflict, strings.Join(ids, ","))
+}
+
+func (e *TaskChildrenConflictError) Unwrap() error {
+	return ErrTaskChildrenConflict
+}
+
+// childPlacementConflicts 按子任务规则检查 children 挂在 parent 下是否合法，返回所有不满足的子任务
+func childPlacementConflicts(parent *Task, children []*Task) []TaskChildConflict {
+	conflicts := make([]TaskChildConflict, 0)
+	for _, child := range children {
+		if err := validateSubTaskPlacement(parent, child.TaskType, child.TimePeriod); err != nil {
+			conflicts = append(conflicts, TaskChildConflict{
+				TaskID:   child.ID,
+				Title:    child.Title,
+				TaskType: child.TaskType,
+				Period:   child.TimePeriod,
+				Reason:   err.Error(),
+			})
+		}
+	}
+	return conflicts
+}
+
+// applyTaskPlacement 修改任务的类型和时间段，未传递的沿用原值
+// 时间段与类型不匹配时按开始时间规范化；只有类型或时间段实际变化时才校验父任务和子任务
+// 子任务只需检查直接子任务：更深的后代与其父任务的关系不受影响
+func (uc *TaskUsecase) applyTaskPlacement(ctx context.Context, task *Task, taskType *PeriodType, period *Period) error {
+	newType := task.TaskType
+	if taskType != nil {
+		if *taskType < PeriodDay || *taskType > PeriodYear {
+			return ErrInvalidInput
+		}
+		newType = *taskType
+	}
+	newPeriod := task.TimePeriod
+	if period != nil {
+		newPeriod = *period
+	}
+	if !newPeriod.MatchesPeriodType(newType) {
+		newPeriod = NewPeriodFromPeriodType(newType, newPeriod.Start)
+	}
+	if newType == task.TaskType && newPeriod.Start.Equal(task.TimePeriod.Start) && newPeriod.End.Equal(task.TimePeriod.End) {
+		return nil
+	}
+
+	if task.ParentID != "" {
+		parent, err := uc.repo.GetTask(ctx, task.ParentID, task.UserID)
+		if err != nil {
+			return err
+		}
+		if parent != nil {
+			if err := validateSubTaskPlacement(parent, newType, newPeriod); err != nil {
+				return err
+			}
+		}
+	}
+
+	updated := *task
+	updated.TaskType = newType
+	updated.TimePeriod = newPeriod
+	children, err := uc.repo.ListChildTasks(ctx, task.ID, task.UserID)
+	if err != nil {
+		return err
+	}
+	if conflicts := childPlacementConflicts(&updated, children); len(conflicts) > 0 {
+		return &TaskChildrenConflictError{Conflicts: conflicts}
+	}
+
+	task.TaskType = newType
+	task.TimePeriod = newPeriod
+	return nil
+}
+
+// 移动任务（连同整棵子树）到新的父任务下，或移动到根级别
+// 校验规则与 CreateSubTask 一致，并且不允许移动到自身或自身的后代之下
+// 任务自身、所有后代、旧父任务、新父任务的树优化字段在同一个事务中重算
+func (uc *TaskUsecase) MoveTask(ctx context.Context, param MoveTaskParam) (*Task, error) {
+	if param.TaskID == "" || param.UserID == "" {
+		return nil, ErrInvalidInput // 参数不合法
+	}
+
+	task, err := uc.repo.GetTask(ctx, param.TaskID, param.UserID)
+	if err != nil {
+		return nil, err // 返回仓库层的错误
+	}
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	// 位置没有变化，直接返回
+	if task.ParentID == param.NewParentID {
+		return task, nil
+	}
+
+	if param.NewParentID != "" {
+		if param.NewParentID == task.ID {
+			return nil, ErrTaskMoveCycle
+		}
+
+		newParent, err := uc.repo.GetTask(ctx, param.NewParentID, param.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if newParent == nil {
+			return nil, ErrTaskNotFound // 新父任务不存在
+		}
+
+		if err := validateSubTaskPlacement(newParent, task.TaskType, task.TimePeriod); err != nil {
+			return nil, err
+		}
+
+		// 环检测：新父任务的祖先链中不能包含被移动的任务
+		chain, err := uc.repo.GetTaskParentChain(ctx, newParent.ID, param.UserID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestor := range chain {
+			if ancestor.ID == task.ID {
+				return nil, ErrTaskMoveCycle
+			}
+		}
+
+		// 移动后子树中最深的任务也不能超过最大深度
+		height, err := uc.taskSubtreeHeight(ctx, task.ID, param.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if err := uc.checkTreeDepth(newParent.TreeDepth + 1 + height); err != nil {
+			return nil, err
+		}
+	}
+
+	oldParentID := task.ParentID
+	oldRootTaskID := task.RootTaskID
+	before := *task
+	task.ParentID = param.NewParentID
+	task.UpdatedAt = time.Now()
+
+	err = uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		// 排在新父任务的子任务最后（移动到根级别时排在最前）
+		if err := uc.assignSortRank(ctx, task); err != nil {
+			return err
+		}
+		if err := uc.updateTaskWithHistory(ctx, &before, task); err != nil {
+			return err
+		}
+		// 重算被移动子树（root_task_id、tree_depth）
+		if err := uc.repo.RebuildSubtreeOptimizationFields(ctx, task.ID, task.UserID); err != nil {
+			return err
+		}
+		// 重算旧父任务和新父任务的子任务计数
+		if oldParentID != "" {
+			if err := uc.repo.UpdateTreeOptimizationFields(ctx, oldParentID, task.UserID); err != nil {
+				return err
+			}
+		}
+		if task.ParentID != "" {
+			if err := uc.repo.UpdateTreeOptimizationFields(ctx, task.ParentID, task.UserID); err != nil {
+				return err
+			}
+		}
+		// 重算旧父任务链和新父任务链的汇总分数
+		if err := uc.refreshRollupFrom(ctx, oldParentID, task.UserID); err != nil {
+			return err
+		}
+		if err := uc.refreshRollupFrom(ctx, task.ParentID, task.UserID); err != nil {
+			return err
+		}
+		// 子树的耗时和估算从旧根任务转移到新根任务
+		moved, err := uc.repo.GetTask(ctx, task.ID, task.UserID)
+		if err != nil {
+			return err
+		}
+		if moved == nil {
+			return ErrTaskNotFound
+		}
+		return uc.refreshTreeTotals(ctx, task.UserID, oldRootTaskID, moved.RootTaskID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 重新读取，返回包含最新树字段的任务
+	moved, err := uc.repo.GetTask(ctx, task.ID, task.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if moved == nil {
+		return nil, ErrTaskNotFound
+	}
+	return moved, nil
+}
+
 // 修改标签 - 直接覆盖替换任务的所有标签
 func (uc *TaskUsecase) EditTag(ctx context.Context, param EditTagParam) (*Task, error) {
 	if param.TaskID == "" || param.UserID == "" {
@@ -463,11 +1038,17 @@ func (uc *TaskUsecase) EditTag(ctx context.Context, param EditTagParam) (*Task,
 		return nil, ErrTaskNotFound // 任务不存在
 	}
 
+	tags, err := normalizeTags(param.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	// 直接覆盖替换所有标签
-	task.Tags = param.Tags
+	before := *task
+	task.Tags = tags
 	task.UpdatedAt = time.Now()
 
-	err = uc.repo.UpdateTask(ctx, task)
+	err = uc.updateTaskWithHistory(ctx, &before, task)
 	if err != nil {
 		return nil, err // 返回仓库层的错误
 	}
@@ -491,10 +1072,11 @@ func (uc *TaskUsecase) SetTaskIcon(ctx context.Context, param SetTaskIconParam)
 	}
 
 	// 设置图标
+	before := *task
 	task.Icon = param.Icon
 	task.UpdatedAt = time.Now()
 
-	err = uc.repo.UpdateTask(ctx, task)
+	err = uc.updateTaskWithHistory(ctx, &before, task)
 	if err != nil {
 		return nil, err // 返回仓库层的错误
 	}
@@ -512,11 +1094,14 @@ func (uc *TaskUsecase) ListTaskByPeriod(ctx context.Context, param ListTaskByPer
 	if !param.Period.IsValid() {
 		return nil, ErrInvalidInput // 时间段不合法
 	}
+	if !param.Match.IsValid() {
+		return nil, ErrInvalidInput // 匹配方式不合法
+	}
 
 	// 调用仓库层获取任务列表
 	// 注意：这里假设 GroupBy 参数用于过滤任务类型
 	taskType := int(param.GroupBy)
-	tasks, err := uc.repo.ListTasks(ctx, param.UserID, param.Period.Start, param.Period.End, taskType)
+	tasks, err := uc.repo.ListTasks(ctx, param.UserID, param.Period.Start, param.Period.End, taskType, param.Match)
 	if err != nil {
 		return nil, err // 返回仓库层的错误
 	}
@@ -576,7 +1161,7 @@ func (uc *TaskUsecase) GetTaskStats(ctx context.Context, param GetTaskStatsParam
 	}
 
 	// 获取指定时间范围内的所有日任务
-	tasks, err := uc.repo.ListTasks(ctx, param.UserID, param.Period.Start, param.Period.End, int(PeriodDay))
+	tasks, err := uc.repo.ListTasks(ctx, param.UserID, param.Period.Start, param.Period.End, int(PeriodDay), PeriodMatchContained)
 	if err != nil {
 		return nil, err // 返回仓库层的错误
 	}
@@ -604,6 +1189,28 @@ func (uc *TaskUsecase) GetTaskStats(ctx context.Context, param GetTaskStatsParam
 		// 累加统计数据
 		statsMap[groupKey].TaskCount++
 		statsMap[groupKey].ScoreTotal += task.Score
+		if task.CarryOverCount > 0 {
+			statsMap[groupKey].CarriedOver++
+		}
+		if task.Status == TaskStatusCompleted {
+			statsMap[groupKey].Completed++
+			if task.CompletedLate() {
+				statsMap[groupKey].CompletedLate++
+			}
+		}
+	}
+
+	// 工时按记录的开始时间分组，包括非日任务上记录的工时
+	entries, err := uc.repo.ListTimeEntriesInRange(ctx, param.UserID, param.Period.Start, param.Period.End)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		groupKey := uc.generateGroupKey(entry.StartedAt, param.GroupBy)
+		if _, exists := statsMap[groupKey]; !exists {
+			statsMap[groupKey] = &GroupStat{GroupKey: groupKey}
+		}
+		statsMap[groupKey].TimeSpent += entry.Duration
 	}
 
 	// 将 map 转换为切片
@@ -749,3 +1356,13 @@ func generateID() string {
 	// 生成UUID并去除连字符，符合项目规范
 	return strings.ReplaceAll(uuid.NewString(), "-", "")
 }
+
+// updateTaskWithHistory 保存任务并记录与 before 相比发生变化的字段
+func (uc *TaskUsecase) updateTaskWithHistory(ctx context.Context, before, task *Task) error {
+	return uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.UpdateTask(ctx, task); err != nil {
+			return err
+		}
+		return uc.recordTaskChange(ctx, ChangeActionUpdate, before, task)
+	})
+}
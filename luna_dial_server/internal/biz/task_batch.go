@@ -0,0 +1,189 @@
+package biz
+
+import (
+	"context"
+	"errors"
+)
+
+// 单次批量操作最多涉及的任务数（所有操作的任务数之和）
+const MaxBatchTaskItems = 500
+
+// BatchTaskAction 批量操作类型
+type BatchTaskAction int
+
+const (
+	BatchTaskActionUpdate BatchTaskAction = iota // 修改状态、优先级、标签、图标或时间段
+	BatchTaskActionDelete                        // 删除（移入回收站）
+	BatchTaskActionMove                          // 移动到新的父任务下
+)
+
+// BatchItemStatus 批量操作中单个任务的执行结果
+type BatchItemStatus string
+
+const (
+	BatchItemSucceeded  BatchItemStatus = "succeeded"
+	BatchItemFailed     BatchItemStatus = "failed"
+	BatchItemRolledBack BatchItemStatus = "rolled_back" // 全部成功模式下执行成功，但因其他任务失败被回滚
+	BatchItemSkipped    BatchItemStatus = "skipped"     // 全部成功模式下已有任务失败，未执行
+)
+
+// 批量操作中的一个操作，作用于 TaskIDs 中的每个任务
+type BatchTaskOperation struct {
+	Action  BatchTaskAction
+	TaskIDs []string
+
+	// BatchTaskActionUpdate：只修改传递了的字段，至少传一个
+	Status   *TaskStatus
+	Priority *TaskPriority
+	Tags     *[]string
+	Icon     *string
+	Period   *Period
+
+	// BatchTaskActionDelete
+	DeleteMode TaskDeleteMode
+
+	// BatchTaskActionMove：为空表示移动到根级别
+	NewParentID string
+}
+
+// 批量操作参数
+type BatchTaskParam struct {
+	UserID     string
+	Operations []BatchTaskOperation
+	Atomic     bool // 全部成功模式：任一任务失败时回滚整个批次
+}
+
+// BatchTaskItemResult 单个任务的执行结果
+type BatchTaskItemResult struct {
+	Operation int             `json:"operation"` // 操作在请求中的下标
+	TaskID    string          `json:"task_id"`
+	Status    BatchItemStatus `json:"status"`
+	Task      *Task           `json:"task,omitempty"` // 修改、移动成功后的任务
+	Err       error           `json:"-"`
+}
+
+// BatchTaskResult 批量操作结果
+type BatchTaskResult struct {
+	Atomic     bool                  `json:"atomic"`
+	RolledBack bool                  `json:"rolled_back"`
+	Succeeded  int                   `json:"succeeded"`
+	Failed     int                   `json:"failed"`
+	Items      []BatchTaskItemResult `json:"items"`
+}
+
+// errBatchRollback 全部成功模式下有任务失败，用于回滚外层事务
+var errBatchRollback = errors.New("batch rolled back")
+
+// validateBatchTaskParam 校验操作本身，任务级别的校验由 UpdateTask、DeleteTask、MoveTask 完成
+func validateBatchTaskParam(param BatchTaskParam) error {
+	if param.UserID == "" {
+		return ErrUserIDEmpty
+	}
+	if len(param.Operations) == 0 {
+		return ErrInvalidInput
+	}
+	total := 0
+	for _, op := range param.Operations {
+		if len(op.TaskIDs) == 0 {
+			return ErrInvalidInput
+		}
+		total += len(op.TaskIDs)
+		switch op.Action {
+		case BatchTaskActionUpdate:
+			if op.Status == nil && op.Priority == nil && op.Tags == nil && op.Icon == nil && op.Period == nil {
+				return ErrInvalidInput
+			}
+		case BatchTaskActionDelete, BatchTaskActionMove:
+		default:
+			return ErrInvalidInput
+		}
+	}
+	if total > MaxBatchTaskItems {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// 批量修改、删除、移动任务
+// 整个批次在一个事务中执行，每个任务在各自的保存点中执行：
+// 默认模式下失败的任务只回滚自身，其他任务照常提交；全部成功模式下任一任务失败即回滚整个批次，后续任务不再执行
+// 每个任务复用 UpdateTask、DeleteTask、MoveTask，校验规则与单个操作一致
+func (uc *TaskUsecase) BatchTasks(ctx context.Context, param BatchTaskParam) (*BatchTaskResult, error) {
+	if err := validateBatchTaskParam(param); err != nil {
+		return nil, err
+	}
+
+	result := &BatchTaskResult{Atomic: param.Atomic, Items: make([]BatchTaskItemResult, 0)}
+	err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		failed := false
+		for i, op := range param.Operations {
+			for _, taskID := range op.TaskIDs {
+				item := BatchTaskItemResult{Operation: i, TaskID: taskID}
+				if failed && param.Atomic {
+					item.Status = BatchItemSkipped
+					result.Items = append(result.Items, item)
+					continue
+				}
+
+				var task *Task
+				err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+					var err error
+					task, err = uc.applyBatchOperation(ctx, param.UserID, taskID, op)
+					return err
+				})
+				if err != nil {
+					failed = true
+					item.Status = BatchItemFailed
+					item.Err = err
+				} else {
+					item.Status = BatchItemSucceeded
+					item.Task = task
+				}
+				result.Items = append(result.Items, item)
+			}
+		}
+		if failed && param.Atomic {
+			return errBatchRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchRollback) {
+		return nil, err
+	}
+
+	result.RolledBack = err != nil
+	for i := range result.Items {
+		item := &result.Items[i]
+		if result.RolledBack && item.Status == BatchItemSucceeded {
+			item.Status = BatchItemRolledBack
+			item.Task = nil
+		}
+		switch item.Status {
+		case BatchItemSucceeded:
+			result.Succeeded++
+		case BatchItemFailed:
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// applyBatchOperation 对单个任务执行操作，需要在事务中调用
+func (uc *TaskUsecase) applyBatchOperation(ctx context.Context, userID, taskID string, op BatchTaskOperation) (*Task, error) {
+	switch op.Action {
+	case BatchTaskActionDelete:
+		return nil, uc.DeleteTask(ctx, DeleteTaskParam{TaskID: taskID, UserID: userID, Mode: op.DeleteMode})
+	case BatchTaskActionMove:
+		return uc.MoveTask(ctx, MoveTaskParam{TaskID: taskID, UserID: userID, NewParentID: op.NewParentID})
+	default:
+		return uc.UpdateTask(ctx, UpdateTaskParam{
+			TaskID:   taskID,
+			UserID:   userID,
+			Status:   op.Status,
+			Priority: op.Priority,
+			Tags:     op.Tags,
+			Icon:     op.Icon,
+			Period:   op.Period,
+		})
+	}
+}
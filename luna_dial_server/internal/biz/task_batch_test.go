@@ -0,0 +1,87 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskUsecase_BatchTasks(t *testing.T) {
+	ctx := context.Background()
+	completed := TaskStatusCompleted
+	high := TaskPriorityHigh
+	// 周任务 week 和同一周内三个独立的日任务
+	setup := func() (*TaskUsecase, *memoryTaskRepo) {
+		repo := newMemoryTaskRepo(
+			newTestTask("week", PeriodWeek, date(2025, 1, 6), TaskStatusNotStarted, nil),
+			newTestTask("a", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, nil),
+			newTestTask("b", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, nil),
+			newTestTask("c", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, nil),
+		)
+		return NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo()), repo
+	}
+
+	t.Run("逐个执行并返回每个任务的结果", func(t *testing.T) {
+		usecase, repo := setup()
+
+		result, err := usecase.BatchTasks(ctx, BatchTaskParam{UserID: "user-123", Operations: []BatchTaskOperation{
+			{Action: BatchTaskActionUpdate, TaskIDs: []string{"a", "missing"}, Status: &completed, Priority: &high},
+			{Action: BatchTaskActionMove, TaskIDs: []string{"b"}, NewParentID: "week"},
+			{Action: BatchTaskActionDelete, TaskIDs: []string{"c"}},
+		}})
+
+		require.NoError(t, err)
+		assert.False(t, result.RolledBack)
+		assert.Equal(t, 3, result.Succeeded)
+		assert.Equal(t, 1, result.Failed)
+		require.Len(t, result.Items, 4)
+		assert.Equal(t, BatchItemFailed, result.Items[1].Status)
+		assert.Equal(t, ErrTaskNotFound, result.Items[1].Err)
+		assert.Equal(t, 2, result.Items[3].Operation)
+
+		assert.Equal(t, TaskStatusCompleted, repo.tasks["a"].Status)
+		assert.Equal(t, TaskPriorityHigh, repo.tasks["a"].Priority)
+		assert.Equal(t, "week", repo.tasks["b"].ParentID)
+		assert.NotContains(t, repo.tasks, "c")
+		assert.Contains(t, repo.trashed, "c")
+	})
+
+	t.Run("全部成功模式下任一任务失败回滚整个批次", func(t *testing.T) {
+		usecase, repo := setup()
+
+		result, err := usecase.BatchTasks(ctx, BatchTaskParam{UserID: "user-123", Atomic: true, Operations: []BatchTaskOperation{
+			{Action: BatchTaskActionUpdate, TaskIDs: []string{"a"}, Status: &completed},
+			{Action: BatchTaskActionMove, TaskIDs: []string{"week"}, NewParentID: "a"}, // 周任务不能挂到日任务下
+			{Action: BatchTaskActionDelete, TaskIDs: []string{"c"}},
+		}})
+
+		require.NoError(t, err)
+		assert.True(t, result.RolledBack)
+		assert.Equal(t, 0, result.Succeeded)
+		assert.Equal(t, 1, result.Failed)
+		assert.Equal(t, []BatchItemStatus{BatchItemRolledBack, BatchItemFailed, BatchItemSkipped},
+			[]BatchItemStatus{result.Items[0].Status, result.Items[1].Status, result.Items[2].Status})
+		assert.Equal(t, ErrSubTaskTypeInvalid, result.Items[1].Err)
+		assert.Nil(t, result.Items[0].Task)
+
+		assert.Equal(t, TaskStatusNotStarted, repo.tasks["a"].Status)
+		assert.Contains(t, repo.tasks, "c")
+	})
+
+	t.Run("操作不合法", func(t *testing.T) {
+		usecase, _ := setup()
+
+		_, err := usecase.BatchTasks(ctx, BatchTaskParam{UserID: "user-123", Operations: []BatchTaskOperation{
+			{Action: BatchTaskActionUpdate, TaskIDs: []string{"a"}},
+		}})
+		assert.Equal(t, ErrInvalidInput, err, "update without fields")
+
+		tooMany := make([]string, MaxBatchTaskItems+1)
+		_, err = usecase.BatchTasks(ctx, BatchTaskParam{UserID: "user-123", Operations: []BatchTaskOperation{
+			{Action: BatchTaskActionDelete, TaskIDs: tooMany},
+		}})
+		assert.Equal(t, ErrInvalidInput, err)
+	})
+}
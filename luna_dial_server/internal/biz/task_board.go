@@ -0,0 +1,265 @@
+package biz
+
+import (
+	"context"
+	"sort"
+)
+
+// boardStatuses 看板的列，按状态顺序排列
+var boardStatuses = []TaskStatus{TaskStatusNotStarted, TaskStatusInProgress, TaskStatusCompleted, TaskStatusCancelled}
+
+// WIPLimits 看板每一列（任务状态）的在制品数量上限，0 表示不限制
+type WIPLimits struct {
+	NotStarted int `json:"not_started"`
+	InProgress int `json:"in_progress"`
+	Completed  int `json:"completed"`
+	Cancelled  int `json:"cancelled"`
+}
+
+// Limit 状态对应列的上限
+func (l WIPLimits) Limit(status TaskStatus) int {
+	switch status {
+	case TaskStatusNotStarted:
+		return l.NotStarted
+	case TaskStatusInProgress:
+		return l.InProgress
+	case TaskStatusCompleted:
+		return l.Completed
+	case TaskStatusCancelled:
+		return l.Cancelled
+	default:
+		return 0
+	}
+}
+
+// set 设置状态对应列的上限
+func (l *WIPLimits) set(status TaskStatus, limit int) {
+	switch status {
+	case TaskStatusNotStarted:
+		l.NotStarted = limit
+	case TaskStatusInProgress:
+		l.InProgress = limit
+	case TaskStatusCompleted:
+		l.Completed = limit
+	case TaskStatusCancelled:
+		l.Cancelled = limit
+	}
+}
+
+// BoardScope 看板包含的任务：指定任务的所有后代，或时间段按 Match 方式命中 Period 的指定类型任务
+type BoardScope struct {
+	RootTaskID string // 不为空时为该任务的子树（不包括任务自身）
+	PeriodType PeriodType
+	Period     Period
+	Match      PeriodMatch
+}
+
+// BoardColumn 看板的一列，卡片按看板位置排列
+type BoardColumn struct {
+	Status    TaskStatus `json:"status"`
+	Tasks     []*Task    `json:"tasks"`
+	Count     int        `json:"count"`
+	WIPLimit  int        `json:"wip_limit"`  // 0 表示不限制
+	OverLimit bool       `json:"over_limit"` // 卡片数超过上限（例如上限在卡片移入后被调低）
+}
+
+// TaskBoard 按状态分列的任务看板
+type TaskBoard struct {
+	Columns []*BoardColumn `json:"columns"`
+}
+
+// 获取任务看板参数
+type GetTaskBoardParam struct {
+	UserID string
+	Scope  BoardScope
+}
+
+// 移动看板卡片参数
+type MoveBoardCardParam struct {
+	UserID   string
+	TaskID   string
+	Scope    BoardScope // 与获取看板时相同，用于确定目标列中的位置和在制品数量
+	Status   TaskStatus
+	Position *int // 在目标列中的位置（从0开始，不含被移动的卡片），为空时放到最后
+
+	// 忽略阻塞依赖，同 UpdateTaskParam
+	OverrideBlockers bool
+}
+
+func (s BoardScope) validate() error {
+	if s.RootTaskID == "" && !s.Period.IsValid() {
+		return ErrInvalidInput // 需要指定子树或合法的时间段
+	}
+	if !s.Match.IsValid() {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// sortBoardCards 按看板位置排列卡片，位置相同时先创建的在前
+func sortBoardCards(tasks []*Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].BoardRank != tasks[j].BoardRank {
+			return tasks[i].BoardRank < tasks[j].BoardRank
+		}
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
+
+// listBoardTasks 获取看板范围内的任务
+func (uc *TaskUsecase) listBoardTasks(ctx context.Context, userID string, scope BoardScope) ([]*Task, error) {
+	if scope.RootTaskID == "" {
+		return uc.repo.ListTasks(ctx, userID, scope.Period.Start, scope.Period.End, int(scope.PeriodType), scope.Match)
+	}
+
+	roots, err := uc.repo.ListSubtreeTasks(ctx, scope.RootTaskID, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return nil, ErrTaskNotFound
+	}
+
+	var tasks []*Task
+	for queue := append([]*Task{}, roots[0].Children...); len(queue) > 0; queue = queue[1:] {
+		tasks = append(tasks, queue[0])
+		queue = append(queue, queue[0].Children...)
+	}
+	for _, task := range tasks {
+		task.Children = nil
+	}
+	return tasks, nil
+}
+
+// buildTaskBoard 把任务按状态分列
+func buildTaskBoard(tasks []*Task, limits WIPLimits) *TaskBoard {
+	board := &TaskBoard{Columns: make([]*BoardColumn, len(boardStatuses))}
+	byStatus := make(map[TaskStatus]*BoardColumn, len(boardStatuses))
+	for i, status := range boardStatuses {
+		board.Columns[i] = &BoardColumn{Status: status, Tasks: []*Task{}, WIPLimit: limits.Limit(status)}
+		byStatus[status] = board.Columns[i]
+	}
+	for _, task := range tasks {
+		if column, ok := byStatus[task.Status]; ok {
+			column.Tasks = append(column.Tasks, task)
+		}
+	}
+	for _, column := range board.Columns {
+		sortBoardCards(column.Tasks)
+		column.Count = len(column.Tasks)
+		column.OverLimit = column.WIPLimit > 0 && column.Count > column.WIPLimit
+	}
+	return board
+}
+
+// 获取任务看板：范围内的任务按状态分为四列，每列按看板位置排列
+func (uc *TaskUsecase) GetTaskBoard(ctx context.Context, param GetTaskBoardParam) (*TaskBoard, error) {
+	if param.UserID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	if err := param.Scope.validate(); err != nil {
+		return nil, err
+	}
+
+	settings, err := loadUserSettings(ctx, uc.settingsRepo, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := uc.listBoardTasks(ctx, param.UserID, param.Scope)
+	if err != nil {
+		return nil, err
+	}
+	return buildTaskBoard(tasks, settings.WIPLimits), nil
+}
+
+// 移动看板卡片：修改任务状态（与 UpdateTask 相同的校验和状态传播）并放到目标列的指定位置，在同一事务中完成
+// 卡片移入其他列时，目标列已达到在制品上限则返回 ErrWIPLimitExceeded；列内调整位置不受上限限制
+// 读取看板前按用户加锁，并发移入同一列的卡片依次检查上限
+func (uc *TaskUsecase) MoveBoardCard(ctx context.Context, param MoveBoardCardParam) (*TaskBoard, error) {
+	if param.UserID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	if param.TaskID == "" || (param.Position != nil && *param.Position < 0) {
+		return nil, ErrInvalidInput
+	}
+	if err := param.Scope.validate(); err != nil {
+		return nil, err
+	}
+
+	settings, err := loadUserSettings(ctx, uc.settingsRepo, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var board *TaskBoard
+	err = uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.LockUserTasks(ctx, param.UserID); err != nil {
+			return err
+		}
+		tasks, err := uc.listBoardTasks(ctx, param.UserID, param.Scope)
+		if err != nil {
+			return err
+		}
+		var card *Task
+		column := make([]*Task, 0)
+		for _, task := range tasks {
+			switch {
+			case task.ID == param.TaskID:
+				card = task
+			case task.Status == param.Status:
+				column = append(column, task)
+			}
+		}
+		if card == nil {
+			return ErrTaskNotOnBoard
+		}
+		limit := settings.WIPLimits.Limit(param.Status)
+		if card.Status != param.Status && limit > 0 && len(column) >= limit {
+			return ErrWIPLimitExceeded
+		}
+
+		if card.Status != param.Status {
+			status := param.Status
+			if _, err := uc.UpdateTask(ctx, UpdateTaskParam{
+				TaskID:           card.ID,
+				UserID:           param.UserID,
+				Status:           &status,
+				OverrideBlockers: param.OverrideBlockers,
+			}); err != nil {
+				return err
+			}
+		}
+
+		// 卡片插入到目标列的指定位置，只调整需要改动的看板位置
+		sortBoardCards(column)
+		position := len(column)
+		if param.Position != nil && *param.Position < position {
+			position = *param.Position
+		}
+		ordered := make([]*Task, 0, len(column)+1)
+		ordered = append(ordered, column[:position]...)
+		ordered = append(ordered, card)
+		ordered = append(ordered, column[position:]...)
+		changed := rerankTasks(ordered, func(task *Task) int64 { return task.BoardRank })
+		if len(changed) > 0 {
+			if err := uc.repo.UpdateTaskBoardRanks(ctx, param.UserID, changed); err != nil {
+				return err
+			}
+		}
+
+		// 状态变化可能传播到范围内的其他任务，重新读取整个看板
+		tasks, err = uc.listBoardTasks(ctx, param.UserID, param.Scope)
+		if err != nil {
+			return err
+		}
+		board = buildTaskBoard(tasks, settings.WIPLimits)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return board, nil
+}
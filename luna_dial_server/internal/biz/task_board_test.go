@@ -0,0 +1,104 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func boardColumnIDs(board *TaskBoard, status TaskStatus) []string {
+	for _, column := range board.Columns {
+		if column.Status == status {
+			ids := make([]string, len(column.Tasks))
+			for i, task := range column.Tasks {
+				ids[i] = task.ID
+			}
+			return ids
+		}
+	}
+	return nil
+}
+
+func TestTaskUsecase_TaskBoard(t *testing.T) {
+	ctx := context.Background()
+	week := NewPeriodFromPeriodType(PeriodWeek, date(2025, 1, 13))
+	newBoardTask := func(id string, status TaskStatus, rank int64) *Task {
+		return &Task{ID: id, UserID: "u1", TaskType: PeriodWeek, TimePeriod: week, Status: status, BoardRank: rank}
+	}
+	newUsecase := func() (*TaskUsecase, *memoryTaskRepo) {
+		repo := newMemoryTaskRepo(
+			newBoardTask("a", TaskStatusNotStarted, 2048),
+			newBoardTask("b", TaskStatusNotStarted, 1024),
+			newBoardTask("c", TaskStatusInProgress, 1024),
+			newBoardTask("d", TaskStatusInProgress, 2048),
+			newBoardTask("e", TaskStatusCompleted, 0),
+		)
+		settingsRepo := newMockUserSettingsRepo()
+		settingsRepo.settings["u1"] = &UserSettings{UserID: "u1", WIPLimits: WIPLimits{InProgress: 3}}
+		return NewTaskUsecase(repo, settingsRepo, newMockChangeHistoryRepo()), repo
+	}
+	scope := BoardScope{PeriodType: PeriodWeek, Period: week}
+
+	t.Run("按状态分列并按看板位置排列", func(t *testing.T) {
+		uc, _ := newUsecase()
+		board, err := uc.GetTaskBoard(ctx, GetTaskBoardParam{UserID: "u1", Scope: scope})
+		require.NoError(t, err)
+		require.Len(t, board.Columns, 4)
+		assert.Equal(t, []string{"b", "a"}, boardColumnIDs(board, TaskStatusNotStarted))
+		assert.Equal(t, []string{"c", "d"}, boardColumnIDs(board, TaskStatusInProgress))
+		assert.Equal(t, []string{"e"}, boardColumnIDs(board, TaskStatusCompleted))
+		assert.Empty(t, boardColumnIDs(board, TaskStatusCancelled))
+		assert.Equal(t, 3, board.Columns[1].WIPLimit)
+		assert.Equal(t, 2, board.Columns[1].Count)
+
+		_, err = uc.GetTaskBoard(ctx, GetTaskBoardParam{UserID: "u1"})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("移动卡片修改状态和位置", func(t *testing.T) {
+		uc, repo := newUsecase()
+		position := 1
+		board, err := uc.MoveBoardCard(ctx, MoveBoardCardParam{UserID: "u1", TaskID: "a", Scope: scope, Status: TaskStatusInProgress, Position: &position})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b"}, boardColumnIDs(board, TaskStatusNotStarted))
+		assert.Equal(t, []string{"c", "a", "d"}, boardColumnIDs(board, TaskStatusInProgress))
+		assert.Equal(t, TaskStatusInProgress, repo.tasks["a"].Status)
+		assert.NotNil(t, repo.tasks["a"].StartedAt)
+
+		// 列内调整位置不受在制品上限限制，没有指定位置时放到最后
+		board, err = uc.MoveBoardCard(ctx, MoveBoardCardParam{UserID: "u1", TaskID: "c", Scope: scope, Status: TaskStatusInProgress})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "d", "c"}, boardColumnIDs(board, TaskStatusInProgress))
+
+		// 目标列已满
+		_, err = uc.MoveBoardCard(ctx, MoveBoardCardParam{UserID: "u1", TaskID: "b", Scope: scope, Status: TaskStatusInProgress})
+		assert.ErrorIs(t, err, ErrWIPLimitExceeded)
+		assert.Equal(t, TaskStatusNotStarted, repo.tasks["b"].Status)
+	})
+
+	t.Run("与更新任务相同的状态校验", func(t *testing.T) {
+		uc, repo := newUsecase()
+		repo.tasks["x"] = newBoardTask("x", TaskStatusCancelled, 0)
+		_, err := uc.MoveBoardCard(ctx, MoveBoardCardParam{UserID: "u1", TaskID: "x", Scope: scope, Status: TaskStatusCompleted})
+		assert.ErrorIs(t, err, ErrTaskStatusTransition)
+
+		_, err = uc.MoveBoardCard(ctx, MoveBoardCardParam{UserID: "u1", TaskID: "missing", Scope: scope, Status: TaskStatusCompleted})
+		assert.ErrorIs(t, err, ErrTaskNotOnBoard)
+	})
+
+	t.Run("子树看板", func(t *testing.T) {
+		uc, repo := newUsecase()
+		repo.tasks["a1"] = &Task{ID: "a1", UserID: "u1", ParentID: "a", RootTaskID: "a", Status: TaskStatusNotStarted}
+		repo.tasks["a2"] = &Task{ID: "a2", UserID: "u1", ParentID: "a1", RootTaskID: "a", Status: TaskStatusCompleted}
+		board, err := uc.GetTaskBoard(ctx, GetTaskBoardParam{UserID: "u1", Scope: BoardScope{RootTaskID: "a1"}})
+		require.NoError(t, err)
+		assert.Empty(t, boardColumnIDs(board, TaskStatusNotStarted))
+		assert.Equal(t, []string{"a2"}, boardColumnIDs(board, TaskStatusCompleted))
+
+		board, err = uc.GetTaskBoard(ctx, GetTaskBoardParam{UserID: "u1", Scope: BoardScope{RootTaskID: "a"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a1"}, boardColumnIDs(board, TaskStatusNotStarted))
+	})
+}
@@ -0,0 +1,350 @@
+package biz
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+)
+
+// ChecklistItem 任务清单项：比子任务更轻量，只有文本和完成状态，按 Position 排序
+type ChecklistItem struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"task_id"`
+	UserID    string    `json:"user_id"`
+	Text      string    `json:"text"`
+	Done      bool      `json:"done"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChecklistItemInput 创建/整体替换清单时的清单项，顺序即位置
+type ChecklistItemInput struct {
+	Text string
+	Done bool
+}
+
+// 设置任务描述参数
+type SetTaskDescriptionParam struct {
+	TaskID      string
+	UserID      string
+	Description string
+}
+
+// 添加清单项参数
+type AddChecklistItemParam struct {
+	TaskID   string
+	UserID   string
+	Text     string
+	Position *int // 插入位置，为空时追加到末尾
+}
+
+// 编辑清单项参数
+type UpdateChecklistItemParam struct {
+	TaskID   string
+	UserID   string
+	ItemID   string
+	Text     *string
+	Done     *bool
+	Position *int // 移动到指定位置
+}
+
+// 删除清单项参数
+type DeleteChecklistItemParam struct {
+	TaskID string
+	UserID string
+	ItemID string
+}
+
+// 获取清单参数
+type ListChecklistItemsParam struct {
+	TaskID string
+	UserID string
+}
+
+// CalcChecklistRatio 清单完成比例，保留两位小数；没有清单项时为0
+func CalcChecklistRatio(done, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(float64(done)/float64(total)*100) / 100
+}
+
+// applyChecklist 把清单挂到任务上，并同步清单统计字段
+func (t *Task) applyChecklist(items []*ChecklistItem) {
+	done := 0
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+	t.Checklist = items
+	t.ChecklistTotal = len(items)
+	t.ChecklistDone = done
+	t.ChecklistRatio = CalcChecklistRatio(done, len(items))
+}
+
+// newChecklistItems 根据输入创建清单项，位置从0开始连续编号
+func newChecklistItems(task *Task, inputs []ChecklistItemInput) ([]*ChecklistItem, error) {
+	now := time.Now()
+	items := make([]*ChecklistItem, 0, len(inputs))
+	for i, input := range inputs {
+		text := strings.TrimSpace(input.Text)
+		if text == "" {
+			return nil, ErrChecklistItemTextEmpty
+		}
+		items = append(items, &ChecklistItem{
+			ID:        generateID(),
+			TaskID:    task.ID,
+			UserID:    task.UserID,
+			Text:      text,
+			Done:      input.Done,
+			Position:  i,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	return items, nil
+}
+
+// createTaskWithChecklist 在同一事务中创建任务和它的清单，并重算父任务链的汇总分数
+func (uc *TaskUsecase) createTaskWithChecklist(ctx context.Context, task *Task, inputs []ChecklistItemInput) error {
+	items, err := newChecklistItems(task, inputs)
+	if err != nil {
+		return err
+	}
+	task.applyChecklist(items)
+	if task.Weight == 0 {
+		task.Weight = DefaultTaskWeight
+	}
+
+	return uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.placeNewTask(ctx, task); err != nil {
+			return err
+		}
+		if err := uc.assignSortRank(ctx, task); err != nil {
+			return err
+		}
+		if err := uc.repo.CreateTask(ctx, task); err != nil {
+			return err
+		}
+		if err := uc.recordTaskChange(ctx, ChangeActionCreate, nil, task); err != nil {
+			return err
+		}
+		if err := uc.refreshRollup(ctx, task); err != nil {
+			return err
+		}
+		if task.Estimate > 0 {
+			if err := uc.repo.RefreshTreeEstimate(ctx, treeRootTaskID(task), task.UserID); err != nil {
+				return err
+			}
+			if task.RootTaskID == task.ID {
+				task.TreeEstimate = task.Estimate
+			}
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return uc.repo.ReplaceChecklistItems(ctx, task.ID, task.UserID, items)
+	})
+}
+
+// saveChecklist 重新编号并保存整个清单，同时更新任务上的清单统计
+// 需要在事务中调用
+func (uc *TaskUsecase) saveChecklist(ctx context.Context, task *Task, items []*ChecklistItem) error {
+	for i, item := range items {
+		item.Position = i
+	}
+	task.applyChecklist(items)
+	task.UpdatedAt = time.Now()
+
+	if err := uc.repo.ReplaceChecklistItems(ctx, task.ID, task.UserID, items); err != nil {
+		return err
+	}
+	return uc.repo.UpdateTask(ctx, task)
+}
+
+// 设置任务描述（markdown）
+func (uc *TaskUsecase) SetTaskDescription(ctx context.Context, param SetTaskDescriptionParam) (*Task, error) {
+	if param.TaskID == "" || param.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	task, err := uc.repo.GetTask(ctx, param.TaskID, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	before := *task
+	task.Description = param.Description
+	task.UpdatedAt = time.Now()
+	if err := uc.updateTaskWithHistory(ctx, &before, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// 获取任务清单（按位置排序）
+func (uc *TaskUsecase) ListChecklistItems(ctx context.Context, param ListChecklistItemsParam) ([]*ChecklistItem, error) {
+	if err := uc.checkTaskExists(ctx, param.TaskID, param.UserID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListChecklistItems(ctx, param.TaskID, param.UserID)
+}
+
+// 添加清单项，返回更新后的任务（包含完整清单）
+func (uc *TaskUsecase) AddChecklistItem(ctx context.Context, param AddChecklistItemParam) (*Task, error) {
+	if param.TaskID == "" || param.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+	text := strings.TrimSpace(param.Text)
+	if text == "" {
+		return nil, ErrChecklistItemTextEmpty
+	}
+
+	var task *Task
+	err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		var items []*ChecklistItem
+		var err error
+		task, items, err = uc.loadTaskWithChecklist(ctx, param.TaskID, param.UserID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		item := &ChecklistItem{
+			ID:        generateID(),
+			TaskID:    task.ID,
+			UserID:    task.UserID,
+			Text:      text,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		position := len(items)
+		if param.Position != nil {
+			position = clampPosition(*param.Position, len(items))
+		}
+		items = append(items[:position], append([]*ChecklistItem{item}, items[position:]...)...)
+
+		return uc.saveChecklist(ctx, task, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// 编辑清单项（文本、完成状态、位置），返回更新后的任务（包含完整清单）
+func (uc *TaskUsecase) UpdateChecklistItem(ctx context.Context, param UpdateChecklistItemParam) (*Task, error) {
+	if param.TaskID == "" || param.UserID == "" || param.ItemID == "" {
+		return nil, ErrInvalidInput
+	}
+	if param.Text != nil && strings.TrimSpace(*param.Text) == "" {
+		return nil, ErrChecklistItemTextEmpty
+	}
+
+	var task *Task
+	err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		var items []*ChecklistItem
+		var err error
+		task, items, err = uc.loadTaskWithChecklist(ctx, param.TaskID, param.UserID)
+		if err != nil {
+			return err
+		}
+
+		index := -1
+		for i, item := range items {
+			if item.ID == param.ItemID {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return ErrChecklistItemNotFound
+		}
+
+		item := items[index]
+		if param.Text != nil {
+			item.Text = strings.TrimSpace(*param.Text)
+		}
+		if param.Done != nil {
+			item.Done = *param.Done
+		}
+		item.UpdatedAt = time.Now()
+
+		if param.Position != nil {
+			items = append(items[:index], items[index+1:]...)
+			position := clampPosition(*param.Position, len(items))
+			items = append(items[:position], append([]*ChecklistItem{item}, items[position:]...)...)
+		}
+
+		return uc.saveChecklist(ctx, task, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// 删除清单项，返回更新后的任务（包含完整清单）
+func (uc *TaskUsecase) DeleteChecklistItem(ctx context.Context, param DeleteChecklistItemParam) (*Task, error) {
+	if param.TaskID == "" || param.UserID == "" || param.ItemID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	var task *Task
+	err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		var items []*ChecklistItem
+		var err error
+		task, items, err = uc.loadTaskWithChecklist(ctx, param.TaskID, param.UserID)
+		if err != nil {
+			return err
+		}
+
+		kept := make([]*ChecklistItem, 0, len(items))
+		for _, item := range items {
+			if item.ID != param.ItemID {
+				kept = append(kept, item)
+			}
+		}
+		if len(kept) == len(items) {
+			return ErrChecklistItemNotFound
+		}
+
+		return uc.saveChecklist(ctx, task, kept)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (uc *TaskUsecase) loadTaskWithChecklist(ctx context.Context, taskID, userID string) (*Task, []*ChecklistItem, error) {
+	task, err := uc.repo.GetTask(ctx, taskID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if task == nil {
+		return nil, nil, ErrTaskNotFound
+	}
+	items, err := uc.repo.ListChecklistItems(ctx, taskID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return task, items, nil
+}
+
+// clampPosition 把位置限制在 [0, length] 范围内
+func clampPosition(position, length int) int {
+	if position < 0 {
+		return 0
+	}
+	if position > length {
+		return length
+	}
+	return position
+}
@@ -0,0 +1,152 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checklistTexts(items []*ChecklistItem) []string {
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = item.Text
+	}
+	return texts
+}
+
+func TestCalcChecklistRatio(t *testing.T) {
+	assert.Equal(t, 0.0, CalcChecklistRatio(0, 0))
+	assert.Equal(t, 0.33, CalcChecklistRatio(1, 3))
+	assert.Equal(t, 1.0, CalcChecklistRatio(2, 2))
+}
+
+func TestTaskUsecase_CreateTask_WithChecklist(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryTaskRepo()
+	usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+	task, err := usecase.CreateTask(ctx, CreateTaskParam{
+		UserID:      "user-123",
+		Title:       "发布版本",
+		Type:        PeriodDay,
+		Period:      NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 6)),
+		Description: "## 步骤\n按顺序执行",
+		Checklist: []ChecklistItemInput{
+			{Text: "打标签", Done: true},
+			{Text: "  构建镜像  "},
+			{Text: "更新文档"},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "## 步骤\n按顺序执行", task.Description)
+	assert.Equal(t, 3, task.ChecklistTotal)
+	assert.Equal(t, 1, task.ChecklistDone)
+	assert.Equal(t, 0.33, task.ChecklistRatio)
+	assert.Equal(t, []string{"打标签", "构建镜像", "更新文档"}, checklistTexts(repo.checklists[task.ID]))
+
+	_, err = usecase.CreateTask(ctx, CreateTaskParam{
+		UserID:    "user-123",
+		Title:     "空清单项",
+		Type:      PeriodDay,
+		Period:    NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 6)),
+		Checklist: []ChecklistItemInput{{Text: " "}},
+	})
+	assert.Equal(t, ErrChecklistItemTextEmpty, err)
+}
+
+func TestTaskUsecase_ChecklistItems(t *testing.T) {
+	ctx := context.Background()
+
+	add := func(usecase *TaskUsecase, text string, position *int) *Task {
+		task, err := usecase.AddChecklistItem(ctx, AddChecklistItemParam{TaskID: "task", UserID: "user-123", Text: text, Position: position})
+		require.NoError(t, err)
+		return task
+	}
+	intPtr := func(v int) *int { return &v }
+	setup := func() (*TaskUsecase, *memoryTaskRepo) {
+		repo := newMemoryTaskRepo(newTestTask("task", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, nil))
+		return NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo()), repo
+	}
+
+	t.Run("追加和插入", func(t *testing.T) {
+		usecase, _ := setup()
+		add(usecase, "a", nil)
+		add(usecase, "c", nil)
+		task := add(usecase, "b", intPtr(1))
+
+		assert.Equal(t, []string{"a", "b", "c"}, checklistTexts(task.Checklist))
+		for i, item := range task.Checklist {
+			assert.Equal(t, i, item.Position)
+		}
+		assert.Equal(t, 3, task.ChecklistTotal)
+	})
+
+	t.Run("勾选和移动", func(t *testing.T) {
+		usecase, repo := setup()
+		add(usecase, "a", nil)
+		add(usecase, "b", nil)
+		task := add(usecase, "c", nil)
+		done := true
+
+		task, err := usecase.UpdateChecklistItem(ctx, UpdateChecklistItemParam{
+			TaskID: "task", UserID: "user-123", ItemID: task.Checklist[2].ID, Done: &done, Position: intPtr(0),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c", "a", "b"}, checklistTexts(task.Checklist))
+		assert.True(t, task.Checklist[0].Done)
+		assert.Equal(t, 1, repo.tasks["task"].ChecklistDone)
+		assert.Equal(t, 0.33, repo.tasks["task"].ChecklistRatio)
+	})
+
+	t.Run("删除后位置重新编号", func(t *testing.T) {
+		usecase, _ := setup()
+		add(usecase, "a", nil)
+		add(usecase, "b", nil)
+		task := add(usecase, "c", nil)
+
+		task, err := usecase.DeleteChecklistItem(ctx, DeleteChecklistItemParam{TaskID: "task", UserID: "user-123", ItemID: task.Checklist[0].ID})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "c"}, checklistTexts(task.Checklist))
+		assert.Equal(t, 0, task.Checklist[0].Position)
+		assert.Equal(t, 1, task.Checklist[1].Position)
+	})
+
+	t.Run("错误情况", func(t *testing.T) {
+		usecase, _ := setup()
+		empty := "  "
+
+		_, err := usecase.AddChecklistItem(ctx, AddChecklistItemParam{TaskID: "task", UserID: "user-123", Text: ""})
+		assert.Equal(t, ErrChecklistItemTextEmpty, err)
+
+		_, err = usecase.AddChecklistItem(ctx, AddChecklistItemParam{TaskID: "non-existent", UserID: "user-123", Text: "a"})
+		assert.Equal(t, ErrTaskNotFound, err)
+
+		_, err = usecase.UpdateChecklistItem(ctx, UpdateChecklistItemParam{TaskID: "task", UserID: "user-123", ItemID: "missing", Text: &empty})
+		assert.Equal(t, ErrChecklistItemTextEmpty, err)
+
+		_, err = usecase.DeleteChecklistItem(ctx, DeleteChecklistItemParam{TaskID: "task", UserID: "user-123", ItemID: "missing"})
+		assert.Equal(t, ErrChecklistItemNotFound, err)
+	})
+}
+
+func TestTaskUsecase_UpdateTask_ReplaceChecklist(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryTaskRepo(newTestTask("task", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, nil))
+	usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+	_, err := usecase.AddChecklistItem(ctx, AddChecklistItemParam{TaskID: "task", UserID: "user-123", Text: "旧的"})
+	require.NoError(t, err)
+
+	description := "新的描述"
+	checklist := []ChecklistItemInput{{Text: "x", Done: true}, {Text: "y", Done: true}}
+	task, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "task", UserID: "user-123", Description: &description, Checklist: &checklist})
+
+	require.NoError(t, err)
+	assert.Equal(t, "新的描述", task.Description)
+	assert.Equal(t, 1.0, task.ChecklistRatio)
+	assert.Equal(t, []string{"x", "y"}, checklistTexts(repo.checklists["task"]))
+}
@@ -0,0 +1,315 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TaskDependency 任务依赖关系：TaskID 被 BlockedByTaskID 阻塞
+// 与 ParentID 层级无关，可以跨任务树
+type TaskDependency struct {
+	TaskID          string    `json:"task_id"`
+	BlockedByTaskID string    `json:"blocked_by_task_id"`
+	UserID          string    `json:"user_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// 添加依赖参数
+type AddTaskDependencyParam struct {
+	TaskID          string
+	BlockedByTaskID string
+	UserID          string
+}
+
+// 删除依赖参数
+type RemoveTaskDependencyParam struct {
+	TaskID          string
+	BlockedByTaskID string
+	UserID          string
+}
+
+// 查询某个任务的阻塞任务/被阻塞任务参数
+type ListTaskDependencyParam struct {
+	TaskID string
+	UserID string
+}
+
+// 获取时间段内关键路径参数
+type GetCriticalPathParam struct {
+	UserID string
+	Period Period
+}
+
+// CriticalPath 时间段内最长的依赖链，按执行顺序排列（先做的在前）
+type CriticalPath struct {
+	Tasks  []*Task `json:"tasks"`
+	Length int     `json:"length"`
+}
+
+// isTaskResolved 阻塞任务已完成或已取消时，不再阻塞其他任务
+func isTaskResolved(task *Task) bool {
+	return task.Status == TaskStatusCompleted || task.Status == TaskStatusCancelled
+}
+
+// 添加依赖：TaskID 被 BlockedByTaskID 阻塞
+// 两个任务都必须属于当前用户；会形成环的依赖被拒绝
+func (uc *TaskUsecase) AddTaskDependency(ctx context.Context, param AddTaskDependencyParam) (*TaskDependency, error) {
+	if param.TaskID == "" || param.BlockedByTaskID == "" || param.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+	if param.TaskID == param.BlockedByTaskID {
+		return nil, ErrTaskDependencyCycle // 任务不能阻塞自己
+	}
+
+	for _, taskID := range []string{param.TaskID, param.BlockedByTaskID} {
+		task, err := uc.repo.GetTask(ctx, taskID, param.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if task == nil {
+			return nil, ErrTaskNotFound
+		}
+	}
+
+	dependency := &TaskDependency{
+		TaskID:          param.TaskID,
+		BlockedByTaskID: param.BlockedByTaskID,
+		UserID:          param.UserID,
+		CreatedAt:       time.Now(),
+	}
+
+	// 读取依赖图前按用户加锁，同一用户并发添加的依赖依次检查，不会各自绕过环检测
+	// 回收站中任务的依赖边也参与检测，恢复这些任务时依赖图不会成环
+	err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.LockUserTasks(ctx, param.UserID); err != nil {
+			return err
+		}
+		dependencies, err := uc.repo.ListTaskDependencies(ctx, param.UserID, true)
+		if err != nil {
+			return err
+		}
+
+		// blockedBy 表：任务 -> 阻塞它的任务
+		blockedBy := make(map[string][]string)
+		for _, dep := range dependencies {
+			if dep.TaskID == param.TaskID && dep.BlockedByTaskID == param.BlockedByTaskID {
+				return ErrTaskDependencyExists
+			}
+			blockedBy[dep.TaskID] = append(blockedBy[dep.TaskID], dep.BlockedByTaskID)
+		}
+
+		// 如果 BlockedByTaskID 已经（直接或间接）被 TaskID 阻塞，新边会形成环
+		if dependencyReachable(blockedBy, param.BlockedByTaskID, param.TaskID) {
+			return ErrTaskDependencyCycle
+		}
+
+		return uc.repo.CreateTaskDependency(ctx, dependency)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dependency, nil
+}
+
+// dependencyReachable 沿 blockedBy 边从 from 出发能否到达 to
+func dependencyReachable(blockedBy map[string][]string, from, to string) bool {
+	visited := make(map[string]bool)
+	stack := []string{from}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == to {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		stack = append(stack, blockedBy[current]...)
+	}
+	return false
+}
+
+// 删除依赖
+func (uc *TaskUsecase) RemoveTaskDependency(ctx context.Context, param RemoveTaskDependencyParam) error {
+	if param.TaskID == "" || param.BlockedByTaskID == "" || param.UserID == "" {
+		return ErrInvalidInput
+	}
+
+	blockers, err := uc.repo.ListTaskBlockers(ctx, param.TaskID, param.UserID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, blocker := range blockers {
+		if blocker.ID == param.BlockedByTaskID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrTaskDependencyNotFound
+	}
+
+	return uc.repo.DeleteTaskDependency(ctx, param.TaskID, param.BlockedByTaskID, param.UserID)
+}
+
+// 获取阻塞指定任务的任务列表
+func (uc *TaskUsecase) ListTaskBlockers(ctx context.Context, param ListTaskDependencyParam) ([]*Task, error) {
+	if err := uc.checkTaskExists(ctx, param.TaskID, param.UserID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListTaskBlockers(ctx, param.TaskID, param.UserID)
+}
+
+// 获取被指定任务阻塞的任务列表
+func (uc *TaskUsecase) ListTaskDependents(ctx context.Context, param ListTaskDependencyParam) ([]*Task, error) {
+	if err := uc.checkTaskExists(ctx, param.TaskID, param.UserID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListTaskDependents(ctx, param.TaskID, param.UserID)
+}
+
+func (uc *TaskUsecase) checkTaskExists(ctx context.Context, taskID, userID string) error {
+	if taskID == "" || userID == "" {
+		return ErrInvalidInput
+	}
+	task, err := uc.repo.GetTask(ctx, taskID, userID)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+// unresolvedBlockers 返回仍在阻塞任务的任务（未完成且未取消）
+func (uc *TaskUsecase) unresolvedBlockers(ctx context.Context, taskID, userID string) ([]*Task, error) {
+	blockers, err := uc.repo.ListTaskBlockers(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	unresolved := make([]*Task, 0)
+	for _, blocker := range blockers {
+		if !isTaskResolved(blocker) {
+			unresolved = append(unresolved, blocker)
+		}
+	}
+	return unresolved, nil
+}
+
+// checkTaskBlockers 任务仍被阻塞时返回 ErrTaskBlocked，错误信息中带上阻塞任务的ID
+func (uc *TaskUsecase) checkTaskBlockers(ctx context.Context, task *Task) error {
+	unresolved, err := uc.unresolvedBlockers(ctx, task.ID, task.UserID)
+	if err != nil {
+		return err
+	}
+	if len(unresolved) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(unresolved))
+	for i, blocker := range unresolved {
+		ids[i] = blocker.ID
+	}
+	return fmt.Errorf("%w: %s", ErrTaskBlocked, strings.Join(ids, ","))
+}
+
+// 获取时间段内的关键路径
+// 只考虑完全落在时间段内且未完成、未取消的任务，两端都在其中的依赖才参与计算
+// 每个任务权重相同，关键路径即最长的依赖链
+func (uc *TaskUsecase) GetCriticalPath(ctx context.Context, param GetCriticalPathParam) (*CriticalPath, error) {
+	if param.UserID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	if !param.Period.IsValid() {
+		return nil, ErrInvalidPeriod
+	}
+
+	tasks, err := uc.repo.ListTasksInPeriod(ctx, param.UserID, param.Period.Start, param.Period.End)
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := uc.repo.ListTaskDependencies(ctx, param.UserID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCriticalPath(tasks, dependencies), nil
+}
+
+// buildCriticalPath 在依赖图（DAG）上按拓扑序求最长路径
+func buildCriticalPath(tasks []*Task, dependencies []*TaskDependency) *CriticalPath {
+	nodes := make([]*Task, 0, len(tasks))
+	taskMap := make(map[string]*Task)
+	for _, task := range tasks {
+		if isTaskResolved(task) {
+			continue
+		}
+		nodes = append(nodes, task)
+		taskMap[task.ID] = task
+	}
+	// 固定遍历顺序，保证结果稳定：开始时间早的优先，其次按ID
+	sort.Slice(nodes, func(i, j int) bool {
+		if !nodes[i].TimePeriod.Start.Equal(nodes[j].TimePeriod.Start) {
+			return nodes[i].TimePeriod.Start.Before(nodes[j].TimePeriod.Start)
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	// 边方向：阻塞任务 -> 被阻塞任务
+	next := make(map[string][]string)
+	inDegree := make(map[string]int)
+	for _, dep := range dependencies {
+		if taskMap[dep.TaskID] == nil || taskMap[dep.BlockedByTaskID] == nil {
+			continue
+		}
+		next[dep.BlockedByTaskID] = append(next[dep.BlockedByTaskID], dep.TaskID)
+		inDegree[dep.TaskID]++
+	}
+
+	queue := make([]string, 0)
+	for _, node := range nodes {
+		if inDegree[node.ID] == 0 {
+			queue = append(queue, node.ID)
+		}
+	}
+
+	length := make(map[string]int)
+	prev := make(map[string]string)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if length[current] == 0 {
+			length[current] = 1
+		}
+		for _, dependent := range next[current] {
+			if length[current]+1 > length[dependent] {
+				length[dependent] = length[current] + 1
+				prev[dependent] = current
+			}
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	end := ""
+	for _, node := range nodes {
+		if end == "" || length[node.ID] > length[end] {
+			end = node.ID
+		}
+	}
+
+	path := &CriticalPath{Tasks: make([]*Task, 0)}
+	for id := end; id != ""; id = prev[id] {
+		path.Tasks = append([]*Task{taskMap[id]}, path.Tasks...)
+	}
+	path.Length = len(path.Tasks)
+	return path
+}
@@ -0,0 +1,272 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskUsecase_AddTaskDependency(t *testing.T) {
+	ctx := context.Background()
+	// 同一周内依次进行的设计、开发、测试、发布
+	setup := func() (*TaskUsecase, *memoryTaskRepo) {
+		repo := newMemoryTaskRepo(
+			newTestTask("design", PeriodDay, date(2025, 1, 6), TaskStatusInProgress, nil),
+			newTestTask("build", PeriodDay, date(2025, 1, 7), TaskStatusNotStarted, nil),
+			newTestTask("test", PeriodDay, date(2025, 1, 8), TaskStatusNotStarted, nil),
+			newTestTask("release", PeriodDay, date(2025, 1, 9), TaskStatusNotStarted, nil),
+		)
+		return NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo()), repo
+	}
+
+	add := func(usecase *TaskUsecase, taskID, blockedBy string) error {
+		_, err := usecase.AddTaskDependency(ctx, AddTaskDependencyParam{TaskID: taskID, BlockedByTaskID: blockedBy, UserID: "user-123"})
+		return err
+	}
+
+	t.Run("成功添加", func(t *testing.T) {
+		usecase, repo := setup()
+
+		dependency, err := usecase.AddTaskDependency(ctx, AddTaskDependencyParam{TaskID: "build", BlockedByTaskID: "design", UserID: "user-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "build", dependency.TaskID)
+		assert.Equal(t, "design", dependency.BlockedByTaskID)
+		assert.Len(t, repo.dependencies, 1)
+	})
+
+	t.Run("不能依赖自己", func(t *testing.T) {
+		usecase, _ := setup()
+		assert.Equal(t, ErrTaskDependencyCycle, add(usecase, "build", "build"))
+	})
+
+	t.Run("直接形成环", func(t *testing.T) {
+		usecase, _ := setup()
+		require.NoError(t, add(usecase, "build", "design"))
+		assert.Equal(t, ErrTaskDependencyCycle, add(usecase, "design", "build"))
+	})
+
+	t.Run("间接形成环", func(t *testing.T) {
+		usecase, repo := setup()
+		require.NoError(t, add(usecase, "build", "design"))
+		require.NoError(t, add(usecase, "test", "build"))
+		require.NoError(t, add(usecase, "release", "test"))
+
+		assert.Equal(t, ErrTaskDependencyCycle, add(usecase, "design", "release"))
+		assert.Len(t, repo.dependencies, 3, "rejected edge should not be stored")
+	})
+
+	t.Run("经过回收站中的任务形成环", func(t *testing.T) {
+		usecase, _ := setup()
+		require.NoError(t, add(usecase, "build", "design"))
+		require.NoError(t, add(usecase, "test", "build"))
+		require.NoError(t, usecase.DeleteTask(ctx, DeleteTaskParam{TaskID: "build", UserID: "user-123"}))
+
+		assert.Equal(t, ErrTaskDependencyCycle, add(usecase, "design", "test"), "restoring build would close the cycle")
+	})
+
+	t.Run("重复添加", func(t *testing.T) {
+		usecase, _ := setup()
+		require.NoError(t, add(usecase, "build", "design"))
+		assert.Equal(t, ErrTaskDependencyExists, add(usecase, "build", "design"))
+	})
+
+	t.Run("任务不存在", func(t *testing.T) {
+		usecase, _ := setup()
+		assert.Equal(t, ErrTaskNotFound, add(usecase, "build", "non-existent"))
+	})
+}
+
+func TestTaskUsecase_RemoveTaskDependency(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryTaskRepo(
+		newTestTask("design", PeriodDay, date(2025, 1, 6), TaskStatusInProgress, nil),
+		newTestTask("build", PeriodDay, date(2025, 1, 7), TaskStatusNotStarted, nil),
+		newTestTask("test", PeriodDay, date(2025, 1, 8), TaskStatusNotStarted, nil),
+	)
+	usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+	_, err := usecase.AddTaskDependency(ctx, AddTaskDependencyParam{TaskID: "build", BlockedByTaskID: "design", UserID: "user-123"})
+	require.NoError(t, err)
+
+	err = usecase.RemoveTaskDependency(ctx, RemoveTaskDependencyParam{TaskID: "build", BlockedByTaskID: "test", UserID: "user-123"})
+	assert.Equal(t, ErrTaskDependencyNotFound, err)
+
+	err = usecase.RemoveTaskDependency(ctx, RemoveTaskDependencyParam{TaskID: "build", BlockedByTaskID: "design", UserID: "user-123"})
+	require.NoError(t, err)
+	assert.Empty(t, repo.dependencies)
+}
+
+func TestTaskUsecase_ListTaskBlockersAndDependents(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryTaskRepo(
+		newTestTask("design", PeriodDay, date(2025, 1, 6), TaskStatusInProgress, nil),
+		newTestTask("done", PeriodDay, date(2025, 1, 6), TaskStatusCompleted, nil),
+		newTestTask("build", PeriodDay, date(2025, 1, 7), TaskStatusNotStarted, nil),
+	)
+	usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+	for _, blockedBy := range []string{"design", "done"} {
+		_, err := usecase.AddTaskDependency(ctx, AddTaskDependencyParam{TaskID: "build", BlockedByTaskID: blockedBy, UserID: "user-123"})
+		require.NoError(t, err)
+	}
+
+	blockers, err := usecase.ListTaskBlockers(ctx, ListTaskDependencyParam{TaskID: "build", UserID: "user-123"})
+	require.NoError(t, err)
+	assert.Len(t, blockers, 2)
+
+	dependents, err := usecase.ListTaskDependents(ctx, ListTaskDependencyParam{TaskID: "design", UserID: "user-123"})
+	require.NoError(t, err)
+	require.Len(t, dependents, 1)
+	assert.Equal(t, "build", dependents[0].ID)
+
+	_, err = usecase.ListTaskBlockers(ctx, ListTaskDependencyParam{TaskID: "non-existent", UserID: "user-123"})
+	assert.Equal(t, ErrTaskNotFound, err)
+}
+
+func TestTaskUsecase_UpdateTask_Blocked(t *testing.T) {
+	ctx := context.Background()
+	inProgress := TaskStatusInProgress
+	completed := TaskStatusCompleted
+
+	// build 被 design 阻塞
+	newBlockedUsecase := func() (*TaskUsecase, *memoryTaskRepo) {
+		repo := newMemoryTaskRepo(
+			newTestTask("design", PeriodDay, date(2025, 1, 6), TaskStatusInProgress, nil),
+			newTestTask("build", PeriodDay, date(2025, 1, 7), TaskStatusNotStarted, nil),
+		)
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+		_, err := usecase.AddTaskDependency(ctx, AddTaskDependencyParam{TaskID: "build", BlockedByTaskID: "design", UserID: "user-123"})
+		require.NoError(t, err)
+		return usecase, repo
+	}
+
+	t.Run("被阻塞时不能开始", func(t *testing.T) {
+		usecase, repo := newBlockedUsecase()
+
+		_, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "build", UserID: "user-123", Status: &inProgress})
+
+		assert.True(t, errors.Is(err, ErrTaskBlocked))
+		assert.Contains(t, err.Error(), "design")
+		assert.Equal(t, TaskStatusNotStarted, repo.tasks["build"].Status)
+	})
+
+	t.Run("被阻塞时不能完成", func(t *testing.T) {
+		usecase, _ := newBlockedUsecase()
+
+		_, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "build", UserID: "user-123", Status: &completed})
+
+		assert.True(t, errors.Is(err, ErrTaskBlocked))
+	})
+
+	t.Run("忽略阻塞强制开始", func(t *testing.T) {
+		usecase, repo := newBlockedUsecase()
+
+		_, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "build", UserID: "user-123", Status: &inProgress, OverrideBlockers: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, TaskStatusInProgress, repo.tasks["build"].Status)
+	})
+
+	t.Run("阻塞任务完成或取消后可以开始", func(t *testing.T) {
+		for _, status := range []TaskStatus{TaskStatusCompleted, TaskStatusCancelled} {
+			usecase, repo := newBlockedUsecase()
+			repo.tasks["design"].Status = status
+
+			_, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "build", UserID: "user-123", Status: &inProgress})
+
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("不改状态的更新不受阻塞影响", func(t *testing.T) {
+		usecase, _ := newBlockedUsecase()
+		title := "新标题"
+
+		_, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "build", UserID: "user-123", Title: &title})
+
+		require.NoError(t, err)
+	})
+}
+
+func TestTaskUsecase_CompletionPropagation_BlockedParent(t *testing.T) {
+	ctx := context.Background()
+	// quarter 下的两个月任务中 month-1 已完成，quarter 被 blocker 阻塞
+	quarter := newTestTask("quarter", PeriodQuarter, date(2025, 1, 1), TaskStatusInProgress, nil)
+	repo := newMemoryTaskRepo(
+		quarter,
+		newTestTask("month-1", PeriodMonth, date(2025, 1, 1), TaskStatusCompleted, quarter),
+		newTestTask("month-2", PeriodMonth, date(2025, 2, 1), TaskStatusInProgress, quarter),
+		newTestTask("blocker", PeriodDay, date(2025, 1, 6), TaskStatusInProgress, nil),
+	)
+	usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+	_, err := usecase.AddTaskDependency(ctx, AddTaskDependencyParam{TaskID: "quarter", BlockedByTaskID: "blocker", UserID: "user-123"})
+	require.NoError(t, err)
+
+	completed := TaskStatusCompleted
+	_, err = usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "month-2", UserID: "user-123", Status: &completed})
+
+	require.NoError(t, err)
+	assert.Equal(t, TaskStatusInProgress, repo.tasks["quarter"].Status, "blocked parent should not be auto-completed")
+	assert.True(t, repo.tasks["quarter"].ReadyToComplete)
+}
+
+func TestTaskUsecase_GetCriticalPath(t *testing.T) {
+	ctx := context.Background()
+	week := NewPeriodFromPeriodType(PeriodWeek, date(2025, 1, 6))
+	// 同一周内的日任务，docs 与 test 同一天，done 已完成
+	setup := func() *TaskUsecase {
+		repo := newMemoryTaskRepo(
+			newTestTask("design", PeriodDay, date(2025, 1, 6), TaskStatusInProgress, nil),
+			newTestTask("build", PeriodDay, date(2025, 1, 7), TaskStatusNotStarted, nil),
+			newTestTask("test", PeriodDay, date(2025, 1, 8), TaskStatusNotStarted, nil),
+			newTestTask("release", PeriodDay, date(2025, 1, 9), TaskStatusNotStarted, nil),
+			newTestTask("docs", PeriodDay, date(2025, 1, 8), TaskStatusNotStarted, nil),
+			newTestTask("done", PeriodDay, date(2025, 1, 6), TaskStatusCompleted, nil),
+		)
+		return NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+	}
+
+	t.Run("返回最长的依赖链", func(t *testing.T) {
+		usecase := setup()
+		edges := [][2]string{
+			{"build", "design"},
+			{"test", "build"},
+			{"release", "test"},
+			{"release", "docs"},
+			{"docs", "done"}, // 已完成的任务不参与
+		}
+		for _, edge := range edges {
+			_, err := usecase.AddTaskDependency(ctx, AddTaskDependencyParam{TaskID: edge[0], BlockedByTaskID: edge[1], UserID: "user-123"})
+			require.NoError(t, err)
+		}
+
+		path, err := usecase.GetCriticalPath(ctx, GetCriticalPathParam{UserID: "user-123", Period: week})
+
+		require.NoError(t, err)
+		require.Equal(t, 4, path.Length)
+		ids := make([]string, len(path.Tasks))
+		for i, task := range path.Tasks {
+			ids[i] = task.ID
+		}
+		assert.Equal(t, []string{"design", "build", "test", "release"}, ids)
+	})
+
+	t.Run("没有依赖时关键路径只有一个任务", func(t *testing.T) {
+		usecase := setup()
+
+		path, err := usecase.GetCriticalPath(ctx, GetCriticalPathParam{UserID: "user-123", Period: week})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, path.Length)
+	})
+
+	t.Run("时间段不合法", func(t *testing.T) {
+		usecase := setup()
+
+		_, err := usecase.GetCriticalPath(ctx, GetCriticalPathParam{UserID: "user-123"})
+
+		assert.Equal(t, ErrInvalidPeriod, err)
+	})
+}
@@ -0,0 +1,232 @@
+package biz
+
+import (
+	"context"
+	"sort"
+
+	"github.com/labstack/gommon/log"
+)
+
+// EstimateUnit 任务工作量估算的单位，由用户设置决定，只影响展示
+type EstimateUnit int
+
+const (
+	EstimateUnitMinutes EstimateUnit = iota // 分钟（默认）
+	EstimateUnitPoints                      // 点数
+)
+
+// IsValid 是否为支持的估算单位
+func (u EstimateUnit) IsValid() bool {
+	return u == EstimateUnitMinutes || u == EstimateUnitPoints
+}
+
+// CapacityWarning 某一天或某一周计划的工作量超过了用户设置的容量
+type CapacityWarning struct {
+	PeriodType PeriodType `json:"period_type"` // 日或周
+	Period     Period     `json:"period"`
+	Planned    int        `json:"planned"`  // 该时间段内未取消任务的估算之和，父子任务不重复计算
+	Capacity   int        `json:"capacity"` // 用户设置的容量
+}
+
+// EffortStat 一个分组内计划工作量与已完成工作量的对比
+type EffortStat struct {
+	GroupKey     string `json:"group_key"`     // 分组键，同 GroupStat
+	Planned      int    `json:"planned"`       // 未取消任务的估算之和，父子任务不重复计算
+	Completed    int    `json:"completed"`     // 已完成任务的估算之和
+	TimeSpent    int64  `json:"time_spent"`    // 该分组内记录的工时总和（秒）
+	Capacity     int    `json:"capacity"`      // 按日、周分组时为用户设置的容量，其他分组或未设置时为0
+	OverCapacity bool   `json:"over_capacity"` // 计划工作量是否超过容量
+}
+
+// 获取计划与完成工作量对比参数
+type GetTaskEffortParam struct {
+	UserID  string
+	Period  Period
+	GroupBy PeriodType
+}
+
+// treeRootTaskID 任务所在任务树的根任务ID，兼容尚未计算树字段的旧数据
+func treeRootTaskID(task *Task) string {
+	if task.RootTaskID == "" {
+		return task.ID
+	}
+	return task.RootTaskID
+}
+
+// placeUnderParent 新任务挂在 parent 下时预先设置树字段，使同一事务中的整树统计能包含新任务
+func (t *Task) placeUnderParent(parent *Task) {
+	t.RootTaskID = treeRootTaskID(parent)
+	t.TreeDepth = parent.TreeDepth + 1
+}
+
+// placeNewTask 创建前确定新任务的根任务和深度（调用方未通过 placeUnderParent 设置时）
+func (uc *TaskUsecase) placeNewTask(ctx context.Context, task *Task) error {
+	if task.RootTaskID != "" {
+		return nil
+	}
+	if task.ParentID == "" {
+		task.RootTaskID = task.ID
+		return nil
+	}
+	parent, err := uc.repo.GetTask(ctx, task.ParentID, task.UserID)
+	if err != nil {
+		return err
+	}
+	if parent != nil {
+		task.placeUnderParent(parent)
+	}
+	return nil
+}
+
+// countsTowardEffort 任务是否计入计划工作量：有估算、未取消，且类型不大于统计的时间段类型
+func countsTowardEffort(task *Task, periodType PeriodType) bool {
+	return task.Estimate > 0 && task.Status != TaskStatusCancelled && task.TaskType <= periodType
+}
+
+// effortShares 统计的任务中每个计入工作量的任务所占的部分
+// 父任务的估算视为包含其子任务：同时被统计的直接子任务的估算从父任务中扣除，不足时按 0 计，避免父子重复计算
+func effortShares(tasks []*Task, periodType PeriodType) map[string]int {
+	shares := make(map[string]int, len(tasks))
+	for _, task := range tasks {
+		if task != nil && countsTowardEffort(task, periodType) {
+			shares[task.ID] = task.Estimate
+		}
+	}
+	for _, task := range tasks {
+		if task == nil || task.ParentID == "" {
+			continue
+		}
+		if _, counted := shares[task.ID]; !counted {
+			continue
+		}
+		if _, ok := shares[task.ParentID]; ok {
+			shares[task.ParentID] -= task.Estimate
+		}
+	}
+	for id, share := range shares {
+		if share < 0 {
+			shares[id] = 0
+		}
+	}
+	return shares
+}
+
+// capacityFor 用户在日、周时间段上的容量，其他时间段不检查容量
+func capacityFor(settings *UserSettings, periodType PeriodType) int {
+	switch periodType {
+	case PeriodDay:
+		return settings.DailyCapacity
+	case PeriodWeek:
+		return settings.WeeklyCapacity
+	default:
+		return 0
+	}
+}
+
+// checkCapacity 检查任务所在的日、周计划工作量是否超过用户容量
+// 日任务同时检查当天和所在周，周任务只检查所在周
+func (uc *TaskUsecase) checkCapacity(ctx context.Context, task *Task) ([]CapacityWarning, error) {
+	if task.Estimate == 0 || task.Status == TaskStatusCancelled {
+		return nil, nil
+	}
+	settings, err := loadUserSettings(ctx, uc.settingsRepo, task.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []CapacityWarning
+	for _, periodType := range []PeriodType{PeriodDay, PeriodWeek} {
+		capacity := capacityFor(settings, periodType)
+		if capacity == 0 || task.TaskType > periodType {
+			continue
+		}
+		period := NewPeriodFromPeriodType(periodType, task.TimePeriod.Start)
+		tasks, err := uc.repo.ListTasksInPeriod(ctx, task.UserID, period.Start, period.End)
+		if err != nil {
+			return nil, err
+		}
+		planned := 0
+		for _, share := range effortShares(tasks, periodType) {
+			planned += share
+		}
+		if planned > capacity {
+			warnings = append(warnings, CapacityWarning{
+				PeriodType: periodType,
+				Period:     period,
+				Planned:    planned,
+				Capacity:   capacity,
+			})
+		}
+	}
+	return warnings, nil
+}
+
+// attachCapacityWarnings 任务保存后附加容量提醒，检查失败不影响已完成的保存
+func (uc *TaskUsecase) attachCapacityWarnings(ctx context.Context, task *Task) {
+	warnings, err := uc.checkCapacity(ctx, task)
+	if err != nil {
+		log.Warnf("Failed to check capacity for task %s: %v", task.ID, err)
+		return
+	}
+	task.CapacityWarnings = warnings
+}
+
+// 获取计划与完成工作量对比
+// 统计完全落在时间段内、类型不大于分组类型的任务（按周分组时包括日任务和周任务），按任务开始时间分组
+// 父任务与子任务同时被统计时，父任务只计入超出子任务估算之和的部分（见 effortShares）
+func (uc *TaskUsecase) GetTaskEffort(ctx context.Context, param GetTaskEffortParam) ([]EffortStat, error) {
+	if param.UserID == "" {
+		return nil, ErrInvalidInput // 参数不合法
+	}
+	if !param.Period.IsValid() {
+		return nil, ErrInvalidInput // 时间段不合法
+	}
+
+	settings, err := loadUserSettings(ctx, uc.settingsRepo, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := uc.repo.ListTasksInPeriod(ctx, param.UserID, param.Period.Start, param.Period.End)
+	if err != nil {
+		return nil, err
+	}
+
+	statsMap := make(map[string]*EffortStat)
+	groupStat := func(groupKey string) *EffortStat {
+		stat, ok := statsMap[groupKey]
+		if !ok {
+			stat = &EffortStat{GroupKey: groupKey, Capacity: capacityFor(settings, param.GroupBy)}
+			statsMap[groupKey] = stat
+		}
+		return stat
+	}
+
+	shares := effortShares(tasks, param.GroupBy)
+	for _, task := range tasks {
+		if task == nil || !countsTowardEffort(task, param.GroupBy) {
+			continue
+		}
+		stat := groupStat(uc.generateGroupKey(task.TimePeriod.Start, param.GroupBy))
+		stat.Planned += shares[task.ID]
+		if task.Status == TaskStatusCompleted {
+			stat.Completed += shares[task.ID]
+		}
+	}
+
+	// 实际工时按记录的开始时间分组
+	entries, err := uc.repo.ListTimeEntriesInRange(ctx, param.UserID, param.Period.Start, param.Period.End)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		groupStat(uc.generateGroupKey(entry.StartedAt, param.GroupBy)).TimeSpent += entry.Duration
+	}
+
+	result := make([]EffortStat, 0, len(statsMap))
+	for _, stat := range statsMap {
+		stat.OverCapacity = stat.Capacity > 0 && stat.Planned > stat.Capacity
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].GroupKey < result[j].GroupKey })
+	return result, nil
+}
@@ -0,0 +1,151 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskUsecase_TreeEstimate(t *testing.T) {
+	ctx := context.Background()
+	period := Period{Start: date(2025, 1, 1), End: date(2025, 2, 1)}
+	repo := newMemoryTaskRepo()
+	uc := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+	root, err := uc.CreateTask(ctx, CreateTaskParam{UserID: "u1", Title: "root", Type: PeriodMonth, Period: period, Estimate: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 10, root.TreeEstimate)
+
+	child, err := uc.CreateSubTask(ctx, CreateSubTaskParam{UserID: "u1", ParentID: root.ID, Title: "a", Type: PeriodMonth, Period: period, Estimate: 5})
+	require.NoError(t, err)
+	assert.Equal(t, root.ID, child.RootTaskID)
+	_, err = uc.CreateSubTask(ctx, CreateSubTaskParam{UserID: "u1", ParentID: child.ID, Title: "a1", Type: PeriodMonth, Period: period, Estimate: 3})
+	require.NoError(t, err)
+	assert.Equal(t, 18, repo.tasks[root.ID].TreeEstimate)
+
+	estimate := 7
+	_, err = uc.UpdateTask(ctx, UpdateTaskParam{TaskID: child.ID, UserID: "u1", Estimate: &estimate})
+	require.NoError(t, err)
+	assert.Equal(t, 20, repo.tasks[root.ID].TreeEstimate)
+
+	// 已取消的任务不计入整树估算
+	cancelled := TaskStatusCancelled
+	_, err = uc.UpdateTask(ctx, UpdateTaskParam{TaskID: child.ID, UserID: "u1", Status: &cancelled})
+	require.NoError(t, err)
+	assert.Equal(t, 13, repo.tasks[root.ID].TreeEstimate)
+
+	// 估算不能为负数
+	negative := -1
+	_, err = uc.UpdateTask(ctx, UpdateTaskParam{TaskID: child.ID, UserID: "u1", Estimate: &negative})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = uc.CreateTask(ctx, CreateTaskParam{UserID: "u1", Title: "bad", Type: PeriodMonth, Period: period, Estimate: -1})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestTaskUsecase_CapacityWarnings(t *testing.T) {
+	ctx := context.Background()
+	monday := date(2025, 1, 13)
+	day := func(d time.Time) Period { return NewPeriodFromPeriodType(PeriodDay, d) }
+
+	settingsRepo := newMockUserSettingsRepo()
+	settingsRepo.settings["u1"] = &UserSettings{UserID: "u1", DailyCapacity: 480, WeeklyCapacity: 1000}
+	repo := newMemoryTaskRepo()
+	uc := NewTaskUsecase(repo, settingsRepo, newMockChangeHistoryRepo())
+
+	first, err := uc.CreateTask(ctx, CreateTaskParam{UserID: "u1", Title: "a", Type: PeriodDay, Period: day(monday), Estimate: 300})
+	require.NoError(t, err)
+	assert.Empty(t, first.CapacityWarnings)
+
+	second, err := uc.CreateTask(ctx, CreateTaskParam{UserID: "u1", Title: "b", Type: PeriodDay, Period: day(monday), Estimate: 240})
+	require.NoError(t, err)
+	require.Len(t, second.CapacityWarnings, 1)
+	assert.Equal(t, CapacityWarning{PeriodType: PeriodDay, Period: day(monday), Planned: 540, Capacity: 480}, second.CapacityWarnings[0])
+
+	// 周任务只检查所在周，周容量统计日任务和周任务
+	week := NewPeriodFromPeriodType(PeriodWeek, monday)
+	weekly, err := uc.CreateTask(ctx, CreateTaskParam{UserID: "u1", Title: "w", Type: PeriodWeek, Period: week, Estimate: 500})
+	require.NoError(t, err)
+	require.Len(t, weekly.CapacityWarnings, 1)
+	assert.Equal(t, CapacityWarning{PeriodType: PeriodWeek, Period: week, Planned: 1040, Capacity: 1000}, weekly.CapacityWarnings[0])
+
+	// 取消任务后不再超出
+	cancelled := TaskStatusCancelled
+	_, err = uc.UpdateTask(ctx, UpdateTaskParam{TaskID: second.ID, UserID: "u1", Status: &cancelled})
+	require.NoError(t, err)
+	estimate := 100
+	updated, err := uc.UpdateTask(ctx, UpdateTaskParam{TaskID: first.ID, UserID: "u1", Estimate: &estimate})
+	require.NoError(t, err)
+	assert.Empty(t, updated.CapacityWarnings)
+}
+
+func TestTaskUsecase_GetTaskEffort(t *testing.T) {
+	ctx := context.Background()
+	monday := date(2025, 1, 13)
+	tuesday := date(2025, 1, 14)
+	day := func(d time.Time) Period { return NewPeriodFromPeriodType(PeriodDay, d) }
+
+	settingsRepo := newMockUserSettingsRepo()
+	settingsRepo.settings["u1"] = &UserSettings{UserID: "u1", DailyCapacity: 100}
+	repo := newMemoryTaskRepo(
+		&Task{ID: "t1", UserID: "u1", TaskType: PeriodDay, TimePeriod: day(monday), Estimate: 60, Status: TaskStatusCompleted},
+		&Task{ID: "t2", UserID: "u1", TaskType: PeriodDay, TimePeriod: day(monday), Estimate: 50},
+		&Task{ID: "t3", UserID: "u1", TaskType: PeriodDay, TimePeriod: day(tuesday), Estimate: 30, Status: TaskStatusCancelled},
+		&Task{ID: "t4", UserID: "u1", TaskType: PeriodDay, TimePeriod: day(tuesday), Estimate: 40, Status: TaskStatusCompleted},
+		&Task{ID: "t5", UserID: "u1", TaskType: PeriodWeek, TimePeriod: NewPeriodFromPeriodType(PeriodWeek, monday), Estimate: 200},
+	)
+	uc := NewTaskUsecase(repo, settingsRepo, newMockChangeHistoryRepo())
+	week := NewPeriodFromPeriodType(PeriodWeek, monday)
+
+	stats, err := uc.GetTaskEffort(ctx, GetTaskEffortParam{UserID: "u1", Period: week, GroupBy: PeriodDay})
+	require.NoError(t, err)
+	assert.Equal(t, []EffortStat{
+		{GroupKey: "2025-01-13", Planned: 110, Completed: 60, Capacity: 100, OverCapacity: true},
+		{GroupKey: "2025-01-14", Planned: 40, Completed: 40, Capacity: 100},
+	}, stats)
+
+	// 按周分组时包括周任务，未设置周容量
+	stats, err = uc.GetTaskEffort(ctx, GetTaskEffortParam{UserID: "u1", Period: week, GroupBy: PeriodWeek})
+	require.NoError(t, err)
+	assert.Equal(t, []EffortStat{{GroupKey: "2025-W03", Planned: 350, Completed: 100}}, stats)
+
+	_, err = uc.GetTaskEffort(ctx, GetTaskEffortParam{UserID: "u1", Period: Period{Start: tuesday, End: monday}, GroupBy: PeriodDay})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestTaskUsecase_EffortParentAndChildren(t *testing.T) {
+	ctx := context.Background()
+	monday := date(2025, 1, 13)
+	week := NewPeriodFromPeriodType(PeriodWeek, monday)
+	tasks := []*Task{{ID: "w", UserID: "u1", TaskType: PeriodWeek, TimePeriod: week, Estimate: 600, HasChildren: true, ChildrenCount: 5}}
+	for i := 0; i < 5; i++ {
+		tasks = append(tasks, &Task{
+			ID: fmt.Sprintf("d%d", i), UserID: "u1", ParentID: "w", RootTaskID: "w", TreeDepth: 1,
+			TaskType: PeriodDay, TimePeriod: NewPeriodFromPeriodType(PeriodDay, monday.AddDate(0, 0, i)), Estimate: 120,
+		})
+	}
+	settingsRepo := newMockUserSettingsRepo()
+	settingsRepo.settings["u1"] = &UserSettings{UserID: "u1", WeeklyCapacity: 700}
+	repo := newMemoryTaskRepo(tasks...)
+	uc := NewTaskUsecase(repo, settingsRepo, newMockChangeHistoryRepo())
+
+	// 父任务的估算包含子任务，不重复计算
+	stats, err := uc.GetTaskEffort(ctx, GetTaskEffortParam{UserID: "u1", Period: week, GroupBy: PeriodWeek})
+	require.NoError(t, err)
+	assert.Equal(t, []EffortStat{{GroupKey: "2025-W03", Planned: 600, Capacity: 700}}, stats)
+
+	estimate := 100
+	updated, err := uc.UpdateTask(ctx, UpdateTaskParam{TaskID: "d0", UserID: "u1", Estimate: &estimate})
+	require.NoError(t, err)
+	assert.Empty(t, updated.CapacityWarnings)
+
+	// 子任务之和超过父任务估算时按子任务计算，父任务计为 0
+	estimate = 400
+	updated, err = uc.UpdateTask(ctx, UpdateTaskParam{TaskID: "d0", UserID: "u1", Estimate: &estimate})
+	require.NoError(t, err)
+	require.Len(t, updated.CapacityWarnings, 1)
+	assert.Equal(t, 880, updated.CapacityWarnings[0].Planned)
+}
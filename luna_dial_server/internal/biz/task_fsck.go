@@ -0,0 +1,233 @@
+package biz
+
+import (
+	"context"
+	"sort"
+	"strconv"
+)
+
+// TreeIssueType 任务树冗余字段或结构的问题类型
+type TreeIssueType string
+
+const (
+	TreeIssueOrphan        TreeIssueType = "orphan"               // 父任务不存在或已删除，修复时成为根任务
+	TreeIssueCycle         TreeIssueType = "cycle"                // parent_id 成环，修复时环中 ID 最小的任务成为根任务
+	TreeIssueDepth         TreeIssueType = "wrong_depth"          // tree_depth 与按 parent_id 计算的深度不一致
+	TreeIssueRoot          TreeIssueType = "wrong_root"           // root_task_id 与按 parent_id 找到的根任务不一致
+	TreeIssueChildrenCount TreeIssueType = "wrong_children_count" // children_count 与未删除的直接子任务数量不一致
+	TreeIssueHasChildren   TreeIssueType = "wrong_has_children"   // has_children 与是否有未删除的直接子任务不一致
+)
+
+// TreeIssue 一个任务上的一个问题，Actual 为当前存储的值，Expected 为修复后的值
+type TreeIssue struct {
+	TaskID   string        `json:"task_id"`
+	Type     TreeIssueType `json:"type"`
+	Actual   string        `json:"actual"`
+	Expected string        `json:"expected"`
+	Cycle    []string      `json:"cycle,omitempty"` // 成环时环上的任务，从 TaskID 开始沿 parent_id 排列
+}
+
+// UserTreeReport 一个用户的任务树检查结果
+type UserTreeReport struct {
+	UserID    string      `json:"user_id"`
+	TaskCount int         `json:"task_count"`
+	Issues    []TreeIssue `json:"issues"`
+}
+
+// TreeFsckReport 任务树检查报告，Users 只包括有问题的用户
+type TreeFsckReport struct {
+	CheckedUsers int               `json:"checked_users"`
+	CheckedTasks int               `json:"checked_tasks"`
+	IssueCount   int               `json:"issue_count"`
+	Fixed        bool              `json:"fixed"` // 问题是否已在本次检查中修复
+	Users        []*UserTreeReport `json:"users"`
+}
+
+// 检查任务树参数
+type CheckTaskTreesParam struct {
+	UserID string // 为空时检查所有用户
+	Fix    bool   // 是否修复发现的问题
+}
+
+// checkUserTaskTree 按 parent_id 重新计算用户所有未删除任务的树字段
+// 返回发现的问题，以及需要修复的任务（已修改为正确值的副本），ID 有序以保证结果稳定
+func checkUserTaskTree(tasks []*Task) ([]TreeIssue, []*Task) {
+	byID := make(map[string]*Task, len(tasks))
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+		ids = append(ids, task.ID)
+	}
+	sort.Strings(ids)
+
+	issues := make([]TreeIssue, 0)
+	parents := make(map[string]string, len(tasks))
+	for _, id := range ids {
+		parentID := byID[id].ParentID
+		if parentID != "" && byID[parentID] == nil {
+			issues = append(issues, TreeIssue{TaskID: id, Type: TreeIssueOrphan, Actual: parentID})
+			parentID = ""
+		}
+		parents[id] = parentID
+	}
+
+	// 沿 parent_id 向上查找环，环中 ID 最小的任务断开成为根任务
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(tasks))
+	for _, id := range ids {
+		var path []string
+		current := id
+		for current != "" && state[current] == unvisited {
+			state[current] = visiting
+			path = append(path, current)
+			current = parents[current]
+		}
+		if current != "" && state[current] == visiting {
+			start := 0
+			for path[start] != current {
+				start++
+			}
+			cycle := path[start:]
+			breakAt := 0
+			for i := range cycle {
+				if cycle[i] < cycle[breakAt] {
+					breakAt = i
+				}
+			}
+			ordered := append(append([]string{}, cycle[breakAt:]...), cycle[:breakAt]...)
+			issues = append(issues, TreeIssue{TaskID: ordered[0], Type: TreeIssueCycle, Actual: parents[ordered[0]], Cycle: ordered})
+			parents[ordered[0]] = ""
+		}
+		for _, visited := range path {
+			state[visited] = done
+		}
+	}
+
+	// 修正父任务后计算深度、根任务和直接子任务数量
+	depths := make(map[string]int, len(tasks))
+	roots := make(map[string]string, len(tasks))
+	var place func(id string)
+	place = func(id string) {
+		if _, ok := roots[id]; ok {
+			return
+		}
+		parentID := parents[id]
+		if parentID == "" {
+			depths[id], roots[id] = 0, id
+			return
+		}
+		place(parentID)
+		depths[id], roots[id] = depths[parentID]+1, roots[parentID]
+	}
+	childrenCount := make(map[string]int, len(tasks))
+	for _, id := range ids {
+		place(id)
+		if parentID := parents[id]; parentID != "" {
+			childrenCount[parentID]++
+		}
+	}
+
+	var fixes []*Task
+	for _, id := range ids {
+		task := byID[id]
+		fixed := *task
+		fixed.ParentID = parents[id]
+		fixed.TreeDepth = depths[id]
+		fixed.RootTaskID = roots[id]
+		fixed.ChildrenCount = childrenCount[id]
+		fixed.HasChildren = childrenCount[id] > 0
+
+		if task.TreeDepth != fixed.TreeDepth {
+			issues = append(issues, TreeIssue{TaskID: id, Type: TreeIssueDepth, Actual: strconv.Itoa(task.TreeDepth), Expected: strconv.Itoa(fixed.TreeDepth)})
+		}
+		if task.RootTaskID != fixed.RootTaskID {
+			issues = append(issues, TreeIssue{TaskID: id, Type: TreeIssueRoot, Actual: task.RootTaskID, Expected: fixed.RootTaskID})
+		}
+		if task.ChildrenCount != fixed.ChildrenCount {
+			issues = append(issues, TreeIssue{TaskID: id, Type: TreeIssueChildrenCount, Actual: strconv.Itoa(task.ChildrenCount), Expected: strconv.Itoa(fixed.ChildrenCount)})
+		}
+		if task.HasChildren != fixed.HasChildren {
+			issues = append(issues, TreeIssue{TaskID: id, Type: TreeIssueHasChildren, Actual: strconv.FormatBool(task.HasChildren), Expected: strconv.FormatBool(fixed.HasChildren)})
+		}
+		if task.ParentID != fixed.ParentID || task.TreeDepth != fixed.TreeDepth || task.RootTaskID != fixed.RootTaskID ||
+			task.ChildrenCount != fixed.ChildrenCount || task.HasChildren != fixed.HasChildren {
+			fixed.Children = nil
+			fixes = append(fixes, &fixed)
+		}
+	}
+	return issues, fixes
+}
+
+// 检查任务树：按 parent_id 找出孤儿任务、环，以及错误的 tree_depth、root_task_id、children_count、has_children
+// Fix 为 true 时在同一事务中修复所有问题，重算修复前后根任务的整树估算和耗时，以及子任务集合变化的任务的汇总结果
+func (uc *TaskUsecase) CheckTaskTrees(ctx context.Context, param CheckTaskTreesParam) (*TreeFsckReport, error) {
+	report := &TreeFsckReport{Users: make([]*UserTreeReport, 0)}
+	check := func(ctx context.Context) error {
+		userIDs := []string{param.UserID}
+		if param.UserID == "" {
+			var err error
+			if userIDs, err = uc.repo.ListTaskUserIDs(ctx); err != nil {
+				return err
+			}
+		}
+
+		for _, userID := range userIDs {
+			tasks, err := uc.repo.ListUserTasks(ctx, userID)
+			if err != nil {
+				return err
+			}
+			issues, fixes := checkUserTaskTree(tasks)
+			report.CheckedUsers++
+			report.CheckedTasks += len(tasks)
+			if len(issues) == 0 {
+				continue
+			}
+			report.IssueCount += len(issues)
+			report.Users = append(report.Users, &UserTreeReport{UserID: userID, TaskCount: len(tasks), Issues: issues})
+			if !param.Fix {
+				continue
+			}
+
+			stored := make(map[string]*Task, len(tasks))
+			for _, task := range tasks {
+				stored[task.ID] = task
+			}
+			roots := make([]string, 0, 2*len(fixes))
+			for _, task := range fixes {
+				if err := uc.repo.UpdateTaskTreeFields(ctx, task); err != nil {
+					return err
+				}
+				roots = append(roots, stored[task.ID].RootTaskID, task.RootTaskID)
+			}
+			// 断开环或孤儿任务后，原父任务的子任务集合变化，需要重算汇总结果
+			for _, task := range fixes {
+				if oldParentID := stored[task.ID].ParentID; oldParentID != task.ParentID {
+					if err := uc.refreshRollupFrom(ctx, oldParentID, userID); err != nil {
+						return err
+					}
+				}
+			}
+			// 原来错误的根任务的整树统计仍包含被移走的任务，新旧根任务都要重算
+			if err := uc.refreshTreeTotals(ctx, userID, roots...); err != nil {
+				return err
+			}
+		}
+		report.Fixed = param.Fix && report.IssueCount > 0
+		return nil
+	}
+
+	var err error
+	if param.Fix {
+		err = uc.repo.Transaction(ctx, check)
+	} else {
+		err = check(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
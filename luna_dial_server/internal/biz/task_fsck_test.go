@@ -0,0 +1,128 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckUserTaskTree(t *testing.T) {
+	t.Run("正确的任务树没有问题", func(t *testing.T) {
+		issues, fixes := checkUserTaskTree([]*Task{
+			{ID: "r", RootTaskID: "r", HasChildren: true, ChildrenCount: 1},
+			{ID: "a", ParentID: "r", RootTaskID: "r", TreeDepth: 1},
+		})
+		assert.Empty(t, issues)
+		assert.Empty(t, fixes)
+	})
+
+	t.Run("孤儿任务成为根任务", func(t *testing.T) {
+		issues, fixes := checkUserTaskTree([]*Task{
+			{ID: "a", ParentID: "deleted", RootTaskID: "deleted", TreeDepth: 1, HasChildren: true, ChildrenCount: 1},
+			{ID: "a1", ParentID: "a", RootTaskID: "deleted", TreeDepth: 2},
+		})
+		assert.Equal(t, []TreeIssue{
+			{TaskID: "a", Type: TreeIssueOrphan, Actual: "deleted"},
+			{TaskID: "a", Type: TreeIssueDepth, Actual: "1", Expected: "0"},
+			{TaskID: "a", Type: TreeIssueRoot, Actual: "deleted", Expected: "a"},
+			{TaskID: "a1", Type: TreeIssueDepth, Actual: "2", Expected: "1"},
+			{TaskID: "a1", Type: TreeIssueRoot, Actual: "deleted", Expected: "a"},
+		}, issues)
+		require.Len(t, fixes, 2)
+		assert.Empty(t, fixes[0].ParentID)
+		assert.Equal(t, "a", fixes[1].RootTaskID)
+	})
+
+	t.Run("环中ID最小的任务断开", func(t *testing.T) {
+		issues, fixes := checkUserTaskTree([]*Task{
+			{ID: "c", ParentID: "b", RootTaskID: "c", HasChildren: true, ChildrenCount: 1},
+			{ID: "b", ParentID: "a", RootTaskID: "c", HasChildren: true, ChildrenCount: 1},
+			{ID: "a", ParentID: "c", RootTaskID: "c", HasChildren: true, ChildrenCount: 1},
+			{ID: "s", ParentID: "s", RootTaskID: "s"},
+		})
+		require.NotEmpty(t, issues)
+		assert.Equal(t, TreeIssue{TaskID: "a", Type: TreeIssueCycle, Actual: "c", Cycle: []string{"a", "c", "b"}}, issues[0])
+		assert.Equal(t, TreeIssue{TaskID: "s", Type: TreeIssueCycle, Actual: "s", Cycle: []string{"s"}}, issues[1])
+
+		fixed := make(map[string]*Task)
+		for _, task := range fixes {
+			fixed[task.ID] = task
+		}
+		assert.Empty(t, fixed["a"].ParentID)
+		assert.Equal(t, 0, fixed["a"].TreeDepth)
+		assert.Equal(t, "a", fixed["c"].RootTaskID)
+		assert.Equal(t, 2, fixed["c"].TreeDepth)
+		assert.False(t, fixed["c"].HasChildren)
+		assert.Empty(t, fixed["s"].ParentID)
+	})
+
+	t.Run("子任务数量", func(t *testing.T) {
+		issues, _ := checkUserTaskTree([]*Task{
+			{ID: "r", RootTaskID: "r", HasChildren: false, ChildrenCount: 5},
+			{ID: "a", ParentID: "r", RootTaskID: "r", TreeDepth: 1},
+		})
+		assert.Equal(t, []TreeIssue{
+			{TaskID: "r", Type: TreeIssueChildrenCount, Actual: "5", Expected: "1"},
+			{TaskID: "r", Type: TreeIssueHasChildren, Actual: "false", Expected: "true"},
+		}, issues)
+	})
+}
+
+func TestTaskUsecase_CheckTaskTrees(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryTaskRepo(
+		&Task{ID: "r", UserID: "u1", RootTaskID: "r", Estimate: 10},
+		&Task{ID: "a", UserID: "u1", ParentID: "r", Estimate: 5},
+		&Task{ID: "x", UserID: "u2", RootTaskID: "x"},
+	)
+	uc := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+	report, err := uc.CheckTaskTrees(ctx, CheckTaskTreesParam{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.CheckedUsers)
+	assert.Equal(t, 3, report.CheckedTasks)
+	assert.Equal(t, 4, report.IssueCount) // a 的深度和根任务，r 的子任务数量和 has_children
+	assert.False(t, report.Fixed)
+	require.Len(t, report.Users, 1)
+	assert.Equal(t, "u1", report.Users[0].UserID)
+	assert.Equal(t, 0, repo.tasks["a"].TreeDepth)
+
+	report, err = uc.CheckTaskTrees(ctx, CheckTaskTreesParam{UserID: "u1", Fix: true})
+	require.NoError(t, err)
+	assert.True(t, report.Fixed)
+	assert.Equal(t, 1, repo.tasks["a"].TreeDepth)
+	assert.Equal(t, "r", repo.tasks["a"].RootTaskID)
+	assert.Equal(t, 1, repo.tasks["r"].ChildrenCount)
+	assert.Equal(t, 15, repo.tasks["r"].TreeEstimate)
+
+	report, err = uc.CheckTaskTrees(ctx, CheckTaskTreesParam{})
+	require.NoError(t, err)
+	assert.Zero(t, report.IssueCount)
+	assert.False(t, report.Fixed)
+}
+
+func TestTaskUsecase_CheckTaskTrees_RefreshTotals(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryTaskRepo(
+		// a 挂在 r 下，但 root_task_id 错误地指向 old，old 的整树估算仍包含 a
+		&Task{ID: "old", UserID: "u1", RootTaskID: "old", Estimate: 1, TreeEstimate: 6},
+		&Task{ID: "r", UserID: "u1", RootTaskID: "r", Estimate: 10, TreeEstimate: 10, HasChildren: true, ChildrenCount: 1},
+		&Task{ID: "a", UserID: "u1", ParentID: "r", RootTaskID: "old", TreeDepth: 1, Estimate: 5},
+		// p 和 q 互为父任务，断开 p 后 q 失去子任务 p
+		&Task{ID: "p", UserID: "u1", ParentID: "q", RootTaskID: "p", Score: 4, RollupScore: 4, HasChildren: true, ChildrenCount: 1},
+		&Task{ID: "q", UserID: "u1", ParentID: "p", RootTaskID: "p", TreeDepth: 1, Score: 3, RollupScore: 4, HasChildren: true, ChildrenCount: 1},
+	)
+	uc := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+	report, err := uc.CheckTaskTrees(ctx, CheckTaskTreesParam{UserID: "u1", Fix: true})
+
+	require.NoError(t, err)
+	assert.True(t, report.Fixed)
+	assert.Equal(t, 1, repo.tasks["old"].TreeEstimate, "the previous root no longer counts a")
+	assert.Equal(t, 15, repo.tasks["r"].TreeEstimate)
+	assert.Empty(t, repo.tasks["p"].ParentID)
+	assert.Equal(t, 3.0, repo.tasks["q"].RollupScore, "q lost its only child")
+	assert.Equal(t, 3.0, repo.tasks["p"].RollupScore)
+}
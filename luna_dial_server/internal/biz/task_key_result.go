@@ -0,0 +1,172 @@
+package biz
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 关键结果单位的最大长度（字符）
+const maxKeyResultUnitLength = 32
+
+// KeyResult 可量化的关键结果，例如"读 24 本书"：起始值 0，目标值 24，单位"本"
+// 当前值只能通过打卡（CheckInKeyResult）修改，每次打卡都会留下记录
+type KeyResult struct {
+	StartValue   float64 `json:"start_value"`
+	TargetValue  float64 `json:"target_value"`
+	CurrentValue float64 `json:"current_value"`
+	Unit         string  `json:"unit"`
+	// 达成率（百分比）：(current - start) / (target - start) * 100，不截断，超额完成时大于 100
+	Attainment float64 `json:"attainment"`
+}
+
+// calcAttainment 计算达成率，目标值可以小于起始值（例如"体重从 80 降到 70"）
+func (kr *KeyResult) calcAttainment() float64 {
+	if kr.TargetValue == kr.StartValue {
+		return 0
+	}
+	return roundRollup((kr.CurrentValue - kr.StartValue) / (kr.TargetValue - kr.StartValue) * 100)
+}
+
+// Progress 关键结果的进度，达成率截断到 0-100
+func (kr *KeyResult) Progress() float64 {
+	return math.Max(0, math.Min(100, kr.Attainment))
+}
+
+// NewKeyResult 根据各个值构造关键结果并计算达成率，数据层读取任务时使用
+func NewKeyResult(startValue, targetValue, currentValue float64, unit string) *KeyResult {
+	kr := &KeyResult{StartValue: startValue, TargetValue: targetValue, CurrentValue: currentValue, Unit: unit}
+	kr.Attainment = kr.calcAttainment()
+	return kr
+}
+
+// KeyResultInput 创建任务或把任务改为关键结果时的设置
+type KeyResultInput struct {
+	StartValue  float64
+	TargetValue float64
+	Unit        string
+}
+
+func isFiniteValue(value float64) bool {
+	return !math.IsInf(value, 0) && !math.IsNaN(value)
+}
+
+// validate 检查关键结果设置并规范化单位
+func (in *KeyResultInput) validate() error {
+	in.Unit = strings.TrimSpace(in.Unit)
+	if !isFiniteValue(in.StartValue) || !isFiniteValue(in.TargetValue) ||
+		in.TargetValue == in.StartValue || utf8.RuneCountInString(in.Unit) > maxKeyResultUnitLength {
+		return ErrKeyResultInvalid
+	}
+	return nil
+}
+
+// applyKeyResult 把任务设置为关键结果：新的关键结果从起始值开始，已有的保留当前值
+func (t *Task) applyKeyResult(in KeyResultInput) {
+	current := in.StartValue
+	if t.KeyResult != nil {
+		current = t.KeyResult.CurrentValue
+	}
+	t.KeyResult = NewKeyResult(in.StartValue, in.TargetValue, current, in.Unit)
+}
+
+// KeyResultCheckIn 关键结果的一次打卡：记录当时的值和备注
+type KeyResultCheckIn struct {
+	ID            string    `json:"id"`
+	TaskID        string    `json:"task_id"`
+	UserID        string    `json:"user_id"`
+	Value         float64   `json:"value"`
+	PreviousValue float64   `json:"previous_value"` // 打卡前关键结果的当前值
+	Note          string    `json:"note"`
+	CheckedAt     time.Time `json:"checked_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// 关键结果打卡参数
+type CheckInKeyResultParam struct {
+	TaskID    string
+	UserID    string
+	Value     float64
+	Note      string
+	CheckedAt time.Time // 为空时为当前时间，可以补录过去的打卡，不能晚于当前时间
+}
+
+// 获取关键结果打卡记录参数
+type ListKeyResultCheckInsParam struct {
+	TaskID string
+	UserID string
+}
+
+// 关键结果打卡
+// 当前值取打卡时间最晚的一次记录，补录更早的打卡只留下记录，不改变当前值
+// 当前值变化后重算任务自身和祖先的进度
+func (uc *TaskUsecase) CheckInKeyResult(ctx context.Context, param CheckInKeyResultParam) (*KeyResultCheckIn, error) {
+	if param.TaskID == "" || param.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+	now := time.Now()
+	if param.CheckedAt.IsZero() {
+		param.CheckedAt = now
+	}
+	if !isFiniteValue(param.Value) || param.CheckedAt.After(now) {
+		return nil, ErrCheckInInvalid
+	}
+
+	task, err := uc.repo.GetTask(ctx, param.TaskID, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+	if task.KeyResult == nil {
+		return nil, ErrTaskNotKeyResult
+	}
+
+	checkIn := &KeyResultCheckIn{
+		ID:            generateID(),
+		TaskID:        task.ID,
+		UserID:        task.UserID,
+		Value:         param.Value,
+		PreviousValue: task.KeyResult.CurrentValue,
+		Note:          param.Note,
+		CheckedAt:     param.CheckedAt,
+		CreatedAt:     now,
+	}
+
+	err = uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		latest, err := uc.repo.GetLatestKeyResultCheckIn(ctx, task.ID, task.UserID)
+		if err != nil {
+			return err
+		}
+		if err := uc.repo.CreateKeyResultCheckIn(ctx, checkIn); err != nil {
+			return err
+		}
+		if latest != nil && checkIn.CheckedAt.Before(latest.CheckedAt) {
+			return nil
+		}
+
+		// 打卡记录本身就是当前值的历史，不再另外记录变更历史
+		kr := task.KeyResult
+		task.KeyResult = NewKeyResult(kr.StartValue, kr.TargetValue, param.Value, kr.Unit)
+		task.UpdatedAt = now
+		if err := uc.repo.UpdateTask(ctx, task); err != nil {
+			return err
+		}
+		return uc.refreshRollup(ctx, task)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return checkIn, nil
+}
+
+// 获取关键结果的打卡记录（按打卡时间倒序）
+func (uc *TaskUsecase) ListKeyResultCheckIns(ctx context.Context, param ListKeyResultCheckInsParam) ([]*KeyResultCheckIn, error) {
+	if err := uc.checkTaskExists(ctx, param.TaskID, param.UserID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListKeyResultCheckIns(ctx, param.TaskID, param.UserID)
+}
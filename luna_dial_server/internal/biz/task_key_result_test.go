@@ -0,0 +1,124 @@
+package biz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyResultAttainment(t *testing.T) {
+	kr := NewKeyResult(0, 24, 6, "本")
+	assert.Equal(t, 25.0, kr.Attainment)
+	assert.Equal(t, 25.0, kr.Progress())
+
+	// 目标值小于起始值：体重从 80 降到 70
+	kr = NewKeyResult(80, 70, 77.5, "kg")
+	assert.Equal(t, 25.0, kr.Attainment)
+
+	// 超额完成时达成率不截断，进度截断到 100
+	kr = NewKeyResult(0, 10, 15, "")
+	assert.Equal(t, 150.0, kr.Attainment)
+	assert.Equal(t, 100.0, kr.Progress())
+
+	kr = NewKeyResult(10, 20, 5, "")
+	assert.Equal(t, -50.0, kr.Attainment)
+	assert.Equal(t, 0.0, kr.Progress())
+}
+
+func TestTaskUsecase_KeyResult(t *testing.T) {
+	ctx := context.Background()
+	period := Period{Start: date(2025, 1, 1), End: date(2025, 4, 1)}
+
+	setup := func(t *testing.T) (*TaskUsecase, *memoryTaskRepo, *Task, *Task) {
+		repo := newMemoryTaskRepo()
+		uc := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+		objective, err := uc.CreateTask(ctx, CreateTaskParam{UserID: "u1", Title: "多读书", Type: PeriodQuarter, Period: period})
+		require.NoError(t, err)
+		kr, err := uc.CreateSubTask(ctx, CreateSubTaskParam{
+			UserID: "u1", ParentID: objective.ID, Title: "读 24 本书", Type: PeriodQuarter, Period: period,
+			KeyResult: &KeyResultInput{StartValue: 0, TargetValue: 24, Unit: " 本 "},
+		})
+		require.NoError(t, err)
+		return uc, repo, objective, kr
+	}
+
+	t.Run("创建关键结果", func(t *testing.T) {
+		_, repo, _, kr := setup(t)
+		require.NotNil(t, kr.KeyResult)
+		assert.Equal(t, &KeyResult{StartValue: 0, TargetValue: 24, CurrentValue: 0, Unit: "本"}, kr.KeyResult)
+		assert.Equal(t, 0.0, repo.tasks[kr.ID].Progress)
+	})
+
+	t.Run("打卡更新当前值和父任务进度", func(t *testing.T) {
+		uc, repo, objective, kr := setup(t)
+
+		checkIn, err := uc.CheckInKeyResult(ctx, CheckInKeyResultParam{TaskID: kr.ID, UserID: "u1", Value: 6, Note: "一月"})
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, checkIn.PreviousValue)
+		assert.Equal(t, 6.0, repo.tasks[kr.ID].KeyResult.CurrentValue)
+		assert.Equal(t, 25.0, repo.tasks[kr.ID].Progress)
+		assert.Equal(t, 25.0, repo.tasks[objective.ID].Progress)
+
+		// 补录更早的打卡只留下记录
+		_, err = uc.CheckInKeyResult(ctx, CheckInKeyResultParam{TaskID: kr.ID, UserID: "u1", Value: 2, CheckedAt: time.Now().Add(-24 * time.Hour)})
+		require.NoError(t, err)
+		assert.Equal(t, 6.0, repo.tasks[kr.ID].KeyResult.CurrentValue)
+
+		checkIns, err := uc.ListKeyResultCheckIns(ctx, ListKeyResultCheckInsParam{TaskID: kr.ID, UserID: "u1"})
+		require.NoError(t, err)
+		require.Len(t, checkIns, 2)
+		assert.Equal(t, 6.0, checkIns[0].Value)
+
+		// 完成状态不影响关键结果的进度
+		completed := TaskStatusCompleted
+		_, err = uc.UpdateTask(ctx, UpdateTaskParam{TaskID: kr.ID, UserID: "u1", Status: &completed})
+		require.NoError(t, err)
+		assert.Equal(t, 25.0, repo.tasks[kr.ID].Progress)
+	})
+
+	t.Run("修改和移除关键结果", func(t *testing.T) {
+		uc, repo, _, kr := setup(t)
+		_, err := uc.CheckInKeyResult(ctx, CheckInKeyResultParam{TaskID: kr.ID, UserID: "u1", Value: 6})
+		require.NoError(t, err)
+
+		updated, err := uc.UpdateTask(ctx, UpdateTaskParam{TaskID: kr.ID, UserID: "u1", KeyResult: &KeyResultInput{StartValue: 0, TargetValue: 12, Unit: "本"}})
+		require.NoError(t, err)
+		assert.Equal(t, 6.0, updated.KeyResult.CurrentValue)
+		assert.Equal(t, 50.0, repo.tasks[kr.ID].Progress)
+
+		updated, err = uc.UpdateTask(ctx, UpdateTaskParam{TaskID: kr.ID, UserID: "u1", RemoveKeyResult: true})
+		require.NoError(t, err)
+		assert.Nil(t, updated.KeyResult)
+		assert.Equal(t, 0.0, repo.tasks[kr.ID].Progress)
+
+		_, err = uc.CheckInKeyResult(ctx, CheckInKeyResultParam{TaskID: kr.ID, UserID: "u1", Value: 7})
+		assert.ErrorIs(t, err, ErrTaskNotKeyResult)
+	})
+
+	t.Run("拒绝不合法的设置和打卡", func(t *testing.T) {
+		uc, _, _, kr := setup(t)
+
+		_, err := uc.CreateTask(ctx, CreateTaskParam{UserID: "u1", Title: "kr", Type: PeriodQuarter, Period: period, KeyResult: &KeyResultInput{StartValue: 5, TargetValue: 5}})
+		assert.ErrorIs(t, err, ErrKeyResultInvalid)
+
+		_, err = uc.CheckInKeyResult(ctx, CheckInKeyResultParam{TaskID: kr.ID, UserID: "u1", Value: 1, CheckedAt: time.Now().Add(time.Hour)})
+		assert.ErrorIs(t, err, ErrCheckInInvalid)
+
+		_, err = uc.CheckInKeyResult(ctx, CheckInKeyResultParam{TaskID: "missing", UserID: "u1", Value: 1})
+		assert.ErrorIs(t, err, ErrTaskNotFound)
+	})
+}
+
+func TestKeyResultSummary(t *testing.T) {
+	tasks := []*Task{
+		{KeyResult: NewKeyResult(0, 10, 5, "")},
+		{KeyResult: NewKeyResult(0, 10, 20, "")}, // 超额完成按 100 计
+		{Title: "普通任务"},
+	}
+	count, progress := keyResultSummary(tasks)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 75.0, progress)
+}
@@ -0,0 +1,201 @@
+package biz
+
+import (
+	"context"
+	"sort"
+)
+
+// SortRankGap 相邻同级任务之间的排序间隔，调整顺序时插入到两个任务之间，间隔用完后整体重排
+const SortRankGap int64 = 1024
+
+// 调整同级任务顺序参数
+type ReorderTasksParam struct {
+	UserID   string
+	ParentID string   // 为空表示根任务
+	TaskIDs  []string // 按期望顺序排列的子任务ID
+}
+
+// sortSiblingTasks 按排序位置排列同级任务，位置相同时先创建的在前
+func sortSiblingTasks(tasks []*Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].SortRank != tasks[j].SortRank {
+			return tasks[i].SortRank < tasks[j].SortRank
+		}
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
+
+// assignSortRank 为即将挂到 task.ParentID 下的任务分配排序位置，需要在事务中调用
+// 子任务排在同级任务最后；根任务排在最前，与原来按创建时间倒序的根任务列表保持一致
+func (uc *TaskUsecase) assignSortRank(ctx context.Context, task *Task) error {
+	minRank, maxRank, err := uc.repo.GetSiblingSortRankRange(ctx, task.UserID, task.ParentID)
+	if err != nil {
+		return err
+	}
+	if task.ParentID == "" {
+		task.SortRank = minRank - SortRankGap
+	} else {
+		task.SortRank = maxRank + SortRankGap
+	}
+	return nil
+}
+
+// rerankSiblings 计算把同级任务排列为 ordered 所需的新排序位置，只返回需要修改的任务
+func rerankSiblings(ordered []*Task) map[string]int64 {
+	return rerankTasks(ordered, func(task *Task) int64 { return task.SortRank })
+}
+
+// rerankTasks 计算把任务排列为 ordered 所需的新位置，rankOf 为任务当前的位置，只返回需要修改的任务
+// 保留原位置已经递增的最长子序列，其余任务插入到相邻保留任务之间；间隔不足时整体重排
+func rerankTasks(ordered []*Task, rankOf func(*Task) int64) map[string]int64 {
+	n := len(ordered)
+	changed := make(map[string]int64)
+	if n == 0 {
+		return changed
+	}
+
+	// 最长严格递增子序列（按原排序位置）
+	tails := make([]int, 0, n) // tails[k] 为长度 k+1 的递增子序列的末尾下标
+	prev := make([]int, n)
+	for i, task := range ordered {
+		k := sort.Search(len(tails), func(k int) bool { return rankOf(ordered[tails[k]]) >= rankOf(task) })
+		prev[i] = -1
+		if k > 0 {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	kept := make([]bool, n)
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		kept[i] = true
+	}
+
+	ranks := make([]int64, n)
+	for i := 0; i < n; {
+		if kept[i] {
+			ranks[i] = rankOf(ordered[i])
+			i++
+			continue
+		}
+		// [i, j) 为连续的未保留任务
+		j := i
+		for j < n && !kept[j] {
+			j++
+		}
+		count := int64(j - i)
+		switch {
+		case i == 0:
+			hi := rankOf(ordered[j])
+			for k := i; k < j; k++ {
+				ranks[k] = hi - SortRankGap*(count-int64(k-i))
+			}
+		case j == n:
+			lo := ranks[i-1]
+			for k := i; k < j; k++ {
+				ranks[k] = lo + SortRankGap*int64(k-i+1)
+			}
+		default:
+			lo, hi := ranks[i-1], rankOf(ordered[j])
+			step := (hi - lo) / (count + 1)
+			if step < 1 {
+				// 间隔用完，按顺序整体重排
+				for k, task := range ordered {
+					if rank := SortRankGap * int64(k+1); rank != rankOf(task) {
+						changed[task.ID] = rank
+					}
+				}
+				return changed
+			}
+			for k := i; k < j; k++ {
+				ranks[k] = lo + step*int64(k-i+1)
+			}
+		}
+		i = j
+	}
+
+	for i, task := range ordered {
+		if ranks[i] != rankOf(task) {
+			changed[task.ID] = ranks[i]
+		}
+	}
+	return changed
+}
+
+// 调整同一父任务下子任务的顺序，返回调整后的全部子任务
+// TaskIDs 可以只包含部分子任务：这些任务按给定顺序重新占据它们原来的位置，其余子任务位置不变
+func (uc *TaskUsecase) ReorderTasks(ctx context.Context, param ReorderTasksParam) ([]*Task, error) {
+	if param.UserID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	if len(param.TaskIDs) == 0 {
+		return nil, ErrInvalidInput
+	}
+	if param.ParentID != "" {
+		parent, err := uc.repo.GetTask(ctx, param.ParentID, param.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, ErrTaskNotFound
+		}
+	}
+
+	var siblings []*Task
+	err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		siblings, err = uc.repo.ListChildTasks(ctx, param.ParentID, param.UserID)
+		if err != nil {
+			return err
+		}
+		sortSiblingTasks(siblings)
+
+		byID := make(map[string]*Task, len(siblings))
+		for _, task := range siblings {
+			byID[task.ID] = task
+		}
+		moving := make(map[string]bool, len(param.TaskIDs))
+		for _, id := range param.TaskIDs {
+			if byID[id] == nil || moving[id] {
+				return ErrTaskReorderInvalid
+			}
+			moving[id] = true
+		}
+
+		// 被调整的任务按给定顺序依次填入它们原来占据的位置
+		ordered := make([]*Task, len(siblings))
+		next := 0
+		for i, task := range siblings {
+			if moving[task.ID] {
+				ordered[i] = byID[param.TaskIDs[next]]
+				next++
+			} else {
+				ordered[i] = task
+			}
+		}
+
+		changed := rerankSiblings(ordered)
+		if len(changed) > 0 {
+			if err := uc.repo.UpdateTaskSortRanks(ctx, param.UserID, changed); err != nil {
+				return err
+			}
+		}
+		for _, task := range ordered {
+			if rank, ok := changed[task.ID]; ok {
+				task.SortRank = rank
+			}
+		}
+		siblings = ordered
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return siblings, nil
+}
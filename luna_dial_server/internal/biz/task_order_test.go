@@ -0,0 +1,155 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rankedTasks(ranks ...int64) []*Task {
+	tasks := make([]*Task, len(ranks))
+	for i, rank := range ranks {
+		tasks[i] = &Task{ID: string(rune('a' + i)), SortRank: rank}
+	}
+	return tasks
+}
+
+// assertRanksIncreasing 应用新的排序位置后，ordered 中的任务按排序位置严格递增
+func assertRanksIncreasing(t *testing.T, ordered []*Task, changed map[string]int64) {
+	t.Helper()
+	ranks := make([]int64, len(ordered))
+	for i, task := range ordered {
+		ranks[i] = task.SortRank
+		if rank, ok := changed[task.ID]; ok {
+			ranks[i] = rank
+		}
+		if i > 0 {
+			assert.Less(t, ranks[i-1], ranks[i])
+		}
+	}
+}
+
+func TestRerankSiblings(t *testing.T) {
+	t.Run("移动一个任务只修改它自己", func(t *testing.T) {
+		tasks := rankedTasks(1024, 2048, 3072, 4096)
+		// d 移动到最前
+		ordered := []*Task{tasks[3], tasks[0], tasks[1], tasks[2]}
+
+		changed := rerankSiblings(ordered)
+		require.Len(t, changed, 1)
+		assert.Equal(t, int64(0), changed["d"])
+
+		// b 移动到 c、d 之间
+		ordered = []*Task{tasks[0], tasks[2], tasks[1], tasks[3]}
+		changed = rerankSiblings(ordered)
+		assert.Len(t, changed, 1)
+		assertRanksIncreasing(t, ordered, changed)
+	})
+
+	t.Run("顺序不变时不修改", func(t *testing.T) {
+		assert.Empty(t, rerankSiblings(rankedTasks(1, 5, 9)))
+	})
+
+	t.Run("间隔用完时整体重排", func(t *testing.T) {
+		tasks := rankedTasks(1, 2, 3)
+		ordered := []*Task{tasks[0], tasks[2], tasks[1]}
+
+		changed := rerankSiblings(ordered)
+		assert.Equal(t, map[string]int64{"a": SortRankGap, "c": 2 * SortRankGap, "b": 3 * SortRankGap}, changed)
+	})
+
+	t.Run("重复的排序位置会被拉开", func(t *testing.T) {
+		ordered := rankedTasks(0, 0, 0)
+		changed := rerankSiblings(ordered)
+		assert.Len(t, changed, 2)
+		assertRanksIncreasing(t, ordered, changed)
+	})
+}
+
+func TestTaskUsecase_SortRank(t *testing.T) {
+	ctx := context.Background()
+	newParent := func() *Task {
+		return &Task{
+			ID: "parent", UserID: "user-123", Title: "月度目标", TaskType: PeriodMonth,
+			TimePeriod: NewPeriodFromPeriodType(PeriodMonth, date(2025, 1, 1)), RootTaskID: "parent", SortRank: 0,
+		}
+	}
+	createChild := func(t *testing.T, usecase *TaskUsecase, title string) *Task {
+		child, err := usecase.CreateSubTask(ctx, CreateSubTaskParam{
+			ParentID: "parent", UserID: "user-123", Title: title, Type: PeriodDay,
+			Period: NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 6)),
+		})
+		require.NoError(t, err)
+		return child
+	}
+
+	t.Run("子任务排在最后，根任务排在最前", func(t *testing.T) {
+		usecase := NewTaskUsecase(newMemoryTaskRepo(newParent()), newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+		first := createChild(t, usecase, "KR1")
+		second := createChild(t, usecase, "KR2")
+		assert.Equal(t, SortRankGap, first.SortRank)
+		assert.Equal(t, 2*SortRankGap, second.SortRank)
+
+		root, err := usecase.CreateTask(ctx, CreateTaskParam{
+			UserID: "user-123", Title: "新目标", Type: PeriodDay,
+			Period: NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 6)),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, -SortRankGap, root.SortRank)
+	})
+
+	t.Run("移动后排在新父任务的子任务最后", func(t *testing.T) {
+		repo := newMemoryTaskRepo(newParent())
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+		createChild(t, usecase, "KR1")
+		loose, err := usecase.CreateTask(ctx, CreateTaskParam{
+			UserID: "user-123", Title: "散落的任务", Type: PeriodDay,
+			Period: NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 7)),
+		})
+		require.NoError(t, err)
+
+		_, err = usecase.MoveTask(ctx, MoveTaskParam{TaskID: loose.ID, UserID: "user-123", NewParentID: "parent"})
+		require.NoError(t, err)
+		assert.Equal(t, 2*SortRankGap, repo.tasks[loose.ID].SortRank)
+	})
+
+	t.Run("按给定顺序调整子任务", func(t *testing.T) {
+		repo := newMemoryTaskRepo(newParent())
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+		kr1 := createChild(t, usecase, "KR1")
+		kr2 := createChild(t, usecase, "KR2")
+		kr3 := createChild(t, usecase, "KR3")
+
+		tasks, err := usecase.ReorderTasks(ctx, ReorderTasksParam{UserID: "user-123", ParentID: "parent", TaskIDs: []string{kr3.ID, kr1.ID, kr2.ID}})
+		require.NoError(t, err)
+		require.Len(t, tasks, 3)
+		assert.Equal(t, []string{kr3.ID, kr1.ID, kr2.ID}, []string{tasks[0].ID, tasks[1].ID, tasks[2].ID})
+		assert.Equal(t, SortRankGap, repo.tasks[kr1.ID].SortRank, "tasks already in order keep their rank")
+		assert.Less(t, repo.tasks[kr3.ID].SortRank, repo.tasks[kr1.ID].SortRank)
+
+		// 只传部分子任务：两者交换位置，其他任务不动
+		tasks, err = usecase.ReorderTasks(ctx, ReorderTasksParam{UserID: "user-123", ParentID: "parent", TaskIDs: []string{kr2.ID, kr3.ID}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{kr2.ID, kr1.ID, kr3.ID}, []string{tasks[0].ID, tasks[1].ID, tasks[2].ID})
+		children, err := repo.ListChildTasks(ctx, "parent", "user-123")
+		require.NoError(t, err)
+		sortSiblingTasks(children)
+		assert.Equal(t, []string{kr2.ID, kr1.ID, kr3.ID}, []string{children[0].ID, children[1].ID, children[2].ID})
+	})
+
+	t.Run("非法的排序请求", func(t *testing.T) {
+		repo := newMemoryTaskRepo(newParent())
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+		kr1 := createChild(t, usecase, "KR1")
+
+		_, err := usecase.ReorderTasks(ctx, ReorderTasksParam{UserID: "user-123", ParentID: "parent", TaskIDs: []string{kr1.ID, kr1.ID}})
+		assert.ErrorIs(t, err, ErrTaskReorderInvalid)
+		_, err = usecase.ReorderTasks(ctx, ReorderTasksParam{UserID: "user-123", ParentID: "parent", TaskIDs: []string{"parent"}})
+		assert.ErrorIs(t, err, ErrTaskReorderInvalid)
+		_, err = usecase.ReorderTasks(ctx, ReorderTasksParam{UserID: "user-123", ParentID: "missing", TaskIDs: []string{kr1.ID}})
+		assert.ErrorIs(t, err, ErrTaskNotFound)
+	})
+}
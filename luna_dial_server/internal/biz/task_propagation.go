@@ -0,0 +1,122 @@
+package biz
+
+import (
+	"context"
+	"time"
+)
+
+// propagateCompletion 子任务状态变化后，沿父任务链向上传播完成状态
+// 规则：
+//   - 父任务所有未取消的子任务都已完成时，根据用户设置自动完成父任务，或仅标记为"可完成"
+//     （父任务仍被其他任务阻塞时只标记）
+//   - 子任务从已完成被重新打开时，已完成的父任务回到进行中
+//
+// 只有父任务的状态真正发生变化时才继续向上传播
+// 返回状态或"可完成"标记被改变的祖先任务（由近到远）
+func (uc *TaskUsecase) propagateCompletion(ctx context.Context, task *Task, oldStatus TaskStatus) ([]*Task, error) {
+	if task.ParentID == "" {
+		return nil, nil
+	}
+
+	settings, err := loadUserSettings(ctx, uc.settingsRepo, task.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 父级链路：从根任务到直接父任务
+	chain, err := uc.repo.GetTaskParentChain(ctx, task.ParentID, task.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	reopened := oldStatus == TaskStatusCompleted && task.Status != TaskStatusCompleted
+	changed := make([]*Task, 0)
+	for i := len(chain) - 1; i >= 0; i-- {
+		parent := chain[i]
+		children, err := uc.repo.ListChildTasks(ctx, parent.ID, task.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 父任务自身仍被阻塞时不能自动完成，只标记为"可完成"
+		mode := settings.CompletionPropagation
+		if mode == CompletionPropagationAuto && parent.Status != TaskStatusCompleted {
+			unresolved, err := uc.unresolvedBlockers(ctx, parent.ID, task.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if len(unresolved) > 0 {
+				mode = CompletionPropagationFlag
+			}
+		}
+
+		parentOldStatus := parent.Status
+		parentBefore := *parent
+		if !applyCompletionRule(parent, children, mode, reopened) {
+			break
+		}
+
+		parent.UpdatedAt = time.Now()
+		if err := uc.repo.UpdateTask(ctx, parent); err != nil {
+			return nil, err
+		}
+		if err := uc.recordTaskChange(ctx, ChangeActionUpdate, &parentBefore, parent); err != nil {
+			return nil, err
+		}
+		changed = append(changed, parent)
+
+		// 状态没变（只改了"可完成"标记）时，更上层的父任务不受影响
+		if parent.Status == parentOldStatus {
+			break
+		}
+		reopened = parentOldStatus == TaskStatusCompleted && parent.Status != TaskStatusCompleted
+	}
+
+	return changed, nil
+}
+
+// applyCompletionRule 根据子任务状态修改父任务的状态或"可完成"标记，返回父任务是否被修改
+func applyCompletionRule(parent *Task, children []*Task, mode CompletionPropagationMode, reopened bool) bool {
+	active, completed := 0, 0
+	for _, child := range children {
+		if child.Status == TaskStatusCancelled {
+			continue
+		}
+		active++
+		if child.Status == TaskStatusCompleted {
+			completed++
+		}
+	}
+	allDone := active > 0 && completed == active
+
+	modified := false
+	if allDone {
+		switch mode {
+		case CompletionPropagationFlag:
+			if parent.Status != TaskStatusCompleted && parent.Status != TaskStatusCancelled && !parent.ReadyToComplete {
+				parent.ReadyToComplete = true
+				modified = true
+			}
+		default:
+			if parent.Status != TaskStatusCompleted && parent.Status != TaskStatusCancelled {
+				parent.applyStatus(TaskStatusCompleted, time.Now())
+				modified = true
+			}
+			if parent.ReadyToComplete {
+				parent.ReadyToComplete = false
+				modified = true
+			}
+		}
+		return modified
+	}
+
+	if parent.ReadyToComplete {
+		parent.ReadyToComplete = false
+		modified = true
+	}
+	if reopened && parent.Status == TaskStatusCompleted {
+		parent.applyStatus(TaskStatusInProgress, time.Now())
+		modified = true
+	}
+	return modified
+}
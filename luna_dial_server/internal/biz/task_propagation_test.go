@@ -0,0 +1,95 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskUsecase_CompletionPropagation(t *testing.T) {
+	ctx := context.Background()
+	completed := TaskStatusCompleted
+	inProgress := TaskStatusInProgress
+
+	// 年目标 -> 季度目标 -> 两个月任务
+	newRepo := func() *memoryTaskRepo {
+		year := newTestTask("year", PeriodYear, date(2025, 1, 1), TaskStatusInProgress, nil)
+		quarter := newTestTask("quarter", PeriodQuarter, date(2025, 1, 1), TaskStatusInProgress, year)
+		return newMemoryTaskRepo(
+			year,
+			quarter,
+			newTestTask("month-1", PeriodMonth, date(2025, 1, 1), TaskStatusCompleted, quarter),
+			newTestTask("month-2", PeriodMonth, date(2025, 2, 1), TaskStatusInProgress, quarter),
+		)
+	}
+
+	t.Run("所有子任务完成后自动完成父任务并继续向上传播", func(t *testing.T) {
+		repo := newRepo()
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+		task, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "month-2", UserID: "user-123", Status: &completed})
+
+		require.NoError(t, err)
+		require.Len(t, task.ChangedAncestors, 2, "quarter and year should both be completed")
+		assert.Equal(t, "quarter", task.ChangedAncestors[0].ID)
+		assert.Equal(t, "year", task.ChangedAncestors[1].ID)
+		assert.Equal(t, TaskStatusCompleted, repo.tasks["quarter"].Status)
+		assert.Equal(t, TaskStatusCompleted, repo.tasks["year"].Status)
+	})
+
+	t.Run("用户设置为仅标记时只设置可完成标记", func(t *testing.T) {
+		repo := newRepo()
+		settingsRepo := newMockUserSettingsRepo()
+		settingsRepo.settings["user-123"] = &UserSettings{UserID: "user-123", CompletionPropagation: CompletionPropagationFlag}
+		usecase := NewTaskUsecase(repo, settingsRepo, newMockChangeHistoryRepo())
+
+		task, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "month-2", UserID: "user-123", Status: &completed})
+
+		require.NoError(t, err)
+		require.Len(t, task.ChangedAncestors, 1, "only the direct parent should be flagged")
+		assert.True(t, repo.tasks["quarter"].ReadyToComplete)
+		assert.Equal(t, TaskStatusInProgress, repo.tasks["quarter"].Status)
+		assert.Equal(t, TaskStatusInProgress, repo.tasks["year"].Status)
+	})
+
+	t.Run("重新打开子任务时已完成的父任务回到进行中", func(t *testing.T) {
+		repo := newRepo()
+		repo.tasks["month-2"].Status = TaskStatusCompleted
+		repo.tasks["quarter"].Status = TaskStatusCompleted
+		repo.tasks["year"].Status = TaskStatusCompleted
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+		task, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "month-1", UserID: "user-123", Status: &inProgress})
+
+		require.NoError(t, err)
+		require.Len(t, task.ChangedAncestors, 2)
+		assert.Equal(t, TaskStatusInProgress, repo.tasks["quarter"].Status)
+		assert.Equal(t, TaskStatusInProgress, repo.tasks["year"].Status)
+	})
+
+	t.Run("已取消的子任务不参与判断", func(t *testing.T) {
+		repo := newRepo()
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+		cancelled := TaskStatusCancelled
+
+		task, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "month-2", UserID: "user-123", Status: &cancelled})
+
+		require.NoError(t, err)
+		require.NotEmpty(t, task.ChangedAncestors)
+		assert.Equal(t, TaskStatusCompleted, repo.tasks["quarter"].Status)
+	})
+
+	t.Run("状态未变化时不传播", func(t *testing.T) {
+		repo := newRepo()
+		usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+		title := "新标题"
+
+		task, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "month-2", UserID: "user-123", Title: &title})
+
+		require.NoError(t, err)
+		assert.Empty(t, task.ChangedAncestors)
+		assert.Equal(t, TaskStatusInProgress, repo.tasks["quarter"].Status)
+	})
+}
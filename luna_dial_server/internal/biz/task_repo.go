@@ -9,12 +9,85 @@ type TaskRepo interface {
 	CreateTask(ctx context.Context, task *Task) error
 	UpdateTask(ctx context.Context, task *Task) error
 	DeleteTask(ctx context.Context, taskID, userID string) error
+	DeleteTaskSubtree(ctx context.Context, taskID, userID string) error
 	GetTask(ctx context.Context, taskID, userID string) (*Task, error)
-	ListTasks(ctx context.Context, userID string, periodStart, periodEnd time.Time, taskType int) ([]*Task, error)
+	// 获取直接子任务，按 sort_rank 排序；parentID 为空时返回根任务
+	ListChildTasks(ctx context.Context, parentID, userID string) ([]*Task, error)
+	// 获取指定类型、时间段按 match 方式命中 [periodStart, periodEnd) 的任务
+	ListTasks(ctx context.Context, userID string, periodStart, periodEnd time.Time, taskType int, match PeriodMatch) ([]*Task, error)
 	ListTaskParentTree(ctx context.Context, taskID, userID string) ([]*Task, error)
 	ListRootTasksWithPagination(ctx context.Context, userID string, page, pageSize int, includeStatus []TaskStatus) ([]*Task, int64, error)
 	ListTasksByRootIDs(ctx context.Context, userID string, rootTaskIDs []string, includeStatus []TaskStatus) ([]*Task, error)
 	GetCompleteTaskTree(ctx context.Context, taskID, userID string, includeStatus []TaskStatus) ([]*Task, error)
+	// 从根任务到指定任务的完整链路（包含自身），不限制层数
 	GetTaskParentChain(ctx context.Context, taskID, userID string) ([]*Task, error)
+	// 获取任务及其所有后代，返回以该任务为根、带 Children 的树；任务不存在时返回空列表
+	// includeStatus 为空时包含所有状态，被过滤掉的任务的后代也不返回
+	ListSubtreeTasks(ctx context.Context, taskID, userID string, includeStatus []TaskStatus) ([]*Task, error)
 	UpdateTreeOptimizationFields(ctx context.Context, taskID, userID string) error
+	RebuildSubtreeOptimizationFields(ctx context.Context, taskID, userID string) error
+	// 同级任务的排序位置：parentID 为空表示根任务，没有同级任务时返回 0, 0
+	GetSiblingSortRankRange(ctx context.Context, userID, parentID string) (minRank, maxRank int64, err error)
+	// 只修改 sort_rank，不更新 updated_at
+	UpdateTaskSortRanks(ctx context.Context, userID string, ranks map[string]int64) error
+	// 只修改 board_rank，不更新 updated_at
+	UpdateTaskBoardRanks(ctx context.Context, userID string, ranks map[string]int64) error
+	// 保存汇总分数和进度，UpdateTask 不会覆盖这两个字段
+	UpdateTaskRollup(ctx context.Context, taskID, userID string, rollup TaskRollup) error
+	// 关键结果打卡
+	CreateKeyResultCheckIn(ctx context.Context, checkIn *KeyResultCheckIn) error
+	// 按打卡时间倒序
+	ListKeyResultCheckIns(ctx context.Context, taskID, userID string) ([]*KeyResultCheckIn, error)
+	// 打卡时间最晚的一次打卡，没有时返回 nil, nil
+	GetLatestKeyResultCheckIn(ctx context.Context, taskID, userID string) (*KeyResultCheckIn, error)
+	// 任务依赖（阻塞关系）
+	CreateTaskDependency(ctx context.Context, dependency *TaskDependency) error
+	DeleteTaskDependency(ctx context.Context, taskID, blockedByTaskID, userID string) error
+	// 用户的依赖边，includeTrashed 为 false 时不返回任一端在回收站中的边
+	ListTaskDependencies(ctx context.Context, userID string, includeTrashed bool) ([]*TaskDependency, error)
+	ListTaskBlockers(ctx context.Context, taskID, userID string) ([]*Task, error)
+	ListTaskDependents(ctx context.Context, taskID, userID string) ([]*Task, error)
+	// 获取完全落在时间段内的所有类型的任务
+	ListTasksInPeriod(ctx context.Context, userID string, periodStart, periodEnd time.Time) ([]*Task, error)
+	// 获取时间段包含 t 的所有类型的任务（period_start <= t < period_end）
+	ListTasksContainingTime(ctx context.Context, userID string, t time.Time) ([]*Task, error)
+	// 任务清单项（按 position 排序）
+	ListChecklistItems(ctx context.Context, taskID, userID string) ([]*ChecklistItem, error)
+	// 用 items 整体替换任务的清单
+	ReplaceChecklistItems(ctx context.Context, taskID, userID string, items []*ChecklistItem) error
+	// 任务工时记录
+	CreateTimeEntry(ctx context.Context, entry *TimeEntry) error
+	UpdateTimeEntry(ctx context.Context, entry *TimeEntry) error
+	DeleteTimeEntry(ctx context.Context, entryID, userID string) error
+	GetTimeEntry(ctx context.Context, entryID, userID string) (*TimeEntry, error)
+	// 获取用户正在运行的计时器，没有时返回 nil, nil
+	GetRunningTimeEntry(ctx context.Context, userID string) (*TimeEntry, error)
+	ListTimeEntries(ctx context.Context, taskID, userID string) ([]*TimeEntry, error)
+	// 获取开始时间落在 [start, end) 内且已结束的工时记录
+	ListTimeEntriesInRange(ctx context.Context, userID string, start, end time.Time) ([]*TimeEntry, error)
+	// 重算任务自身耗时，并通过 root_task_id 重算其根任务的整树耗时
+	RefreshTaskTimeSpent(ctx context.Context, taskID, userID string) error
+	// 重算根任务的整树耗时
+	RefreshTreeTimeSpent(ctx context.Context, rootTaskID, userID string) error
+	// 重算根任务的整树估算（root_task_id 相同且未取消的任务估算之和）
+	RefreshTreeEstimate(ctx context.Context, rootTaskID, userID string) error
+	// 回收站：DeleteTask / DeleteTaskSubtree 为软删除，以下方法操作已删除的任务
+	ListTrashedTasks(ctx context.Context, userID string) ([]*Task, error)
+	// 获取回收站中的任务，不存在或未被删除时返回 nil, nil
+	GetTrashedTask(ctx context.Context, taskID, userID string) (*Task, error)
+	// 恢复任务以及与它同一时刻被删除的后代
+	RestoreTaskSubtree(ctx context.Context, taskID, userID string) error
+	// 永久删除在 before 之前进入回收站的任务（所有用户），返回删除数量
+	PurgeTrashedTasks(ctx context.Context, before time.Time) (int64, error)
+	// 任务树检查：有未删除任务的所有用户
+	ListTaskUserIDs(ctx context.Context) ([]string, error)
+	// 获取用户所有未删除的任务
+	ListUserTasks(ctx context.Context, userID string) ([]*Task, error)
+	// 只修改 parent_id、root_task_id、tree_depth、children_count、has_children，不更新 updated_at
+	UpdateTaskTreeFields(ctx context.Context, task *Task) error
+	// 在当前事务中获取用户级的锁，同一用户的其他事务在此等待直到当前事务结束
+	// 用于先读后写、需要按用户串行化的检查（依赖环检测、看板在制品上限、运行中的计时器），必须在 Transaction 内调用
+	LockUserTasks(ctx context.Context, userID string) error
+	// Transaction 在同一事务中执行 fn，fn 内必须使用传入的 ctx 调用仓库方法
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
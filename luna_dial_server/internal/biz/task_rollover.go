@@ -0,0 +1,318 @@
+package biz
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// RolloverMode 未完成任务顺延到下一个时间段的方式
+type RolloverMode int
+
+const (
+	RolloverModeOff   RolloverMode = iota // 不顺延（用户设置的默认值）
+	RolloverModeMove                      // 把任务移动到下一个时间段
+	RolloverModeClone                     // 在下一个时间段复制一份，并关联原任务
+)
+
+// 顺延时跳过任务的原因
+const (
+	RolloverSkipAlreadyCarried = "already_carried_over"   // 复制模式下已经顺延过
+	RolloverSkipParentPeriod   = "parent_period_mismatch" // 下一个时间段不在父任务的时间范围内
+	RolloverSkipChildPeriod    = "child_period_mismatch"  // 移动模式下有不随之顺延的子任务，移动后会超出任务的时间范围
+)
+
+// 顺延未完成任务参数
+type RolloverTasksParam struct {
+	UserID string
+	Type   PeriodType
+	Period Period // 需要顺延的时间段，必须与 Type 匹配
+	Mode   RolloverMode
+}
+
+// RolloverSkip 没有被顺延的任务
+type RolloverSkip struct {
+	TaskID string `json:"task_id"`
+	Reason string `json:"reason"`
+}
+
+// RolloverResult 顺延结果
+type RolloverResult struct {
+	Mode       RolloverMode   `json:"mode"`
+	FromPeriod Period         `json:"from_period"`
+	ToPeriod   Period         `json:"to_period"`
+	Tasks      []*Task        `json:"tasks"` // 移动后的任务，或复制出的新任务
+	Skipped    []RolloverSkip `json:"skipped"`
+}
+
+// isRolloverCandidate 只有未开始和进行中的任务需要顺延
+func isRolloverCandidate(task *Task) bool {
+	return task.Status == TaskStatusNotStarted || task.Status == TaskStatusInProgress
+}
+
+// previousPeriod 参考时间所在时间段的上一个时间段
+func previousPeriod(pt PeriodType, reference time.Time) Period {
+	current := NewPeriodFromPeriodType(pt, reference)
+	return NewPeriodFromPeriodType(pt, current.Start.Add(-time.Nanosecond))
+}
+
+// 把时间段内未完成的指定类型任务顺延到下一个时间段
+// 父任务同时被顺延时，子任务挂到顺延后的父任务下；否则父任务必须能容纳下一个时间段，不能容纳的任务被跳过
+// 移动模式下任务的子任务（已完成、已取消或更小类型的任务）不随之移动，移动后无法容纳这些子任务的任务被跳过
+// 复制模式下已经有顺延副本的任务也被跳过，因此重复执行是安全的
+func (uc *TaskUsecase) RolloverTasks(ctx context.Context, param RolloverTasksParam) (*RolloverResult, error) {
+	if param.UserID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	if param.Mode != RolloverModeMove && param.Mode != RolloverModeClone {
+		return nil, ErrRolloverModeInvalid
+	}
+	if !param.Period.MatchesPeriodType(param.Type) {
+		return nil, ErrInvalidPeriod
+	}
+
+	next := NewPeriodFromPeriodType(param.Type, param.Period.End)
+	result := &RolloverResult{
+		Mode:       param.Mode,
+		FromPeriod: param.Period,
+		ToPeriod:   next,
+		Tasks:      make([]*Task, 0),
+		Skipped:    make([]RolloverSkip, 0),
+	}
+
+	err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		tasks, err := uc.repo.ListTasks(ctx, param.UserID, param.Period.Start, param.Period.End, int(param.Type), PeriodMatchContained)
+		if err != nil {
+			return err
+		}
+		candidates := make([]*Task, 0, len(tasks))
+		for _, task := range tasks {
+			if isRolloverCandidate(task) {
+				candidates = append(candidates, task)
+			}
+		}
+		// 父任务先于子任务处理
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].TreeDepth != candidates[j].TreeDepth {
+				return candidates[i].TreeDepth < candidates[j].TreeDepth
+			}
+			return candidates[i].ID < candidates[j].ID
+		})
+
+		carried := make(map[string]bool)
+		blocked := make(map[string]bool)
+		if param.Mode == RolloverModeMove {
+			if blocked, err = uc.rolloverBlockedByChildren(ctx, candidates, next); err != nil {
+				return err
+			}
+		}
+		if param.Mode == RolloverModeClone {
+			existing, err := uc.repo.ListTasks(ctx, param.UserID, next.Start, next.End, int(param.Type), PeriodMatchContained)
+			if err != nil {
+				return err
+			}
+			for _, task := range existing {
+				if task.CarriedOverFromID != "" {
+					carried[task.CarriedOverFromID] = true
+				}
+			}
+		}
+
+		// 原任务ID -> 顺延后的任务
+		rolled := make(map[string]*Task)
+		for _, task := range candidates {
+			if carried[task.ID] {
+				result.Skipped = append(result.Skipped, RolloverSkip{TaskID: task.ID, Reason: RolloverSkipAlreadyCarried})
+				continue
+			}
+			if blocked[task.ID] {
+				result.Skipped = append(result.Skipped, RolloverSkip{TaskID: task.ID, Reason: RolloverSkipChildPeriod})
+				continue
+			}
+
+			parentID, err := uc.rolloverParentID(ctx, task, next, rolled)
+			if err != nil {
+				return err
+			}
+			if parentID == nil {
+				result.Skipped = append(result.Skipped, RolloverSkip{TaskID: task.ID, Reason: RolloverSkipParentPeriod})
+				continue
+			}
+
+			var target *Task
+			if param.Mode == RolloverModeMove {
+				target, err = uc.moveTaskToPeriod(ctx, task, next)
+			} else {
+				target, err = uc.cloneTaskToPeriod(ctx, task, next, *parentID)
+			}
+			if err != nil {
+				return err
+			}
+			rolled[task.ID] = target
+			result.Tasks = append(result.Tasks, target)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// rolloverParentID 顺延后任务的父任务ID，返回 nil 表示父任务无法容纳下一个时间段
+func (uc *TaskUsecase) rolloverParentID(ctx context.Context, task *Task, next Period, rolled map[string]*Task) (*string, error) {
+	parentID := task.ParentID
+	if parentID == "" {
+		return &parentID, nil
+	}
+
+	parent, ok := rolled[parentID]
+	if ok {
+		parentID = parent.ID
+	} else {
+		var err error
+		parent, err = uc.repo.GetTask(ctx, parentID, task.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return &parentID, nil
+		}
+	}
+	if err := validateSubTaskPlacement(parent, task.TaskType, next); err != nil {
+		return nil, nil
+	}
+	return &parentID, nil
+}
+
+// rolloverBlockedByChildren 移动模式下因子任务无法移动的候选任务
+// 同类型的未完成子任务会随父任务一起顺延，其他子任务留在原时间段，必须仍在移动后的任务的时间范围内
+// 从最深的任务开始判断：子任务被跳过时，它也留在原时间段
+func (uc *TaskUsecase) rolloverBlockedByChildren(ctx context.Context, candidates []*Task, next Period) (map[string]bool, error) {
+	isCandidate := make(map[string]bool, len(candidates))
+	for _, task := range candidates {
+		isCandidate[task.ID] = true
+	}
+	blocked := make(map[string]bool)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		task := candidates[i]
+		children, err := uc.repo.ListChildTasks(ctx, task.ID, task.UserID)
+		if err != nil {
+			return nil, err
+		}
+		staying := make([]*Task, 0, len(children))
+		for _, child := range children {
+			if !isCandidate[child.ID] || blocked[child.ID] {
+				staying = append(staying, child)
+			}
+		}
+		moved := *task
+		moved.TimePeriod = next
+		if len(childPlacementConflicts(&moved, staying)) > 0 {
+			blocked[task.ID] = true
+		}
+	}
+	return blocked, nil
+}
+
+// moveTaskToPeriod 把任务移动到下一个时间段，需要在事务中调用
+func (uc *TaskUsecase) moveTaskToPeriod(ctx context.Context, task *Task, next Period) (*Task, error) {
+	before := *task
+	task.TimePeriod = next
+	task.CarryOverCount++
+	task.UpdatedAt = time.Now()
+	if err := uc.repo.UpdateTask(ctx, task); err != nil {
+		return nil, err
+	}
+	if err := uc.recordTaskChange(ctx, ChangeActionUpdate, &before, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// cloneTaskToPeriod 在下一个时间段复制任务（包括清单），需要在事务中调用
+func (uc *TaskUsecase) cloneTaskToPeriod(ctx context.Context, task *Task, next Period, parentID string) (*Task, error) {
+	items, err := uc.repo.ListChecklistItems(ctx, task.ID, task.UserID)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([]ChecklistItemInput, len(items))
+	for i, item := range items {
+		inputs[i] = ChecklistItemInput{Text: item.Text, Done: item.Done}
+	}
+
+	now := time.Now()
+	clone := &Task{
+		ID:          generateID(),
+		Title:       task.Title,
+		Description: task.Description,
+		TaskType:    task.TaskType,
+		TimePeriod:  next,
+		Tags:        task.Tags,
+		Icon:        task.Icon,
+		Score:       task.Score,
+		Status:      task.Status,
+		StartedAt:   task.StartedAt,
+		Priority:    task.Priority,
+		ScoreRollup: task.ScoreRollup,
+		Weight:      task.Weight,
+		Estimate:    task.Estimate,
+
+		RollupCompletedOnly: task.RollupCompletedOnly,
+		ParentID:            parentID,
+		UserID:              task.UserID,
+		CarriedOverFromID:   task.ID,
+		CarryOverCount:      task.CarryOverCount + 1,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	if task.KeyResult != nil {
+		kr := task.KeyResult
+		clone.KeyResult = NewKeyResult(kr.StartValue, kr.TargetValue, kr.CurrentValue, kr.Unit)
+	}
+	if err := uc.createTaskWithChecklist(ctx, clone, inputs); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.UpdateTreeOptimizationFields(ctx, clone.ID, clone.UserID); err != nil {
+		return nil, err
+	}
+	if clone.ParentID != "" {
+		if err := uc.repo.UpdateTreeOptimizationFields(ctx, clone.ParentID, clone.UserID); err != nil {
+			return nil, err
+		}
+	}
+	return clone, nil
+}
+
+// RunAutoRollover 为开启了自动顺延的用户顺延上一天和上一周的未完成任务，返回顺延的任务数
+// 单个用户失败时记录日志并继续处理其他用户
+func (uc *TaskUsecase) RunAutoRollover(ctx context.Context, now time.Time) (int, error) {
+	if uc.settingsRepo == nil {
+		return 0, nil
+	}
+	settings, err := uc.settingsRepo.ListAutoRolloverSettings(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = ContextWithActor(ctx, SystemActorRollover)
+	total := 0
+	for _, setting := range settings {
+		for _, pt := range []PeriodType{PeriodDay, PeriodWeek} {
+			result, err := uc.RolloverTasks(ctx, RolloverTasksParam{
+				UserID: setting.UserID,
+				Type:   pt,
+				Period: previousPeriod(pt, now),
+				Mode:   setting.AutoRollover,
+			})
+			if err != nil {
+				log.Errorf("Failed to roll over tasks for user %s: %v", setting.UserID, err)
+				continue
+			}
+			total += len(result.Tasks)
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,69 @@
+package biz
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// TaskRolloverJob 后台定时为开启了自动顺延的用户顺延上一天、上一周的未完成任务
+// 顺延是幂等的，执行间隔可以短于一天，跨过零点后的第一次执行完成当天的顺延
+type TaskRolloverJob struct {
+	usecase  *TaskUsecase
+	interval time.Duration
+
+	// 顺延协程控制
+	stopRollover chan bool
+	rolloverDone chan bool
+}
+
+// NewTaskRolloverJob 创建自动顺延任务
+func NewTaskRolloverJob(usecase *TaskUsecase, interval time.Duration) *TaskRolloverJob {
+	return &TaskRolloverJob{
+		usecase:      usecase,
+		interval:     interval,
+		stopRollover: make(chan bool),
+		rolloverDone: make(chan bool),
+	}
+}
+
+// Start 启动顺延协程，启动时立即执行一次
+func (j *TaskRolloverJob) Start() {
+	go j.rolloverWorker()
+}
+
+// Stop 停止顺延协程，等待当前这一轮执行完成
+func (j *TaskRolloverJob) Stop() {
+	j.stopRollover <- true
+	<-j.rolloverDone
+}
+
+// rolloverWorker 顺延工作协程
+func (j *TaskRolloverJob) rolloverWorker() {
+	j.runOnce()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.runOnce()
+		case <-j.stopRollover:
+			j.rolloverDone <- true
+			return
+		}
+	}
+}
+
+func (j *TaskRolloverJob) runOnce() {
+	rolled, err := j.usecase.RunAutoRollover(context.Background(), time.Now())
+	if err != nil {
+		log.Errorf("Failed to roll over unfinished tasks: %v", err)
+		return
+	}
+	if rolled > 0 {
+		log.Infof("Rolled over %d unfinished tasks", rolled)
+	}
+}
@@ -0,0 +1,163 @@
+package biz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rolledTaskIDs(result *RolloverResult) []string {
+	ids := make([]string, len(result.Tasks))
+	for i, task := range result.Tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func TestTaskUsecase_RolloverTasks(t *testing.T) {
+	ctx := context.Background()
+	day := NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 6))
+	nextDay := NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 7))
+	// 2025-01-06（周一）所在的周任务下四个不同状态的日任务
+	setup := func() (*TaskUsecase, *memoryTaskRepo) {
+		week := newTestTask("week", PeriodWeek, date(2025, 1, 6), TaskStatusInProgress, nil)
+		repo := newMemoryTaskRepo(
+			week,
+			newTestTask("todo", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, week),
+			newTestTask("doing", PeriodDay, date(2025, 1, 6), TaskStatusInProgress, week),
+			newTestTask("done", PeriodDay, date(2025, 1, 6), TaskStatusCompleted, week),
+			newTestTask("cancelled", PeriodDay, date(2025, 1, 6), TaskStatusCancelled, week),
+		)
+		return NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo()), repo
+	}
+
+	t.Run("移动未完成的任务", func(t *testing.T) {
+		usecase, repo := setup()
+
+		result, err := usecase.RolloverTasks(ctx, RolloverTasksParam{UserID: "user-123", Type: PeriodDay, Period: day, Mode: RolloverModeMove})
+
+		require.NoError(t, err)
+		assert.Equal(t, nextDay, result.ToPeriod)
+		assert.ElementsMatch(t, []string{"todo", "doing"}, rolledTaskIDs(result))
+		assert.Equal(t, nextDay, repo.tasks["todo"].TimePeriod)
+		assert.Equal(t, 1, repo.tasks["todo"].CarryOverCount)
+		assert.Equal(t, day, repo.tasks["done"].TimePeriod, "completed tasks stay")
+		assert.Equal(t, day, repo.tasks["cancelled"].TimePeriod)
+	})
+
+	t.Run("复制未完成的任务并关联原任务", func(t *testing.T) {
+		usecase, repo := setup()
+		repo.checklists["todo"] = []*ChecklistItem{{ID: "item", TaskID: "todo", UserID: "user-123", Text: "步骤一", Done: true}}
+
+		result, err := usecase.RolloverTasks(ctx, RolloverTasksParam{UserID: "user-123", Type: PeriodDay, Period: day, Mode: RolloverModeClone})
+
+		require.NoError(t, err)
+		require.Len(t, result.Tasks, 2)
+		for _, clone := range result.Tasks {
+			stored := repo.tasks[clone.ID]
+			assert.Equal(t, nextDay, stored.TimePeriod)
+			assert.Equal(t, "week", stored.ParentID)
+			assert.Equal(t, 1, stored.CarryOverCount)
+			assert.Equal(t, day, repo.tasks[stored.CarriedOverFromID].TimePeriod, "original stays in place")
+			if stored.CarriedOverFromID == "todo" {
+				require.Len(t, repo.checklists[clone.ID], 1)
+				assert.Equal(t, "步骤一", repo.checklists[clone.ID][0].Text)
+			}
+		}
+		assert.Equal(t, 6, repo.tasks["week"].ChildrenCount, "clones are counted under the parent")
+
+		again, err := usecase.RolloverTasks(ctx, RolloverTasksParam{UserID: "user-123", Type: PeriodDay, Period: day, Mode: RolloverModeClone})
+		require.NoError(t, err)
+		assert.Empty(t, again.Tasks, "running twice does not clone again")
+		assert.Len(t, again.Skipped, 2)
+	})
+
+	t.Run("父任务无法容纳下一个时间段时跳过", func(t *testing.T) {
+		usecase, repo := setup()
+		// 子任务的开始时间允许落在父任务的结束边界上，再往后顺延就超出了父任务
+		boundary := NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 13))
+		repo.tasks["todo"].TimePeriod = boundary
+
+		result, err := usecase.RolloverTasks(ctx, RolloverTasksParam{UserID: "user-123", Type: PeriodDay, Period: boundary, Mode: RolloverModeMove})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Tasks)
+		require.Len(t, result.Skipped, 1)
+		assert.Equal(t, RolloverSkip{TaskID: "todo", Reason: RolloverSkipParentPeriod}, result.Skipped[0])
+		assert.Equal(t, boundary, repo.tasks["todo"].TimePeriod)
+	})
+
+	t.Run("移动周任务时留在原周的日任务阻止移动", func(t *testing.T) {
+		usecase, repo := setup()
+		// plan 的同类型子任务 sub 一起顺延；outer -> inner 下有日任务，两者都不能移动
+		plan := newTestTask("plan", PeriodWeek, date(2025, 1, 6), TaskStatusNotStarted, nil)
+		outer := newTestTask("outer", PeriodWeek, date(2025, 1, 6), TaskStatusNotStarted, nil)
+		inner := newTestTask("inner", PeriodWeek, date(2025, 1, 6), TaskStatusNotStarted, outer)
+		for _, task := range []*Task{
+			plan,
+			newTestTask("sub", PeriodWeek, date(2025, 1, 6), TaskStatusInProgress, plan),
+			outer,
+			inner,
+			newTestTask("inner-day", PeriodDay, date(2025, 1, 8), TaskStatusCompleted, inner),
+		} {
+			repo.tasks[task.ID] = task
+		}
+		week := NewPeriodFromPeriodType(PeriodWeek, date(2025, 1, 6))
+		nextWeek := NewPeriodFromPeriodType(PeriodWeek, date(2025, 1, 13))
+
+		result, err := usecase.RolloverTasks(ctx, RolloverTasksParam{UserID: "user-123", Type: PeriodWeek, Period: week, Mode: RolloverModeMove})
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"plan", "sub"}, rolledTaskIDs(result))
+		assert.ElementsMatch(t, []RolloverSkip{
+			{TaskID: "week", Reason: RolloverSkipChildPeriod},
+			{TaskID: "outer", Reason: RolloverSkipChildPeriod},
+			{TaskID: "inner", Reason: RolloverSkipChildPeriod},
+		}, result.Skipped)
+		assert.Equal(t, week, repo.tasks["week"].TimePeriod)
+		assert.Equal(t, week, repo.tasks["outer"].TimePeriod)
+		assert.Equal(t, nextWeek, repo.tasks["sub"].TimePeriod)
+		assert.Equal(t, "plan", repo.tasks["sub"].ParentID)
+		for _, task := range repo.tasks {
+			if task.ParentID != "" {
+				assert.NoError(t, validateSubTaskPlacement(repo.tasks[task.ParentID], task.TaskType, task.TimePeriod), task.ID)
+			}
+		}
+	})
+
+	t.Run("参数不合法", func(t *testing.T) {
+		usecase, _ := setup()
+
+		_, err := usecase.RolloverTasks(ctx, RolloverTasksParam{UserID: "user-123", Type: PeriodDay, Period: day, Mode: RolloverModeOff})
+		assert.Equal(t, ErrRolloverModeInvalid, err)
+
+		_, err = usecase.RolloverTasks(ctx, RolloverTasksParam{UserID: "user-123", Type: PeriodWeek, Period: day, Mode: RolloverModeMove})
+		assert.Equal(t, ErrInvalidPeriod, err)
+	})
+}
+
+func TestTaskUsecase_RunAutoRollover(t *testing.T) {
+	ctx := context.Background()
+	week := newTestTask("week", PeriodWeek, date(2025, 1, 6), TaskStatusInProgress, nil)
+	repo := newMemoryTaskRepo(
+		week,
+		newTestTask("todo", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, week),
+		newTestTask("doing", PeriodDay, date(2025, 1, 6), TaskStatusInProgress, week),
+		newTestTask("done", PeriodDay, date(2025, 1, 6), TaskStatusCompleted, week),
+	)
+	settingsRepo := newMockUserSettingsRepo()
+	settingsRepo.settings["user-123"] = &UserSettings{UserID: "user-123", AutoRollover: RolloverModeMove}
+	historyRepo := newMockChangeHistoryRepo()
+	usecase := NewTaskUsecase(repo, settingsRepo, historyRepo)
+
+	rolled, err := usecase.RunAutoRollover(ctx, date(2025, 1, 7).Add(time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, rolled)
+	assert.Equal(t, NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 7)), repo.tasks["doing"].TimePeriod)
+	require.NotEmpty(t, historyRepo.records)
+	assert.Equal(t, SystemActorRollover, historyRepo.records[0].ActorID)
+}
@@ -0,0 +1,141 @@
+package biz
+
+import (
+	"context"
+	"math"
+)
+
+// ScoreRollupMode 非叶子任务由子任务汇总分数的方式
+type ScoreRollupMode int
+
+const (
+	ScoreRollupSum      ScoreRollupMode = iota // 子任务汇总分数之和（默认）
+	ScoreRollupAverage                         // 子任务汇总分数的平均值
+	ScoreRollupWeighted                        // 按子任务权重的加权平均
+)
+
+// IsValid 检查汇总方式是否合法
+func (m ScoreRollupMode) IsValid() bool {
+	return m >= ScoreRollupSum && m <= ScoreRollupWeighted
+}
+
+// isValidTaskWeight 权重必须是非负的有限数
+func isValidTaskWeight(weight float64) bool {
+	return weight >= 0 && !math.IsInf(weight, 0) && !math.IsNaN(weight)
+}
+
+// 新建任务的默认权重
+const DefaultTaskWeight = 1.0
+
+// 汇总结果保留的小数位数
+const rollupPrecision = 1e4
+
+// TaskRollup 任务的汇总分数和进度
+type TaskRollup struct {
+	Score    float64 `json:"rollup_score"`
+	Progress float64 `json:"progress"` // 0-100
+}
+
+func roundRollup(value float64) float64 {
+	return math.Round(value*rollupPrecision) / rollupPrecision
+}
+
+// computeTaskRollup 根据直接子任务计算任务的汇总分数和进度，子任务的汇总结果需要已经是最新的
+// 已取消的子任务不参与计算；没有参与计算的子任务时按叶子任务处理：汇总分数为自身分数，已完成为 100，否则为 0
+// 只统计已完成的子任务时，未完成子任务的分数按 0 计入，但仍参与平均值的分母，使汇总分数反映实际完成情况
+// 进度为子任务进度的平均值（加权平均模式下按权重），任务自身已完成时进度为 100
+// 关键结果的进度总是取自身的达成率（截断到 0-100），与子任务和完成状态无关
+func computeTaskRollup(task *Task, children []*Task) TaskRollup {
+	var scoreSum, weightedScore, progressSum, weightedProgress, weightSum float64
+	count := 0
+	for _, child := range children {
+		if child.Status == TaskStatusCancelled {
+			continue
+		}
+		count++
+		score := child.RollupScore
+		if task.RollupCompletedOnly && child.Status != TaskStatusCompleted {
+			score = 0
+		}
+		scoreSum += score
+		weightedScore += score * child.Weight
+		progressSum += child.Progress
+		weightedProgress += child.Progress * child.Weight
+		weightSum += child.Weight
+	}
+
+	var rollup TaskRollup
+	if count == 0 {
+		rollup.Score = float64(task.Score)
+	} else {
+		switch task.ScoreRollup {
+		case ScoreRollupAverage:
+			rollup.Score = scoreSum / float64(count)
+			rollup.Progress = progressSum / float64(count)
+		case ScoreRollupWeighted:
+			if weightSum > 0 {
+				rollup.Score = weightedScore / weightSum
+				rollup.Progress = weightedProgress / weightSum
+			}
+		default:
+			rollup.Score = scoreSum
+			rollup.Progress = progressSum / float64(count)
+		}
+	}
+	if task.Status == TaskStatusCompleted {
+		rollup.Progress = 100
+	}
+	if task.KeyResult != nil {
+		rollup.Progress = task.KeyResult.Progress()
+	}
+	rollup.Score = roundRollup(rollup.Score)
+	rollup.Progress = roundRollup(rollup.Progress)
+	return rollup
+}
+
+// updateTaskRollup 重算单个任务的汇总结果，有变化时保存并同步到 task，需要在事务中调用
+func (uc *TaskUsecase) updateTaskRollup(ctx context.Context, task *Task) error {
+	children, err := uc.repo.ListChildTasks(ctx, task.ID, task.UserID)
+	if err != nil {
+		return err
+	}
+	rollup := computeTaskRollup(task, children)
+	if rollup.Score == task.RollupScore && rollup.Progress == task.Progress {
+		return nil
+	}
+	if err := uc.repo.UpdateTaskRollup(ctx, task.ID, task.UserID, rollup); err != nil {
+		return err
+	}
+	task.RollupScore = rollup.Score
+	task.Progress = rollup.Progress
+	return nil
+}
+
+// refreshRollup 任务的分数、状态、权重、汇总方式或子任务变化后，重算它自身以及所有祖先的汇总结果
+// 需要在事务中调用；祖先的状态可能刚被完成状态传播修改，因此不提前结束
+func (uc *TaskUsecase) refreshRollup(ctx context.Context, task *Task) error {
+	if err := uc.updateTaskRollup(ctx, task); err != nil {
+		return err
+	}
+	return uc.refreshRollupFrom(ctx, task.ParentID, task.UserID)
+}
+
+// refreshRollupFrom 从 taskID 开始沿父任务链向上重算汇总结果，taskID 为空或任务不存在时忽略
+func (uc *TaskUsecase) refreshRollupFrom(ctx context.Context, taskID, userID string) error {
+	visited := make(map[string]bool)
+	for taskID != "" && !visited[taskID] {
+		visited[taskID] = true
+		task, err := uc.repo.GetTask(ctx, taskID, userID)
+		if err != nil {
+			return err
+		}
+		if task == nil {
+			return nil
+		}
+		if err := uc.updateTaskRollup(ctx, task); err != nil {
+			return err
+		}
+		taskID = task.ParentID
+	}
+	return nil
+}
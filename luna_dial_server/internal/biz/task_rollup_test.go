@@ -0,0 +1,119 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rollupChild(score float64, progress float64, weight float64, status TaskStatus) *Task {
+	return &Task{RollupScore: score, Progress: progress, Weight: weight, Status: status}
+}
+
+func TestComputeTaskRollup(t *testing.T) {
+	children := []*Task{
+		rollupChild(10, 100, 1, TaskStatusCompleted),
+		rollupChild(20, 50, 3, TaskStatusInProgress),
+		rollupChild(99, 0, 5, TaskStatusCancelled), // 已取消，不参与计算
+	}
+
+	t.Run("叶子任务使用自身分数", func(t *testing.T) {
+		rollup := computeTaskRollup(&Task{Score: 7, Status: TaskStatusCompleted}, nil)
+		assert.Equal(t, TaskRollup{Score: 7, Progress: 100}, rollup)
+
+		rollup = computeTaskRollup(&Task{Score: 7}, []*Task{rollupChild(3, 0, 1, TaskStatusCancelled)})
+		assert.Equal(t, TaskRollup{Score: 7, Progress: 0}, rollup)
+	})
+
+	t.Run("求和", func(t *testing.T) {
+		rollup := computeTaskRollup(&Task{Score: 1}, children)
+		assert.Equal(t, TaskRollup{Score: 30, Progress: 75}, rollup)
+	})
+
+	t.Run("平均值", func(t *testing.T) {
+		rollup := computeTaskRollup(&Task{ScoreRollup: ScoreRollupAverage}, children)
+		assert.Equal(t, TaskRollup{Score: 15, Progress: 75}, rollup)
+	})
+
+	t.Run("加权平均", func(t *testing.T) {
+		rollup := computeTaskRollup(&Task{ScoreRollup: ScoreRollupWeighted}, children)
+		assert.Equal(t, TaskRollup{Score: 17.5, Progress: 62.5}, rollup)
+
+		// 权重全部为 0 时结果为 0
+		rollup = computeTaskRollup(&Task{ScoreRollup: ScoreRollupWeighted}, []*Task{rollupChild(10, 100, 0, TaskStatusCompleted)})
+		assert.Equal(t, TaskRollup{Score: 0, Progress: 0}, rollup)
+	})
+
+	t.Run("只统计已完成的子任务", func(t *testing.T) {
+		rollup := computeTaskRollup(&Task{ScoreRollup: ScoreRollupAverage, RollupCompletedOnly: true}, children)
+		assert.Equal(t, TaskRollup{Score: 5, Progress: 75}, rollup)
+	})
+
+	t.Run("已完成的父任务进度为 100", func(t *testing.T) {
+		rollup := computeTaskRollup(&Task{Status: TaskStatusCompleted}, children)
+		assert.Equal(t, 100.0, rollup.Progress)
+	})
+}
+
+func TestTaskUsecase_ScoreRollup(t *testing.T) {
+	ctx := context.Background()
+	period := Period{Start: date(2025, 1, 1), End: date(2025, 2, 1)}
+
+	t.Run("子任务变化时重算父任务链", func(t *testing.T) {
+		repo := newMemoryTaskRepo()
+		uc := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+		root, err := uc.CreateTask(ctx, CreateTaskParam{UserID: "u1", Title: "root", Type: PeriodMonth, Period: period, Score: 1})
+		require.NoError(t, err)
+		assert.Equal(t, DefaultTaskWeight, root.Weight)
+		assert.Equal(t, 1.0, repo.tasks[root.ID].RollupScore)
+
+		child, err := uc.CreateSubTask(ctx, CreateSubTaskParam{UserID: "u1", ParentID: root.ID, Title: "a", Type: PeriodMonth, Period: period, Score: 4})
+		require.NoError(t, err)
+		grandchild, err := uc.CreateSubTask(ctx, CreateSubTaskParam{UserID: "u1", ParentID: child.ID, Title: "a1", Type: PeriodMonth, Period: period, Score: 6})
+		require.NoError(t, err)
+		_, err = uc.CreateSubTask(ctx, CreateSubTaskParam{UserID: "u1", ParentID: root.ID, Title: "b", Type: PeriodMonth, Period: period, Score: 2})
+		require.NoError(t, err)
+		assert.Equal(t, 6.0, repo.tasks[child.ID].RollupScore)
+		assert.Equal(t, 8.0, repo.tasks[root.ID].RollupScore)
+
+		completed := TaskStatusCompleted
+		_, err = uc.UpdateTask(ctx, UpdateTaskParam{TaskID: grandchild.ID, UserID: "u1", Status: &completed})
+		require.NoError(t, err)
+		assert.Equal(t, 100.0, repo.tasks[child.ID].Progress)
+		assert.Equal(t, 50.0, repo.tasks[root.ID].Progress)
+
+		_, err = uc.SetTaskScore(ctx, SetTaskScoreParam{TaskID: grandchild.ID, UserID: "u1", Score: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 12.0, repo.tasks[root.ID].RollupScore)
+
+		// 改为加权平均：a 的权重为 3
+		weighted := ScoreRollupWeighted
+		weight := 3.0
+		_, err = uc.UpdateTask(ctx, UpdateTaskParam{TaskID: child.ID, UserID: "u1", Weight: &weight})
+		require.NoError(t, err)
+		updated, err := uc.UpdateTask(ctx, UpdateTaskParam{TaskID: root.ID, UserID: "u1", ScoreRollup: &weighted})
+		require.NoError(t, err)
+		assert.Equal(t, 8.0, updated.RollupScore)
+		assert.Equal(t, 75.0, updated.Progress)
+
+		require.NoError(t, uc.DeleteTask(ctx, DeleteTaskParam{TaskID: child.ID, UserID: "u1", Mode: TaskDeleteModeCascade}))
+		assert.Equal(t, 2.0, repo.tasks[root.ID].RollupScore)
+		assert.Equal(t, 0.0, repo.tasks[root.ID].Progress)
+	})
+
+	t.Run("拒绝不合法的汇总设置", func(t *testing.T) {
+		repo := newMemoryTaskRepo(&Task{ID: "t1", UserID: "u1", Title: "t", TaskType: PeriodMonth, TimePeriod: period})
+		uc := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+		weight := -1.0
+		_, err := uc.UpdateTask(ctx, UpdateTaskParam{TaskID: "t1", UserID: "u1", Weight: &weight})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+
+		mode := ScoreRollupMode(9)
+		_, err = uc.UpdateTask(ctx, UpdateTaskParam{TaskID: "t1", UserID: "u1", ScoreRollup: &mode})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
@@ -0,0 +1,107 @@
+package biz
+
+import (
+	"fmt"
+	"time"
+)
+
+// 取消原因的最大长度（字符）
+const maxCancelReasonLength = 500
+
+// taskStatusTransitions 允许的状态变化：未开始 → 进行中 → 已完成，任何未结束的状态都可以取消，
+// 已完成或已取消的任务可以重新打开（回到未开始或进行中）；未开始的任务也可以直接完成
+// 状态不变不算变化，总是允许
+var taskStatusTransitions = map[TaskStatus][]TaskStatus{
+	TaskStatusNotStarted: {TaskStatusInProgress, TaskStatusCompleted, TaskStatusCancelled},
+	TaskStatusInProgress: {TaskStatusNotStarted, TaskStatusCompleted, TaskStatusCancelled},
+	TaskStatusCompleted:  {TaskStatusNotStarted, TaskStatusInProgress},
+	TaskStatusCancelled:  {TaskStatusNotStarted, TaskStatusInProgress},
+}
+
+// String 状态名称，与接口中使用的字符串一致
+func (s TaskStatus) String() string {
+	switch s {
+	case TaskStatusNotStarted:
+		return "not_started"
+	case TaskStatusInProgress:
+		return "in_progress"
+	case TaskStatusCompleted:
+		return "completed"
+	case TaskStatusCancelled:
+		return "cancelled"
+	default:
+		return fmt.Sprintf("status(%d)", int(s))
+	}
+}
+
+// CanTransitionTo 判断任务能否从当前状态变为 to
+func (s TaskStatus) CanTransitionTo(to TaskStatus) bool {
+	if s == to {
+		return true
+	}
+	for _, allowed := range taskStatusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskStatusTransitionError 不允许的状态变化，可以用 errors.Is(err, ErrTaskStatusTransition) 判断
+type TaskStatusTransitionError struct {
+	From TaskStatus
+	To   TaskStatus
+}
+
+func (e *TaskStatusTransitionError) Error() string {
+	return fmt.Sprintf("%s: %s -> %s", ErrTaskStatusTransition.Error(), e.From, e.To)
+}
+
+func (e *TaskStatusTransitionError) Unwrap() error {
+	return ErrTaskStatusTransition
+}
+
+// transitionTo 校验状态变化后修改状态和对应的时间
+func (t *Task) transitionTo(status TaskStatus, now time.Time) error {
+	if !t.Status.CanTransitionTo(status) {
+		return &TaskStatusTransitionError{From: t.Status, To: status}
+	}
+	t.applyStatus(status, now)
+	return nil
+}
+
+// applyStatus 修改状态并维护状态时间，不做校验（完成状态传播等内部规则已保证变化合法）
+//   - StartedAt 为第一次开始的时间，回到未开始时清空
+//   - CompletedAt 为最近一次完成的时间，离开已完成时清空
+//   - CancelledAt 和 CancelReason 只在已取消状态下保留
+func (t *Task) applyStatus(status TaskStatus, now time.Time) {
+	if status == t.Status {
+		return
+	}
+	t.Status = status
+	switch status {
+	case TaskStatusNotStarted:
+		t.StartedAt = nil
+	case TaskStatusInProgress:
+		if t.StartedAt == nil {
+			t.StartedAt = &now
+		}
+	case TaskStatusCompleted:
+		t.CompletedAt = &now
+		t.ReadyToComplete = false // 已完成，不再需要"可完成"提示
+	case TaskStatusCancelled:
+		t.CancelledAt = &now
+	}
+	if status != TaskStatusCompleted {
+		t.CompletedAt = nil
+	}
+	if status != TaskStatusCancelled {
+		t.CancelledAt = nil
+		t.CancelReason = ""
+	}
+}
+
+// CompletedLate 任务在时间段结束之后才完成
+func (t *Task) CompletedLate() bool {
+	return t.Status == TaskStatusCompleted && t.CompletedAt != nil && !t.CompletedAt.Before(t.TimePeriod.End)
+}
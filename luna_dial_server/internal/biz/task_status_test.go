@@ -0,0 +1,120 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to TaskStatus
+		allowed  bool
+	}{
+		{TaskStatusNotStarted, TaskStatusInProgress, true},
+		{TaskStatusNotStarted, TaskStatusCompleted, true},
+		{TaskStatusNotStarted, TaskStatusCancelled, true},
+		{TaskStatusInProgress, TaskStatusCompleted, true},
+		{TaskStatusInProgress, TaskStatusNotStarted, true},
+		{TaskStatusCompleted, TaskStatusInProgress, true}, // 重新打开
+		{TaskStatusCancelled, TaskStatusNotStarted, true}, // 重新打开
+		{TaskStatusCompleted, TaskStatusCompleted, true},
+		{TaskStatusCompleted, TaskStatusCancelled, false},
+		{TaskStatusCancelled, TaskStatusCompleted, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, tt.from.CanTransitionTo(tt.to), "%s -> %s", tt.from, tt.to)
+	}
+}
+
+func TestTask_ApplyStatus(t *testing.T) {
+	t1 := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	task := &Task{TimePeriod: Period{Start: date(2025, 1, 1), End: date(2025, 1, 2)}}
+
+	task.applyStatus(TaskStatusInProgress, t1)
+	require.NotNil(t, task.StartedAt)
+	assert.Equal(t, t1, *task.StartedAt)
+
+	task.applyStatus(TaskStatusCompleted, t2)
+	assert.Equal(t, t1, *task.StartedAt)
+	assert.Equal(t, t2, *task.CompletedAt)
+	assert.False(t, task.CompletedLate())
+
+	// 重新打开后再次开始，保留第一次开始的时间
+	task.applyStatus(TaskStatusInProgress, t2)
+	assert.Nil(t, task.CompletedAt)
+	assert.Equal(t, t1, *task.StartedAt)
+
+	task.applyStatus(TaskStatusCancelled, t2)
+	task.CancelReason = "不再需要"
+	assert.Equal(t, t2, *task.CancelledAt)
+
+	task.applyStatus(TaskStatusNotStarted, t2)
+	assert.Nil(t, task.StartedAt)
+	assert.Nil(t, task.CancelledAt)
+	assert.Empty(t, task.CancelReason)
+
+	// 时间段结束之后才完成
+	task.applyStatus(TaskStatusCompleted, date(2025, 1, 3))
+	assert.True(t, task.CompletedLate())
+}
+
+func TestTaskUsecase_UpdateTaskStatus(t *testing.T) {
+	ctx := context.Background()
+	period := Period{Start: date(2025, 1, 1), End: date(2025, 1, 2)}
+	newUsecase := func(tasks ...*Task) (*TaskUsecase, *memoryTaskRepo) {
+		repo := newMemoryTaskRepo(tasks...)
+		return NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo()), repo
+	}
+	status := func(s TaskStatus) *TaskStatus { return &s }
+	reason := func(s string) *string { return &s }
+
+	t.Run("不允许的状态变化返回类型化错误", func(t *testing.T) {
+		uc, repo := newUsecase(&Task{ID: "t1", UserID: "u1", Title: "t", TaskType: PeriodDay, TimePeriod: period, Status: TaskStatusCompleted})
+
+		_, err := uc.UpdateTask(ctx, UpdateTaskParam{TaskID: "t1", UserID: "u1", Status: status(TaskStatusCancelled)})
+		require.ErrorIs(t, err, ErrTaskStatusTransition)
+		var transitionErr *TaskStatusTransitionError
+		require.True(t, errors.As(err, &transitionErr))
+		assert.Equal(t, TaskStatusCompleted, transitionErr.From)
+		assert.Equal(t, TaskStatusCancelled, transitionErr.To)
+		assert.Equal(t, TaskStatusCompleted, repo.tasks["t1"].Status)
+	})
+
+	t.Run("取消并记录原因，重新打开后清空", func(t *testing.T) {
+		uc, repo := newUsecase(&Task{ID: "t1", UserID: "u1", Title: "t", TaskType: PeriodDay, TimePeriod: period})
+
+		task, err := uc.UpdateTask(ctx, UpdateTaskParam{TaskID: "t1", UserID: "u1", Status: status(TaskStatusCancelled), CancelReason: reason("计划变更")})
+		require.NoError(t, err)
+		assert.NotNil(t, task.CancelledAt)
+		assert.Equal(t, "计划变更", repo.tasks["t1"].CancelReason)
+
+		// 只有已取消的任务可以设置取消原因
+		_, err = uc.UpdateTask(ctx, UpdateTaskParam{TaskID: "t1", UserID: "u1", Status: status(TaskStatusInProgress), CancelReason: reason("x")})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+
+		task, err = uc.UpdateTask(ctx, UpdateTaskParam{TaskID: "t1", UserID: "u1", Status: status(TaskStatusInProgress)})
+		require.NoError(t, err)
+		assert.Nil(t, task.CancelledAt)
+		assert.Empty(t, task.CancelReason)
+		assert.NotNil(t, task.StartedAt)
+	})
+
+	t.Run("自动完成的父任务记录完成时间", func(t *testing.T) {
+		uc, repo := newUsecase(
+			&Task{ID: "p", UserID: "u1", Title: "p", TaskType: PeriodDay, TimePeriod: period, Status: TaskStatusInProgress},
+			&Task{ID: "c", UserID: "u1", Title: "c", TaskType: PeriodDay, TimePeriod: period, ParentID: "p", RootTaskID: "p"},
+		)
+
+		_, err := uc.UpdateTask(ctx, UpdateTaskParam{TaskID: "c", UserID: "u1", Status: status(TaskStatusCompleted)})
+		require.NoError(t, err)
+		assert.NotNil(t, repo.tasks["c"].CompletedAt)
+		assert.Equal(t, TaskStatusCompleted, repo.tasks["p"].Status)
+		assert.NotNil(t, repo.tasks["p"].CompletedAt)
+	})
+}
@@ -0,0 +1,388 @@
+package biz
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// 单个模板最多包含的节点数
+const MaxTaskTemplateNodes = 200
+
+// 节点偏移量的上限，避免异常数据导致长时间循环
+const maxTemplateNodeOffset = 1000
+
+// TaskTemplate 任务模板：一棵保存下来的任务树，可以在任意参考日期实例化
+type TaskTemplate struct {
+	ID          string            `json:"id"`
+	UserID      string            `json:"user_id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Root        *TaskTemplateNode `json:"root"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// TaskTemplateNode 模板中的一个任务节点
+// 时间段是相对的：根节点取参考日期所在的 TaskType 时间段；
+// 子节点取父节点时间段内第一个 TaskType 时间段，再往后偏移 Offset 个
+type TaskTemplateNode struct {
+	Title    string              `json:"title"`
+	TaskType PeriodType          `json:"task_type"`
+	Offset   int                 `json:"offset"`
+	Tags     []string            `json:"tags"`
+	Icon     string              `json:"icon"`
+	Priority TaskPriority        `json:"priority"`
+	Children []*TaskTemplateNode `json:"children,omitempty"`
+}
+
+// 创建任务模板参数
+type CreateTaskTemplateParam struct {
+	UserID      string
+	Name        string
+	Description string
+	Root        *TaskTemplateNode
+}
+
+// 从已有任务树保存模板参数
+type CaptureTaskTemplateParam struct {
+	UserID      string
+	TaskID      string // 以该任务为根的子树
+	Name        string
+	Description string
+}
+
+// 获取/删除任务模板参数
+type GetTaskTemplateParam struct {
+	TemplateID string
+	UserID     string
+}
+
+// 实例化任务模板参数
+type InstantiateTaskTemplateParam struct {
+	TemplateID    string
+	UserID        string
+	ReferenceDate time.Time
+	ParentID      string // 可选：挂到已有任务下
+}
+
+type TaskTemplateUsecase struct {
+	repo        TaskTemplateRepo
+	taskUsecase *TaskUsecase
+}
+
+func NewTaskTemplateUsecase(repo TaskTemplateRepo, taskUsecase *TaskUsecase) *TaskTemplateUsecase {
+	return &TaskTemplateUsecase{repo: repo, taskUsecase: taskUsecase}
+}
+
+// addPeriods 把时间往后移动 n 个指定类型的时间段
+func addPeriods(t time.Time, pt PeriodType, n int) time.Time {
+	switch pt {
+	case PeriodWeek:
+		return t.AddDate(0, 0, 7*n)
+	case PeriodMonth:
+		return t.AddDate(0, n, 0)
+	case PeriodQuarter:
+		return t.AddDate(0, 3*n, 0)
+	case PeriodYear:
+		return t.AddDate(n, 0, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
+
+// firstPeriodWithin 从 start 开始（含）的第一个完整的指定类型时间段
+// 例如月初不是周一时，取该月的第一个周一所在的周，保证子任务从父任务时间段内开始
+func firstPeriodWithin(pt PeriodType, start time.Time) Period {
+	period := NewPeriodFromPeriodType(pt, start)
+	if period.Start.Before(start) {
+		period = NewPeriodFromPeriodType(pt, period.End)
+	}
+	return period
+}
+
+// templateNodePeriod 子节点在父任务时间段内的具体时间段
+func templateNodePeriod(node *TaskTemplateNode, parentPeriod Period) Period {
+	base := firstPeriodWithin(node.TaskType, parentPeriod.Start)
+	return NewPeriodFromPeriodType(node.TaskType, addPeriods(base.Start, node.TaskType, node.Offset))
+}
+
+// templateNodeOffset 子任务相对父任务时间段的偏移量，与 templateNodePeriod 互逆
+func templateNodeOffset(pt PeriodType, parentPeriod, period Period) int {
+	base := firstPeriodWithin(pt, parentPeriod.Start).Start
+	offset := 0
+	for offset < maxTemplateNodeOffset && !addPeriods(base, pt, offset+1).After(period.Start) {
+		offset++
+	}
+	return offset
+}
+
+// validateTemplateNode 校验节点及其子树，返回节点总数
+func validateTemplateNode(node *TaskTemplateNode, parentType *PeriodType) (int, error) {
+	if node == nil || strings.TrimSpace(node.Title) == "" {
+		return 0, ErrTaskTemplateInvalid
+	}
+	if node.TaskType < PeriodDay || node.TaskType > PeriodYear {
+		return 0, ErrTaskTemplateInvalid
+	}
+	if node.Offset < 0 || node.Offset > maxTemplateNodeOffset {
+		return 0, ErrTaskTemplateInvalid
+	}
+	if parentType != nil && node.TaskType > *parentType {
+		return 0, ErrSubTaskTypeInvalid
+	}
+
+	count := 1
+	for _, child := range node.Children {
+		childCount, err := validateTemplateNode(child, &node.TaskType)
+		if err != nil {
+			return 0, err
+		}
+		count += childCount
+		if count > MaxTaskTemplateNodes {
+			return 0, ErrTaskTemplateInvalid
+		}
+	}
+	return count, nil
+}
+
+// templateNodeHeight 节点子树最深的后代相对该节点的层数
+func templateNodeHeight(node *TaskTemplateNode) int {
+	height := 0
+	for _, child := range node.Children {
+		if h := templateNodeHeight(child) + 1; h > height {
+			height = h
+		}
+	}
+	return height
+}
+
+// 创建任务模板
+func (uc *TaskTemplateUsecase) CreateTaskTemplate(ctx context.Context, param CreateTaskTemplateParam) (*TaskTemplate, error) {
+	if param.UserID == "" || strings.TrimSpace(param.Name) == "" {
+		return nil, ErrInvalidInput
+	}
+	if _, err := validateTemplateNode(param.Root, nil); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &TaskTemplate{
+		ID:          generateID(),
+		UserID:      param.UserID,
+		Name:        strings.TrimSpace(param.Name),
+		Description: param.Description,
+		Root:        param.Root,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := uc.repo.CreateTaskTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// 从已有任务树保存模板：以指定任务为根，记录每个任务的标题、类型、标签、图标、优先级和相对时间段
+func (uc *TaskTemplateUsecase) CaptureTaskTemplate(ctx context.Context, param CaptureTaskTemplateParam) (*TaskTemplate, error) {
+	if param.UserID == "" || param.TaskID == "" {
+		return nil, ErrInvalidInput
+	}
+	if err := uc.taskUsecase.checkTaskExists(ctx, param.TaskID, param.UserID); err != nil {
+		return nil, err
+	}
+
+	roots, err := uc.taskUsecase.GetCompleteTaskTree(ctx, GetCompleteTaskTreeParam{UserID: param.UserID, TaskID: param.TaskID})
+	if err != nil {
+		return nil, err
+	}
+	task := findTaskInTree(roots, param.TaskID)
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	name := param.Name
+	if strings.TrimSpace(name) == "" {
+		name = task.Title
+	}
+	return uc.CreateTaskTemplate(ctx, CreateTaskTemplateParam{
+		UserID:      param.UserID,
+		Name:        name,
+		Description: param.Description,
+		Root:        templateNodeFromTask(task, nil),
+	})
+}
+
+// findTaskInTree 在内存任务树中查找任务
+func findTaskInTree(tasks []*Task, taskID string) *Task {
+	for _, task := range tasks {
+		if task.ID == taskID {
+			return task
+		}
+		if found := findTaskInTree(task.Children, taskID); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func templateNodeFromTask(task *Task, parent *Task) *TaskTemplateNode {
+	node := &TaskTemplateNode{
+		Title:    task.Title,
+		TaskType: task.TaskType,
+		Tags:     append([]string{}, task.Tags...),
+		Icon:     task.Icon,
+		Priority: task.Priority,
+	}
+	if parent != nil {
+		node.Offset = templateNodeOffset(task.TaskType, parent.TimePeriod, task.TimePeriod)
+	}
+	for _, child := range task.Children {
+		node.Children = append(node.Children, templateNodeFromTask(child, task))
+	}
+	return node
+}
+
+// 获取任务模板
+func (uc *TaskTemplateUsecase) GetTaskTemplate(ctx context.Context, param GetTaskTemplateParam) (*TaskTemplate, error) {
+	if param.TemplateID == "" || param.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+	template, err := uc.repo.GetTaskTemplate(ctx, param.TemplateID, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, ErrTaskTemplateNotFound
+	}
+	return template, nil
+}
+
+// 获取用户的全部任务模板
+func (uc *TaskTemplateUsecase) ListTaskTemplates(ctx context.Context, userID string) ([]*TaskTemplate, error) {
+	if userID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	return uc.repo.ListTaskTemplates(ctx, userID)
+}
+
+// 删除任务模板，已经实例化的任务保留
+func (uc *TaskTemplateUsecase) DeleteTaskTemplate(ctx context.Context, param GetTaskTemplateParam) error {
+	if _, err := uc.GetTaskTemplate(ctx, param); err != nil {
+		return err
+	}
+	return uc.repo.DeleteTaskTemplate(ctx, param.TemplateID, param.UserID)
+}
+
+// 在参考日期实例化模板，整棵任务树在同一个事务中创建，返回带 Children 的根任务
+// 树结构字段（root_task_id、tree_depth、children_count 等）在创建时直接计算好
+func (uc *TaskTemplateUsecase) InstantiateTaskTemplate(ctx context.Context, param InstantiateTaskTemplateParam) (*Task, error) {
+	if param.ReferenceDate.IsZero() {
+		return nil, ErrInvalidInput
+	}
+	template, err := uc.GetTaskTemplate(ctx, GetTaskTemplateParam{TemplateID: param.TemplateID, UserID: param.UserID})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := validateTemplateNode(template.Root, nil); err != nil {
+		return nil, err
+	}
+
+	taskUC := uc.taskUsecase
+	var root *Task
+	err = taskUC.repo.Transaction(ctx, func(ctx context.Context) error {
+		period := NewPeriodFromPeriodType(template.Root.TaskType, param.ReferenceDate)
+		var parent *Task
+		if param.ParentID != "" {
+			parent, err = taskUC.repo.GetTask(ctx, param.ParentID, param.UserID)
+			if err != nil {
+				return err
+			}
+			if parent == nil {
+				return ErrTaskNotFound
+			}
+			if err := validateSubTaskPlacement(parent, template.Root.TaskType, period); err != nil {
+				return err
+			}
+		}
+
+		depth := templateNodeHeight(template.Root)
+		if parent != nil {
+			depth += parent.TreeDepth + 1
+		}
+		if err := taskUC.checkTreeDepth(depth); err != nil {
+			return err
+		}
+
+		root, err = uc.createTemplateNode(ctx, template.Root, period, parent, param.UserID)
+		if err != nil {
+			return err
+		}
+		if parent != nil {
+			if err := taskUC.repo.UpdateTreeOptimizationFields(ctx, parent.ID, parent.UserID); err != nil {
+				return err
+			}
+			// 新的子树会拉低父任务链的进度
+			return taskUC.refreshRollupFrom(ctx, parent.ID, parent.UserID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// createTemplateNode 创建节点对应的任务及其子树，需要在事务中调用
+func (uc *TaskTemplateUsecase) createTemplateNode(ctx context.Context, node *TaskTemplateNode, period Period, parent *Task, userID string) (*Task, error) {
+	now := time.Now()
+	task := &Task{
+		ID:            generateID(),
+		Title:         node.Title,
+		TaskType:      node.TaskType,
+		TimePeriod:    period,
+		Tags:          append([]string{}, node.Tags...),
+		Icon:          node.Icon,
+		Status:        TaskStatusNotStarted,
+		Priority:      node.Priority,
+		Weight:        DefaultTaskWeight,
+		UserID:        userID,
+		HasChildren:   len(node.Children) > 0,
+		ChildrenCount: len(node.Children),
+		Children:      make([]*Task, 0, len(node.Children)),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if parent != nil {
+		task.ParentID = parent.ID
+		task.RootTaskID = parent.RootTaskID
+		if task.RootTaskID == "" {
+			task.RootTaskID = parent.ID
+		}
+		task.TreeDepth = parent.TreeDepth + 1
+	} else {
+		task.RootTaskID = task.ID
+	}
+
+	if err := uc.taskUsecase.assignSortRank(ctx, task); err != nil {
+		return nil, err
+	}
+	if err := uc.taskUsecase.repo.CreateTask(ctx, task); err != nil {
+		return nil, err
+	}
+	if err := uc.taskUsecase.recordTaskChange(ctx, ChangeActionCreate, nil, task); err != nil {
+		return nil, err
+	}
+
+	for _, childNode := range node.Children {
+		childPeriod := templateNodePeriod(childNode, period)
+		if err := validateSubTaskPlacement(task, childNode.TaskType, childPeriod); err != nil {
+			return nil, err
+		}
+		child, err := uc.createTemplateNode(ctx, childNode, childPeriod, task, userID)
+		if err != nil {
+			return nil, err
+		}
+		task.Children = append(task.Children, child)
+	}
+	return task, nil
+}
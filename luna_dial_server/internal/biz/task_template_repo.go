@@ -0,0 +1,11 @@
+package biz
+
+import "context"
+
+type TaskTemplateRepo interface {
+	CreateTaskTemplate(ctx context.Context, template *TaskTemplate) error
+	DeleteTaskTemplate(ctx context.Context, templateID, userID string) error
+	// GetTaskTemplate 获取模板，不存在时返回 nil, nil
+	GetTaskTemplate(ctx context.Context, templateID, userID string) (*TaskTemplate, error)
+	ListTaskTemplates(ctx context.Context, userID string) ([]*TaskTemplate, error)
+}
@@ -0,0 +1,145 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockTaskTemplateRepo 内存任务模板仓库
+type mockTaskTemplateRepo struct {
+	templates map[string]*TaskTemplate
+}
+
+func newMockTaskTemplateRepo() *mockTaskTemplateRepo {
+	return &mockTaskTemplateRepo{templates: make(map[string]*TaskTemplate)}
+}
+
+func (m *mockTaskTemplateRepo) CreateTaskTemplate(ctx context.Context, template *TaskTemplate) error {
+	m.templates[template.ID] = template
+	return nil
+}
+
+func (m *mockTaskTemplateRepo) DeleteTaskTemplate(ctx context.Context, templateID, userID string) error {
+	delete(m.templates, templateID)
+	return nil
+}
+
+func (m *mockTaskTemplateRepo) GetTaskTemplate(ctx context.Context, templateID, userID string) (*TaskTemplate, error) {
+	template, ok := m.templates[templateID]
+	if !ok || template.UserID != userID {
+		return nil, nil
+	}
+	return template, nil
+}
+
+func (m *mockTaskTemplateRepo) ListTaskTemplates(ctx context.Context, userID string) ([]*TaskTemplate, error) {
+	templates := make([]*TaskTemplate, 0)
+	for _, template := range m.templates {
+		if template.UserID == userID {
+			templates = append(templates, template)
+		}
+	}
+	return templates, nil
+}
+
+func TestTaskTemplateUsecase_CaptureAndInstantiate(t *testing.T) {
+	ctx := context.Background()
+	// 2025年1月的月任务，下面是 1月13日所在的周任务和 1月15日的日任务
+	// 2025-01-01 是周三，1月的第一个完整周从 1月6日开始
+	monthTask := newTestTask("month", PeriodMonth, date(2025, 1, 1), TaskStatusNotStarted, nil)
+	monthTask.Title, monthTask.Tags, monthTask.Priority = "一月目标", []string{"目标"}, TaskPriorityHigh
+	weekTask := newTestTask("week", PeriodWeek, date(2025, 1, 13), TaskStatusNotStarted, monthTask)
+	dayTask := newTestTask("day", PeriodDay, date(2025, 1, 15), TaskStatusNotStarted, weekTask)
+	dayTask.Icon = "📝"
+	taskRepo := newMemoryTaskRepo(monthTask, weekTask, dayTask)
+	historyRepo := newMockChangeHistoryRepo()
+	usecase := NewTaskTemplateUsecase(newMockTaskTemplateRepo(), NewTaskUsecase(taskRepo, newMockUserSettingsRepo(), historyRepo))
+
+	template, err := usecase.CaptureTaskTemplate(ctx, CaptureTaskTemplateParam{UserID: "user-123", TaskID: "month"})
+	require.NoError(t, err)
+	assert.Equal(t, "一月目标", template.Name)
+	require.Len(t, template.Root.Children, 1)
+	week := template.Root.Children[0]
+	assert.Equal(t, PeriodWeek, week.TaskType)
+	assert.Equal(t, 1, week.Offset)
+	require.Len(t, week.Children, 1)
+	assert.Equal(t, 2, week.Children[0].Offset)
+	assert.Equal(t, "📝", week.Children[0].Icon)
+
+	t.Run("在参考日期创建整棵任务树", func(t *testing.T) {
+		// 2025-03-01 是周六，3月的第一个完整周从 3月3日开始
+		root, err := usecase.InstantiateTaskTemplate(ctx, InstantiateTaskTemplateParam{TemplateID: template.ID, UserID: "user-123", ReferenceDate: date(2025, 3, 20)})
+		require.NoError(t, err)
+
+		assert.Equal(t, NewPeriodFromPeriodType(PeriodMonth, date(2025, 3, 1)), root.TimePeriod)
+		assert.Equal(t, []string{"目标"}, root.Tags)
+		assert.Equal(t, TaskPriorityHigh, root.Priority)
+		require.Len(t, root.Children, 1)
+		weekTask := root.Children[0]
+		assert.Equal(t, NewPeriodFromPeriodType(PeriodWeek, date(2025, 3, 10)), weekTask.TimePeriod)
+		require.Len(t, weekTask.Children, 1)
+		dayTask := weekTask.Children[0]
+		assert.Equal(t, NewPeriodFromPeriodType(PeriodDay, date(2025, 3, 12)), dayTask.TimePeriod)
+
+		stored := taskRepo.tasks[dayTask.ID]
+		assert.Equal(t, weekTask.ID, stored.ParentID)
+		assert.Equal(t, root.ID, stored.RootTaskID)
+		assert.Equal(t, 2, stored.TreeDepth)
+		assert.Equal(t, TaskStatusNotStarted, stored.Status)
+		assert.Equal(t, root.ID, taskRepo.tasks[root.ID].RootTaskID)
+		assert.Equal(t, 1, taskRepo.tasks[root.ID].ChildrenCount)
+		assert.True(t, taskRepo.tasks[weekTask.ID].HasChildren)
+		assert.NotEmpty(t, historyRepo.records)
+	})
+
+	t.Run("挂到已有任务下", func(t *testing.T) {
+		taskRepo.tasks["year"] = &Task{ID: "year", UserID: "user-123", TaskType: PeriodYear, TimePeriod: NewPeriodFromPeriodType(PeriodYear, date(2025, 1, 1)), RootTaskID: "year"}
+
+		root, err := usecase.InstantiateTaskTemplate(ctx, InstantiateTaskTemplateParam{TemplateID: template.ID, UserID: "user-123", ReferenceDate: date(2025, 2, 1), ParentID: "year"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "year", taskRepo.tasks[root.ID].ParentID)
+		assert.Equal(t, "year", taskRepo.tasks[root.ID].RootTaskID)
+		assert.Equal(t, 3, taskRepo.tasks[root.Children[0].Children[0].ID].TreeDepth)
+		assert.Equal(t, "year", taskRepo.tasks[root.Children[0].Children[0].ID].RootTaskID)
+		assert.Equal(t, 1, taskRepo.tasks["year"].ChildrenCount)
+	})
+}
+
+func TestTaskTemplateUsecase_CreateTaskTemplate(t *testing.T) {
+	ctx := context.Background()
+	taskRepo := newMemoryTaskRepo()
+	usecase := NewTaskTemplateUsecase(newMockTaskTemplateRepo(), NewTaskUsecase(taskRepo, newMockUserSettingsRepo(), newMockChangeHistoryRepo()))
+
+	t.Run("子节点类型不能大于父节点", func(t *testing.T) {
+		_, err := usecase.CreateTaskTemplate(ctx, CreateTaskTemplateParam{UserID: "user-123", Name: "周计划", Root: &TaskTemplateNode{
+			Title: "本周", TaskType: PeriodWeek,
+			Children: []*TaskTemplateNode{{Title: "本月", TaskType: PeriodMonth}},
+		}})
+		assert.Equal(t, ErrSubTaskTypeInvalid, err)
+	})
+
+	t.Run("节点标题不能为空", func(t *testing.T) {
+		_, err := usecase.CreateTaskTemplate(ctx, CreateTaskTemplateParam{UserID: "user-123", Name: "周计划", Root: &TaskTemplateNode{TaskType: PeriodWeek}})
+		assert.Equal(t, ErrTaskTemplateInvalid, err)
+	})
+
+	t.Run("偏移超出父任务时间段时实例化失败", func(t *testing.T) {
+		template, err := usecase.CreateTaskTemplate(ctx, CreateTaskTemplateParam{UserID: "user-123", Name: "周计划", Root: &TaskTemplateNode{
+			Title: "本周", TaskType: PeriodWeek,
+			Children: []*TaskTemplateNode{{Title: "第十天", TaskType: PeriodDay, Offset: 9}},
+		}})
+		require.NoError(t, err)
+
+		_, err = usecase.InstantiateTaskTemplate(ctx, InstantiateTaskTemplateParam{TemplateID: template.ID, UserID: "user-123", ReferenceDate: date(2025, 1, 6)})
+		assert.Equal(t, ErrSubTaskPeriodInvalid, err)
+	})
+
+	t.Run("模板不存在", func(t *testing.T) {
+		_, err := usecase.GetTaskTemplate(ctx, GetTaskTemplateParam{TemplateID: "missing", UserID: "user-123"})
+		assert.Equal(t, ErrTaskTemplateNotFound, err)
+	})
+}
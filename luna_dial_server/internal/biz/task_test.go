@@ -13,16 +13,18 @@ import (
 // 创建测试用的 TaskUsecase 实例
 func createTestTaskUsecase() *TaskUsecase {
 	repo := &mockTaskRepo{}
-	return NewTaskUsecase(repo)
+	return NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
 }
 
 // 测试 NewTaskUsecase 构造函数
 func TestNewTaskUsecase(t *testing.T) {
 	repo := &mockTaskRepo{}
-	usecase := NewTaskUsecase(repo)
+	settingsRepo := newMockUserSettingsRepo()
+	usecase := NewTaskUsecase(repo, settingsRepo, newMockChangeHistoryRepo())
 
 	require.NotNil(t, usecase, "NewTaskUsecase should not return nil")
 	assert.Equal(t, repo, usecase.repo, "repo should be set correctly")
+	assert.Equal(t, settingsRepo, usecase.settingsRepo, "settings repo should be set correctly")
 }
 
 // 测试 CreateTask 方法
@@ -291,6 +293,93 @@ func TestTaskUsecase_DeleteTask(t *testing.T) {
 		require.NoError(t, err, "DeleteTask should succeed")
 	})
 
+	// 1月的月任务 m -> 跨月的周任务 w -> 日任务 d1 -> 日任务 d11
+	month := NewPeriodFromPeriodType(PeriodMonth, date(2025, 1, 1))
+	week := NewPeriodFromPeriodType(PeriodWeek, date(2025, 1, 28))
+	day := NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 28))
+
+	t.Run("级联删除子树", func(t *testing.T) {
+		repo := newMemoryTaskRepo(
+			&Task{ID: "m", UserID: "user-123", TaskType: PeriodMonth, TimePeriod: month, RootTaskID: "m", HasChildren: true, ChildrenCount: 1},
+			&Task{ID: "w", UserID: "user-123", TaskType: PeriodWeek, TimePeriod: week, ParentID: "m", RootTaskID: "m", TreeDepth: 1, HasChildren: true, ChildrenCount: 1},
+			&Task{ID: "d1", UserID: "user-123", TaskType: PeriodDay, TimePeriod: day, ParentID: "w", RootTaskID: "m", TreeDepth: 2, HasChildren: true, ChildrenCount: 1},
+			&Task{ID: "d11", UserID: "user-123", TaskType: PeriodDay, TimePeriod: day, ParentID: "d1", RootTaskID: "m", TreeDepth: 3},
+		)
+		uc := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+		err := uc.DeleteTask(ctx, DeleteTaskParam{TaskID: "w", UserID: "user-123", Mode: TaskDeleteModeCascade})
+		require.NoError(t, err, "DeleteTask should succeed in cascade mode")
+
+		assert.Len(t, repo.trashed, 3)
+		assert.Contains(t, repo.trashed, "d11")
+		assert.Equal(t, 0, repo.tasks["m"].ChildrenCount)
+		assert.False(t, repo.tasks["m"].HasChildren)
+	})
+
+	t.Run("子任务上移后删除", func(t *testing.T) {
+		repo := newMemoryTaskRepo(
+			&Task{ID: "m", UserID: "user-123", TaskType: PeriodMonth, TimePeriod: month, RootTaskID: "m", HasChildren: true, ChildrenCount: 1},
+			&Task{ID: "w", UserID: "user-123", TaskType: PeriodWeek, TimePeriod: week, ParentID: "m", RootTaskID: "m", TreeDepth: 1, HasChildren: true, ChildrenCount: 1},
+			&Task{ID: "d1", UserID: "user-123", TaskType: PeriodDay, TimePeriod: day, ParentID: "w", RootTaskID: "m", TreeDepth: 2, HasChildren: true, ChildrenCount: 1},
+			&Task{ID: "d11", UserID: "user-123", TaskType: PeriodDay, TimePeriod: day, ParentID: "d1", RootTaskID: "m", TreeDepth: 3},
+		)
+		uc := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+		err := uc.DeleteTask(ctx, DeleteTaskParam{TaskID: "w", UserID: "user-123", Mode: TaskDeleteModePromote})
+		require.NoError(t, err, "DeleteTask should succeed in promote mode")
+
+		assert.Contains(t, repo.trashed, "w")
+		assert.Equal(t, "m", repo.tasks["d1"].ParentID)
+		assert.Equal(t, 1, repo.tasks["d1"].TreeDepth)
+		assert.Equal(t, "m", repo.tasks["d1"].RootTaskID)
+		assert.Equal(t, 2, repo.tasks["d11"].TreeDepth)
+		assert.Equal(t, "m", repo.tasks["d11"].RootTaskID)
+		assert.Equal(t, 1, repo.tasks["m"].ChildrenCount)
+		assert.True(t, repo.tasks["m"].HasChildren)
+
+		// 没有祖父任务时子任务成为根任务
+		err = uc.DeleteTask(ctx, DeleteTaskParam{TaskID: "m", UserID: "user-123", Mode: TaskDeleteModePromote})
+		require.NoError(t, err)
+		assert.Empty(t, repo.tasks["d1"].ParentID)
+		assert.Equal(t, 0, repo.tasks["d1"].TreeDepth)
+		assert.Equal(t, "d1", repo.tasks["d1"].RootTaskID)
+		assert.Equal(t, 1, repo.tasks["d11"].TreeDepth)
+		assert.Equal(t, "d1", repo.tasks["d11"].RootTaskID)
+	})
+
+	t.Run("上移的子任务不满足祖父任务的规则", func(t *testing.T) {
+		repo := newMemoryTaskRepo(
+			&Task{ID: "m", UserID: "user-123", TaskType: PeriodMonth, TimePeriod: month, RootTaskID: "m", HasChildren: true, ChildrenCount: 1},
+			&Task{ID: "w", UserID: "user-123", TaskType: PeriodWeek, TimePeriod: week, ParentID: "m", RootTaskID: "m", TreeDepth: 1, HasChildren: true, ChildrenCount: 2},
+			&Task{ID: "d1", UserID: "user-123", TaskType: PeriodDay, TimePeriod: day, ParentID: "w", RootTaskID: "m", TreeDepth: 2},
+			&Task{ID: "d2", UserID: "user-123", TaskType: PeriodDay, TimePeriod: NewPeriodFromPeriodType(PeriodDay, date(2025, 2, 2)), ParentID: "w", RootTaskID: "m", TreeDepth: 2},
+		)
+		uc := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+		err := uc.DeleteTask(ctx, DeleteTaskParam{TaskID: "w", UserID: "user-123", Mode: TaskDeleteModePromote})
+		assert.ErrorIs(t, err, ErrTaskChildrenConflict)
+		var conflictErr *TaskChildrenConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		require.Len(t, conflictErr.Conflicts, 1)
+		assert.Equal(t, "d2", conflictErr.Conflicts[0].TaskID)
+		assert.Equal(t, ErrSubTaskPeriodInvalid.Error(), conflictErr.Conflicts[0].Reason)
+
+		// 不做任何修改
+		assert.Contains(t, repo.tasks, "w")
+		assert.Equal(t, "w", repo.tasks["d1"].ParentID)
+		assert.Empty(t, repo.trashed)
+	})
+
+	t.Run("无效删除模式", func(t *testing.T) {
+		err := usecase.DeleteTask(ctx, DeleteTaskParam{
+			TaskID: "task-123",
+			UserID: "user-123",
+			Mode:   TaskDeleteMode(99),
+		})
+
+		assert.Equal(t, ErrInvalidInput, err, "should reject unknown delete mode")
+	})
+
 	t.Run("权限验证失败", func(t *testing.T) {
 		param := DeleteTaskParam{
 			TaskID: "task-123",
@@ -397,6 +486,193 @@ func TestTaskUsecase_CreateSubTask(t *testing.T) {
 	})
 }
 
+// 测试 MoveTask 方法
+func TestTaskUsecase_MoveTask(t *testing.T) {
+	ctx := context.Background()
+	// 季度目标 q1 -> 一月 jan -> 第二周 w -> 周四 d 和同周子任务 w2；另有独立的一月任务 other
+	setup := func() (*TaskUsecase, *memoryTaskRepo) {
+		repo := newMemoryTaskRepo(
+			&Task{ID: "q1", UserID: "user-123", TaskType: PeriodQuarter, TimePeriod: NewPeriodFromPeriodType(PeriodQuarter, date(2025, 1, 1)), RootTaskID: "q1", HasChildren: true, ChildrenCount: 1},
+			&Task{ID: "jan", UserID: "user-123", TaskType: PeriodMonth, TimePeriod: NewPeriodFromPeriodType(PeriodMonth, date(2025, 1, 1)), ParentID: "q1", RootTaskID: "q1", TreeDepth: 1, HasChildren: true, ChildrenCount: 1},
+			&Task{ID: "w", UserID: "user-123", TaskType: PeriodWeek, TimePeriod: NewPeriodFromPeriodType(PeriodWeek, date(2025, 1, 6)), ParentID: "jan", RootTaskID: "q1", TreeDepth: 2, HasChildren: true, ChildrenCount: 2},
+			&Task{ID: "d", UserID: "user-123", TaskType: PeriodDay, TimePeriod: NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 9)), ParentID: "w", RootTaskID: "q1", TreeDepth: 3},
+			&Task{ID: "w2", UserID: "user-123", TaskType: PeriodWeek, TimePeriod: NewPeriodFromPeriodType(PeriodWeek, date(2025, 1, 6)), ParentID: "w", RootTaskID: "q1", TreeDepth: 3},
+			&Task{ID: "other", UserID: "user-123", TaskType: PeriodMonth, TimePeriod: NewPeriodFromPeriodType(PeriodMonth, date(2025, 1, 1)), RootTaskID: "other"},
+		)
+		return NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo()), repo
+	}
+
+	t.Run("成功移动到根级别", func(t *testing.T) {
+		usecase, repo := setup()
+
+		task, err := usecase.MoveTask(ctx, MoveTaskParam{TaskID: "w", UserID: "user-123", NewParentID: ""})
+		require.NoError(t, err, "MoveTask should succeed when moving to root")
+		require.NotNil(t, task, "should return moved task")
+
+		assert.Empty(t, repo.tasks["w"].ParentID)
+		assert.Equal(t, 0, repo.tasks["w"].TreeDepth)
+		assert.Equal(t, "w", repo.tasks["w"].RootTaskID)
+		assert.Equal(t, 1, repo.tasks["d"].TreeDepth)
+		assert.Equal(t, "w", repo.tasks["d"].RootTaskID)
+		// 原父任务不再有子任务
+		assert.Equal(t, 0, repo.tasks["jan"].ChildrenCount)
+		assert.False(t, repo.tasks["jan"].HasChildren)
+	})
+
+	t.Run("成功移动到新父任务下", func(t *testing.T) {
+		usecase, repo := setup()
+
+		task, err := usecase.MoveTask(ctx, MoveTaskParam{TaskID: "w", UserID: "user-123", NewParentID: "other"})
+		require.NoError(t, err, "MoveTask should succeed for a valid parent")
+		require.NotNil(t, task, "should return moved task")
+
+		assert.Equal(t, "other", repo.tasks["w"].ParentID)
+		assert.Equal(t, 1, repo.tasks["w"].TreeDepth)
+		assert.Equal(t, "other", repo.tasks["w"].RootTaskID)
+		assert.Equal(t, 2, repo.tasks["d"].TreeDepth)
+		assert.Equal(t, "other", repo.tasks["d"].RootTaskID)
+		assert.Equal(t, 0, repo.tasks["jan"].ChildrenCount)
+		assert.False(t, repo.tasks["jan"].HasChildren)
+		assert.Equal(t, 1, repo.tasks["other"].ChildrenCount)
+		assert.True(t, repo.tasks["other"].HasChildren)
+	})
+
+	t.Run("不能移动到自身之下", func(t *testing.T) {
+		usecase, _ := setup()
+
+		task, err := usecase.MoveTask(ctx, MoveTaskParam{TaskID: "w", UserID: "user-123", NewParentID: "w"})
+		assert.Nil(t, task)
+		assert.Equal(t, ErrTaskMoveCycle, err, "should reject moving a task under itself")
+	})
+
+	t.Run("不能移动到自己的后代之下", func(t *testing.T) {
+		usecase, repo := setup()
+
+		task, err := usecase.MoveTask(ctx, MoveTaskParam{TaskID: "w", UserID: "user-123", NewParentID: "w2"})
+		assert.Nil(t, task)
+		assert.Equal(t, ErrTaskMoveCycle, err, "should reject moving a task under its descendant")
+		assert.Equal(t, "jan", repo.tasks["w"].ParentID)
+	})
+
+	t.Run("新父任务不存在", func(t *testing.T) {
+		usecase, _ := setup()
+
+		task, err := usecase.MoveTask(ctx, MoveTaskParam{TaskID: "w", UserID: "user-123", NewParentID: "non-existent-parent"})
+		assert.Nil(t, task)
+		assert.Equal(t, ErrTaskNotFound, err)
+	})
+
+	t.Run("参数验证失败", func(t *testing.T) {
+		usecase, _ := setup()
+
+		task, err := usecase.MoveTask(ctx, MoveTaskParam{UserID: "user-123"})
+		assert.Nil(t, task)
+		assert.Equal(t, ErrInvalidInput, err)
+	})
+}
+
+// 测试修改任务类型时的父子规则校验
+func TestTaskUsecase_UpdateTaskType(t *testing.T) {
+	ctx := context.Background()
+	setup := func() (*TaskUsecase, *memoryTaskRepo) {
+		repo := newMemoryTaskRepo(
+			&Task{ID: "okr", UserID: "user-123", Title: "一月目标", TaskType: PeriodMonth, TimePeriod: NewPeriodFromPeriodType(PeriodMonth, date(2025, 1, 1)), RootTaskID: "okr"},
+			&Task{ID: "week", UserID: "user-123", Title: "第二周", TaskType: PeriodWeek, TimePeriod: NewPeriodFromPeriodType(PeriodWeek, date(2025, 1, 6)), ParentID: "okr", RootTaskID: "okr"},
+			&Task{ID: "day", UserID: "user-123", Title: "周四", TaskType: PeriodDay, TimePeriod: NewPeriodFromPeriodType(PeriodDay, date(2025, 1, 9)), ParentID: "week", RootTaskID: "okr"},
+			&Task{ID: "sub-week", UserID: "user-123", Title: "同周子任务", TaskType: PeriodWeek, TimePeriod: NewPeriodFromPeriodType(PeriodWeek, date(2025, 1, 6)), ParentID: "week", RootTaskID: "okr"},
+		)
+		return NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo()), repo
+	}
+	periodType := func(pt PeriodType) *PeriodType { return &pt }
+
+	t.Run("修改类型后时间段按新类型规范化", func(t *testing.T) {
+		usecase, repo := setup()
+
+		task, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "week", UserID: "user-123", Type: periodType(PeriodMonth)})
+		require.NoError(t, err)
+		assert.Equal(t, PeriodMonth, task.TaskType)
+		assert.Equal(t, NewPeriodFromPeriodType(PeriodMonth, date(2025, 1, 1)), task.TimePeriod)
+		assert.Equal(t, PeriodMonth, repo.tasks["week"].TaskType)
+	})
+
+	t.Run("类型不能大于父任务", func(t *testing.T) {
+		usecase, repo := setup()
+
+		_, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "week", UserID: "user-123", Type: periodType(PeriodQuarter)})
+		assert.ErrorIs(t, err, ErrSubTaskTypeInvalid)
+		assert.Equal(t, PeriodWeek, repo.tasks["week"].TaskType)
+	})
+
+	t.Run("时间段必须在父任务范围内", func(t *testing.T) {
+		usecase, _ := setup()
+		period := NewPeriodFromPeriodType(PeriodWeek, date(2025, 2, 10))
+
+		_, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "week", UserID: "user-123", Period: &period})
+		assert.ErrorIs(t, err, ErrSubTaskPeriodInvalid)
+	})
+
+	t.Run("列出所有冲突的子任务", func(t *testing.T) {
+		usecase, repo := setup()
+
+		_, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "week", UserID: "user-123", Type: periodType(PeriodDay)})
+		require.ErrorIs(t, err, ErrTaskChildrenConflict)
+		var conflictErr *TaskChildrenConflictError
+		require.ErrorAs(t, err, &conflictErr)
+
+		reasons := make(map[string]string)
+		for _, conflict := range conflictErr.Conflicts {
+			reasons[conflict.TaskID] = conflict.Reason
+		}
+		assert.Equal(t, map[string]string{
+			"day":      ErrSubTaskPeriodInvalid.Error(),
+			"sub-week": ErrSubTaskTypeInvalid.Error(),
+		}, reasons)
+		assert.Equal(t, PeriodWeek, repo.tasks["week"].TaskType, "task is left unchanged")
+	})
+
+	t.Run("类型不变时不校验子任务", func(t *testing.T) {
+		usecase, _ := setup()
+		title := "第二周计划"
+
+		task, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "week", UserID: "user-123", Title: &title, Type: periodType(PeriodWeek)})
+		require.NoError(t, err)
+		assert.Equal(t, "第二周计划", task.Title)
+	})
+
+	t.Run("非法类型", func(t *testing.T) {
+		usecase, _ := setup()
+
+		_, err := usecase.UpdateTask(ctx, UpdateTaskParam{TaskID: "okr", UserID: "user-123", Type: periodType(PeriodType(9))})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
+
+// 测试 validateSubTaskPlacement 规则
+func TestValidateSubTaskPlacement(t *testing.T) {
+	parent := &Task{
+		TaskType: PeriodWeek,
+		TimePeriod: Period{
+			Start: time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	t.Run("日任务挂在周任务下", func(t *testing.T) {
+		err := validateSubTaskPlacement(parent, PeriodDay, NewPeriodFromPeriodType(PeriodDay, parent.TimePeriod.Start))
+		assert.NoError(t, err)
+	})
+
+	t.Run("月任务不能挂在周任务下", func(t *testing.T) {
+		err := validateSubTaskPlacement(parent, PeriodMonth, NewPeriodFromPeriodType(PeriodMonth, parent.TimePeriod.Start))
+		assert.Equal(t, ErrSubTaskTypeInvalid, err)
+	})
+
+	t.Run("开始时间不在父任务范围内", func(t *testing.T) {
+		err := validateSubTaskPlacement(parent, PeriodDay, NewPeriodFromPeriodType(PeriodDay, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, ErrSubTaskPeriodInvalid, err)
+	})
+}
+
 // 测试 EditTag 方法
 func TestTaskUsecase_TagOperations(t *testing.T) {
 	usecase := createTestTaskUsecase()
@@ -533,6 +809,25 @@ func TestTaskUsecase_ListTaskByPeriod(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("按匹配方式获取跨月的周任务", func(t *testing.T) {
+		straddling := NewPeriodFromPeriodType(PeriodWeek, time.Date(2025, 1, 29, 0, 0, 0, 0, time.UTC))
+		repo := newMemoryTaskRepo(&Task{ID: "w", UserID: "u1", TaskType: PeriodWeek, TimePeriod: straddling})
+		uc := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+		february := NewPeriodFromPeriodType(PeriodMonth, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+
+		tasks, err := uc.ListTaskByPeriod(ctx, ListTaskByPeriodParam{UserID: "u1", Period: february, GroupBy: PeriodWeek})
+		require.NoError(t, err)
+		assert.Empty(t, tasks, "默认只返回完全落在时间段内的任务")
+
+		tasks, err = uc.ListTaskByPeriod(ctx, ListTaskByPeriodParam{UserID: "u1", Period: february, GroupBy: PeriodWeek, Match: PeriodMatchIntersecting})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "w", tasks[0].ID)
+
+		_, err = uc.ListTaskByPeriod(ctx, ListTaskByPeriodParam{UserID: "u1", Period: february, GroupBy: PeriodWeek, Match: PeriodMatch(9)})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
 }
 
 // 测试 ListTaskParentTree 方法
@@ -0,0 +1,235 @@
+package biz
+
+import (
+	"context"
+	"time"
+)
+
+// TimeEntry 任务的工时记录：计时器开始后 EndedAt 为空，停止或手动补录后才计入任务耗时
+type TimeEntry struct {
+	ID        string     `json:"id"`
+	TaskID    string     `json:"task_id"`
+	UserID    string     `json:"user_id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Duration  int64      `json:"duration"` // 时长（秒），计时中的记录为0
+	Note      string     `json:"note"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// IsRunning 计时器是否仍在运行
+func (e *TimeEntry) IsRunning() bool {
+	return e.EndedAt == nil
+}
+
+// finish 结束计时并计算时长
+func (e *TimeEntry) finish(endedAt time.Time) {
+	e.EndedAt = &endedAt
+	e.Duration = int64(endedAt.Sub(e.StartedAt).Seconds())
+	e.UpdatedAt = time.Now()
+}
+
+// 开始计时参数
+type StartTimerParam struct {
+	TaskID string
+	UserID string
+	Note   string
+	// 已有计时器在运行时先停止它，否则返回 ErrTimerAlreadyRunning
+	StopRunning bool
+}
+
+// 停止计时参数
+type StopTimerParam struct {
+	UserID string
+	Note   *string // 不为空时覆盖开始计时时的备注
+}
+
+// 手动补录工时参数
+type AddTimeEntryParam struct {
+	TaskID    string
+	UserID    string
+	StartedAt time.Time
+	EndedAt   time.Time
+	Note      string
+}
+
+// 获取任务工时记录参数
+type ListTimeEntriesParam struct {
+	TaskID string
+	UserID string
+}
+
+// 删除工时记录参数
+type DeleteTimeEntryParam struct {
+	EntryID string
+	UserID  string
+}
+
+// 开始计时：每个用户同一时间只能有一个计时器在运行
+func (uc *TaskUsecase) StartTimer(ctx context.Context, param StartTimerParam) (*TimeEntry, error) {
+	if err := uc.checkTaskExists(ctx, param.TaskID, param.UserID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := &TimeEntry{
+		ID:        generateID(),
+		TaskID:    param.TaskID,
+		UserID:    param.UserID,
+		StartedAt: now,
+		Note:      param.Note,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// 检查前按用户加锁，同一用户并发开始的计时依次检查，后到的请求返回 ErrTimerAlreadyRunning
+	// 数据库上还有 (user_id) WHERE ended_at IS NULL 的唯一索引兜底
+	err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.LockUserTasks(ctx, param.UserID); err != nil {
+			return err
+		}
+		running, err := uc.repo.GetRunningTimeEntry(ctx, param.UserID)
+		if err != nil {
+			return err
+		}
+		if running != nil {
+			if !param.StopRunning {
+				return ErrTimerAlreadyRunning
+			}
+			if err := uc.finishTimeEntry(ctx, running, now); err != nil {
+				return err
+			}
+		}
+		return uc.repo.CreateTimeEntry(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// 停止当前用户正在运行的计时器
+func (uc *TaskUsecase) StopTimer(ctx context.Context, param StopTimerParam) (*TimeEntry, error) {
+	if param.UserID == "" {
+		return nil, ErrUserIDEmpty
+	}
+
+	var entry *TimeEntry
+	err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		entry, err = uc.repo.GetRunningTimeEntry(ctx, param.UserID)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			return ErrNoRunningTimer
+		}
+		if param.Note != nil {
+			entry.Note = *param.Note
+		}
+		return uc.finishTimeEntry(ctx, entry, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// 获取当前用户正在运行的计时器，没有时返回 nil
+func (uc *TaskUsecase) GetRunningTimer(ctx context.Context, userID string) (*TimeEntry, error) {
+	if userID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	return uc.repo.GetRunningTimeEntry(ctx, userID)
+}
+
+// 手动补录工时
+func (uc *TaskUsecase) AddTimeEntry(ctx context.Context, param AddTimeEntryParam) (*TimeEntry, error) {
+	if param.StartedAt.IsZero() || !param.EndedAt.After(param.StartedAt) {
+		return nil, ErrTimeEntryInvalid
+	}
+	if err := uc.checkTaskExists(ctx, param.TaskID, param.UserID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := &TimeEntry{
+		ID:        generateID(),
+		TaskID:    param.TaskID,
+		UserID:    param.UserID,
+		StartedAt: param.StartedAt,
+		Note:      param.Note,
+		CreatedAt: now,
+	}
+	entry.finish(param.EndedAt)
+
+	err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.CreateTimeEntry(ctx, entry); err != nil {
+			return err
+		}
+		return uc.repo.RefreshTaskTimeSpent(ctx, entry.TaskID, entry.UserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// 获取任务的工时记录（按开始时间倒序）
+func (uc *TaskUsecase) ListTimeEntries(ctx context.Context, param ListTimeEntriesParam) ([]*TimeEntry, error) {
+	if err := uc.checkTaskExists(ctx, param.TaskID, param.UserID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListTimeEntries(ctx, param.TaskID, param.UserID)
+}
+
+// 删除工时记录，任务耗时随之重算
+func (uc *TaskUsecase) DeleteTimeEntry(ctx context.Context, param DeleteTimeEntryParam) error {
+	if param.EntryID == "" || param.UserID == "" {
+		return ErrInvalidInput
+	}
+
+	entry, err := uc.repo.GetTimeEntry(ctx, param.EntryID, param.UserID)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return ErrTimeEntryNotFound
+	}
+
+	return uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.DeleteTimeEntry(ctx, entry.ID, entry.UserID); err != nil {
+			return err
+		}
+		return uc.repo.RefreshTaskTimeSpent(ctx, entry.TaskID, entry.UserID)
+	})
+}
+
+// finishTimeEntry 结束计时并重算任务耗时，需要在事务中调用
+func (uc *TaskUsecase) finishTimeEntry(ctx context.Context, entry *TimeEntry, endedAt time.Time) error {
+	entry.finish(endedAt)
+	if err := uc.repo.UpdateTimeEntry(ctx, entry); err != nil {
+		return err
+	}
+	return uc.repo.RefreshTaskTimeSpent(ctx, entry.TaskID, entry.UserID)
+}
+
+// refreshTreeTotals 任务树结构变化后重算相关根任务的整树耗时和整树估算
+// 不再是根任务的ID也可以传入，其整树统计会被清零
+func (uc *TaskUsecase) refreshTreeTotals(ctx context.Context, userID string, rootTaskIDs ...string) error {
+	refreshed := make(map[string]bool)
+	for _, rootTaskID := range rootTaskIDs {
+		if rootTaskID == "" || refreshed[rootTaskID] {
+			continue
+		}
+		refreshed[rootTaskID] = true
+		if err := uc.repo.RefreshTreeTimeSpent(ctx, rootTaskID, userID); err != nil {
+			return err
+		}
+		if err := uc.repo.RefreshTreeEstimate(ctx, rootTaskID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
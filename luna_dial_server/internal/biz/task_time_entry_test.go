@@ -0,0 +1,128 @@
+package biz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskUsecase_Timer(t *testing.T) {
+	ctx := context.Background()
+	// 根任务 root 下有子任务 child，另有一个独立任务 other
+	setup := func() (*TaskUsecase, *memoryTaskRepo) {
+		root := newTestTask("root", PeriodWeek, date(2025, 1, 6), TaskStatusNotStarted, nil)
+		repo := newMemoryTaskRepo(
+			root,
+			newTestTask("child", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, root),
+			newTestTask("other", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, nil),
+		)
+		return NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo()), repo
+	}
+
+	t.Run("开始和停止计时", func(t *testing.T) {
+		usecase, repo := setup()
+
+		entry, err := usecase.StartTimer(ctx, StartTimerParam{TaskID: "child", UserID: "user-123", Note: "写代码"})
+		require.NoError(t, err)
+		assert.True(t, entry.IsRunning())
+
+		// 模拟计时器已经运行了 30 分钟
+		repo.timeEntries[entry.ID].StartedAt = time.Now().Add(-30 * time.Minute)
+
+		note := "写完了"
+		stopped, err := usecase.StopTimer(ctx, StopTimerParam{UserID: "user-123", Note: &note})
+
+		require.NoError(t, err)
+		assert.False(t, stopped.IsRunning())
+		assert.Equal(t, "写完了", stopped.Note)
+		assert.InDelta(t, 1800, stopped.Duration, 2)
+		assert.InDelta(t, 1800, repo.tasks["child"].TimeSpent, 2)
+		assert.InDelta(t, 1800, repo.tasks["root"].TreeTimeSpent, 2, "time should roll up to the root task")
+	})
+
+	t.Run("每个用户只能有一个运行中的计时器", func(t *testing.T) {
+		usecase, repo := setup()
+		first, err := usecase.StartTimer(ctx, StartTimerParam{TaskID: "child", UserID: "user-123"})
+		require.NoError(t, err)
+
+		_, err = usecase.StartTimer(ctx, StartTimerParam{TaskID: "other", UserID: "user-123"})
+		assert.Equal(t, ErrTimerAlreadyRunning, err)
+
+		second, err := usecase.StartTimer(ctx, StartTimerParam{TaskID: "other", UserID: "user-123", StopRunning: true})
+		require.NoError(t, err)
+		assert.False(t, repo.timeEntries[first.ID].IsRunning(), "previous timer should be stopped")
+
+		running, err := usecase.GetRunningTimer(ctx, "user-123")
+		require.NoError(t, err)
+		assert.Equal(t, second.ID, running.ID)
+	})
+
+	t.Run("没有运行中的计时器", func(t *testing.T) {
+		usecase, _ := setup()
+		_, err := usecase.StopTimer(ctx, StopTimerParam{UserID: "user-123"})
+		assert.Equal(t, ErrNoRunningTimer, err)
+	})
+
+	t.Run("任务不存在", func(t *testing.T) {
+		usecase, _ := setup()
+		_, err := usecase.StartTimer(ctx, StartTimerParam{TaskID: "non-existent", UserID: "user-123"})
+		assert.Equal(t, ErrTaskNotFound, err)
+	})
+}
+
+func TestTaskUsecase_ManualTimeEntries(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+
+	root := newTestTask("root", PeriodWeek, date(2025, 1, 6), TaskStatusNotStarted, nil)
+	repo := newMemoryTaskRepo(root, newTestTask("child", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, root))
+	usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+	_, err := usecase.AddTimeEntry(ctx, AddTimeEntryParam{TaskID: "child", UserID: "user-123", StartedAt: start, EndedAt: start})
+	assert.Equal(t, ErrTimeEntryInvalid, err)
+
+	entry, err := usecase.AddTimeEntry(ctx, AddTimeEntryParam{TaskID: "child", UserID: "user-123", StartedAt: start, EndedAt: start.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	_, err = usecase.AddTimeEntry(ctx, AddTimeEntryParam{TaskID: "root", UserID: "user-123", StartedAt: start, EndedAt: start.Add(time.Hour)})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(7200), entry.Duration)
+	assert.Equal(t, int64(7200), repo.tasks["child"].TimeSpent)
+	assert.Equal(t, int64(3600), repo.tasks["root"].TimeSpent)
+	assert.Equal(t, int64(10800), repo.tasks["root"].TreeTimeSpent)
+
+	require.NoError(t, usecase.DeleteTimeEntry(ctx, DeleteTimeEntryParam{EntryID: entry.ID, UserID: "user-123"}))
+	assert.Equal(t, int64(0), repo.tasks["child"].TimeSpent)
+	assert.Equal(t, int64(3600), repo.tasks["root"].TreeTimeSpent)
+
+	err = usecase.DeleteTimeEntry(ctx, DeleteTimeEntryParam{EntryID: entry.ID, UserID: "user-123"})
+	assert.Equal(t, ErrTimeEntryNotFound, err)
+}
+
+func TestTaskUsecase_GetTaskStats_TimeSpent(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryTaskRepo(newTestTask("child", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, nil))
+	usecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+	for _, day := range []int{6, 7, 14} {
+		start := time.Date(2025, 1, day, 9, 0, 0, 0, time.UTC)
+		_, err := usecase.AddTimeEntry(ctx, AddTimeEntryParam{TaskID: "child", UserID: "user-123", StartedAt: start, EndedAt: start.Add(time.Hour)})
+		require.NoError(t, err)
+	}
+
+	stats, err := usecase.GetTaskStats(ctx, GetTaskStatsParam{
+		UserID:  "user-123",
+		Period:  Period{Start: date(2025, 1, 6), End: date(2025, 1, 20)},
+		GroupBy: PeriodWeek,
+	})
+
+	require.NoError(t, err)
+	timeByGroup := make(map[string]int64)
+	for _, stat := range stats {
+		timeByGroup[stat.GroupKey] = stat.TimeSpent
+	}
+	assert.Equal(t, map[string]int64{"2025-W02": 7200, "2025-W03": 3600}, timeByGroup)
+}
@@ -0,0 +1,70 @@
+package biz
+
+import "context"
+
+// DefaultMaxTreeDepth 默认的子任务最大深度（根任务深度为 0）
+const DefaultMaxTreeDepth = 10
+
+// 获取子树参数
+type GetTaskSubtreeParam struct {
+	UserID        string
+	TaskID        string
+	IncludeStatus []TaskStatus // 可选：指定要包含的状态，为空时包含所有状态
+}
+
+// SetMaxTreeDepth 设置子任务的最大深度，不大于 0 时使用 DefaultMaxTreeDepth
+func (uc *TaskUsecase) SetMaxTreeDepth(depth int) {
+	uc.maxTreeDepth = depth
+}
+
+// MaxTreeDepth 子任务的最大深度
+func (uc *TaskUsecase) MaxTreeDepth() int {
+	if uc.maxTreeDepth <= 0 {
+		return DefaultMaxTreeDepth
+	}
+	return uc.maxTreeDepth
+}
+
+// checkTreeDepth 新的或移动后的最深任务深度不能超过最大深度
+func (uc *TaskUsecase) checkTreeDepth(depth int) error {
+	if depth > uc.MaxTreeDepth() {
+		return ErrTaskTreeTooDeep
+	}
+	return nil
+}
+
+// subtreeHeight 子树最深的后代相对根节点的层数，只有根节点时为 0
+func subtreeHeight(node *Task) int {
+	height := 0
+	for _, child := range node.Children {
+		if h := subtreeHeight(child) + 1; h > height {
+			height = h
+		}
+	}
+	return height
+}
+
+// taskSubtreeHeight 任务子树的高度，任务不存在时为 0
+func (uc *TaskUsecase) taskSubtreeHeight(ctx context.Context, taskID, userID string) (int, error) {
+	roots, err := uc.repo.ListSubtreeTasks(ctx, taskID, userID, nil)
+	if err != nil || len(roots) == 0 {
+		return 0, err
+	}
+	return subtreeHeight(roots[0]), nil
+}
+
+// 获取任意层级任务的子树：任务自身及其所有后代，Children 逐层嵌套
+func (uc *TaskUsecase) GetTaskSubtree(ctx context.Context, param GetTaskSubtreeParam) (*Task, error) {
+	if param.UserID == "" || param.TaskID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	roots, err := uc.repo.ListSubtreeTasks(ctx, param.TaskID, param.UserID, param.IncludeStatus)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return nil, ErrTaskNotFound
+	}
+	return roots[0], nil
+}
@@ -0,0 +1,77 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskUsecase_MaxTreeDepth(t *testing.T) {
+	ctx := context.Background()
+	period := Period{Start: date(2025, 1, 1), End: date(2025, 2, 1)}
+	repo := newMemoryTaskRepo()
+	uc := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+	assert.Equal(t, DefaultMaxTreeDepth, uc.MaxTreeDepth())
+	uc.SetMaxTreeDepth(2)
+	assert.Equal(t, 2, uc.MaxTreeDepth())
+
+	root, err := uc.CreateTask(ctx, CreateTaskParam{UserID: "u1", Title: "root", Type: PeriodMonth, Period: period})
+	require.NoError(t, err)
+	child, err := uc.CreateSubTask(ctx, CreateSubTaskParam{UserID: "u1", ParentID: root.ID, Title: "a", Type: PeriodMonth, Period: period})
+	require.NoError(t, err)
+	grandchild, err := uc.CreateSubTask(ctx, CreateSubTaskParam{UserID: "u1", ParentID: child.ID, Title: "a1", Type: PeriodMonth, Period: period})
+	require.NoError(t, err)
+	assert.Equal(t, 2, grandchild.TreeDepth)
+
+	// 超过最大深度时不创建任务
+	_, err = uc.CreateSubTask(ctx, CreateSubTaskParam{UserID: "u1", ParentID: grandchild.ID, Title: "a11", Type: PeriodMonth, Period: period})
+	assert.ErrorIs(t, err, ErrTaskTreeTooDeep)
+	assert.Len(t, repo.tasks, 3)
+
+	// 移动时按子树中最深的任务检查
+	other, err := uc.CreateTask(ctx, CreateTaskParam{UserID: "u1", Title: "other", Type: PeriodMonth, Period: period})
+	require.NoError(t, err)
+	_, err = uc.MoveTask(ctx, MoveTaskParam{TaskID: child.ID, UserID: "u1", NewParentID: other.ID})
+	require.NoError(t, err)
+	otherChild, err := uc.CreateTask(ctx, CreateTaskParam{UserID: "u1", Title: "b", Type: PeriodMonth, Period: period})
+	require.NoError(t, err)
+	_, err = uc.MoveTask(ctx, MoveTaskParam{TaskID: otherChild.ID, UserID: "u1", NewParentID: grandchild.ID})
+	assert.ErrorIs(t, err, ErrTaskTreeTooDeep)
+	_, err = uc.MoveTask(ctx, MoveTaskParam{TaskID: other.ID, UserID: "u1", NewParentID: root.ID})
+	assert.ErrorIs(t, err, ErrTaskTreeTooDeep)
+	assert.Empty(t, repo.tasks[other.ID].ParentID)
+}
+
+func TestTaskUsecase_GetTaskSubtree(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryTaskRepo(
+		&Task{ID: "r", UserID: "u1"},
+		&Task{ID: "a", UserID: "u1", ParentID: "r", RootTaskID: "r", TreeDepth: 1},
+		&Task{ID: "a1", UserID: "u1", ParentID: "a", RootTaskID: "r", TreeDepth: 2, Status: TaskStatusCompleted},
+		&Task{ID: "a2", UserID: "u1", ParentID: "a", RootTaskID: "r", TreeDepth: 2},
+		&Task{ID: "a21", UserID: "u1", ParentID: "a2", RootTaskID: "r", TreeDepth: 3},
+		&Task{ID: "b", UserID: "u1", ParentID: "r", RootTaskID: "r", TreeDepth: 1},
+	)
+	uc := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+
+	// 中间层级的任务只返回自身及其后代
+	subtree, err := uc.GetTaskSubtree(ctx, GetTaskSubtreeParam{UserID: "u1", TaskID: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, "a", subtree.ID)
+	require.Len(t, subtree.Children, 2)
+	assert.Equal(t, "a1", subtree.Children[0].ID)
+	assert.Equal(t, "a21", subtree.Children[1].Children[0].ID)
+
+	// 状态过滤不作用于请求的任务自身
+	subtree, err = uc.GetTaskSubtree(ctx, GetTaskSubtreeParam{UserID: "u1", TaskID: "a", IncludeStatus: []TaskStatus{TaskStatusCompleted}})
+	require.NoError(t, err)
+	require.Len(t, subtree.Children, 1)
+	assert.Equal(t, "a1", subtree.Children[0].ID)
+
+	_, err = uc.GetTaskSubtree(ctx, GetTaskSubtreeParam{UserID: "u2", TaskID: "a"})
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	_, err = uc.GetTaskSubtree(ctx, GetTaskSubtreeParam{UserID: "u1"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
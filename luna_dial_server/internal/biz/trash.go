@@ -0,0 +1,226 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"luna_dial/internal/model"
+)
+
+// Trash 回收站内容：删除的任务（只列出被删除子树的根）和日志
+type Trash struct {
+	Tasks    []*Task    `json:"tasks"`
+	Journals []*Journal `json:"journals"`
+}
+
+// 恢复任务参数
+type RestoreTaskParam struct {
+	TaskID string
+	UserID string
+}
+
+// 恢复日志参数
+type RestoreJournalParam struct {
+	JournalID string
+	UserID    string
+}
+
+// TrashUsecase 回收站：汇总任务和日志的软删除数据
+type TrashUsecase struct {
+	taskUsecase    *TaskUsecase
+	journalUsecase *JournalUsecase
+}
+
+func NewTrashUsecase(taskUsecase *TaskUsecase, journalUsecase *JournalUsecase) *TrashUsecase {
+	return &TrashUsecase{
+		taskUsecase:    taskUsecase,
+		journalUsecase: journalUsecase,
+	}
+}
+
+// 获取用户的回收站
+func (uc *TrashUsecase) ListTrash(ctx context.Context, userID string) (*Trash, error) {
+	if userID == "" {
+		return nil, ErrUserIDEmpty
+	}
+
+	tasks, err := uc.taskUsecase.ListTrashedTasks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	journals, err := uc.journalUsecase.ListTrashedJournals(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &Trash{Tasks: tasks, Journals: journals}, nil
+}
+
+// PurgeExpired 永久删除在 before 之前进入回收站的任务和日志（所有用户）
+func (uc *TrashUsecase) PurgeExpired(ctx context.Context, before time.Time) (int64, int64, error) {
+	tasks, err := uc.taskUsecase.repo.PurgeTrashedTasks(ctx, before)
+	if err != nil {
+		return 0, 0, err
+	}
+	journals, err := uc.journalUsecase.repo.PurgeTrashedJournals(ctx, before)
+	if err != nil {
+		return tasks, 0, err
+	}
+	return tasks, journals, nil
+}
+
+// 获取回收站中的任务
+// 级联删除的子树只返回其根任务，同一时刻随之删除的后代会在恢复时一起恢复
+func (uc *TaskUsecase) ListTrashedTasks(ctx context.Context, userID string) ([]*Task, error) {
+	if userID == "" {
+		return nil, ErrUserIDEmpty
+	}
+
+	trashed, err := uc.repo.ListTrashedTasks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trashedMap := make(map[string]*Task, len(trashed))
+	for _, task := range trashed {
+		trashedMap[task.ID] = task
+	}
+	roots := make([]*Task, 0, len(trashed))
+	for _, task := range trashed {
+		parent := trashedMap[task.ParentID]
+		if parent != nil && parent.DeletedAt != nil && task.DeletedAt != nil && parent.DeletedAt.Equal(*task.DeletedAt) {
+			continue // 随父任务一起删除
+		}
+		roots = append(roots, task)
+	}
+	return roots, nil
+}
+
+// 从回收站恢复任务，同一时刻随之删除的后代一起恢复
+// 原父任务已不存在（仍在回收站或已被清理），或按 MoveTask 的规则已无法容纳该子树（类型、时间段或深度）时，恢复为根任务
+// 恢复后重建子树的树优化字段，并维护父任务和根任务的统计字段
+func (uc *TaskUsecase) RestoreTask(ctx context.Context, param RestoreTaskParam) (*Task, error) {
+	if param.TaskID == "" || param.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	task, err := uc.repo.GetTrashedTask(ctx, param.TaskID, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	before := *task
+	err = uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.RestoreTaskSubtree(ctx, task.ID, task.UserID); err != nil {
+			return err
+		}
+
+		if task.ParentID != "" {
+			parent, err := uc.repo.GetTask(ctx, task.ParentID, task.UserID)
+			if err != nil {
+				return err
+			}
+			attach, err := uc.canRestoreUnder(ctx, task, parent)
+			if err != nil {
+				return err
+			}
+			if !attach {
+				task.ParentID = ""
+				task.DeletedAt = nil
+				task.UpdatedAt = time.Now()
+				if err := uc.repo.UpdateTask(ctx, task); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := uc.repo.RebuildSubtreeOptimizationFields(ctx, task.ID, task.UserID); err != nil {
+			return err
+		}
+		if task.ParentID != "" {
+			if err := uc.repo.UpdateTreeOptimizationFields(ctx, task.ParentID, task.UserID); err != nil {
+				return err
+			}
+			if err := uc.refreshRollupFrom(ctx, task.ParentID, task.UserID); err != nil {
+				return err
+			}
+		}
+
+		restored, err := uc.repo.GetTask(ctx, task.ID, task.UserID)
+		if err != nil {
+			return err
+		}
+		if restored == nil {
+			return ErrTaskNotFound
+		}
+		task = restored
+		if err := uc.recordTaskChange(ctx, ChangeActionRestore, &before, task); err != nil {
+			return err
+		}
+		return uc.refreshTreeTotals(ctx, task.UserID, task.RootTaskID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// canRestoreUnder 恢复的子树能否挂回原父任务：父任务存在，且满足与 MoveTask 相同的类型、时间段和深度限制
+// 删除后父任务可能修改了类型或时间段，或者被移动到了更深的位置；需要在子树恢复后调用
+func (uc *TaskUsecase) canRestoreUnder(ctx context.Context, task, parent *Task) (bool, error) {
+	if parent == nil {
+		return false, nil
+	}
+	if err := validateSubTaskPlacement(parent, task.TaskType, task.TimePeriod); err != nil {
+		return false, nil
+	}
+	height, err := uc.taskSubtreeHeight(ctx, task.ID, task.UserID)
+	if err != nil {
+		return false, err
+	}
+	return uc.checkTreeDepth(parent.TreeDepth+1+height) == nil, nil
+}
+
+// 获取回收站中的日志
+func (uc *JournalUsecase) ListTrashedJournals(ctx context.Context, userID string) ([]*Journal, error) {
+	if userID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	return uc.repo.ListTrashedJournals(ctx, userID)
+}
+
+// 从回收站恢复日志
+func (uc *JournalUsecase) RestoreJournal(ctx context.Context, param RestoreJournalParam) (*Journal, error) {
+	if param.JournalID == "" || param.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	var journal *Journal
+	err := uc.repo.Transaction(ctx, func(ctx context.Context) error {
+		trashed, err := uc.repo.GetTrashedJournal(ctx, param.JournalID, param.UserID)
+		if err != nil {
+			if errors.Is(err, model.ErrRecordNotFound) {
+				return ErrJournalNotFound
+			}
+			return err
+		}
+		if err := uc.repo.RestoreJournal(ctx, param.JournalID, param.UserID); err != nil {
+			if errors.Is(err, model.ErrRecordNotFound) {
+				return ErrJournalNotFound
+			}
+			return err
+		}
+		journal, err = uc.GetJournal(ctx, GetJournalParam(param))
+		if err != nil {
+			return err
+		}
+		return uc.recordJournalChange(ctx, ChangeActionRestore, trashed, journal)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
@@ -0,0 +1,70 @@
+package biz
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// TrashPurger 后台定时永久删除在回收站中超过保留期的任务和日志
+type TrashPurger struct {
+	usecase   *TrashUsecase
+	retention time.Duration
+	interval  time.Duration
+
+	// 清理协程控制
+	stopPurge chan bool
+	purgeDone chan bool
+}
+
+// NewTrashPurger 创建回收站清理器
+func NewTrashPurger(usecase *TrashUsecase, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{
+		usecase:   usecase,
+		retention: retention,
+		interval:  interval,
+		stopPurge: make(chan bool),
+		purgeDone: make(chan bool),
+	}
+}
+
+// Start 启动清理协程，启动时立即执行一次
+func (p *TrashPurger) Start() {
+	go p.purgeWorker()
+}
+
+// Stop 停止清理协程，等待当前这一轮执行完成
+func (p *TrashPurger) Stop() {
+	p.stopPurge <- true
+	<-p.purgeDone
+}
+
+// purgeWorker 清理工作协程
+func (p *TrashPurger) purgeWorker() {
+	p.runOnce()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.runOnce()
+		case <-p.stopPurge:
+			p.purgeDone <- true
+			return
+		}
+	}
+}
+
+func (p *TrashPurger) runOnce() {
+	tasks, journals, err := p.usecase.PurgeExpired(context.Background(), time.Now().Add(-p.retention))
+	if err != nil {
+		log.Errorf("Failed to purge trash: %v", err)
+		return
+	}
+	if tasks > 0 || journals > 0 {
+		log.Infof("Purged %d tasks and %d journals from trash", tasks, journals)
+	}
+}
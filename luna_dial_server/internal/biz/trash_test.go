@@ -0,0 +1,145 @@
+package biz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func trashedTaskIDs(tasks []*Task) []string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func TestTaskUsecase_TrashAndRestore(t *testing.T) {
+	ctx := context.Background()
+	// 年任务 year -> 月任务 month -> 日任务 day
+	setup := func() (*TaskUsecase, *memoryTaskRepo) {
+		year := newTestTask("year", PeriodYear, date(2025, 1, 1), TaskStatusNotStarted, nil)
+		month := newTestTask("month", PeriodMonth, date(2025, 1, 1), TaskStatusNotStarted, year)
+		repo := newMemoryTaskRepo(year, month, newTestTask("day", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, month))
+		return NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo()), repo
+	}
+
+	t.Run("级联删除后整棵子树一起恢复", func(t *testing.T) {
+		usecase, repo := setup()
+		require.NoError(t, usecase.DeleteTask(ctx, DeleteTaskParam{TaskID: "month", UserID: "user-123"}))
+
+		trashed, err := usecase.ListTrashedTasks(ctx, "user-123")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"month"}, trashedTaskIDs(trashed), "descendants deleted together are hidden behind their root")
+		assert.Equal(t, 0, repo.tasks["year"].ChildrenCount)
+
+		restored, err := usecase.RestoreTask(ctx, RestoreTaskParam{TaskID: "month", UserID: "user-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "year", restored.ParentID)
+		assert.Equal(t, 1, restored.TreeDepth)
+		assert.Contains(t, repo.tasks, "day")
+		assert.Equal(t, 2, repo.tasks["day"].TreeDepth)
+		assert.Equal(t, 1, repo.tasks["year"].ChildrenCount)
+		assert.Empty(t, repo.trashed)
+	})
+
+	t.Run("之前单独删除的后代不随之恢复", func(t *testing.T) {
+		usecase, repo := setup()
+		require.NoError(t, usecase.DeleteTask(ctx, DeleteTaskParam{TaskID: "day", UserID: "user-123"}))
+		earlier := time.Now().Add(-time.Hour)
+		repo.trashed["day"].DeletedAt = &earlier
+		require.NoError(t, usecase.DeleteTask(ctx, DeleteTaskParam{TaskID: "month", UserID: "user-123"}))
+
+		trashed, err := usecase.ListTrashedTasks(ctx, "user-123")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"month", "day"}, trashedTaskIDs(trashed))
+
+		_, err = usecase.RestoreTask(ctx, RestoreTaskParam{TaskID: "month", UserID: "user-123"})
+
+		require.NoError(t, err)
+		assert.Contains(t, repo.trashed, "day")
+		assert.False(t, repo.tasks["month"].HasChildren)
+	})
+
+	t.Run("父任务仍在回收站时恢复为根任务", func(t *testing.T) {
+		usecase, repo := setup()
+		require.NoError(t, usecase.DeleteTask(ctx, DeleteTaskParam{TaskID: "year", UserID: "user-123"}))
+
+		restored, err := usecase.RestoreTask(ctx, RestoreTaskParam{TaskID: "day", UserID: "user-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "", restored.ParentID)
+		assert.Equal(t, "day", restored.RootTaskID)
+		assert.Equal(t, 0, restored.TreeDepth)
+		assert.Contains(t, repo.trashed, "year")
+	})
+
+	t.Run("父任务的时间段已修改时恢复为根任务", func(t *testing.T) {
+		usecase, repo := setup()
+		require.NoError(t, usecase.DeleteTask(ctx, DeleteTaskParam{TaskID: "month", UserID: "user-123"}))
+		repo.tasks["year"].TimePeriod = NewPeriodFromPeriodType(PeriodYear, date(2026, 1, 1))
+
+		restored, err := usecase.RestoreTask(ctx, RestoreTaskParam{TaskID: "month", UserID: "user-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "", restored.ParentID)
+		assert.Equal(t, "month", restored.RootTaskID)
+		assert.Equal(t, "month", repo.tasks["day"].RootTaskID)
+		assert.Equal(t, 1, repo.tasks["day"].TreeDepth)
+		assert.Equal(t, 0, repo.tasks["year"].ChildrenCount)
+	})
+
+	t.Run("挂回后超过最大深度时恢复为根任务", func(t *testing.T) {
+		usecase, repo := setup()
+		require.NoError(t, usecase.DeleteTask(ctx, DeleteTaskParam{TaskID: "month", UserID: "user-123"}))
+		usecase.SetMaxTreeDepth(1) // day 挂回后深度为 2
+
+		restored, err := usecase.RestoreTask(ctx, RestoreTaskParam{TaskID: "month", UserID: "user-123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "", restored.ParentID)
+		assert.Equal(t, 1, repo.tasks["day"].TreeDepth)
+		assert.False(t, repo.tasks["year"].HasChildren)
+	})
+
+	t.Run("回收站中不存在", func(t *testing.T) {
+		usecase, _ := setup()
+		_, err := usecase.RestoreTask(ctx, RestoreTaskParam{TaskID: "year", UserID: "user-123"})
+		assert.Equal(t, ErrTaskNotFound, err)
+	})
+}
+
+func TestTrashUsecase_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	month := newTestTask("month", PeriodMonth, date(2025, 1, 1), TaskStatusNotStarted, nil)
+	repo := newMemoryTaskRepo(month, newTestTask("day", PeriodDay, date(2025, 1, 6), TaskStatusNotStarted, month))
+	taskUsecase := NewTaskUsecase(repo, newMockUserSettingsRepo(), newMockChangeHistoryRepo())
+	trashUsecase := NewTrashUsecase(taskUsecase, NewJournalUsecase(&mockJournalRepo{}, newMockChangeHistoryRepo()))
+	require.NoError(t, taskUsecase.DeleteTask(ctx, DeleteTaskParam{TaskID: "day", UserID: "user-123"}))
+	require.NoError(t, taskUsecase.DeleteTask(ctx, DeleteTaskParam{TaskID: "month", UserID: "user-123"}))
+	expired := time.Now().Add(-31 * 24 * time.Hour)
+	repo.trashed["day"].DeletedAt = &expired
+
+	tasks, _, err := trashUsecase.PurgeExpired(ctx, time.Now().Add(-30*24*time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tasks)
+	assert.NotContains(t, repo.trashed, "day")
+	assert.Contains(t, repo.trashed, "month")
+}
+
+func TestJournalUsecase_RestoreJournal(t *testing.T) {
+	ctx := context.Background()
+	usecase := NewJournalUsecase(&mockJournalRepo{}, newMockChangeHistoryRepo())
+
+	journal, err := usecase.RestoreJournal(ctx, RestoreJournalParam{JournalID: TestJournalID123, UserID: TestUserID123})
+	require.NoError(t, err)
+	assert.Equal(t, TestJournalID123, journal.ID)
+
+	_, err = usecase.RestoreJournal(ctx, RestoreJournalParam{JournalID: TestJournalIDNonExistent, UserID: TestUserID123})
+	assert.Equal(t, ErrJournalNotFound, err)
+}
@@ -0,0 +1,144 @@
+package biz
+
+import (
+	"context"
+	"time"
+)
+
+// CompletionPropagationMode 子任务全部完成后父任务的处理方式
+type CompletionPropagationMode int
+
+const (
+	CompletionPropagationAuto CompletionPropagationMode = iota // 自动完成父任务（默认）
+	CompletionPropagationFlag                                  // 仅标记父任务为"可完成"，由用户手动确认
+)
+
+// UserSettings 用户个性化设置
+type UserSettings struct {
+	UserID                string                    `json:"user_id"`
+	CompletionPropagation CompletionPropagationMode `json:"completion_propagation"`
+	AutoRollover          RolloverMode              `json:"auto_rollover"` // 每天自动顺延上一天、上一周未完成任务的方式，默认不顺延
+	// 工作量估算的单位，以及每天、每周的容量（与估算同单位，0 表示不检查）
+	EstimateUnit   EstimateUnit `json:"estimate_unit"`
+	DailyCapacity  int          `json:"daily_capacity"`
+	WeeklyCapacity int          `json:"weekly_capacity"`
+	// 任务看板每一列的在制品上限
+	WIPLimits WIPLimits `json:"wip_limits"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// 更新用户设置参数
+type UpdateUserSettingsParam struct {
+	UserID                string
+	CompletionPropagation *CompletionPropagationMode
+	AutoRollover          *RolloverMode
+	EstimateUnit          *EstimateUnit
+	DailyCapacity         *int
+	WeeklyCapacity        *int
+	WIPLimits             map[TaskStatus]int // 只修改传递了的列，0 表示不限制
+}
+
+type UserSettingsUsecase struct {
+	repo UserSettingsRepo
+}
+
+func NewUserSettingsUsecase(repo UserSettingsRepo) *UserSettingsUsecase {
+	return &UserSettingsUsecase{repo: repo}
+}
+
+// DefaultUserSettings 用户尚未保存过设置时使用的默认值
+func DefaultUserSettings(userID string) *UserSettings {
+	return &UserSettings{
+		UserID:                userID,
+		CompletionPropagation: CompletionPropagationAuto,
+	}
+}
+
+// 获取用户设置，未保存过时返回默认设置
+func (uc *UserSettingsUsecase) GetUserSettings(ctx context.Context, userID string) (*UserSettings, error) {
+	if userID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	return loadUserSettings(ctx, uc.repo, userID)
+}
+
+// 更新用户设置（只更新传递了的字段）
+func (uc *UserSettingsUsecase) UpdateUserSettings(ctx context.Context, param UpdateUserSettingsParam) (*UserSettings, error) {
+	if param.UserID == "" {
+		return nil, ErrUserIDEmpty
+	}
+	if param.CompletionPropagation != nil &&
+		*param.CompletionPropagation != CompletionPropagationAuto &&
+		*param.CompletionPropagation != CompletionPropagationFlag {
+		return nil, ErrInvalidInput
+	}
+	if param.AutoRollover != nil &&
+		(*param.AutoRollover < RolloverModeOff || *param.AutoRollover > RolloverModeClone) {
+		return nil, ErrRolloverModeInvalid
+	}
+	if param.EstimateUnit != nil && !param.EstimateUnit.IsValid() {
+		return nil, ErrInvalidInput
+	}
+	if (param.DailyCapacity != nil && *param.DailyCapacity < 0) ||
+		(param.WeeklyCapacity != nil && *param.WeeklyCapacity < 0) {
+		return nil, ErrInvalidInput // 容量不能为负数
+	}
+	for status, limit := range param.WIPLimits {
+		if limit < 0 || status < TaskStatusNotStarted || status > TaskStatusCancelled {
+			return nil, ErrInvalidInput // 在制品上限不能为负数
+		}
+	}
+
+	settings, err := loadUserSettings(ctx, uc.repo, param.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if param.CompletionPropagation != nil {
+		settings.CompletionPropagation = *param.CompletionPropagation
+	}
+	if param.AutoRollover != nil {
+		settings.AutoRollover = *param.AutoRollover
+	}
+	if param.EstimateUnit != nil {
+		settings.EstimateUnit = *param.EstimateUnit
+	}
+	if param.DailyCapacity != nil {
+		settings.DailyCapacity = *param.DailyCapacity
+	}
+	if param.WeeklyCapacity != nil {
+		settings.WeeklyCapacity = *param.WeeklyCapacity
+	}
+	for status, limit := range param.WIPLimits {
+		settings.WIPLimits.set(status, limit)
+	}
+
+	now := time.Now()
+	if settings.CreatedAt.IsZero() {
+		settings.CreatedAt = now
+	}
+	settings.UpdatedAt = now
+
+	if err := uc.repo.SaveUserSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// loadUserSettings 读取用户设置，没有记录时返回默认设置
+// 其他用例（如任务完成状态传播）也通过它读取设置
+func loadUserSettings(ctx context.Context, repo UserSettingsRepo, userID string) (*UserSettings, error) {
+	if repo == nil {
+		return DefaultUserSettings(userID), nil
+	}
+	settings, err := repo.GetUserSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return DefaultUserSettings(userID), nil
+	}
+	return settings, nil
+}
@@ -0,0 +1,12 @@
+package biz
+
+import "context"
+
+type UserSettingsRepo interface {
+	// GetUserSettings 获取用户设置，没有记录时返回 nil, nil
+	GetUserSettings(ctx context.Context, userID string) (*UserSettings, error)
+	// SaveUserSettings 保存用户设置（不存在时插入）
+	SaveUserSettings(ctx context.Context, settings *UserSettings) error
+	// ListAutoRolloverSettings 获取开启了自动顺延的用户设置
+	ListAutoRolloverSettings(ctx context.Context) ([]*UserSettings, error)
+}
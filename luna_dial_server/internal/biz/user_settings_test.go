@@ -0,0 +1,97 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock UserSettingsRepo 实现（内存存储）
+type mockUserSettingsRepo struct {
+	settings map[string]*UserSettings
+}
+
+func newMockUserSettingsRepo() *mockUserSettingsRepo {
+	return &mockUserSettingsRepo{settings: make(map[string]*UserSettings)}
+}
+
+func (m *mockUserSettingsRepo) GetUserSettings(ctx context.Context, userID string) (*UserSettings, error) {
+	if settings, ok := m.settings[userID]; ok {
+		copied := *settings
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockUserSettingsRepo) SaveUserSettings(ctx context.Context, settings *UserSettings) error {
+	copied := *settings
+	m.settings[settings.UserID] = &copied
+	return nil
+}
+
+func (m *mockUserSettingsRepo) ListAutoRolloverSettings(ctx context.Context) ([]*UserSettings, error) {
+	result := make([]*UserSettings, 0)
+	for _, settings := range m.settings {
+		if settings.AutoRollover != RolloverModeOff {
+			copied := *settings
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func TestUserSettingsUsecase_GetUserSettings(t *testing.T) {
+	usecase := NewUserSettingsUsecase(newMockUserSettingsRepo())
+	ctx := context.Background()
+
+	t.Run("未保存过设置时返回默认值", func(t *testing.T) {
+		settings, err := usecase.GetUserSettings(ctx, "user-123")
+
+		require.NoError(t, err)
+		require.NotNil(t, settings)
+		assert.Equal(t, "user-123", settings.UserID)
+		assert.Equal(t, CompletionPropagationAuto, settings.CompletionPropagation)
+	})
+
+	t.Run("空用户ID", func(t *testing.T) {
+		settings, err := usecase.GetUserSettings(ctx, "")
+
+		assert.Nil(t, settings)
+		assert.Equal(t, ErrUserIDEmpty, err)
+	})
+}
+
+func TestUserSettingsUsecase_UpdateUserSettings(t *testing.T) {
+	repo := newMockUserSettingsRepo()
+	usecase := NewUserSettingsUsecase(repo)
+	ctx := context.Background()
+
+	t.Run("成功更新完成传播方式", func(t *testing.T) {
+		mode := CompletionPropagationFlag
+		settings, err := usecase.UpdateUserSettings(ctx, UpdateUserSettingsParam{
+			UserID:                "user-123",
+			CompletionPropagation: &mode,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, CompletionPropagationFlag, settings.CompletionPropagation)
+		assert.False(t, settings.UpdatedAt.IsZero())
+
+		saved, err := usecase.GetUserSettings(ctx, "user-123")
+		require.NoError(t, err)
+		assert.Equal(t, CompletionPropagationFlag, saved.CompletionPropagation, "settings should be persisted")
+	})
+
+	t.Run("无效的完成传播方式", func(t *testing.T) {
+		mode := CompletionPropagationMode(99)
+		settings, err := usecase.UpdateUserSettings(ctx, UpdateUserSettingsParam{
+			UserID:                "user-123",
+			CompletionPropagation: &mode,
+		})
+
+		assert.Nil(t, settings)
+		assert.Equal(t, ErrInvalidInput, err)
+	})
+}
@@ -25,12 +25,31 @@ type LogConfig struct {
 	Level string `ini:"level"`
 }
 
+// 回收站配置：删除的任务和日志保留 RetentionDays 天后永久删除
+type TrashConfig struct {
+	RetentionDays     int `ini:"retention_days"`
+	PurgeIntervalHour int `ini:"purge_interval_hour"` // 后台清理的执行间隔（小时）
+}
+
+// 任务配置
+type TaskConfig struct {
+	MaxTreeDepth int `ini:"max_tree_depth"` // 子任务的最大深度（根任务深度为 0），不大于 0 时使用默认值
+}
+
 type Config struct {
 	Server   ServerConfig   `ini:"server"`
 	Database DatabaseConfig `ini:"database"`
 	Log      LogConfig      `ini:"log"`
+	Trash    TrashConfig    `ini:"trash"`
+	Task     TaskConfig     `ini:"task"`
 }
 
+// 回收站默认配置，配置文件中没有 [trash] 时使用
+const (
+	DefaultTrashRetentionDays     = 30
+	DefaultTrashPurgeIntervalHour = 24
+)
+
 var Cfg *Config
 
 func InitConfig(configPath string) {
@@ -47,7 +66,12 @@ func InitConfig(configPath string) {
 		log.Fatalf("Fail to read config file %s: %v", configPath, err)
 	}
 
-	Cfg = &Config{}
+	Cfg = &Config{
+		Trash: TrashConfig{
+			RetentionDays:     DefaultTrashRetentionDays,
+			PurgeIntervalHour: DefaultTrashPurgeIntervalHour,
+		},
+	}
 	err = cfg.MapTo(Cfg)
 	if err != nil {
 		log.Fatalf("Fail to map config: %v", err)
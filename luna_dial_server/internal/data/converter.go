@@ -1,10 +1,18 @@
 package data
 
 import (
+	"encoding/json"
 	"luna_dial/internal/biz"
+	"strconv"
 	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// TaskTagsSeparator tasks.tags 冗余文本中标签名的分隔符
+const TaskTagsSeparator = "\n"
+
 // TaskConverter 任务数据转换器
 type TaskConverter struct{}
 
@@ -22,6 +30,7 @@ func (c *TaskConverter) BizToData(bizTask *biz.Task) *Task {
 		ID:          bizTask.ID,
 		UserID:      bizTask.UserID,
 		Title:       bizTask.Title,
+		Description: bizTask.Description,
 		TaskType:    int(bizTask.TaskType),
 		PeriodStart: bizTask.TimePeriod.Start,
 		PeriodEnd:   bizTask.TimePeriod.End,
@@ -32,6 +41,25 @@ func (c *TaskConverter) BizToData(bizTask *biz.Task) *Task {
 		Icon:        bizTask.Icon,
 		CreatedAt:   bizTask.CreatedAt,
 		UpdatedAt:   bizTask.UpdatedAt,
+
+		ReadyToComplete: bizTask.ReadyToComplete,
+		ChecklistTotal:  bizTask.ChecklistTotal,
+		ChecklistDone:   bizTask.ChecklistDone,
+
+		StartedAt:    bizTask.StartedAt,
+		CompletedAt:  bizTask.CompletedAt,
+		CancelledAt:  bizTask.CancelledAt,
+		CancelReason: bizTask.CancelReason,
+
+		CarriedOverFromID: bizTask.CarriedOverFromID,
+		CarryOverCount:    bizTask.CarryOverCount,
+
+		Estimate:  bizTask.Estimate,
+		BoardRank: bizTask.BoardRank,
+
+		ScoreRollup:         int(bizTask.ScoreRollup),
+		RollupCompletedOnly: bizTask.RollupCompletedOnly,
+		Weight:              bizTask.Weight,
 		
 		// 新增：树结构优化字段转换
 		// 这些字段直接从业务层同步到数据层，确保数据一致性
@@ -39,11 +67,22 @@ func (c *TaskConverter) BizToData(bizTask *biz.Task) *Task {
 		ChildrenCount: bizTask.ChildrenCount,
 		RootTaskID:    bizTask.RootTaskID,
 		TreeDepth:     bizTask.TreeDepth,
+		SortRank:      bizTask.SortRank,
+	}
+	if bizTask.DeletedAt != nil {
+		dataTask.DeletedAt = gorm.DeletedAt{Time: *bizTask.DeletedAt, Valid: true}
+	}
+	if kr := bizTask.KeyResult; kr != nil {
+		dataTask.IsKeyResult = true
+		dataTask.KeyResultStart = kr.StartValue
+		dataTask.KeyResultTarget = kr.TargetValue
+		dataTask.KeyResultCurrent = kr.CurrentValue
+		dataTask.KeyResultUnit = kr.Unit
 	}
 
-	// 处理Tags数组转逗号分隔字符串
+	// 处理Tags数组转换行分隔的冗余文本（标签名可以包含逗号，不能包含换行）
 	if len(bizTask.Tags) > 0 {
-		dataTask.Tags = strings.Join(bizTask.Tags, ",")
+		dataTask.Tags = strings.Join(bizTask.Tags, TaskTagsSeparator)
 	}
 
 	return dataTask
@@ -58,8 +97,9 @@ func (c *TaskConverter) DataToBiz(dataTask *Task) *biz.Task {
 	bizTask := &biz.Task{
 		ID:       dataTask.ID,
 		UserID:   dataTask.UserID,
-		Title:    dataTask.Title,
-		TaskType: biz.PeriodType(dataTask.TaskType),
+		Title:       dataTask.Title,
+		Description: dataTask.Description,
+		TaskType:    biz.PeriodType(dataTask.TaskType),
 		TimePeriod: biz.Period{
 			Start: dataTask.PeriodStart,
 			End:   dataTask.PeriodEnd,
@@ -71,6 +111,31 @@ func (c *TaskConverter) DataToBiz(dataTask *Task) *biz.Task {
 		Icon:        dataTask.Icon,
 		CreatedAt:   dataTask.CreatedAt,
 		UpdatedAt:   dataTask.UpdatedAt,
+
+		ReadyToComplete: dataTask.ReadyToComplete,
+		ChecklistTotal:  dataTask.ChecklistTotal,
+		ChecklistDone:   dataTask.ChecklistDone,
+		ChecklistRatio:  biz.CalcChecklistRatio(dataTask.ChecklistDone, dataTask.ChecklistTotal),
+		TimeSpent:       dataTask.TimeSpent,
+		TreeTimeSpent:   dataTask.TreeTimeSpent,
+		Estimate:        dataTask.Estimate,
+		TreeEstimate:    dataTask.TreeEstimate,
+		BoardRank:       dataTask.BoardRank,
+		DeletedAt:       deletedAtToBiz(dataTask.DeletedAt),
+
+		StartedAt:    dataTask.StartedAt,
+		CompletedAt:  dataTask.CompletedAt,
+		CancelledAt:  dataTask.CancelledAt,
+		CancelReason: dataTask.CancelReason,
+
+		CarriedOverFromID: dataTask.CarriedOverFromID,
+		CarryOverCount:    dataTask.CarryOverCount,
+
+		ScoreRollup:         biz.ScoreRollupMode(dataTask.ScoreRollup),
+		RollupCompletedOnly: dataTask.RollupCompletedOnly,
+		Weight:              dataTask.Weight,
+		RollupScore:         dataTask.RollupScore,
+		Progress:            dataTask.Progress,
 		
 		// 新增：树结构优化字段转换
 		// 从数据库字段同步到业务层，为后续树构建提供基础数据
@@ -78,15 +143,16 @@ func (c *TaskConverter) DataToBiz(dataTask *Task) *biz.Task {
 		ChildrenCount: dataTask.ChildrenCount,
 		RootTaskID:    dataTask.RootTaskID,
 		TreeDepth:     dataTask.TreeDepth,
+		SortRank:      dataTask.SortRank,
 		
 		// Children字段在这里初始化为空切片，由上层业务逻辑负责构建树结构
 		// 设计思路：转换器只负责基础数据转换，树关系构建由专门的业务方法处理
 		Children: make([]*biz.Task, 0),
 	}
 
-	// 处理Tags逗号分隔字符串转数组
+	// 处理Tags换行分隔的冗余文本转数组
 	if dataTask.Tags != "" {
-		bizTask.Tags = strings.Split(dataTask.Tags, ",")
+		bizTask.Tags = strings.Split(dataTask.Tags, TaskTagsSeparator)
 		// 去除空字符串元素
 		validTags := make([]string, 0, len(bizTask.Tags))
 		for _, tag := range bizTask.Tags {
@@ -96,6 +162,9 @@ func (c *TaskConverter) DataToBiz(dataTask *Task) *biz.Task {
 		}
 		bizTask.Tags = validTags
 	}
+	if dataTask.IsKeyResult {
+		bizTask.KeyResult = biz.NewKeyResult(dataTask.KeyResultStart, dataTask.KeyResultTarget, dataTask.KeyResultCurrent, dataTask.KeyResultUnit)
+	}
 
 	return bizTask
 }
@@ -126,6 +195,70 @@ func (c *TaskConverter) BizToDataList(bizTasks []*biz.Task) []*Task {
 	return dataTasks
 }
 
+// DependencyBizToData 任务依赖业务模型转数据模型
+func (c *TaskConverter) DependencyBizToData(bizDependency *biz.TaskDependency) *TaskDependency {
+	if bizDependency == nil {
+		return nil
+	}
+
+	return &TaskDependency{
+		TaskID:          bizDependency.TaskID,
+		BlockedByTaskID: bizDependency.BlockedByTaskID,
+		UserID:          bizDependency.UserID,
+		CreatedAt:       bizDependency.CreatedAt,
+	}
+}
+
+// DependencyDataToBiz 任务依赖数据模型转业务模型
+func (c *TaskConverter) DependencyDataToBiz(dataDependency *TaskDependency) *biz.TaskDependency {
+	if dataDependency == nil {
+		return nil
+	}
+
+	return &biz.TaskDependency{
+		TaskID:          dataDependency.TaskID,
+		BlockedByTaskID: dataDependency.BlockedByTaskID,
+		UserID:          dataDependency.UserID,
+		CreatedAt:       dataDependency.CreatedAt,
+	}
+}
+
+// ChecklistItemBizToData 清单项业务模型转数据模型
+func (c *TaskConverter) ChecklistItemBizToData(bizItem *biz.ChecklistItem) *ChecklistItem {
+	if bizItem == nil {
+		return nil
+	}
+
+	return &ChecklistItem{
+		ID:        bizItem.ID,
+		TaskID:    bizItem.TaskID,
+		UserID:    bizItem.UserID,
+		Text:      bizItem.Text,
+		Done:      bizItem.Done,
+		Position:  bizItem.Position,
+		CreatedAt: bizItem.CreatedAt,
+		UpdatedAt: bizItem.UpdatedAt,
+	}
+}
+
+// ChecklistItemDataToBiz 清单项数据模型转业务模型
+func (c *TaskConverter) ChecklistItemDataToBiz(dataItem *ChecklistItem) *biz.ChecklistItem {
+	if dataItem == nil {
+		return nil
+	}
+
+	return &biz.ChecklistItem{
+		ID:        dataItem.ID,
+		TaskID:    dataItem.TaskID,
+		UserID:    dataItem.UserID,
+		Text:      dataItem.Text,
+		Done:      dataItem.Done,
+		Position:  dataItem.Position,
+		CreatedAt: dataItem.CreatedAt,
+		UpdatedAt: dataItem.UpdatedAt,
+	}
+}
+
 // JournalConverter 日志数据转换器
 type JournalConverter struct{}
 
@@ -139,7 +272,7 @@ func (c *JournalConverter) BizToData(bizJournal *biz.Journal) *Journal {
 		return nil
 	}
 
-	return &Journal{
+	dataJournal := &Journal{
 		ID:          bizJournal.ID,
 		UserID:      bizJournal.UserID,
 		Title:       bizJournal.Title,
@@ -151,6 +284,10 @@ func (c *JournalConverter) BizToData(bizJournal *biz.Journal) *Journal {
 		CreatedAt:   bizJournal.CreatedAt,
 		UpdatedAt:   bizJournal.UpdatedAt,
 	}
+	if bizJournal.DeletedAt != nil {
+		dataJournal.DeletedAt = gorm.DeletedAt{Time: *bizJournal.DeletedAt, Valid: true}
+	}
+	return dataJournal
 }
 
 // DataToBiz 数据模型转业务模型
@@ -172,6 +309,7 @@ func (c *JournalConverter) DataToBiz(dataJournal *Journal) *biz.Journal {
 		Icon:      dataJournal.Icon,
 		CreatedAt: dataJournal.CreatedAt,
 		UpdatedAt: dataJournal.UpdatedAt,
+		DeletedAt: deletedAtToBiz(dataJournal.DeletedAt),
 	}
 }
 
@@ -267,3 +405,409 @@ func (c *UserConverter) BizToDataList(bizUsers []*biz.User) []*User {
 	}
 	return dataUsers
 }
+
+// UserSettingsConverter 用户设置数据转换器
+type UserSettingsConverter struct{}
+
+func NewUserSettingsConverter() *UserSettingsConverter {
+	return &UserSettingsConverter{}
+}
+
+// BizToData 业务模型转数据模型
+func (c *UserSettingsConverter) BizToData(bizSettings *biz.UserSettings) *UserSettings {
+	if bizSettings == nil {
+		return nil
+	}
+
+	return &UserSettings{
+		UserID:                bizSettings.UserID,
+		CompletionPropagation: int(bizSettings.CompletionPropagation),
+		AutoRollover:          int(bizSettings.AutoRollover),
+		EstimateUnit:          int(bizSettings.EstimateUnit),
+		DailyCapacity:         bizSettings.DailyCapacity,
+		WeeklyCapacity:        bizSettings.WeeklyCapacity,
+		WIPLimitNotStarted:    bizSettings.WIPLimits.NotStarted,
+		WIPLimitInProgress:    bizSettings.WIPLimits.InProgress,
+		WIPLimitCompleted:     bizSettings.WIPLimits.Completed,
+		WIPLimitCancelled:     bizSettings.WIPLimits.Cancelled,
+		CreatedAt:             bizSettings.CreatedAt,
+		UpdatedAt:             bizSettings.UpdatedAt,
+	}
+}
+
+// DataToBiz 数据模型转业务模型
+func (c *UserSettingsConverter) DataToBiz(dataSettings *UserSettings) *biz.UserSettings {
+	if dataSettings == nil {
+		return nil
+	}
+
+	return &biz.UserSettings{
+		UserID:                dataSettings.UserID,
+		CompletionPropagation: biz.CompletionPropagationMode(dataSettings.CompletionPropagation),
+		AutoRollover:          biz.RolloverMode(dataSettings.AutoRollover),
+		EstimateUnit:          biz.EstimateUnit(dataSettings.EstimateUnit),
+		DailyCapacity:         dataSettings.DailyCapacity,
+		WeeklyCapacity:        dataSettings.WeeklyCapacity,
+		WIPLimits: biz.WIPLimits{
+			NotStarted: dataSettings.WIPLimitNotStarted,
+			InProgress: dataSettings.WIPLimitInProgress,
+			Completed:  dataSettings.WIPLimitCompleted,
+			Cancelled:  dataSettings.WIPLimitCancelled,
+		},
+		CreatedAt:             dataSettings.CreatedAt,
+		UpdatedAt:             dataSettings.UpdatedAt,
+	}
+}
+
+// CronTaskConverter 周期任务数据转换器
+type CronTaskConverter struct{}
+
+func NewCronTaskConverter() *CronTaskConverter {
+	return &CronTaskConverter{}
+}
+
+// BizToData 业务模型转数据模型
+func (c *CronTaskConverter) BizToData(bizCronTask *biz.CronTask) *CronTask {
+	if bizCronTask == nil {
+		return nil
+	}
+
+	dataCronTask := &CronTask{
+		ID:                bizCronTask.ID,
+		UserID:            bizCronTask.UserID,
+		Title:             bizCronTask.Title,
+		Frequency:         int(bizCronTask.Frequency),
+		MonthDay:          bizCronTask.MonthDay,
+		CronExpr:          bizCronTask.CronExpr,
+		StartDate:         bizCronTask.StartDate,
+		EndDate:           bizCronTask.EndDate,
+		TaskType:          int(bizCronTask.TaskType),
+		Icon:              bizCronTask.Icon,
+		Priority:          int(bizCronTask.Priority),
+		ParentID:          bizCronTask.ParentID,
+		LastGeneratedDate: bizCronTask.LastGeneratedDate,
+		CreatedAt:         bizCronTask.CreatedAt,
+		UpdatedAt:         bizCronTask.UpdatedAt,
+	}
+
+	// 星期几数组转逗号分隔字符串
+	if len(bizCronTask.Weekdays) > 0 {
+		weekdays := make([]string, len(bizCronTask.Weekdays))
+		for i, weekday := range bizCronTask.Weekdays {
+			weekdays[i] = strconv.Itoa(weekday)
+		}
+		dataCronTask.Weekdays = strings.Join(weekdays, ",")
+	}
+	if len(bizCronTask.Tags) > 0 {
+		dataCronTask.Tags = strings.Join(bizCronTask.Tags, ",")
+	}
+
+	return dataCronTask
+}
+
+// DataToBiz 数据模型转业务模型
+func (c *CronTaskConverter) DataToBiz(dataCronTask *CronTask) *biz.CronTask {
+	if dataCronTask == nil {
+		return nil
+	}
+
+	bizCronTask := &biz.CronTask{
+		ID:                dataCronTask.ID,
+		UserID:            dataCronTask.UserID,
+		Title:             dataCronTask.Title,
+		Frequency:         biz.CronFrequency(dataCronTask.Frequency),
+		Weekdays:          make([]int, 0),
+		MonthDay:          dataCronTask.MonthDay,
+		CronExpr:          dataCronTask.CronExpr,
+		StartDate:         dataCronTask.StartDate,
+		EndDate:           dataCronTask.EndDate,
+		TaskType:          biz.PeriodType(dataCronTask.TaskType),
+		Tags:              make([]string, 0),
+		Icon:              dataCronTask.Icon,
+		Priority:          biz.TaskPriority(dataCronTask.Priority),
+		ParentID:          dataCronTask.ParentID,
+		LastGeneratedDate: dataCronTask.LastGeneratedDate,
+		CreatedAt:         dataCronTask.CreatedAt,
+		UpdatedAt:         dataCronTask.UpdatedAt,
+	}
+
+	if dataCronTask.Weekdays != "" {
+		for _, item := range strings.Split(dataCronTask.Weekdays, ",") {
+			if weekday, err := strconv.Atoi(strings.TrimSpace(item)); err == nil {
+				bizCronTask.Weekdays = append(bizCronTask.Weekdays, weekday)
+			}
+		}
+	}
+	if dataCronTask.Tags != "" {
+		for _, tag := range strings.Split(dataCronTask.Tags, ",") {
+			if trimmed := strings.TrimSpace(tag); trimmed != "" {
+				bizCronTask.Tags = append(bizCronTask.Tags, trimmed)
+			}
+		}
+	}
+
+	return bizCronTask
+}
+
+// DataToBizList 批量数据模型转业务模型
+func (c *CronTaskConverter) DataToBizList(dataCronTasks []*CronTask) []*biz.CronTask {
+	bizCronTasks := make([]*biz.CronTask, len(dataCronTasks))
+	for i, dataCronTask := range dataCronTasks {
+		bizCronTasks[i] = c.DataToBiz(dataCronTask)
+	}
+	return bizCronTasks
+}
+
+// TimeEntryBizToData 工时记录业务模型转数据模型
+func (c *TaskConverter) TimeEntryBizToData(bizEntry *biz.TimeEntry) *TimeEntry {
+	if bizEntry == nil {
+		return nil
+	}
+
+	return &TimeEntry{
+		ID:        bizEntry.ID,
+		TaskID:    bizEntry.TaskID,
+		UserID:    bizEntry.UserID,
+		StartedAt: bizEntry.StartedAt,
+		EndedAt:   bizEntry.EndedAt,
+		Duration:  bizEntry.Duration,
+		Note:      bizEntry.Note,
+		CreatedAt: bizEntry.CreatedAt,
+		UpdatedAt: bizEntry.UpdatedAt,
+	}
+}
+
+// TimeEntryDataToBiz 工时记录数据模型转业务模型
+func (c *TaskConverter) TimeEntryDataToBiz(dataEntry *TimeEntry) *biz.TimeEntry {
+	if dataEntry == nil {
+		return nil
+	}
+
+	return &biz.TimeEntry{
+		ID:        dataEntry.ID,
+		TaskID:    dataEntry.TaskID,
+		UserID:    dataEntry.UserID,
+		StartedAt: dataEntry.StartedAt,
+		EndedAt:   dataEntry.EndedAt,
+		Duration:  dataEntry.Duration,
+		Note:      dataEntry.Note,
+		CreatedAt: dataEntry.CreatedAt,
+		UpdatedAt: dataEntry.UpdatedAt,
+	}
+}
+
+// TimeEntryDataToBizList 批量转换工时记录
+func (c *TaskConverter) TimeEntryDataToBizList(dataEntries []*TimeEntry) []*biz.TimeEntry {
+	bizEntries := make([]*biz.TimeEntry, len(dataEntries))
+	for i, dataEntry := range dataEntries {
+		bizEntries[i] = c.TimeEntryDataToBiz(dataEntry)
+	}
+	return bizEntries
+}
+
+// KeyResultCheckInBizToData 关键结果打卡业务模型转数据模型
+func (c *TaskConverter) KeyResultCheckInBizToData(bizCheckIn *biz.KeyResultCheckIn) *KeyResultCheckIn {
+	if bizCheckIn == nil {
+		return nil
+	}
+
+	return &KeyResultCheckIn{
+		ID:            bizCheckIn.ID,
+		TaskID:        bizCheckIn.TaskID,
+		UserID:        bizCheckIn.UserID,
+		Value:         bizCheckIn.Value,
+		PreviousValue: bizCheckIn.PreviousValue,
+		Note:          bizCheckIn.Note,
+		CheckedAt:     bizCheckIn.CheckedAt,
+		CreatedAt:     bizCheckIn.CreatedAt,
+	}
+}
+
+// KeyResultCheckInDataToBiz 关键结果打卡数据模型转业务模型
+func (c *TaskConverter) KeyResultCheckInDataToBiz(dataCheckIn *KeyResultCheckIn) *biz.KeyResultCheckIn {
+	if dataCheckIn == nil {
+		return nil
+	}
+
+	return &biz.KeyResultCheckIn{
+		ID:            dataCheckIn.ID,
+		TaskID:        dataCheckIn.TaskID,
+		UserID:        dataCheckIn.UserID,
+		Value:         dataCheckIn.Value,
+		PreviousValue: dataCheckIn.PreviousValue,
+		Note:          dataCheckIn.Note,
+		CheckedAt:     dataCheckIn.CheckedAt,
+		CreatedAt:     dataCheckIn.CreatedAt,
+	}
+}
+
+// deletedAtToBiz 软删除时间转业务模型，未删除时为 nil
+func deletedAtToBiz(deletedAt gorm.DeletedAt) *time.Time {
+	if !deletedAt.Valid {
+		return nil
+	}
+	t := deletedAt.Time
+	return &t
+}
+
+// ChangeRecordConverter 变更历史数据转换器
+type ChangeRecordConverter struct{}
+
+func NewChangeRecordConverter() *ChangeRecordConverter {
+	return &ChangeRecordConverter{}
+}
+
+// BizToData 业务模型转数据模型
+func (c *ChangeRecordConverter) BizToData(bizRecord *biz.ChangeRecord) *ChangeRecord {
+	if bizRecord == nil {
+		return nil
+	}
+	return &ChangeRecord{
+		ID:         bizRecord.ID,
+		EntityType: string(bizRecord.EntityType),
+		EntityID:   bizRecord.EntityID,
+		UserID:     bizRecord.UserID,
+		ActorID:    bizRecord.ActorID,
+		Action:     string(bizRecord.Action),
+		Field:      bizRecord.Field,
+		OldValue:   bizRecord.OldValue,
+		NewValue:   bizRecord.NewValue,
+		CreatedAt:  bizRecord.CreatedAt,
+	}
+}
+
+// DataToBiz 数据模型转业务模型
+func (c *ChangeRecordConverter) DataToBiz(dataRecord *ChangeRecord) *biz.ChangeRecord {
+	if dataRecord == nil {
+		return nil
+	}
+	return &biz.ChangeRecord{
+		ID:         dataRecord.ID,
+		EntityType: biz.ChangeEntityType(dataRecord.EntityType),
+		EntityID:   dataRecord.EntityID,
+		UserID:     dataRecord.UserID,
+		ActorID:    dataRecord.ActorID,
+		Action:     biz.ChangeAction(dataRecord.Action),
+		Field:      dataRecord.Field,
+		OldValue:   dataRecord.OldValue,
+		NewValue:   dataRecord.NewValue,
+		CreatedAt:  dataRecord.CreatedAt,
+	}
+}
+
+// BizToDataList 批量业务模型转数据模型
+func (c *ChangeRecordConverter) BizToDataList(bizRecords []*biz.ChangeRecord) []*ChangeRecord {
+	dataRecords := make([]*ChangeRecord, len(bizRecords))
+	for i, bizRecord := range bizRecords {
+		dataRecords[i] = c.BizToData(bizRecord)
+	}
+	return dataRecords
+}
+
+// DataToBizList 批量数据模型转业务模型
+func (c *ChangeRecordConverter) DataToBizList(dataRecords []*ChangeRecord) []*biz.ChangeRecord {
+	bizRecords := make([]*biz.ChangeRecord, len(dataRecords))
+	for i, dataRecord := range dataRecords {
+		bizRecords[i] = c.DataToBiz(dataRecord)
+	}
+	return bizRecords
+}
+
+// TaskTemplateConverter 任务模板数据转换器
+type TaskTemplateConverter struct{}
+
+func NewTaskTemplateConverter() *TaskTemplateConverter {
+	return &TaskTemplateConverter{}
+}
+
+// BizToData 业务模型转数据模型，模板树序列化为 JSON
+func (c *TaskTemplateConverter) BizToData(bizTemplate *biz.TaskTemplate) (*TaskTemplate, error) {
+	if bizTemplate == nil {
+		return nil, nil
+	}
+	root, err := json.Marshal(bizTemplate.Root)
+	if err != nil {
+		return nil, err
+	}
+	return &TaskTemplate{
+		ID:          bizTemplate.ID,
+		UserID:      bizTemplate.UserID,
+		Name:        bizTemplate.Name,
+		Description: bizTemplate.Description,
+		Root:        string(root),
+		CreatedAt:   bizTemplate.CreatedAt,
+		UpdatedAt:   bizTemplate.UpdatedAt,
+	}, nil
+}
+
+// DataToBiz 数据模型转业务模型
+func (c *TaskTemplateConverter) DataToBiz(dataTemplate *TaskTemplate) (*biz.TaskTemplate, error) {
+	if dataTemplate == nil {
+		return nil, nil
+	}
+	var root biz.TaskTemplateNode
+	if err := json.Unmarshal([]byte(dataTemplate.Root), &root); err != nil {
+		return nil, err
+	}
+	return &biz.TaskTemplate{
+		ID:          dataTemplate.ID,
+		UserID:      dataTemplate.UserID,
+		Name:        dataTemplate.Name,
+		Description: dataTemplate.Description,
+		Root:        &root,
+		CreatedAt:   dataTemplate.CreatedAt,
+		UpdatedAt:   dataTemplate.UpdatedAt,
+	}, nil
+}
+
+// DataToBizList 批量数据模型转业务模型
+func (c *TaskTemplateConverter) DataToBizList(dataTemplates []*TaskTemplate) ([]*biz.TaskTemplate, error) {
+	bizTemplates := make([]*biz.TaskTemplate, len(dataTemplates))
+	for i, dataTemplate := range dataTemplates {
+		bizTemplate, err := c.DataToBiz(dataTemplate)
+		if err != nil {
+			return nil, err
+		}
+		bizTemplates[i] = bizTemplate
+	}
+	return bizTemplates, nil
+}
+
+// TagConverter 标签数据转换器
+type TagConverter struct{}
+
+func NewTagConverter() *TagConverter {
+	return &TagConverter{}
+}
+
+// BizToData 业务模型转数据模型
+func (c *TagConverter) BizToData(bizTag *biz.Tag) *Tag {
+	if bizTag == nil {
+		return nil
+	}
+	return &Tag{
+		ID:          bizTag.ID,
+		UserID:      bizTag.UserID,
+		Name:        bizTag.Name,
+		Color:       bizTag.Color,
+		Description: bizTag.Description,
+		CreatedAt:   bizTag.CreatedAt,
+		UpdatedAt:   bizTag.UpdatedAt,
+	}
+}
+
+// DataToBiz 数据模型转业务模型
+func (c *TagConverter) DataToBiz(dataTag *Tag) *biz.Tag {
+	if dataTag == nil {
+		return nil
+	}
+	return &biz.Tag{
+		ID:          dataTag.ID,
+		UserID:      dataTag.UserID,
+		Name:        dataTag.Name,
+		Color:       dataTag.Color,
+		Description: dataTag.Description,
+		CreatedAt:   dataTag.CreatedAt,
+		UpdatedAt:   dataTag.UpdatedAt,
+	}
+}
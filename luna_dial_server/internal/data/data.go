@@ -11,6 +11,9 @@ type Data struct {
 	DB             *gorm.DB
 	SystemConfig   *SystemConfig  // 导出SystemConfig供service层使用
 	SessionManager SessionManager // 导出SessionManager供service层使用
+
+	// 上层注册的后台任务清理函数（如周期任务生成器），在关闭会话管理器之前按注册的逆序执行
+	cleanups []func()
 }
 
 // NewData 创建数据层实例
@@ -25,6 +28,11 @@ func NewData(db *gorm.DB) (*Data, func(), error) {
 	}
 
 	cleanup := func() {
+		// 先停止上层注册的后台任务，它们可能还在使用数据库
+		for i := len(d.cleanups) - 1; i >= 0; i-- {
+			d.cleanups[i]()
+		}
+
 		// 关闭Session管理器
 		if sessionManager != nil {
 			sessionManager.Close()
@@ -36,3 +44,9 @@ func NewData(db *gorm.DB) (*Data, func(), error) {
 
 	return d, cleanup, nil
 }
+
+// AddCleanup 注册一个在数据层关闭时执行的清理函数
+// 与会话清理协程一样，业务层的后台协程需要随服务一起优雅退出
+func (d *Data) AddCleanup(fn func()) {
+	d.cleanups = append(d.cleanups, fn)
+}
@@ -1,6 +1,10 @@
 package data
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // 采用gorm吧
 // 用户数据模型
@@ -19,15 +23,57 @@ type Task struct {
 	ID          string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
 	UserID      string    `gorm:"type:varchar(36);index;not null" json:"user_id"`
 	Title       string    `gorm:"type:varchar(255);not null" json:"title"`
+	Description string    `gorm:"type:text" json:"description"` // markdown 格式的任务描述
 	TaskType    int       `gorm:"type:int;not null" json:"task_type"`
 	PeriodStart time.Time `gorm:"type:datetime" json:"period_start"`
 	PeriodEnd   time.Time `gorm:"type:datetime" json:"period_end"`
-	Tags        string    `gorm:"type:text" json:"tags"`
+	Tags        string    `gorm:"type:text" json:"tags"` // 标签名的冗余文本（换行分隔），以 task_tags 关联表为准，用于读取和全文搜索
 	Icon        string    `gorm:"type:varchar(10)" json:"icon"`
 	Score       int       `gorm:"default:0" json:"score"`
 	Status      int       `gorm:"default:0;not null" json:"status"`
 	Priority    int       `gorm:"default:0;not null" json:"priority"`
 	ParentID    string    `gorm:"type:varchar(36);index" json:"parent_id"`
+
+	ReadyToComplete bool `gorm:"default:false" json:"ready_to_complete"` // 所有子任务已完成，等待用户确认
+
+	// 清单统计：冗余字段，列表查询时无需关联清单表即可计算完成比例
+	ChecklistTotal int `gorm:"default:0" json:"checklist_total"`
+	ChecklistDone  int `gorm:"default:0" json:"checklist_done"`
+
+	// 状态时间：由业务层在状态变化时维护，取消原因只在已取消状态下保留
+	StartedAt    *time.Time `json:"started_at"`
+	CompletedAt  *time.Time `gorm:"index" json:"completed_at"`
+	CancelledAt  *time.Time `json:"cancelled_at"`
+	CancelReason string     `gorm:"type:text" json:"cancel_reason"`
+
+	// 顺延：复制模式下指向原任务，以及任务被顺延的次数
+	CarriedOverFromID string `gorm:"type:varchar(36);index" json:"carried_over_from_id"`
+	CarryOverCount    int    `gorm:"default:0" json:"carry_over_count"`
+
+	// 工时统计（秒）：只读字段，由 RefreshTaskTimeSpent / RefreshTreeTimeSpent 按工时记录重算，Save 时不会覆盖
+	TimeSpent     int64 `gorm:"<-:false;default:0" json:"time_spent"`
+	TreeTimeSpent int64 `gorm:"<-:false;default:0" json:"tree_time_spent"` // 只在根任务上维护
+
+	// 看板列中的排序位置：看板每一列的卡片按它升序排列，由 UpdateTaskBoardRanks 修改
+	BoardRank int64 `gorm:"default:0;not null" json:"board_rank"`
+
+	// 工作量估算：整树估算为只读字段，由 RefreshTreeEstimate 重算，Save 时不会覆盖
+	Estimate     int `gorm:"default:0;not null" json:"estimate"`
+	TreeEstimate int `gorm:"<-:false;default:0" json:"tree_estimate"` // 只在根任务上维护
+
+	// 分数汇总：汇总方式和权重随任务保存；汇总结果为只读字段，由 UpdateTaskRollup 写入，Save 时不会覆盖
+	ScoreRollup         int     `gorm:"default:0" json:"score_rollup"`
+	RollupCompletedOnly bool    `gorm:"default:false" json:"rollup_completed_only"`
+	Weight              float64 `gorm:"default:1;not null" json:"weight"`
+	RollupScore         float64 `gorm:"<-:false;default:0" json:"rollup_score"`
+	Progress            float64 `gorm:"<-:false;default:0" json:"progress"`
+
+	// 关键结果：is_key_result 为 true 时其余字段有效，当前值由打卡更新
+	IsKeyResult      bool    `gorm:"default:false" json:"is_key_result"`
+	KeyResultStart   float64 `gorm:"default:0" json:"key_result_start"`
+	KeyResultTarget  float64 `gorm:"default:0" json:"key_result_target"`
+	KeyResultCurrent float64 `gorm:"default:0" json:"key_result_current"`
+	KeyResultUnit    string  `gorm:"type:varchar(32)" json:"key_result_unit"`
 	
 	// 新增：树结构优化字段
 	// 设计思路：通过冗余字段减少递归查询，提升性能
@@ -37,6 +83,7 @@ type Task struct {
 	ChildrenCount int    `gorm:"default:0" json:"children_count"`       // 直接子任务数量：用于统计和分页计算
 	RootTaskID    string `gorm:"type:varchar(36);index" json:"root_task_id"` // 根任务ID：批量查询整个树的关键字段
 	TreeDepth     int    `gorm:"default:0" json:"tree_depth"`           // 树深度：排序和层级控制，根任务depth=0
+	SortRank      int64  `gorm:"default:0;not null" json:"sort_rank"`   // 同级任务中的排序位置：树和列表中的子任务按它升序排列
 	
 	// 查询示例：
 	// 1. 获取指定任务的完整任务树（任意层级的taskID）：
@@ -48,6 +95,74 @@ type Task struct {
 	
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// 软删除：gorm 的查询会自动排除已删除的任务，回收站相关方法使用 Unscoped
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+}
+
+// 任务依赖数据模型：task_id 被 blocked_by_task_id 阻塞
+type TaskDependency struct {
+	TaskID          string    `gorm:"primaryKey;type:varchar(36)" json:"task_id"`
+	BlockedByTaskID string    `gorm:"primaryKey;type:varchar(36);index" json:"blocked_by_task_id"`
+	UserID          string    `gorm:"type:varchar(36);index;not null" json:"user_id"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (TaskDependency) TableName() string {
+	return "task_dependencies"
+}
+
+// 任务清单项数据模型
+type ChecklistItem struct {
+	ID        string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	TaskID    string    `gorm:"type:varchar(36);index;not null" json:"task_id"`
+	UserID    string    `gorm:"type:varchar(36);not null" json:"user_id"`
+	Text      string    `gorm:"type:varchar(500);not null" json:"text"`
+	Done      bool      `gorm:"default:false" json:"done"`
+	Position  int       `gorm:"default:0;not null" json:"position"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ChecklistItem) TableName() string {
+	return "task_checklist_items"
+}
+
+// 工时记录数据模型，ended_at 为空表示计时器正在运行
+type TimeEntry struct {
+	ID        string     `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	TaskID    string     `gorm:"type:varchar(36);index;not null" json:"task_id"`
+	UserID    string     `gorm:"type:varchar(36);index;not null" json:"user_id"`
+	StartedAt time.Time  `gorm:"not null" json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Duration  int64      `gorm:"default:0;not null" json:"duration"` // 秒
+	Note      string     `gorm:"type:text" json:"note"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (TimeEntry) TableName() string {
+	return "time_entries"
+}
+
+// 关键结果打卡数据模型
+type KeyResultCheckIn struct {
+	ID            string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	TaskID        string    `gorm:"type:varchar(36);index;not null" json:"task_id"`
+	UserID        string    `gorm:"type:varchar(36);index;not null" json:"user_id"`
+	Value         float64   `gorm:"not null" json:"value"`
+	PreviousValue float64   `gorm:"not null" json:"previous_value"`
+	Note          string    `gorm:"type:text" json:"note"`
+	CheckedAt     time.Time `gorm:"not null" json:"checked_at"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (KeyResultCheckIn) TableName() string {
+	return "key_result_check_ins"
 }
 
 // 日志数据模型
@@ -62,4 +177,124 @@ type Journal struct {
 	Icon        string    `gorm:"type:varchar(10)" json:"icon"`
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// 软删除：gorm 的查询会自动排除已删除的日志，回收站相关方法使用 Unscoped
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+}
+
+// 用户设置数据模型
+type UserSettings struct {
+	UserID                string    `gorm:"primaryKey;type:varchar(36)" json:"user_id"`
+	CompletionPropagation int       `gorm:"default:0;not null" json:"completion_propagation"`
+	AutoRollover          int       `gorm:"default:0;not null" json:"auto_rollover"` // 0=不顺延, 1=移动, 2=复制
+	// 估算单位 0=分钟, 1=点数；每天、每周的容量为0时不检查
+	EstimateUnit          int       `gorm:"default:0;not null" json:"estimate_unit"`
+	DailyCapacity         int       `gorm:"default:0;not null" json:"daily_capacity"`
+	WeeklyCapacity        int       `gorm:"default:0;not null" json:"weekly_capacity"`
+	// 看板每一列（任务状态）的在制品上限，0=不限制
+	WIPLimitNotStarted    int       `gorm:"default:0;not null" json:"wip_limit_not_started"`
+	WIPLimitInProgress    int       `gorm:"default:0;not null" json:"wip_limit_in_progress"`
+	WIPLimitCompleted     int       `gorm:"default:0;not null" json:"wip_limit_completed"`
+	WIPLimitCancelled     int       `gorm:"default:0;not null" json:"wip_limit_cancelled"`
+	CreatedAt             time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt             time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserSettings) TableName() string {
+	return "user_settings"
+}
+
+// 周期任务规则数据模型
+type CronTask struct {
+	ID        string `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	UserID    string `gorm:"type:varchar(36);index;not null" json:"user_id"`
+	Title     string `gorm:"type:varchar(255);not null" json:"title"`
+	Frequency int    `gorm:"type:int;not null" json:"frequency"`
+	Weekdays  string `gorm:"type:varchar(20)" json:"weekdays"` // 逗号分隔，0=周日
+	MonthDay  int    `gorm:"default:0" json:"month_day"`
+	CronExpr  string `gorm:"type:varchar(100)" json:"cron_expr"`
+
+	StartDate time.Time  `gorm:"type:timestamp;not null" json:"start_date"`
+	EndDate   *time.Time `gorm:"type:timestamp" json:"end_date"`
+
+	// 生成任务的模板
+	TaskType int    `gorm:"type:int;not null" json:"task_type"`
+	Tags     string `gorm:"type:text" json:"tags"`
+	Icon     string `gorm:"type:varchar(10)" json:"icon"`
+	Priority int    `gorm:"default:0;not null" json:"priority"`
+	ParentID string `gorm:"type:varchar(36)" json:"parent_id"`
+
+	LastGeneratedDate *time.Time `gorm:"type:timestamp" json:"last_generated_date"` // 最后一次生成任务的日期
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CronTask) TableName() string {
+	return "cron_tasks"
+}
+
+// 变更历史数据模型：每条记录对应实体单个字段的一次变更
+type ChangeRecord struct {
+	ID         string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	EntityType string    `gorm:"type:varchar(20);not null" json:"entity_type"`
+	EntityID   string    `gorm:"type:varchar(36);not null" json:"entity_id"`
+	UserID     string    `gorm:"type:varchar(36);index;not null" json:"user_id"`
+	ActorID    string    `gorm:"type:varchar(36);not null" json:"actor_id"`
+	Action     string    `gorm:"type:varchar(20);not null" json:"action"`
+	Field      string    `gorm:"type:varchar(50);not null" json:"field"`
+	OldValue   string    `gorm:"type:text" json:"old_value"`
+	NewValue   string    `gorm:"type:text" json:"new_value"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (ChangeRecord) TableName() string {
+	return "change_history"
+}
+
+// 任务模板数据模型，模板树以 JSON 形式保存在 root 字段中
+type TaskTemplate struct {
+	ID          string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	UserID      string    `gorm:"type:varchar(36);index;not null" json:"user_id"`
+	Name        string    `gorm:"type:varchar(255);not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	Root        string    `gorm:"type:text;not null" json:"root"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (TaskTemplate) TableName() string {
+	return "task_templates"
+}
+
+// 标签数据模型，同一用户下名称唯一
+type Tag struct {
+	ID          string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	UserID      string    `gorm:"type:varchar(36);uniqueIndex:idx_tags_user_name;not null" json:"user_id"`
+	Name        string    `gorm:"type:varchar(50);uniqueIndex:idx_tags_user_name;not null" json:"name"`
+	Color       string    `gorm:"type:varchar(7);default:'';not null" json:"color"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// 任务与标签的关联，position 保持任务上标签的顺序
+type TaskTag struct {
+	TaskID   string `gorm:"primaryKey;type:varchar(36)" json:"task_id"`
+	TagID    string `gorm:"primaryKey;type:varchar(36);index" json:"tag_id"`
+	Position int    `gorm:"default:0;not null" json:"position"`
+}
+
+// TableName 指定表名
+func (TaskTag) TableName() string {
+	return "task_tags"
 }
@@ -6,9 +6,12 @@ import (
     "fmt"
     "luna_dial/internal/biz"
     "luna_dial/internal/model"
+    "strings"
     "time"
 
+    "github.com/google/uuid"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 // TaskRepo 任务仓库实现
@@ -24,14 +27,90 @@ func NewTaskRepo(db *gorm.DB) biz.TaskRepo {
 	}
 }
 
+// getDB 获取当前 context 对应的数据库句柄（在事务中时返回事务句柄）
+func (r *taskRepo) getDB(ctx context.Context) *gorm.DB {
+	return dbFromContext(ctx, r.db)
+}
+
+// Transaction 在同一个数据库事务中执行 fn，fn 内通过 ctx 调用的仓库方法共享该事务
+func (r *taskRepo) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return runInTransaction(ctx, r.db, fn)
+}
+
+// LockUserTasks 获取用户级的事务锁，事务提交或回滚时自动释放
+func (r *taskRepo) LockUserTasks(ctx context.Context, userID string) error {
+	return r.getDB(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", userID).Error
+}
+
 func (r *taskRepo) CreateTask(ctx context.Context, bizTask *biz.Task) error {
 	dataTask := r.converter.BizToData(bizTask)
-	return r.db.WithContext(ctx).Create(dataTask).Error
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		if err := r.getDB(ctx).Create(dataTask).Error; err != nil {
+			return err
+		}
+		return r.syncTaskTags(ctx, bizTask)
+	})
+}
+
+// syncTaskTags 让 task_tags 关联与任务的标签列表一致，按名称自动创建不存在的标签
+// 标签没有变化时不写入，大多数 UpdateTask 只需要一次查询
+func (r *taskRepo) syncTaskTags(ctx context.Context, bizTask *biz.Task) error {
+	var current []string
+	err := r.getDB(ctx).Table("task_tags").
+		Select("tags.name").
+		Joins("JOIN tags ON tags.id = task_tags.tag_id").
+		Where("task_tags.task_id = ?", bizTask.ID).
+		Order("task_tags.position").
+		Pluck("tags.name", &current).Error
+	if err != nil {
+		return err
+	}
+	if strings.Join(current, TaskTagsSeparator) == strings.Join(bizTask.Tags, TaskTagsSeparator) {
+		return nil
+	}
+
+	if err := r.getDB(ctx).Where("task_id = ?", bizTask.ID).Delete(&TaskTag{}).Error; err != nil {
+		return err
+	}
+	if len(bizTask.Tags) == 0 {
+		return nil
+	}
+
+	newTags := make([]*Tag, len(bizTask.Tags))
+	for i, name := range bizTask.Tags {
+		newTags[i] = &Tag{ID: strings.ReplaceAll(uuid.NewString(), "-", ""), UserID: bizTask.UserID, Name: name}
+	}
+	err = r.getDB(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}, {Name: "name"}}, DoNothing: true}).
+		Create(&newTags).Error
+	if err != nil {
+		return err
+	}
+
+	var tags []*Tag
+	err = r.getDB(ctx).Where("user_id = ? AND name IN ?", bizTask.UserID, bizTask.Tags).Find(&tags).Error
+	if err != nil {
+		return err
+	}
+	tagIDs := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		tagIDs[tag.Name] = tag.ID
+	}
+	links := make([]*TaskTag, 0, len(bizTask.Tags))
+	for i, name := range bizTask.Tags {
+		if tagID, ok := tagIDs[name]; ok {
+			links = append(links, &TaskTag{TaskID: bizTask.ID, TagID: tagID, Position: i})
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return r.getDB(ctx).Create(&links).Error
 }
 
 func (r *taskRepo) GetTask(ctx context.Context, taskID, userID string) (*biz.Task, error) {
     var dataTask Task
-    err := r.db.WithContext(ctx).
+    err := r.getDB(ctx).
         Where("id = ? AND user_id = ?", taskID, userID).
         First(&dataTask).Error
 
@@ -48,22 +127,285 @@ func (r *taskRepo) GetTask(ctx context.Context, taskID, userID string) (*biz.Tas
 
 func (r *taskRepo) UpdateTask(ctx context.Context, bizTask *biz.Task) error {
 	dataTask := r.converter.BizToData(bizTask)
-	return r.db.WithContext(ctx).Save(dataTask).Error
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		if err := r.getDB(ctx).Save(dataTask).Error; err != nil {
+			return err
+		}
+		return r.syncTaskTags(ctx, bizTask)
+	})
 }
 
 func (r *taskRepo) DeleteTask(ctx context.Context, taskID, userID string) error {
-	return r.db.WithContext(ctx).
+	return r.getDB(ctx).
 		Where("id = ? AND user_id = ?", taskID, userID).
 		Delete(&Task{}).Error
 }
 
-func (r *taskRepo) ListTasks(ctx context.Context, userID string, periodStart, periodEnd time.Time, taskType int) ([]*biz.Task, error) {
+// DeleteTaskSubtree 删除任务及其所有后代任务
+// 一条语句完成软删除，整棵子树的 deleted_at 相同，恢复时据此找回一起删除的后代
+func (r *taskRepo) DeleteTaskSubtree(ctx context.Context, taskID, userID string) error {
+	ids, err := r.collectSubtreeIDs(ctx, taskID, userID)
+	if err != nil {
+		return err
+	}
+
+	return r.getDB(ctx).
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Delete(&Task{}).Error
+}
+
+// ListChildTasks 获取任务的直接子任务，按排序位置排列；parentID 为空时返回根任务
+func (r *taskRepo) ListChildTasks(ctx context.Context, parentID, userID string) ([]*biz.Task, error) {
+	var dataTasks []*Task
+	err := r.siblingQuery(ctx, userID, parentID).
+		Order("sort_rank, created_at").
+		Find(&dataTasks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.converter.DataToBizList(dataTasks), nil
+}
+
+// siblingQuery 父任务为 parentID 的任务，parentID 为空表示根任务
+func (r *taskRepo) siblingQuery(ctx context.Context, userID, parentID string) *gorm.DB {
+	query := r.getDB(ctx).Model(&Task{}).Where("user_id = ?", userID)
+	if parentID == "" {
+		return query.Where("parent_id IS NULL OR parent_id = ''")
+	}
+	return query.Where("parent_id = ?", parentID)
+}
+
+// GetSiblingSortRankRange 获取同级任务排序位置的最小值和最大值，没有同级任务时返回 0, 0
+func (r *taskRepo) GetSiblingSortRankRange(ctx context.Context, userID, parentID string) (int64, int64, error) {
+	var bounds struct {
+		MinRank int64
+		MaxRank int64
+	}
+	err := r.siblingQuery(ctx, userID, parentID).
+		Select("COALESCE(MIN(sort_rank), 0) AS min_rank, COALESCE(MAX(sort_rank), 0) AS max_rank").
+		Scan(&bounds).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	return bounds.MinRank, bounds.MaxRank, nil
+}
+
+// UpdateTaskBoardRanks 批量修改看板位置，只更新 board_rank 列
+func (r *taskRepo) UpdateTaskBoardRanks(ctx context.Context, userID string, ranks map[string]int64) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		for taskID, rank := range ranks {
+			err := r.getDB(ctx).Model(&Task{}).
+				Where("id = ? AND user_id = ?", taskID, userID).
+				UpdateColumn("board_rank", rank).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateTaskSortRanks 批量修改排序位置，只更新 sort_rank 列
+func (r *taskRepo) UpdateTaskSortRanks(ctx context.Context, userID string, ranks map[string]int64) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		for taskID, rank := range ranks {
+			err := r.getDB(ctx).Model(&Task{}).
+				Where("id = ? AND user_id = ?", taskID, userID).
+				UpdateColumn("sort_rank", rank).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateTaskRollup 保存汇总分数和进度，只更新这两列，不更新 updated_at
+func (r *taskRepo) UpdateTaskRollup(ctx context.Context, taskID, userID string, rollup biz.TaskRollup) error {
+	return r.getDB(ctx).Model(&Task{}).
+		Where("id = ? AND user_id = ?", taskID, userID).
+		UpdateColumns(map[string]interface{}{
+			"rollup_score": rollup.Score,
+			"progress":     rollup.Progress,
+		}).Error
+}
+
+// subtreeCTE 递归查询任务及其所有未删除的后代，level 为相对该任务的层数
+// path 记录已经过的节点，防止脏数据中的环导致无限递归；参数依次为 taskID, userID, userID
+const subtreeCTE = `WITH RECURSIVE subtree (id, level, path) AS (
+	SELECT id, 0, ARRAY[id::text] FROM tasks
+	WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	UNION ALL
+	SELECT t.id, s.level + 1, s.path || t.id::text FROM tasks t
+	JOIN subtree s ON t.parent_id = s.id
+	WHERE t.user_id = ? AND t.deleted_at IS NULL AND NOT t.id::text = ANY(s.path)
+) `
+
+// ancestorsCTE 递归查询任务自身及其所有未删除的祖先，level 为 0 的是任务自身
+// 参数依次为 taskID, userID, userID
+const ancestorsCTE = `WITH RECURSIVE ancestors (id, parent_id, level, path) AS (
+	SELECT id, COALESCE(parent_id, ''), 0, ARRAY[id::text] FROM tasks
+	WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	UNION ALL
+	SELECT t.id, COALESCE(t.parent_id, ''), a.level + 1, a.path || t.id::text FROM tasks t
+	JOIN ancestors a ON t.id = a.parent_id
+	WHERE t.user_id = ? AND t.deleted_at IS NULL AND NOT t.id::text = ANY(a.path)
+) `
+
+// collectSubtreeIDs 收集子树中所有任务ID（包含自身）
+func (r *taskRepo) collectSubtreeIDs(ctx context.Context, taskID, userID string) ([]string, error) {
+	var ids []string
+	err := r.getDB(ctx).
+		Raw(subtreeCTE+"SELECT id FROM subtree", taskID, userID, userID).
+		Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// listAncestors 查询任务自身及其所有祖先，order 为 ancestors.level 的排序方向
+func (r *taskRepo) listAncestors(ctx context.Context, taskID, userID, order string) ([]*Task, error) {
+	var dataTasks []*Task
+	err := r.getDB(ctx).
+		Raw(ancestorsCTE+"SELECT tasks.* FROM tasks JOIN ancestors ON tasks.id = ancestors.id ORDER BY ancestors.level "+order,
+			taskID, userID, userID).
+		Scan(&dataTasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return dataTasks, nil
+}
+
+// wherePeriodMatch 按匹配方式过滤 period_start / period_end，start 或 end 为空时不限制对应的一侧
+func wherePeriodMatch(query *gorm.DB, match biz.PeriodMatch, start, end *time.Time) *gorm.DB {
+	switch match {
+	case biz.PeriodMatchIntersecting:
+		if start != nil {
+			query = query.Where("period_end > ?", *start)
+		}
+		if end != nil {
+			query = query.Where("period_start < ?", *end)
+		}
+	case biz.PeriodMatchStartingWithin:
+		if start != nil {
+			query = query.Where("period_start >= ?", *start)
+		}
+		if end != nil {
+			query = query.Where("period_start < ?", *end)
+		}
+	default:
+		if start != nil {
+			query = query.Where("period_start >= ?", *start)
+		}
+		if end != nil {
+			query = query.Where("period_end <= ?", *end)
+		}
+	}
+	return query
+}
+
+func (r *taskRepo) ListTasks(ctx context.Context, userID string, periodStart, periodEnd time.Time, taskType int, match biz.PeriodMatch) ([]*biz.Task, error) {
+	var dataTasks []*Task
+	query := r.getDB(ctx).Where("user_id = ? AND task_type = ?", userID, taskType)
+	err := wherePeriodMatch(query, match, &periodStart, &periodEnd).
+		Order("period_start, sort_rank, created_at").
+		Find(&dataTasks).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.converter.DataToBizList(dataTasks), nil
+}
+
+// ListTasksInPeriod 获取完全落在时间段内的所有类型的任务
+func (r *taskRepo) ListTasksInPeriod(ctx context.Context, userID string, periodStart, periodEnd time.Time) ([]*biz.Task, error) {
+	var dataTasks []*Task
+	err := r.getDB(ctx).
+		Where("user_id = ? AND period_start >= ? AND period_end <= ?", userID, periodStart, periodEnd).
+		Order("period_start, sort_rank, created_at").
+		Find(&dataTasks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.converter.DataToBizList(dataTasks), nil
+}
+
+// ListTasksContainingTime 获取时间段包含 t 的所有类型的任务
+func (r *taskRepo) ListTasksContainingTime(ctx context.Context, userID string, t time.Time) ([]*biz.Task, error) {
+	var dataTasks []*Task
+	err := r.getDB(ctx).
+		Where("user_id = ? AND period_start <= ? AND period_end > ?", userID, t, t).
+		Order("task_type DESC, period_start, sort_rank, created_at").
+		Find(&dataTasks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.converter.DataToBizList(dataTasks), nil
+}
+
+// CreateTaskDependency 添加任务依赖
+func (r *taskRepo) CreateTaskDependency(ctx context.Context, bizDependency *biz.TaskDependency) error {
+	dataDependency := r.converter.DependencyBizToData(bizDependency)
+	return r.getDB(ctx).Create(dataDependency).Error
+}
+
+// DeleteTaskDependency 删除任务依赖
+func (r *taskRepo) DeleteTaskDependency(ctx context.Context, taskID, blockedByTaskID, userID string) error {
+	return r.getDB(ctx).
+		Where("task_id = ? AND blocked_by_task_id = ? AND user_id = ?", taskID, blockedByTaskID, userID).
+		Delete(&TaskDependency{}).Error
+}
+
+// ListTaskDependencies 获取用户的依赖边，用于环检测和关键路径计算
+// includeTrashed 为 false 时任一端在回收站中的依赖边不返回；环检测需要包括它们，否则恢复任务后可能成环
+func (r *taskRepo) ListTaskDependencies(ctx context.Context, userID string, includeTrashed bool) ([]*biz.TaskDependency, error) {
+	var dataDependencies []*TaskDependency
+	taskIDs := r.getDB(ctx).Model(&Task{}).Select("id").Where("user_id = ?", userID)
+	if includeTrashed {
+		taskIDs = taskIDs.Unscoped()
+	}
+	err := r.getDB(ctx).
+		Where("user_id = ? AND task_id IN (?) AND blocked_by_task_id IN (?)", userID, taskIDs, taskIDs).
+		Find(&dataDependencies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	bizDependencies := make([]*biz.TaskDependency, len(dataDependencies))
+	for i, dataDependency := range dataDependencies {
+		bizDependencies[i] = r.converter.DependencyDataToBiz(dataDependency)
+	}
+	return bizDependencies, nil
+}
+
+// ListTaskBlockers 获取阻塞指定任务的任务
+func (r *taskRepo) ListTaskBlockers(ctx context.Context, taskID, userID string) ([]*biz.Task, error) {
 	var dataTasks []*Task
-	err := r.db.WithContext(ctx).
-		Where("user_id = ? AND task_type = ? AND period_start >= ? AND period_end <= ?",
-			userID, taskType, periodStart, periodEnd).
+	err := r.getDB(ctx).
+		Joins("JOIN task_dependencies ON task_dependencies.blocked_by_task_id = tasks.id").
+		Where("task_dependencies.task_id = ? AND task_dependencies.user_id = ?", taskID, userID).
+		Order("tasks.created_at").
 		Find(&dataTasks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.converter.DataToBizList(dataTasks), nil
+}
 
+// ListTaskDependents 获取被指定任务阻塞的任务
+func (r *taskRepo) ListTaskDependents(ctx context.Context, taskID, userID string) ([]*biz.Task, error) {
+	var dataTasks []*Task
+	err := r.getDB(ctx).
+		Joins("JOIN task_dependencies ON task_dependencies.task_id = tasks.id").
+		Where("task_dependencies.blocked_by_task_id = ? AND task_dependencies.user_id = ?", taskID, userID).
+		Order("tasks.created_at").
+		Find(&dataTasks).Error
 	if err != nil {
 		return nil, err
 	}
@@ -71,8 +413,321 @@ func (r *taskRepo) ListTasks(ctx context.Context, userID string, periodStart, pe
 	return r.converter.DataToBizList(dataTasks), nil
 }
 
+// ListChecklistItems 获取任务清单项，按位置排序
+func (r *taskRepo) ListChecklistItems(ctx context.Context, taskID, userID string) ([]*biz.ChecklistItem, error) {
+	var dataItems []*ChecklistItem
+	err := r.getDB(ctx).
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Order("position").
+		Find(&dataItems).Error
+	if err != nil {
+		return nil, err
+	}
+
+	bizItems := make([]*biz.ChecklistItem, len(dataItems))
+	for i, dataItem := range dataItems {
+		bizItems[i] = r.converter.ChecklistItemDataToBiz(dataItem)
+	}
+	return bizItems, nil
+}
+
+// ReplaceChecklistItems 删除任务原有清单项后写入新的清单
+// 清单项数量很少，整体替换比逐条比对更简单，也保证 position 连续
+func (r *taskRepo) ReplaceChecklistItems(ctx context.Context, taskID, userID string, bizItems []*biz.ChecklistItem) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		if err := r.getDB(ctx).
+			Where("task_id = ? AND user_id = ?", taskID, userID).
+			Delete(&ChecklistItem{}).Error; err != nil {
+			return err
+		}
+		if len(bizItems) == 0 {
+			return nil
+		}
+
+		dataItems := make([]*ChecklistItem, len(bizItems))
+		for i, bizItem := range bizItems {
+			dataItems[i] = r.converter.ChecklistItemBizToData(bizItem)
+		}
+		return r.getDB(ctx).Create(dataItems).Error
+	})
+}
+
+func (r *taskRepo) CreateTimeEntry(ctx context.Context, bizEntry *biz.TimeEntry) error {
+	return r.getDB(ctx).Create(r.converter.TimeEntryBizToData(bizEntry)).Error
+}
+
+func (r *taskRepo) UpdateTimeEntry(ctx context.Context, bizEntry *biz.TimeEntry) error {
+	return r.getDB(ctx).Save(r.converter.TimeEntryBizToData(bizEntry)).Error
+}
+
+func (r *taskRepo) DeleteTimeEntry(ctx context.Context, entryID, userID string) error {
+	return r.getDB(ctx).
+		Where("id = ? AND user_id = ?", entryID, userID).
+		Delete(&TimeEntry{}).Error
+}
+
+func (r *taskRepo) GetTimeEntry(ctx context.Context, entryID, userID string) (*biz.TimeEntry, error) {
+	var dataEntry TimeEntry
+	err := r.getDB(ctx).
+		Where("id = ? AND user_id = ?", entryID, userID).
+		First(&dataEntry).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.converter.TimeEntryDataToBiz(&dataEntry), nil
+}
+
+// GetRunningTimeEntry 获取用户正在运行的计时器（ended_at 为空），没有时返回 nil, nil
+// 在事务中加行锁，避免并发停止/开始时读到同一个计时器
+func (r *taskRepo) GetRunningTimeEntry(ctx context.Context, userID string) (*biz.TimeEntry, error) {
+	var dataEntries []*TimeEntry
+	err := r.getDB(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND ended_at IS NULL", userID).
+		Limit(1).
+		Find(&dataEntries).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(dataEntries) == 0 {
+		return nil, nil
+	}
+	return r.converter.TimeEntryDataToBiz(dataEntries[0]), nil
+}
+
+func (r *taskRepo) CreateKeyResultCheckIn(ctx context.Context, bizCheckIn *biz.KeyResultCheckIn) error {
+	return r.getDB(ctx).Create(r.converter.KeyResultCheckInBizToData(bizCheckIn)).Error
+}
+
+// ListKeyResultCheckIns 获取关键结果的打卡记录，按打卡时间倒序
+func (r *taskRepo) ListKeyResultCheckIns(ctx context.Context, taskID, userID string) ([]*biz.KeyResultCheckIn, error) {
+	var dataCheckIns []*KeyResultCheckIn
+	err := r.getDB(ctx).
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Order("checked_at DESC, created_at DESC").
+		Find(&dataCheckIns).Error
+	if err != nil {
+		return nil, err
+	}
+
+	bizCheckIns := make([]*biz.KeyResultCheckIn, len(dataCheckIns))
+	for i, dataCheckIn := range dataCheckIns {
+		bizCheckIns[i] = r.converter.KeyResultCheckInDataToBiz(dataCheckIn)
+	}
+	return bizCheckIns, nil
+}
+
+// GetLatestKeyResultCheckIn 获取打卡时间最晚的一次打卡，没有时返回 nil, nil
+func (r *taskRepo) GetLatestKeyResultCheckIn(ctx context.Context, taskID, userID string) (*biz.KeyResultCheckIn, error) {
+	var dataCheckIns []*KeyResultCheckIn
+	err := r.getDB(ctx).
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Order("checked_at DESC, created_at DESC").
+		Limit(1).
+		Find(&dataCheckIns).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(dataCheckIns) == 0 {
+		return nil, nil
+	}
+	return r.converter.KeyResultCheckInDataToBiz(dataCheckIns[0]), nil
+}
+
+// ListTimeEntries 获取任务的工时记录，按开始时间倒序
+func (r *taskRepo) ListTimeEntries(ctx context.Context, taskID, userID string) ([]*biz.TimeEntry, error) {
+	var dataEntries []*TimeEntry
+	err := r.getDB(ctx).
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Order("started_at DESC").
+		Find(&dataEntries).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.converter.TimeEntryDataToBizList(dataEntries), nil
+}
+
+// ListTimeEntriesInRange 获取开始时间落在 [start, end) 内且已结束的工时记录
+func (r *taskRepo) ListTimeEntriesInRange(ctx context.Context, userID string, start, end time.Time) ([]*biz.TimeEntry, error) {
+	var dataEntries []*TimeEntry
+	err := r.getDB(ctx).
+		Joins("JOIN tasks ON tasks.id = time_entries.task_id AND tasks.deleted_at IS NULL").
+		Where("time_entries.user_id = ? AND time_entries.ended_at IS NOT NULL AND time_entries.started_at >= ? AND time_entries.started_at < ?", userID, start, end).
+		Order("time_entries.started_at").
+		Find(&dataEntries).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.converter.TimeEntryDataToBizList(dataEntries), nil
+}
+
+// RefreshTaskTimeSpent 按工时记录重算任务自身耗时，再通过 root_task_id 重算根任务的整树耗时
+func (r *taskRepo) RefreshTaskTimeSpent(ctx context.Context, taskID, userID string) error {
+	err := r.getDB(ctx).Exec(`
+		UPDATE tasks SET time_spent = (
+			SELECT COALESCE(SUM(duration), 0) FROM time_entries
+			WHERE task_id = ? AND user_id = ? AND ended_at IS NOT NULL
+		) WHERE id = ? AND user_id = ?`,
+		taskID, userID, taskID, userID).Error
+	if err != nil {
+		return err
+	}
+
+	var rootTaskIDs []string
+	err = r.getDB(ctx).Model(&Task{}).
+		Where("id = ? AND user_id = ?", taskID, userID).
+		Pluck("root_task_id", &rootTaskIDs).Error
+	if err != nil {
+		return err
+	}
+	if len(rootTaskIDs) == 0 {
+		return nil
+	}
+	rootTaskID := rootTaskIDs[0]
+	if rootTaskID == "" {
+		rootTaskID = taskID // 兼容尚未计算树字段的旧数据
+	}
+	return r.RefreshTreeTimeSpent(ctx, rootTaskID, userID)
+}
+
+// RefreshTreeTimeSpent 重算根任务的整树耗时（root_task_id 相同的任务耗时之和，包括根任务自身）
+func (r *taskRepo) RefreshTreeTimeSpent(ctx context.Context, rootTaskID, userID string) error {
+	return r.getDB(ctx).Exec(`
+		UPDATE tasks SET tree_time_spent = (
+			SELECT COALESCE(SUM(time_spent), 0) FROM tasks
+			WHERE user_id = ? AND (root_task_id = ? OR id = ?) AND deleted_at IS NULL
+		) WHERE id = ? AND user_id = ?`,
+		userID, rootTaskID, rootTaskID, rootTaskID, userID).Error
+}
+
+// RefreshTreeEstimate 重算根任务的整树估算（root_task_id 相同且未取消的任务估算之和，包括根任务自身）
+func (r *taskRepo) RefreshTreeEstimate(ctx context.Context, rootTaskID, userID string) error {
+	return r.getDB(ctx).Exec(`
+		UPDATE tasks SET tree_estimate = (
+			SELECT COALESCE(SUM(estimate), 0) FROM tasks
+			WHERE user_id = ? AND (root_task_id = ? OR id = ?) AND status <> ? AND deleted_at IS NULL
+		) WHERE id = ? AND user_id = ?`,
+		userID, rootTaskID, rootTaskID, int(biz.TaskStatusCancelled), rootTaskID, userID).Error
+}
+
+// ListTrashedTasks 获取回收站中的任务，最近删除的在前
+func (r *taskRepo) ListTrashedTasks(ctx context.Context, userID string) ([]*biz.Task, error) {
+	var dataTasks []*Task
+	err := r.getDB(ctx).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC, tree_depth").
+		Find(&dataTasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.converter.DataToBizList(dataTasks), nil
+}
+
+// GetTrashedTask 获取回收站中的任务，不存在或未被删除时返回 nil, nil
+func (r *taskRepo) GetTrashedTask(ctx context.Context, taskID, userID string) (*biz.Task, error) {
+	var dataTasks []*Task
+	err := r.getDB(ctx).Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", taskID, userID).
+		Limit(1).
+		Find(&dataTasks).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(dataTasks) == 0 {
+		return nil, nil
+	}
+	return r.converter.DataToBiz(dataTasks[0]), nil
+}
+
+// RestoreTaskSubtree 恢复任务以及与它在同一时刻被删除的后代
+// 在此之前单独删除的后代 deleted_at 不同，继续留在回收站
+func (r *taskRepo) RestoreTaskSubtree(ctx context.Context, taskID, userID string) error {
+	var task Task
+	err := r.getDB(ctx).Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", taskID, userID).
+		First(&task).Error
+	if err != nil {
+		return err
+	}
+
+	ids := []string{task.ID}
+	visited := map[string]bool{task.ID: true}
+	parentIDs := []string{task.ID}
+	for len(parentIDs) > 0 {
+		var childIDs []string
+		err := r.getDB(ctx).Unscoped().Model(&Task{}).
+			Where("user_id = ? AND parent_id IN ? AND deleted_at = ?", userID, parentIDs, task.DeletedAt.Time).
+			Pluck("id", &childIDs).Error
+		if err != nil {
+			return err
+		}
+
+		nextIDs := make([]string, 0, len(childIDs))
+		for _, id := range childIDs {
+			if !visited[id] {
+				visited[id] = true
+				nextIDs = append(nextIDs, id)
+			}
+		}
+		ids = append(ids, nextIDs...)
+		parentIDs = nextIDs
+	}
+
+	return r.getDB(ctx).Unscoped().Model(&Task{}).
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Update("deleted_at", nil).Error
+}
+
+// PurgeTrashedTasks 永久删除在 before 之前进入回收站的任务
+// 清单项、依赖和工时记录通过外键级联删除
+func (r *taskRepo) PurgeTrashedTasks(ctx context.Context, before time.Time) (int64, error) {
+	result := r.getDB(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&Task{})
+	return result.RowsAffected, result.Error
+}
+
+// ListTaskUserIDs 有未删除任务的所有用户
+func (r *taskRepo) ListTaskUserIDs(ctx context.Context) ([]string, error) {
+	var userIDs []string
+	err := r.getDB(ctx).Model(&Task{}).
+		Distinct("user_id").
+		Order("user_id").
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// ListUserTasks 获取用户所有未删除的任务
+func (r *taskRepo) ListUserTasks(ctx context.Context, userID string) ([]*biz.Task, error) {
+	var dataTasks []*Task
+	err := r.getDB(ctx).
+		Where("user_id = ?", userID).
+		Order("tree_depth, sort_rank, created_at").
+		Find(&dataTasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.converter.DataToBizList(dataTasks), nil
+}
+
+// UpdateTaskTreeFields 只修改树结构字段，不更新 updated_at
+func (r *taskRepo) UpdateTaskTreeFields(ctx context.Context, task *biz.Task) error {
+	return r.getDB(ctx).Model(&Task{}).
+		Where("id = ? AND user_id = ?", task.ID, task.UserID).
+		UpdateColumns(map[string]interface{}{
+			"parent_id":      task.ParentID,
+			"root_task_id":   task.RootTaskID,
+			"tree_depth":     task.TreeDepth,
+			"children_count": task.ChildrenCount,
+			"has_children":   task.HasChildren,
+		}).Error
+}
+
 // buildTreeStructure 在内存中构建树形结构
-// 输入：已按 tree_depth 排序的任务列表
+// 输入：已按 tree_depth、sort_rank 排序的任务列表，同级任务保持输入中的顺序
 // 输出：构建好父子关系的任务树
 func (r *taskRepo) buildTreeStructure(tasks []*biz.Task) []*biz.Task {
 	if len(tasks) == 0 {
@@ -104,25 +759,12 @@ func (r *taskRepo) buildTreeStructure(tasks []*biz.Task) []*biz.Task {
 	return rootTasks
 }
 
+// ListTaskParentTree 获取任务及其所有祖先，从任务自身到根任务
 func (r *taskRepo) ListTaskParentTree(ctx context.Context, taskID, userID string) ([]*biz.Task, error) {
-	// 递归查询父任务树
-	var dataTasks []*Task
-
-	// 从当前任务开始，向上查询父任务
-	currentTaskID := taskID
-	for currentTaskID != "" {
-		var task Task
-		err := r.db.WithContext(ctx).
-			Where("id = ? AND user_id = ?", currentTaskID, userID).
-			First(&task).Error
-		if err != nil {
-			break
-		}
-
-		dataTasks = append(dataTasks, &task)
-		currentTaskID = task.ParentID
+	dataTasks, err := r.listAncestors(ctx, taskID, userID, "ASC")
+	if err != nil {
+		return nil, err
 	}
-
 	return r.converter.DataToBizList(dataTasks), nil
 }
 
@@ -130,7 +772,7 @@ func (r *taskRepo) ListTaskParentTree(ctx context.Context, taskID, userID string
 // 用于全局任务树视图的第一步：获取根任务列表
 func (r *taskRepo) ListRootTasksWithPagination(ctx context.Context, userID string, page, pageSize int, includeStatus []biz.TaskStatus) ([]*biz.Task, int64, error) {
 	// 构建查询条件
-	query := r.db.WithContext(ctx).Model(&Task{}).
+	query := r.getDB(ctx).Model(&Task{}).
 		Where("user_id = ? AND (parent_id IS NULL OR parent_id = '')", userID)
 
 	// 状态过滤：默认排除已取消的任务
@@ -154,7 +796,7 @@ func (r *taskRepo) ListRootTasksWithPagination(ctx context.Context, userID strin
 	// 分页查询
 	var dataTasks []*Task
 	offset := (page - 1) * pageSize
-	err := query.Order("created_at DESC").
+	err := query.Order("sort_rank, created_at DESC").
 		Offset(offset).
 		Limit(pageSize).
 		Find(&dataTasks).Error
@@ -174,7 +816,7 @@ func (r *taskRepo) ListTasksByRootIDs(ctx context.Context, userID string, rootTa
 	}
 
 	// 构建查询条件
-	query := r.db.WithContext(ctx).
+	query := r.getDB(ctx).
 		Where("user_id = ? AND root_task_id IN ?", userID, rootTaskIDs)
 
 	// 状态过滤
@@ -190,7 +832,7 @@ func (r *taskRepo) ListTasksByRootIDs(ctx context.Context, userID string, rootTa
 	}
 
 	var dataTasks []*Task
-	err := query.Order("root_task_id, tree_depth, created_at").
+	err := query.Order("tree_depth, sort_rank, created_at").
 		Find(&dataTasks).Error
 
 	if err != nil {
@@ -207,7 +849,7 @@ func (r *taskRepo) ListTasksByRootIDs(ctx context.Context, userID string, rootTa
 func (r *taskRepo) GetCompleteTaskTree(ctx context.Context, taskID, userID string, includeStatus []biz.TaskStatus) ([]*biz.Task, error) {
 	// 步骤1：获取指定任务的根任务ID
 	var rootTaskID string
-	err := r.db.WithContext(ctx).Model(&Task{}).
+	err := r.getDB(ctx).Model(&Task{}).
 		Select("root_task_id").
 		Where("id = ? AND user_id = ?", taskID, userID).
 		Scan(&rootTaskID).Error
@@ -216,7 +858,7 @@ func (r *taskRepo) GetCompleteTaskTree(ctx context.Context, taskID, userID strin
 	}
 
 	// 步骤2：获取完整的任务树
-	query := r.db.WithContext(ctx).
+	query := r.getDB(ctx).
 		Where("user_id = ? AND (id = ? OR root_task_id = ?)", userID, rootTaskID, rootTaskID)
 
 	// 状态过滤（父任务链查询时包含所有状态，便于理解完整层级关系）
@@ -229,7 +871,7 @@ func (r *taskRepo) GetCompleteTaskTree(ctx context.Context, taskID, userID strin
 	}
 
 	var dataTasks []*Task
-	err = query.Order("tree_depth, created_at").Find(&dataTasks).Error
+	err = query.Order("tree_depth, sort_rank, created_at").Find(&dataTasks).Error
 	if err != nil {
 		return nil, err
 	}
@@ -240,34 +882,56 @@ func (r *taskRepo) GetCompleteTaskTree(ctx context.Context, taskID, userID strin
 }
 
 // GetTaskParentChain 获取任务的父级链路
-// 从指定任务开始向上遍历，返回完整的父级链路（包含自身）
+// 一次递归查询返回从根任务到指定任务的完整链路（包含自身），不限制层数
 func (r *taskRepo) GetTaskParentChain(ctx context.Context, taskID, userID string) ([]*biz.Task, error) {
-	var parentChain []*biz.Task
-	currentTaskID := taskID
+	dataTasks, err := r.listAncestors(ctx, taskID, userID, "DESC")
+	if err != nil {
+		return nil, err
+	}
+	return r.converter.DataToBizList(dataTasks), nil
+}
 
-	// 循环向上查找父级任务，最多遍历5层（防止死循环）
-	for i := 0; i < 5 && currentTaskID != ""; i++ {
-		var dataTask Task
-		err := r.db.WithContext(ctx).
-			Where("id = ? AND user_id = ?", currentTaskID, userID).
-			First(&dataTask).Error
+// ListSubtreeTasks 获取任务及其所有后代，返回以该任务为根、带 Children 的树
+// 按 parent_id 递归查询，不依赖 root_task_id 等冗余字段
+func (r *taskRepo) ListSubtreeTasks(ctx context.Context, taskID, userID string, includeStatus []biz.TaskStatus) ([]*biz.Task, error) {
+	query := r.getDB(ctx).
+		Where("user_id = ? AND id IN (?)", userID, gorm.Expr(subtreeCTE+"SELECT id FROM subtree", taskID, userID, userID))
 
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				break // 任务不存在，结束查找
-			}
-			return nil, err
+	// 状态过滤：被过滤掉的任务，其后代也不再返回
+	if len(includeStatus) > 0 {
+		statusInts := make([]int, len(includeStatus))
+		for i, status := range includeStatus {
+			statusInts[i] = int(status)
 		}
+		query = query.Where("(id = ? OR status IN ?)", taskID, statusInts)
+	}
 
-		// 转换为业务模型并添加到链路前端
-		bizTask := r.converter.DataToBiz(&dataTask)
-		parentChain = append([]*biz.Task{bizTask}, parentChain...)
-
-		// 设置下一个要查找的父级任务ID
-		currentTaskID = dataTask.ParentID
+	var dataTasks []*Task
+	err := query.Order("tree_depth, sort_rank, created_at").Find(&dataTasks).Error
+	if err != nil {
+		return nil, err
 	}
 
-	return parentChain, nil
+	bizTasks := r.converter.DataToBizList(dataTasks)
+	taskMap := make(map[string]*biz.Task, len(bizTasks))
+	for _, task := range bizTasks {
+		task.Children = make([]*biz.Task, 0)
+		taskMap[task.ID] = task
+	}
+	root, ok := taskMap[taskID]
+	if !ok {
+		return []*biz.Task{}, nil
+	}
+	// 父任务被过滤掉的任务挂不到树上，不会返回
+	for _, task := range bizTasks {
+		if task == root {
+			continue
+		}
+		if parent, exists := taskMap[task.ParentID]; exists {
+			parent.Children = append(parent.Children, task)
+		}
+	}
+	return []*biz.Task{root}, nil
 }
 
 // UpdateTreeOptimizationFields 更新任务的树优化字段
@@ -275,41 +939,35 @@ func (r *taskRepo) GetTaskParentChain(ctx context.Context, taskID, userID string
 func (r *taskRepo) UpdateTreeOptimizationFields(ctx context.Context, taskID, userID string) error {
 	// 获取任务详情
 	var task Task
-	err := r.db.WithContext(ctx).
+	err := r.getDB(ctx).
 		Where("id = ? AND user_id = ?", taskID, userID).
 		First(&task).Error
 	if err != nil {
 		return err
 	}
 
-	// 计算树深度和根任务ID
-	treeDepth := 0
-	rootTaskID := taskID
-	currentParentID := task.ParentID
-
-	// 向上遍历找到根任务并计算深度
-	for currentParentID != "" && treeDepth < 5 {
-		var parentTask Task
-		err := r.db.WithContext(ctx).
-			Select("id, parent_id").
-			Where("id = ? AND user_id = ?", currentParentID, userID).
-			First(&parentTask).Error
-		if err != nil {
-			// 父任务查询失败，返回错误避免写入脏数据
-			return fmt.Errorf("failed to query parent task %s: %w", currentParentID, err)
-		}
-
-		treeDepth++
-		if parentTask.ParentID == "" {
-			rootTaskID = parentTask.ID
-			break
-		}
-		currentParentID = parentTask.ParentID
+	// 一次递归查询找到根任务，层数即树深度
+	var top struct {
+		ID       string
+		ParentID string
+		Level    int
+	}
+	err = r.getDB(ctx).
+		Raw(ancestorsCTE+"SELECT id, parent_id, level FROM ancestors ORDER BY level DESC LIMIT 1", taskID, userID, userID).
+		Scan(&top).Error
+	if err != nil {
+		return err
 	}
+	if top.ParentID != "" {
+		// 最上层的任务仍有父任务：父任务不存在或形成环，返回错误避免写入脏数据
+		return fmt.Errorf("failed to query parent task %s: %w", top.ParentID, gorm.ErrRecordNotFound)
+	}
+	treeDepth := top.Level
+	rootTaskID := top.ID
 
 	// 计算子任务数量
 	var childrenCount int64
-	err = r.db.WithContext(ctx).Model(&Task{}).
+	err = r.getDB(ctx).Model(&Task{}).
 		Where("parent_id = ? AND user_id = ?", taskID, userID).
 		Count(&childrenCount).Error
 	if err != nil {
@@ -324,9 +982,33 @@ func (r *taskRepo) UpdateTreeOptimizationFields(ctx context.Context, taskID, use
 		"has_children":   childrenCount > 0,
 	}
 
-	return r.db.WithContext(ctx).Model(&Task{}).
-		Where("id = ? AND user_id = ?", taskID, userID).
-		Updates(updates).Error
+	return r.getDB(ctx).Model(&Task{}).
+		Where("id = ? AND user_id = ?", taskID, userID).
+		Updates(updates).Error
+}
+
+// RebuildSubtreeOptimizationFields 重建以指定任务为根的整棵子树的树优化字段
+// 用于任务移动后：先按新位置重算任务自身的字段，再用一条递归更新刷新所有后代的 root_task_id 和 tree_depth
+// 后代之间的父子关系没有变化，所以它们的 children_count / has_children 不需要重算
+func (r *taskRepo) RebuildSubtreeOptimizationFields(ctx context.Context, taskID, userID string) error {
+	if err := r.UpdateTreeOptimizationFields(ctx, taskID, userID); err != nil {
+		return err
+	}
+
+	var task Task
+	err := r.getDB(ctx).
+		Select("id, root_task_id, tree_depth").
+		Where("id = ? AND user_id = ?", taskID, userID).
+		First(&task).Error
+	if err != nil {
+		return err
+	}
+
+	// 一条语句刷新所有后代，深度为任务深度加上相对层数
+	return r.getDB(ctx).
+		Exec(subtreeCTE+`UPDATE tasks SET root_task_id = ?, tree_depth = ? + subtree.level
+	FROM subtree WHERE tasks.id = subtree.id AND subtree.level > 0`,
+			taskID, userID, userID, task.RootTaskID, task.TreeDepth).Error
 }
 
 // JournalRepo 日志仓库实现
@@ -342,14 +1024,23 @@ func NewJournalRepo(db *gorm.DB) biz.JournalRepo {
 	}
 }
 
+func (r *journalRepo) getDB(ctx context.Context) *gorm.DB {
+	return dbFromContext(ctx, r.db)
+}
+
+// Transaction 在同一个数据库事务中执行 fn，与 taskRepo.Transaction 相同
+func (r *journalRepo) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return runInTransaction(ctx, r.db, fn)
+}
+
 func (r *journalRepo) CreateJournal(ctx context.Context, bizJournal *biz.Journal) error {
 	dataJournal := r.converter.BizToData(bizJournal)
-	return r.db.WithContext(ctx).Create(dataJournal).Error
+	return r.getDB(ctx).Create(dataJournal).Error
 }
 
 func (r *journalRepo) GetJournalWithAuth(ctx context.Context, journalID, userID string) (*biz.Journal, error) {
     var dataJournal Journal
-    err := r.db.WithContext(ctx).
+    err := r.getDB(ctx).
         Where("id = ? AND user_id = ?", journalID, userID).
         First(&dataJournal).Error
 
@@ -365,20 +1056,19 @@ func (r *journalRepo) GetJournalWithAuth(ctx context.Context, journalID, userID
 
 func (r *journalRepo) UpdateJournal(ctx context.Context, bizJournal *biz.Journal) error {
 	dataJournal := r.converter.BizToData(bizJournal)
-	return r.db.WithContext(ctx).Save(dataJournal).Error
+	return r.getDB(ctx).Save(dataJournal).Error
 }
 
 func (r *journalRepo) DeleteJournalWithAuth(ctx context.Context, journalID, userID string) error {
-	return r.db.WithContext(ctx).
+	return r.getDB(ctx).
 		Where("id = ? AND user_id = ?", journalID, userID).
 		Delete(&Journal{}).Error
 }
 
-func (r *journalRepo) ListJournals(ctx context.Context, userID string, periodStart, periodEnd time.Time, journalType int) ([]*biz.Journal, error) {
+func (r *journalRepo) ListJournals(ctx context.Context, userID string, periodStart, periodEnd time.Time, journalType int, match biz.PeriodMatch) ([]*biz.Journal, error) {
 	var dataJournals []*Journal
-	err := r.db.WithContext(ctx).
-		Where("user_id = ? AND journal_type = ? AND period_start >= ? AND period_end <= ?",
-			userID, journalType, periodStart, periodEnd).
+	query := r.getDB(ctx).Where("user_id = ? AND journal_type = ?", userID, journalType)
+	err := wherePeriodMatch(query, match, &periodStart, &periodEnd).
 		Find(&dataJournals).Error
 
 	if err != nil {
@@ -388,9 +1078,23 @@ func (r *journalRepo) ListJournals(ctx context.Context, userID string, periodSta
 	return r.converter.DataToBizList(dataJournals), nil
 }
 
+// ListJournalsContainingTime 获取时间段包含 t 的所有类型的日志
+func (r *journalRepo) ListJournalsContainingTime(ctx context.Context, userID string, t time.Time) ([]*biz.Journal, error) {
+	var dataJournals []*Journal
+	err := r.getDB(ctx).
+		Where("user_id = ? AND period_start <= ? AND period_end > ?", userID, t, t).
+		Order("journal_type DESC, period_start, created_at").
+		Find(&dataJournals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.converter.DataToBizList(dataJournals), nil
+}
+
 func (r *journalRepo) ListAllJournals(ctx context.Context, userID string, offset, limit int) ([]*biz.Journal, error) {
 	var dataJournals []*Journal
-	err := r.db.WithContext(ctx).
+	err := r.getDB(ctx).
 		Where("user_id = ?", userID).
 		Offset(offset).
 		Limit(limit).
@@ -406,9 +1110,9 @@ func (r *journalRepo) ListAllJournals(ctx context.Context, userID string, offset
 
 // ListJournalsWithPagination 分页查询日志并返回总数
 // 支持按日志类型过滤和时间范围过滤
-func (r *journalRepo) ListJournalsWithPagination(ctx context.Context, userID string, page, pageSize int, journalType *int, periodStart, periodEnd *time.Time) ([]*biz.Journal, int64, error) {
+func (r *journalRepo) ListJournalsWithPagination(ctx context.Context, userID string, page, pageSize int, journalType *int, periodStart, periodEnd *time.Time, match biz.PeriodMatch) ([]*biz.Journal, int64, error) {
 	// 构建基础查询
-	query := r.db.WithContext(ctx).Model(&Journal{}).Where("user_id = ?", userID)
+	query := r.getDB(ctx).Model(&Journal{}).Where("user_id = ?", userID)
 
 	// 日志类型过滤
 	if journalType != nil {
@@ -416,12 +1120,7 @@ func (r *journalRepo) ListJournalsWithPagination(ctx context.Context, userID str
 	}
 
 	// 时间范围过滤
-	if periodStart != nil {
-		query = query.Where("period_start >= ?", *periodStart)
-	}
-	if periodEnd != nil {
-		query = query.Where("period_end <= ?", *periodEnd)
-	}
+	query = wherePeriodMatch(query, match, periodStart, periodEnd)
 
 	// 获取总数
 	var total int64
@@ -444,6 +1143,56 @@ func (r *journalRepo) ListJournalsWithPagination(ctx context.Context, userID str
 	return r.converter.DataToBizList(dataJournals), total, nil
 }
 
+// ListTrashedJournals 获取回收站中的日志，最近删除的在前
+func (r *journalRepo) ListTrashedJournals(ctx context.Context, userID string) ([]*biz.Journal, error) {
+	var dataJournals []*Journal
+	err := r.getDB(ctx).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Find(&dataJournals).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.converter.DataToBizList(dataJournals), nil
+}
+
+// GetTrashedJournal 获取回收站中的日志，不存在或未被删除时返回 model.ErrRecordNotFound
+func (r *journalRepo) GetTrashedJournal(ctx context.Context, journalID, userID string) (*biz.Journal, error) {
+	var dataJournal Journal
+	err := r.getDB(ctx).Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", journalID, userID).
+		First(&dataJournal).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return r.converter.DataToBiz(&dataJournal), nil
+}
+
+// RestoreJournal 恢复回收站中的日志，不存在时返回 model.ErrRecordNotFound
+func (r *journalRepo) RestoreJournal(ctx context.Context, journalID, userID string) error {
+	result := r.getDB(ctx).Unscoped().Model(&Journal{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", journalID, userID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeTrashedJournals 永久删除在 before 之前进入回收站的日志
+func (r *journalRepo) PurgeTrashedJournals(ctx context.Context, before time.Time) (int64, error) {
+	result := r.getDB(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&Journal{})
+	return result.RowsAffected, result.Error
+}
+
 // UserRepo 用户仓库实现
 type userRepo struct {
 	db        *gorm.DB
@@ -520,3 +1269,549 @@ func (r *userRepo) DeleteUser(ctx context.Context, userID string) error {
 		Where("id = ?", userID).
 		Delete(&User{}).Error
 }
+
+// UserSettingsRepo 用户设置仓库实现
+type userSettingsRepo struct {
+	db        *gorm.DB
+	converter *UserSettingsConverter
+}
+
+func NewUserSettingsRepo(db *gorm.DB) biz.UserSettingsRepo {
+	return &userSettingsRepo{
+		db:        db,
+		converter: NewUserSettingsConverter(),
+	}
+}
+
+func (r *userSettingsRepo) GetUserSettings(ctx context.Context, userID string) (*biz.UserSettings, error) {
+	var dataSettings UserSettings
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		First(&dataSettings).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 用户还没有保存过设置，由业务层使用默认值
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return r.converter.DataToBiz(&dataSettings), nil
+}
+
+func (r *userSettingsRepo) SaveUserSettings(ctx context.Context, bizSettings *biz.UserSettings) error {
+	dataSettings := r.converter.BizToData(bizSettings)
+	return dbFromContext(ctx, r.db).Save(dataSettings).Error
+}
+
+// ListAutoRolloverSettings 获取开启了自动顺延的用户设置
+func (r *userSettingsRepo) ListAutoRolloverSettings(ctx context.Context) ([]*biz.UserSettings, error) {
+	var dataSettings []*UserSettings
+	err := dbFromContext(ctx, r.db).
+		Where("auto_rollover <> ?", int(biz.RolloverModeOff)).
+		Find(&dataSettings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]*biz.UserSettings, len(dataSettings))
+	for i, dataSetting := range dataSettings {
+		settings[i] = r.converter.DataToBiz(dataSetting)
+	}
+	return settings, nil
+}
+
+// CronTaskRepo 周期任务仓库实现
+type cronTaskRepo struct {
+	db        *gorm.DB
+	converter *CronTaskConverter
+}
+
+func NewCronTaskRepo(db *gorm.DB) biz.CronTaskRepo {
+	return &cronTaskRepo{
+		db:        db,
+		converter: NewCronTaskConverter(),
+	}
+}
+
+func (r *cronTaskRepo) CreateCronTask(ctx context.Context, bizCronTask *biz.CronTask) error {
+	dataCronTask := r.converter.BizToData(bizCronTask)
+	return dbFromContext(ctx, r.db).Create(dataCronTask).Error
+}
+
+func (r *cronTaskRepo) UpdateCronTask(ctx context.Context, bizCronTask *biz.CronTask) error {
+	dataCronTask := r.converter.BizToData(bizCronTask)
+	return dbFromContext(ctx, r.db).Save(dataCronTask).Error
+}
+
+func (r *cronTaskRepo) DeleteCronTask(ctx context.Context, cronTaskID, userID string) error {
+	return dbFromContext(ctx, r.db).
+		Where("id = ? AND user_id = ?", cronTaskID, userID).
+		Delete(&CronTask{}).Error
+}
+
+func (r *cronTaskRepo) GetCronTask(ctx context.Context, cronTaskID, userID string) (*biz.CronTask, error) {
+	var dataCronTask CronTask
+	err := dbFromContext(ctx, r.db).
+		Where("id = ? AND user_id = ?", cronTaskID, userID).
+		First(&dataCronTask).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 与任务一致：返回 nil, nil，由上层判断为未找到
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return r.converter.DataToBiz(&dataCronTask), nil
+}
+
+func (r *cronTaskRepo) ListCronTasks(ctx context.Context, userID string) ([]*biz.CronTask, error) {
+	var dataCronTasks []*CronTask
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&dataCronTasks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.converter.DataToBizList(dataCronTasks), nil
+}
+
+func (r *cronTaskRepo) ListActiveCronTasks(ctx context.Context, date time.Time) ([]*biz.CronTask, error) {
+	var dataCronTasks []*CronTask
+	err := dbFromContext(ctx, r.db).
+		Where("end_date IS NULL OR end_date >= ?", date).
+		Order("created_at ASC").
+		Find(&dataCronTasks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.converter.DataToBizList(dataCronTasks), nil
+}
+
+// changeHistoryRepo 变更历史仓库实现
+type changeHistoryRepo struct {
+	db        *gorm.DB
+	converter *ChangeRecordConverter
+}
+
+func NewChangeHistoryRepo(db *gorm.DB) biz.ChangeHistoryRepo {
+	return &changeHistoryRepo{
+		db:        db,
+		converter: NewChangeRecordConverter(),
+	}
+}
+
+// CreateChangeRecords 批量写入变更记录，在事务中调用时与实体的修改一起提交
+func (r *changeHistoryRepo) CreateChangeRecords(ctx context.Context, records []*biz.ChangeRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return dbFromContext(ctx, r.db).Create(r.converter.BizToDataList(records)).Error
+}
+
+// ListChangeRecords 获取实体的变更记录，最近的在前
+func (r *changeHistoryRepo) ListChangeRecords(ctx context.Context, entityType biz.ChangeEntityType, entityID, userID string) ([]*biz.ChangeRecord, error) {
+	var dataRecords []*ChangeRecord
+	err := dbFromContext(ctx, r.db).
+		Where("entity_type = ? AND entity_id = ? AND user_id = ?", string(entityType), entityID, userID).
+		Order("created_at DESC, field ASC").
+		Find(&dataRecords).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.converter.DataToBizList(dataRecords), nil
+}
+
+// taskTemplateRepo 任务模板仓库实现
+type taskTemplateRepo struct {
+	db        *gorm.DB
+	converter *TaskTemplateConverter
+}
+
+func NewTaskTemplateRepo(db *gorm.DB) biz.TaskTemplateRepo {
+	return &taskTemplateRepo{
+		db:        db,
+		converter: NewTaskTemplateConverter(),
+	}
+}
+
+func (r *taskTemplateRepo) CreateTaskTemplate(ctx context.Context, template *biz.TaskTemplate) error {
+	dataTemplate, err := r.converter.BizToData(template)
+	if err != nil {
+		return err
+	}
+	return dbFromContext(ctx, r.db).Create(dataTemplate).Error
+}
+
+func (r *taskTemplateRepo) DeleteTaskTemplate(ctx context.Context, templateID, userID string) error {
+	return dbFromContext(ctx, r.db).
+		Where("id = ? AND user_id = ?", templateID, userID).
+		Delete(&TaskTemplate{}).Error
+}
+
+// GetTaskTemplate 获取模板，不存在时返回 nil, nil
+func (r *taskTemplateRepo) GetTaskTemplate(ctx context.Context, templateID, userID string) (*biz.TaskTemplate, error) {
+	var dataTemplate TaskTemplate
+	err := dbFromContext(ctx, r.db).
+		Where("id = ? AND user_id = ?", templateID, userID).
+		First(&dataTemplate).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.converter.DataToBiz(&dataTemplate)
+}
+
+// ListTaskTemplates 获取用户的全部模板，最近创建的在前
+func (r *taskTemplateRepo) ListTaskTemplates(ctx context.Context, userID string) ([]*biz.TaskTemplate, error) {
+	var dataTemplates []*TaskTemplate
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&dataTemplates).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.converter.DataToBizList(dataTemplates)
+}
+
+// searchRepo 全文搜索仓库实现
+type searchRepo struct {
+	db               *gorm.DB
+	taskConverter    *TaskConverter
+	journalConverter *JournalConverter
+}
+
+func NewSearchRepo(db *gorm.DB) biz.SearchRepo {
+	return &searchRepo{
+		db:               db,
+		taskConverter:    NewTaskConverter(),
+		journalConverter: NewJournalConverter(),
+	}
+}
+
+// 带相关度的搜索结果行
+type taskSearchRow struct {
+	Task `gorm:"embedded"`
+	Rank float64
+}
+
+type journalSearchRow struct {
+	Journal `gorm:"embedded"`
+	Rank    float64
+}
+
+// 相关度：全文检索得分加上标题的三元组相似度，后者让中文等无法分词的内容也有区分度
+const searchRankExpr = "ts_rank(search_vector, plainto_tsquery('simple', ?)) + similarity(title, ?)"
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// searchMatch 全文检索命中，或者每个关键词都以子串形式出现在任一字段中
+func searchMatch(db *gorm.DB, q biz.SearchQuery, columns ...string) *gorm.DB {
+	args := []interface{}{q.Query}
+	terms := make([]string, 0, len(q.Terms))
+	for _, term := range q.Terms {
+		pattern := "%" + escapeLike(term) + "%"
+		conds := make([]string, len(columns))
+		for i, column := range columns {
+			conds[i] = column + " ILIKE ?"
+			args = append(args, pattern)
+		}
+		terms = append(terms, "("+strings.Join(conds, " OR ")+")")
+	}
+	return db.Where("(search_vector @@ plainto_tsquery('simple', ?) OR ("+strings.Join(terms, " AND ")+"))", args...)
+}
+
+// searchFilters 类型和时间段过滤，时间段按开始时间落在 [StartDate, EndDate) 内匹配
+func searchFilters(db *gorm.DB, q biz.SearchQuery, typeColumn string) *gorm.DB {
+	if q.PeriodType != nil {
+		db = db.Where(typeColumn+" = ?", int(*q.PeriodType))
+	}
+	if q.StartDate != nil {
+		db = db.Where("period_start >= ?", *q.StartDate)
+	}
+	if q.EndDate != nil {
+		db = db.Where("period_start < ?", *q.EndDate)
+	}
+	return db
+}
+
+func (r *searchRepo) SearchTasks(ctx context.Context, q biz.SearchQuery) ([]*biz.SearchHit, int64, error) {
+	query := dbFromContext(ctx, r.db).Model(&Task{}).
+		Where("user_id = ? AND deleted_at IS NULL", q.UserID)
+	query = searchMatch(query, q, "title", "tags")
+	query = searchFilters(query, q, "task_type")
+	if len(q.Statuses) > 0 {
+		statuses := make([]int, len(q.Statuses))
+		for i, status := range q.Statuses {
+			statuses[i] = int(status)
+		}
+		query = query.Where("status IN ?", statuses)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []taskSearchRow
+	err := query.Session(&gorm.Session{}).
+		Select("tasks.*, ("+searchRankExpr+") AS rank", q.Query, q.Query).
+		Order("rank DESC, period_start DESC").
+		Limit(q.Limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]*biz.SearchHit, len(rows))
+	for i := range rows {
+		hits[i] = &biz.SearchHit{
+			Kind: biz.SearchEntityTask,
+			Rank: rows[i].Rank,
+			Task: r.taskConverter.DataToBiz(&rows[i].Task),
+		}
+	}
+	return hits, total, nil
+}
+
+func (r *searchRepo) SearchJournals(ctx context.Context, q biz.SearchQuery) ([]*biz.SearchHit, int64, error) {
+	query := dbFromContext(ctx, r.db).Model(&Journal{}).
+		Where("user_id = ? AND deleted_at IS NULL", q.UserID)
+	query = searchMatch(query, q, "title", "content")
+	query = searchFilters(query, q, "journal_type")
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []journalSearchRow
+	err := query.Session(&gorm.Session{}).
+		Select("journals.*, ("+searchRankExpr+") AS rank", q.Query, q.Query).
+		Order("rank DESC, period_start DESC").
+		Limit(q.Limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]*biz.SearchHit, len(rows))
+	for i := range rows {
+		hits[i] = &biz.SearchHit{
+			Kind:    biz.SearchEntityJournal,
+			Rank:    rows[i].Rank,
+			Journal: r.journalConverter.DataToBiz(&rows[i].Journal),
+		}
+	}
+	return hits, total, nil
+}
+
+// tagRepo 标签仓库实现
+type tagRepo struct {
+	db            *gorm.DB
+	converter     *TagConverter
+	taskConverter *TaskConverter
+}
+
+func NewTagRepo(db *gorm.DB) biz.TagRepo {
+	return &tagRepo{
+		db:            db,
+		converter:     NewTagConverter(),
+		taskConverter: NewTaskConverter(),
+	}
+}
+
+// 带使用次数的标签行
+type tagWithCount struct {
+	Tag       `gorm:"embedded"`
+	TaskCount int
+}
+
+func (r *tagRepo) ListTags(ctx context.Context, userID string) ([]*biz.Tag, error) {
+	var rows []tagWithCount
+	err := dbFromContext(ctx, r.db).Table("tags").
+		Select("tags.*, COUNT(tasks.id) AS task_count").
+		Joins("LEFT JOIN task_tags ON task_tags.tag_id = tags.id").
+		Joins("LEFT JOIN tasks ON tasks.id = task_tags.task_id AND tasks.deleted_at IS NULL").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id").
+		Order("tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]*biz.Tag, len(rows))
+	for i := range rows {
+		tags[i] = r.converter.DataToBiz(&rows[i].Tag)
+		tags[i].TaskCount = rows[i].TaskCount
+	}
+	return tags, nil
+}
+
+// GetTag 获取标签，不存在时返回 nil, nil
+func (r *tagRepo) GetTag(ctx context.Context, tagID, userID string) (*biz.Tag, error) {
+	return r.findTag(ctx, "id = ? AND user_id = ?", tagID, userID)
+}
+
+// GetTagByName 按名称获取标签，不存在时返回 nil, nil
+func (r *tagRepo) GetTagByName(ctx context.Context, userID, name string) (*biz.Tag, error) {
+	return r.findTag(ctx, "user_id = ? AND name = ?", userID, name)
+}
+
+func (r *tagRepo) findTag(ctx context.Context, query string, args ...interface{}) (*biz.Tag, error) {
+	var dataTag Tag
+	err := dbFromContext(ctx, r.db).Where(query, args...).First(&dataTag).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.converter.DataToBiz(&dataTag), nil
+}
+
+func (r *tagRepo) CreateTag(ctx context.Context, bizTag *biz.Tag) error {
+	return dbFromContext(ctx, r.db).Create(r.converter.BizToData(bizTag)).Error
+}
+
+// refreshTaskTagText 按 task_tags 重写任务上冗余的标签文本，包括回收站中的任务
+func (r *tagRepo) refreshTaskTagText(ctx context.Context, taskIDs []string) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	return dbFromContext(ctx, r.db).Exec(`
+		UPDATE tasks SET tags = COALESCE((
+			SELECT string_agg(tags.name, ? ORDER BY task_tags.position, tags.name)
+			FROM task_tags JOIN tags ON tags.id = task_tags.tag_id
+			WHERE task_tags.task_id = tasks.id
+		), '')
+		WHERE id IN ?`, TaskTagsSeparator, taskIDs).Error
+}
+
+// taggedTaskIDs 使用标签的全部任务ID（包括回收站中的任务）
+func (r *tagRepo) taggedTaskIDs(ctx context.Context, tagID string) ([]string, error) {
+	var taskIDs []string
+	err := dbFromContext(ctx, r.db).Model(&TaskTag{}).
+		Where("tag_id = ?", tagID).
+		Pluck("task_id", &taskIDs).Error
+	return taskIDs, err
+}
+
+// UpdateTag 更新名称、颜色和描述，名称变化时同步任务上冗余的标签文本
+func (r *tagRepo) UpdateTag(ctx context.Context, bizTag *biz.Tag) error {
+	return runInTransaction(ctx, r.db, func(ctx context.Context) error {
+		var old Tag
+		if err := dbFromContext(ctx, r.db).Where("id = ?", bizTag.ID).First(&old).Error; err != nil {
+			return err
+		}
+		if err := dbFromContext(ctx, r.db).Save(r.converter.BizToData(bizTag)).Error; err != nil {
+			return err
+		}
+		if old.Name == bizTag.Name {
+			return nil
+		}
+		taskIDs, err := r.taggedTaskIDs(ctx, bizTag.ID)
+		if err != nil {
+			return err
+		}
+		return r.refreshTaskTagText(ctx, taskIDs)
+	})
+}
+
+// MergeTags 把源标签的任务关联转到目标标签并删除源标签
+// 已经同时带有两个标签的任务只保留目标标签，位置取两者中靠前的一个
+func (r *tagRepo) MergeTags(ctx context.Context, userID, sourceID, targetID string) error {
+	return runInTransaction(ctx, r.db, func(ctx context.Context) error {
+		err := dbFromContext(ctx, r.db).Exec(`
+			INSERT INTO task_tags (task_id, tag_id, position)
+			SELECT task_id, ?, position FROM task_tags WHERE tag_id = ?
+			ON CONFLICT (task_id, tag_id) DO UPDATE SET position = LEAST(task_tags.position, EXCLUDED.position)`,
+			targetID, sourceID).Error
+		if err != nil {
+			return err
+		}
+		// 删除源标签时重写受影响任务的冗余文本
+		return r.DeleteTag(ctx, sourceID, userID)
+	})
+}
+
+// DeleteTag 删除标签及其任务关联，同步任务上冗余的标签文本
+func (r *tagRepo) DeleteTag(ctx context.Context, tagID, userID string) error {
+	return runInTransaction(ctx, r.db, func(ctx context.Context) error {
+		taskIDs, err := r.taggedTaskIDs(ctx, tagID)
+		if err != nil {
+			return err
+		}
+		if err := dbFromContext(ctx, r.db).Where("tag_id = ?", tagID).Delete(&TaskTag{}).Error; err != nil {
+			return err
+		}
+		err = dbFromContext(ctx, r.db).
+			Where("id = ? AND user_id = ?", tagID, userID).
+			Delete(&Tag{}).Error
+		if err != nil {
+			return err
+		}
+		return r.refreshTaskTagText(ctx, taskIDs)
+	})
+}
+
+// tagExprSQL 把标签表达式转换为针对 tasks 表的 SQL 条件
+func tagExprSQL(expr *biz.TagExpr) (string, []interface{}) {
+	switch expr.Op {
+	case biz.TagExprAnd, biz.TagExprOr:
+		joiner := " AND "
+		if expr.Op == biz.TagExprOr {
+			joiner = " OR "
+		}
+		conds := make([]string, len(expr.Children))
+		args := make([]interface{}, 0)
+		for i, child := range expr.Children {
+			cond, childArgs := tagExprSQL(child)
+			conds[i] = cond
+			args = append(args, childArgs...)
+		}
+		return "(" + strings.Join(conds, joiner) + ")", args
+	case biz.TagExprNot:
+		cond, args := tagExprSQL(expr.Children[0])
+		return "NOT " + cond, args
+	default:
+		return `EXISTS (SELECT 1 FROM task_tags JOIN tags ON tags.id = task_tags.tag_id
+			WHERE task_tags.task_id = tasks.id AND tags.name = ?)`, []interface{}{expr.Name}
+	}
+}
+
+// ListTasksByTagExpr 按标签表达式分页查询未删除的任务，按时间段倒序
+func (r *tagRepo) ListTasksByTagExpr(ctx context.Context, userID string, expr *biz.TagExpr, limit, offset int) ([]*biz.Task, int64, error) {
+	cond, args := tagExprSQL(expr)
+	query := dbFromContext(ctx, r.db).Model(&Task{}).
+		Where("user_id = ?", userID).
+		Where(cond, args...)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("period_start DESC, created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+	var dataTasks []*Task
+	if err := query.Find(&dataTasks).Error; err != nil {
+		return nil, 0, err
+	}
+	return r.taskConverter.DataToBizList(dataTasks), total, nil
+}
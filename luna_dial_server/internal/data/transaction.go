@@ -0,0 +1,31 @@
+package data
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txContextKey 事务在 context 中的键
+type txContextKey struct{}
+
+// withTx 将事务句柄放入 context，供同一事务内的仓库方法复用
+func withTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// dbFromContext 优先返回 context 中的事务句柄，没有事务时返回普通连接
+func dbFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok && tx != nil {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// runInTransaction 在事务中执行 fn
+// 如果 context 中已经存在事务，则使用 SAVEPOINT 开启嵌套事务，内层失败只回滚到保存点
+func runInTransaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	return dbFromContext(ctx, db).Transaction(func(tx *gorm.DB) error {
+		return fn(withTx(ctx, tx))
+	})
+}
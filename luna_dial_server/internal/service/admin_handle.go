@@ -0,0 +1,36 @@
+package service
+
+import (
+	"luna_dial/internal/biz"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 系统初始化时创建的管理员账号
+const adminUserName = "admin"
+
+// 检查任务树（管理员）：报告所有用户或指定用户的任务树问题，可选在事务中修复
+func (s *Service) handleCheckTaskTrees(c echo.Context) error {
+	_, username, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+	if username != adminUserName {
+		return c.JSON(403, NewErrorResponse(403, "Admin only"))
+	}
+
+	var req CheckTaskTreesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+
+	report, err := s.taskUsecase.CheckTaskTrees(c.Request().Context(), biz.CheckTaskTreesParam{
+		UserID: req.UserID,
+		Fix:    req.Fix,
+	})
+	if err != nil {
+		c.Logger().Error("Failed to check task trees:", err)
+		return c.JSON(500, NewErrorResponse(500, "Failed to check task trees"))
+	}
+	return c.JSON(200, NewSuccessResponse(report))
+}
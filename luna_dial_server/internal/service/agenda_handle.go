@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+	"luna_dial/internal/biz"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 获取指定日期的日程：时间段包含该日期的年、季度、月、周、日任务（附带祖先链）和日志
+func (s *Service) handleGetAgenda(c echo.Context) error {
+	var req GetAgendaRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid date format, expected YYYY-MM-DD"))
+	}
+
+	agenda, err := s.planUsecase.GetAgenda(c.Request().Context(), biz.GetAgendaParam{
+		UserID: userID,
+		Date:   date,
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrInvalidInput) {
+			return c.JSON(400, NewErrorResponse(400, "Invalid date"))
+		}
+		c.Logger().Error("Failed to get agenda:", err)
+		return c.JSON(500, NewErrorResponse(500, "Failed to get agenda"))
+	}
+	return c.JSON(200, NewSuccessResponse(agenda))
+}
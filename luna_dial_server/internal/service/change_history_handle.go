@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"luna_dial/internal/biz"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 获取任务的变更历史
+func (s *Service) handleListTaskHistory(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	records, err := s.taskUsecase.ListTaskHistory(c.Request().Context(), biz.ListChangeHistoryParam{
+		EntityID: taskID,
+		UserID:   userID,
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrTaskNotFound) {
+			return c.JSON(404, NewErrorResponse(404, "Task not found"))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to list task history"))
+	}
+	return c.JSON(200, NewSuccessResponse(records))
+}
+
+// 获取日志的变更历史
+func (s *Service) handleListJournalHistory(c echo.Context) error {
+	journalID := c.Param("journal_id")
+	if journalID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Journal ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	records, err := s.journalUsecase.ListJournalHistory(c.Request().Context(), biz.ListChangeHistoryParam{
+		EntityID: journalID,
+		UserID:   userID,
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrJournalNotFound) {
+			return c.JSON(404, NewErrorResponse(404, "Journal not found"))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to list journal history"))
+	}
+	return c.JSON(200, NewSuccessResponse(records))
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"luna_dial/internal/biz"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 获取当前用户的周期任务列表
+func (s *Service) handleListCronTasks(c echo.Context) error {
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	cronTasks, err := s.cronTaskUsecase.ListCronTasks(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(500, NewErrorResponse(500, "Failed to list cron tasks"))
+	}
+	return c.JSON(200, NewSuccessResponse(cronTasks))
+}
+
+// 获取周期任务详情
+func (s *Service) handleGetCronTask(c echo.Context) error {
+	cronTaskID := c.Param("cron_task_id")
+	if cronTaskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Cron task ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	cronTask, err := s.cronTaskUsecase.GetCronTask(c.Request().Context(), biz.GetCronTaskParam{
+		CronTaskID: cronTaskID,
+		UserID:     userID,
+	})
+	if err != nil {
+		return cronTaskErrorResponse(c, err, "Failed to get cron task")
+	}
+	return c.JSON(200, NewSuccessResponse(cronTask))
+}
+
+// 创建周期任务
+func (s *Service) handleCreateCronTask(c echo.Context) error {
+	var req CreateCronTaskRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	if req.Icon != "" && !IsIcon(req.Icon) {
+		return c.JSON(400, NewErrorResponse(400, "Invalid icon format"))
+	}
+
+	frequency, err := CronFrequencyFromString(req.Frequency)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid frequency: %s", req.Frequency)))
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid start_date format, expected YYYY-MM-DD"))
+	}
+	var endDate *time.Time
+	if req.EndDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, "Invalid end_date format, expected YYYY-MM-DD"))
+		}
+		endDate = &parsed
+	}
+
+	pType, err := PeriodTypeFromString(req.PeriodType)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid period type: %s", req.PeriodType)))
+	}
+
+	priority := biz.TaskPriorityLow
+	if req.Priority != "" {
+		priority, err = TaskPriorityFromString(req.Priority)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid priority: %s", req.Priority)))
+		}
+	}
+
+	cronTask, err := s.cronTaskUsecase.CreateCronTask(c.Request().Context(), biz.CreateCronTaskParam{
+		UserID:    userID,
+		Title:     req.Title,
+		Frequency: frequency,
+		Weekdays:  req.Weekdays,
+		MonthDay:  req.MonthDay,
+		CronExpr:  req.CronExpr,
+		StartDate: startDate,
+		EndDate:   endDate,
+		TaskType:  pType,
+		Tags:      req.Tags,
+		Icon:      req.Icon,
+		Priority:  priority,
+		ParentID:  req.ParentID,
+	})
+	if err != nil {
+		return cronTaskErrorResponse(c, err, "Failed to create cron task")
+	}
+	return c.JSON(201, NewSuccessResponse(cronTask))
+}
+
+// 更新周期任务
+func (s *Service) handleUpdateCronTask(c echo.Context) error {
+	cronTaskID := c.Param("cron_task_id")
+	if cronTaskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Cron task ID is required"))
+	}
+
+	var req UpdateCronTaskRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	if req.Icon != nil && *req.Icon != "" && !IsIcon(*req.Icon) {
+		return c.JSON(400, NewErrorResponse(400, "Invalid icon format"))
+	}
+
+	param := biz.UpdateCronTaskParam{
+		CronTaskID: cronTaskID,
+		UserID:     userID,
+		Title:      req.Title,
+		Weekdays:   req.Weekdays,
+		MonthDay:   req.MonthDay,
+		CronExpr:   req.CronExpr,
+		Tags:       req.Tags,
+		Icon:       req.Icon,
+		ParentID:   req.ParentID,
+	}
+
+	if req.Frequency != nil {
+		frequency, err := CronFrequencyFromString(*req.Frequency)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid frequency: %s", *req.Frequency)))
+		}
+		param.Frequency = &frequency
+	}
+	if req.StartDate != nil {
+		startDate, err := time.Parse("2006-01-02", *req.StartDate)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, "Invalid start_date format, expected YYYY-MM-DD"))
+		}
+		param.StartDate = &startDate
+	}
+	if req.EndDate != nil {
+		if *req.EndDate == "" {
+			param.ClearEnd = true
+		} else {
+			endDate, err := time.Parse("2006-01-02", *req.EndDate)
+			if err != nil {
+				return c.JSON(400, NewErrorResponse(400, "Invalid end_date format, expected YYYY-MM-DD"))
+			}
+			param.EndDate = &endDate
+		}
+	}
+	if req.PeriodType != nil {
+		pType, err := PeriodTypeFromString(*req.PeriodType)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid period type: %s", *req.PeriodType)))
+		}
+		param.TaskType = &pType
+	}
+	if req.Priority != nil {
+		priority, err := TaskPriorityFromString(*req.Priority)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid priority: %s", *req.Priority)))
+		}
+		param.Priority = &priority
+	}
+
+	cronTask, err := s.cronTaskUsecase.UpdateCronTask(c.Request().Context(), param)
+	if err != nil {
+		return cronTaskErrorResponse(c, err, "Failed to update cron task")
+	}
+	return c.JSON(200, NewSuccessResponse(cronTask))
+}
+
+// 删除周期任务（已生成的任务保留）
+func (s *Service) handleDeleteCronTask(c echo.Context) error {
+	cronTaskID := c.Param("cron_task_id")
+	if cronTaskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Cron task ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	if err := s.cronTaskUsecase.DeleteCronTask(c.Request().Context(), biz.DeleteCronTaskParam{
+		CronTaskID: cronTaskID,
+		UserID:     userID,
+	}); err != nil {
+		return cronTaskErrorResponse(c, err, "Failed to delete cron task")
+	}
+	return c.NoContent(204)
+}
+
+// cronTaskErrorResponse 将周期任务业务错误映射为 HTTP 响应
+func cronTaskErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, biz.ErrCronTaskNotFound):
+		return c.JSON(404, NewErrorResponse(404, "Cron task not found"))
+	case errors.Is(err, biz.ErrTaskNotFound):
+		return c.JSON(400, NewErrorResponse(400, "Parent task not found"))
+	case errors.Is(err, biz.ErrInvalidInput),
+		errors.Is(err, biz.ErrInvalidPeriod),
+		errors.Is(err, biz.ErrCronTaskRuleInvalid),
+		errors.Is(err, biz.ErrCronExprInvalid),
+		errors.Is(err, biz.ErrSubTaskTypeInvalid):
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	default:
+		return c.JSON(500, NewErrorResponse(500, fallback))
+	}
+}
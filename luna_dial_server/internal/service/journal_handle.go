@@ -45,11 +45,16 @@ func (s *Service) handleListJournalsByPeriod(c echo.Context) error {
 	if err != nil {
 		return c.JSON(400, NewErrorResponse(400, "Invalid period type"))
 	}
+	match, err := PeriodMatchFromString(c.QueryParam("match"), biz.PeriodMatchContained)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid match, expected contained, intersecting or starting_within"))
+	}
 
 	journalList, err := s.journalUsecase.ListJournalByPeriod(c.Request().Context(), biz.ListJournalByPeriodParam{
 		UserID:  userID,
 		Period:  biz.Period{Start: startDate, End: endDate},
 		GroupBy: periodTypeEnum,
+		Match:   match,
 	})
 	if err != nil {
 		c.Logger().Error("Failed to get journals:", err)
@@ -226,6 +231,14 @@ func (s *Service) handleListJournalsWithPagination(c echo.Context) error {
 		}
 		periodEnd = &endDate
 	}
+	var matchStr string
+	if req.Match != nil {
+		matchStr = *req.Match
+	}
+	match, err := PeriodMatchFromString(matchStr, biz.PeriodMatchContained)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid match, expected contained, intersecting or starting_within"))
+	}
 
 	// 调用业务层
 	journals, total, err := s.journalUsecase.ListJournalsWithPagination(c.Request().Context(), biz.ListJournalsWithPaginationParam{
@@ -235,6 +248,7 @@ func (s *Service) handleListJournalsWithPagination(c echo.Context) error {
 		JournalType: journalType,
 		PeriodStart: periodStart,
 		PeriodEnd:   periodEnd,
+		Match:       match,
 	})
 	if err != nil {
 		return c.JSON(500, NewErrorResponse(500, "Failed to get journals"))
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"luna_dial/internal/biz"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 关键结果打卡：更新当前值并留下记录
+func (s *Service) handleCheckInKeyResult(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	var req CheckInKeyResultRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	param := biz.CheckInKeyResultParam{
+		TaskID: taskID,
+		UserID: userID,
+		Value:  *req.Value,
+		Note:   req.Note,
+	}
+	if req.CheckedAt != nil {
+		param.CheckedAt = *req.CheckedAt
+	}
+	checkIn, err := s.taskUsecase.CheckInKeyResult(c.Request().Context(), param)
+	if err != nil {
+		return keyResultErrorResponse(c, err, "Failed to check in key result")
+	}
+	return c.JSON(201, NewSuccessResponse(checkIn))
+}
+
+// 获取关键结果的打卡记录
+func (s *Service) handleListKeyResultCheckIns(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	checkIns, err := s.taskUsecase.ListKeyResultCheckIns(c.Request().Context(), biz.ListKeyResultCheckInsParam{
+		TaskID: taskID,
+		UserID: userID,
+	})
+	if err != nil {
+		return keyResultErrorResponse(c, err, "Failed to list check-ins")
+	}
+	return c.JSON(200, NewSuccessResponse(checkIns))
+}
+
+// keyResultErrorResponse 把关键结果相关的业务错误映射为 HTTP 响应
+func keyResultErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, biz.ErrTaskNotFound):
+		return c.JSON(404, NewErrorResponse(404, "Task not found"))
+	case errors.Is(err, biz.ErrTaskNotKeyResult):
+		return c.JSON(409, NewErrorResponse(409, err.Error()))
+	case errors.Is(err, biz.ErrCheckInInvalid), errors.Is(err, biz.ErrInvalidInput):
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	default:
+		return c.JSON(500, NewErrorResponse(500, fallback))
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"luna_dial/internal/biz"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 按分组对比计划工作量（任务估算）与已完成工作量，按日、周分组时附带用户容量
+func (s *Service) handleGetPlanEffort(c echo.Context) error {
+	var req GetPlanEffortRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	groupBy, err := PeriodTypeFromString(req.GroupBy)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid group_by type"))
+	}
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid start_date format, expected YYYY-MM-DD"))
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid end_date format, expected YYYY-MM-DD"))
+	}
+
+	stats, err := s.planUsecase.GetPlanEffort(c.Request().Context(), biz.GetTaskEffortParam{
+		UserID:  userID,
+		Period:  biz.Period{Start: startDate, End: endDate},
+		GroupBy: groupBy,
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrPlanPeriodInvalid) || errors.Is(err, biz.ErrInvalidInput) {
+			return c.JSON(400, NewErrorResponse(400,
+				fmt.Sprintf("Invalid period: start_date must be before end_date. Got start=%s, end=%s", req.StartDate, req.EndDate)))
+		}
+		c.Logger().Error("Failed to get plan effort:", err)
+		return c.JSON(500, NewErrorResponse(500, "Failed to get plan effort"))
+	}
+	return c.JSON(200, NewSuccessResponse(stats))
+}
@@ -44,6 +44,11 @@ func (s *Service) handleListPlans(c echo.Context) error {
     if err != nil {
         return c.JSON(400, NewErrorResponse(400, "Invalid period type"))
     }
+    // 计划视图默认包含与时间段有交集的任务和日志，例如跨月的周目标出现在两个月的计划中
+    match, err := PeriodMatchFromString(c.QueryParam("match"), biz.PeriodMatchIntersecting)
+    if err != nil {
+        return c.JSON(400, NewErrorResponse(400, "Invalid match, expected contained, intersecting or starting_within"))
+    }
 
     plan, err := s.planUsecase.GetPlanByPeriod(c.Request().Context(), biz.GetPlanByPeriodParam{
         UserID: userID,
@@ -52,6 +57,7 @@ func (s *Service) handleListPlans(c echo.Context) error {
             End:   endDate,
         },
         GroupBy: groupBy,
+        Match:   match,
     })
     if err != nil {
         // 打印详细错误信息到日志
@@ -15,6 +15,7 @@ type ListTaskRequest struct {
     PeriodType string    `json:"period_type" query:"period_type" validate:"required,oneof=day week month quarter year"`
     StartDate  time.Time `json:"start_date" query:"start_date" validate:"required"`
     EndDate    time.Time `json:"end_date" query:"end_date" validate:"required"`
+    Match      string    `json:"match" query:"match" validate:"omitempty,oneof=contained intersecting starting_within"` // 默认 contained
 }
 
 type CreateTaskRequest struct {
@@ -25,6 +26,25 @@ type CreateTaskRequest struct {
 	Priority   string   `json:"priority" validate:"required,oneof=low medium high urgent"`
 	Icon       string   `json:"icon"`
 	Tags       []string `json:"tags"`
+
+	Description string                 `json:"description,omitempty"` // markdown 格式
+	Checklist   []ChecklistItemRequest `json:"checklist,omitempty" validate:"dive"`
+	KeyResult   *KeyResultRequest      `json:"key_result,omitempty"` // 不为空时创建为关键结果
+	// 工作量估算，单位见用户设置
+	Estimate int `json:"estimate,omitempty" validate:"min=0"`
+}
+
+// 关键结果设置，当前值从起始值开始，之后通过打卡更新
+type KeyResultRequest struct {
+	StartValue  float64 `json:"start_value"`
+	TargetValue float64 `json:"target_value"`
+	Unit        string  `json:"unit" validate:"max=32"`
+}
+
+// 清单项（创建任务或整体替换清单时使用，数组顺序即位置）
+type ChecklistItemRequest struct {
+	Text string `json:"text" validate:"required,max=500"`
+	Done bool   `json:"done"`
 }
 
 type CreateSubTaskRequest struct {
@@ -35,6 +55,10 @@ type CreateSubTaskRequest struct {
     Priority   string   `json:"priority" validate:"required,oneof=low medium high urgent"`
     Icon       string   `json:"icon"`
     Tags       []string `json:"tags"`
+    Description string                 `json:"description,omitempty"`
+    Checklist   []ChecklistItemRequest `json:"checklist,omitempty" validate:"dive"`
+    KeyResult   *KeyResultRequest      `json:"key_result,omitempty"`
+    Estimate    int                    `json:"estimate,omitempty" validate:"min=0"`
     // 兼容旧客户端：允许携带 task_id，但不再校验；服务端使用路径参数作为父任务ID
     TaskID     string   `json:"task_id,omitempty"`
 }
@@ -44,10 +68,27 @@ type UpdateTaskRequest struct {
     Title     *string   `json:"title,omitempty"`
     StartDate *string   `json:"start_date,omitempty"`
     EndDate   *string   `json:"end_date,omitempty"`
+    // 修改任务类型：时间段按新类型和开始时间规范化
+    PeriodType *string  `json:"period_type,omitempty" validate:"omitempty,oneof=day week month quarter year"`
     Priority  string    `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
     Status    string    `json:"status,omitempty" validate:"omitempty,oneof=not_started in_progress completed cancelled"`
+    // 工作量估算，单位见用户设置
+    Estimate  *int      `json:"estimate,omitempty" validate:"omitempty,min=0"`
+    // 取消原因：任务更新后处于已取消状态时才能设置
+    CancelReason *string `json:"cancel_reason,omitempty" validate:"omitempty,max=500"`
     Icon      *string   `json:"icon,omitempty"`
     Tags      *[]string `json:"tags,omitempty"`
+    Description *string                 `json:"description,omitempty"`
+    Checklist   *[]ChecklistItemRequest `json:"checklist,omitempty" validate:"omitempty,dive"` // 整体替换清单
+    // 分数汇总：子任务汇总方式、是否只统计已完成的子任务、在父任务加权平均中的权重
+    ScoreRollup         *string  `json:"score_rollup,omitempty" validate:"omitempty,oneof=sum average weighted"`
+    RollupCompletedOnly *bool    `json:"rollup_completed_only,omitempty"`
+    Weight              *float64 `json:"weight,omitempty" validate:"omitempty,min=0"`
+    // 关键结果：key_result 设置为关键结果（已是关键结果时保留当前值），remove_key_result 改回普通任务
+    KeyResult       *KeyResultRequest `json:"key_result,omitempty"`
+    RemoveKeyResult bool              `json:"remove_key_result,omitempty"`
+    // 忽略阻塞依赖，强制开始或完成被阻塞的任务
+    OverrideBlockers bool `json:"override_blockers,omitempty"`
     // 任务ID改由路径参数传入，保留字段以向后兼容
     TaskID    string    `json:"task_id,omitempty"`
 }
@@ -71,6 +112,7 @@ type ListJournalByPeriodRequest struct {
 	PeriodType string    `json:"period_type" query:"period_type" validate:"required,oneof=day week month quarter year"`
 	StartDate  time.Time `json:"start_date" query:"start_date" validate:"required"`
 	EndDate    time.Time `json:"end_date" query:"end_date" validate:"required"`
+	Match      string    `json:"match" query:"match" validate:"omitempty,oneof=contained intersecting starting_within"` // 默认 contained
 }
 
 // 新建日志请求
@@ -101,6 +143,7 @@ type ListPlansRequest struct {
 	PeriodType string    `json:"period_type" query:"period_type" validate:"required,oneof=day week month quarter year"`
 	StartDate  time.Time `json:"start_date" query:"start_date" validate:"required"`
 	EndDate    time.Time `json:"end_date" query:"end_date" validate:"required"`
+	Match      string    `json:"match" query:"match" validate:"omitempty,oneof=contained intersecting starting_within"` // 默认 intersecting
 }
 
 // 分页查询根任务请求
@@ -122,8 +165,57 @@ type ListGlobalTaskTreeRequest struct {
 
 // 移动任务请求
 type MoveTaskRequest struct {
-	TaskID      string `json:"task_id" validate:"required"` // 要移动的任务ID
-	NewParentID string `json:"new_parent_id,omitempty"`     // 新父任务ID，空表示移动到根级别
+	NewParentID string `json:"new_parent_id,omitempty"` // 新父任务ID，空表示移动到根级别
+	// 任务ID改由路径参数传入，保留字段以向后兼容
+	TaskID string `json:"task_id,omitempty"`
+}
+
+// 设置任务描述请求
+type SetTaskDescriptionRequest struct {
+	Description string `json:"description"` // markdown 格式，空字符串表示清空
+}
+
+// 添加清单项请求
+type AddChecklistItemRequest struct {
+	Text     string `json:"text" validate:"required,max=500"`
+	Position *int   `json:"position,omitempty" validate:"omitempty,min=0"` // 插入位置，不传时追加到末尾
+}
+
+// 更新清单项请求
+type UpdateChecklistItemRequest struct {
+	Text     *string `json:"text,omitempty" validate:"omitempty,max=500"`
+	Done     *bool   `json:"done,omitempty"`
+	Position *int    `json:"position,omitempty" validate:"omitempty,min=0"` // 移动到指定位置
+}
+
+// 添加任务依赖请求
+type AddTaskDependencyRequest struct {
+	BlockedByTaskID string `json:"blocked_by_task_id" validate:"required"` // 阻塞当前任务的任务ID
+}
+
+// 开始计时请求
+type StartTimerRequest struct {
+	Note        string `json:"note"`
+	StopRunning bool   `json:"stop_running"` // 已有计时器在运行时先停止它
+}
+
+// 停止计时请求
+type StopTimerRequest struct {
+	Note *string `json:"note,omitempty"` // 不为空时覆盖开始计时时的备注
+}
+
+// 手动补录工时请求（RFC3339 时间）
+type AddTimeEntryRequest struct {
+	StartedAt time.Time `json:"started_at" validate:"required"`
+	EndedAt   time.Time `json:"ended_at" validate:"required"`
+	Note      string    `json:"note"`
+}
+
+// 关键结果打卡请求
+type CheckInKeyResultRequest struct {
+	Value     *float64   `json:"value" validate:"required"`
+	Note      string     `json:"note"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"` // 为空时为当前时间
 }
 
 // 分页查询日志请求（新版本，支持过滤）
@@ -133,6 +225,194 @@ type ListJournalsWithPaginationRequest struct {
 	JournalType *string `json:"journal_type,omitempty" validate:"omitempty,oneof=day week month quarter year"` // 日志类型过滤
 	StartDate   *string `json:"start_date,omitempty"`                                                          // 时间范围过滤开始
 	EndDate     *string `json:"end_date,omitempty"`                                                            // 时间范围过滤结束
+	Match       *string `json:"match,omitempty" validate:"omitempty,oneof=contained intersecting starting_within"` // 时间范围匹配方式，默认 contained
+}
+
+// 更新用户设置请求
+type UpdateUserSettingsRequest struct {
+	CompletionPropagation *string `json:"completion_propagation,omitempty" validate:"omitempty,oneof=auto flag"` // 子任务全部完成后：auto 自动完成父任务，flag 仅标记为可完成
+	AutoRollover          *string `json:"auto_rollover,omitempty" validate:"omitempty,oneof=off move clone"`     // 每天自动顺延未完成任务：off 不顺延，move 移动，clone 复制
+	// 工作量估算单位，以及每天、每周的容量（0 表示不检查）
+	EstimateUnit   *string `json:"estimate_unit,omitempty" validate:"omitempty,oneof=minutes points"`
+	DailyCapacity  *int    `json:"daily_capacity,omitempty" validate:"omitempty,min=0"`
+	WeeklyCapacity *int    `json:"weekly_capacity,omitempty" validate:"omitempty,min=0"`
+	// 看板列的在制品上限，键为任务状态，只修改传递了的列，0 表示不限制
+	WIPLimits map[string]int `json:"wip_limits,omitempty" validate:"omitempty,dive,keys,oneof=not_started in_progress completed cancelled,endkeys,min=0"`
+}
+
+// 顺延未完成任务请求
+type RolloverTasksRequest struct {
+	PeriodType string `json:"period_type" validate:"required,oneof=day week month quarter year"`
+	Date       string `json:"date" validate:"required"` // 需要顺延的时间段内的任意一天，YYYY-MM-DD
+	Mode       string `json:"mode" validate:"required,oneof=move clone"`
+}
+
+// 创建周期任务请求
+type CreateCronTaskRequest struct {
+	Title      string   `json:"title" validate:"required"`
+	Frequency  string   `json:"frequency" validate:"required,oneof=daily weekly monthly workdays cron"`
+	Weekdays   []int    `json:"weekdays,omitempty" validate:"dive,min=0,max=6"` // weekly：0=周日 ... 6=周六
+	MonthDay   int      `json:"month_day,omitempty" validate:"min=0,max=31"`    // monthly：每月几号，0表示取开始日期
+	CronExpr   string   `json:"cron_expr,omitempty"`                            // cron：标准5段表达式
+	StartDate  string   `json:"start_date" validate:"required"`
+	EndDate    string   `json:"end_date,omitempty"` // 为空表示一直重复
+	PeriodType string   `json:"period_type" validate:"required,oneof=day week month quarter year"`
+	Priority   string   `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
+	Icon       string   `json:"icon"`
+	Tags       []string `json:"tags"`
+	ParentID   string   `json:"parent_id,omitempty"`
+}
+
+// 更新周期任务请求
+type UpdateCronTaskRequest struct {
+	Title      *string   `json:"title,omitempty"`
+	Frequency  *string   `json:"frequency,omitempty" validate:"omitempty,oneof=daily weekly monthly workdays cron"`
+	Weekdays   *[]int    `json:"weekdays,omitempty" validate:"omitempty,dive,min=0,max=6"`
+	MonthDay   *int      `json:"month_day,omitempty" validate:"omitempty,min=0,max=31"`
+	CronExpr   *string   `json:"cron_expr,omitempty"`
+	StartDate  *string   `json:"start_date,omitempty"`
+	EndDate    *string   `json:"end_date,omitempty"` // 传空字符串表示清空结束日期
+	PeriodType *string   `json:"period_type,omitempty" validate:"omitempty,oneof=day week month quarter year"`
+	Priority   *string   `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
+	Icon       *string   `json:"icon,omitempty"`
+	Tags       *[]string `json:"tags,omitempty"`
+	ParentID   *string   `json:"parent_id,omitempty"` // 传空字符串表示生成根任务
+}
+
+// 全文搜索请求（查询参数）
+type SearchRequest struct {
+	Query      string   `query:"q" validate:"required"`
+	Kind       []string `query:"kind" validate:"dive,oneof=task journal"`                                  // 为空时搜索任务和日志
+	PeriodType string   `query:"period_type" validate:"omitempty,oneof=day week month quarter year"`       // 任务类型或日志类型
+	StartDate  string   `query:"start_date"`                                                               // YYYY-MM-DD，时间段开始时间不早于该日期
+	EndDate    string   `query:"end_date"`                                                                 // YYYY-MM-DD，时间段开始时间早于该日期
+	Status     []string `query:"status" validate:"dive,oneof=not_started in_progress completed cancelled"` // 只作用于任务，指定后不返回日志
+	Page       int      `query:"page" validate:"omitempty,min=1"`                                          // 页码，默认1
+	PageSize   int      `query:"page_size" validate:"omitempty,min=1,max=100"`                             // 每页大小，默认20
+}
+
+// 计划与完成工作量对比请求（查询参数）
+type GetPlanEffortRequest struct {
+	GroupBy   string `query:"group_by" validate:"required,oneof=day week month quarter year"`
+	StartDate string `query:"start_date" validate:"required"` // YYYY-MM-DD
+	EndDate   string `query:"end_date" validate:"required"`   // YYYY-MM-DD
+}
+
+// 日程请求（查询参数）
+type GetAgendaRequest struct {
+	Date string `query:"date" validate:"required"` // YYYY-MM-DD
+}
+
+// 批量操作中的一个操作
+type BatchTaskOperationRequest struct {
+	Action  string   `json:"action" validate:"required,oneof=update delete move"`
+	TaskIDs []string `json:"task_ids" validate:"required,min=1,dive,required"`
+
+	// update：只修改传递了的字段；start_date 和 end_date 需要同时传递
+	Status    string    `json:"status,omitempty" validate:"omitempty,oneof=not_started in_progress completed cancelled"`
+	Priority  string    `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
+	Tags      *[]string `json:"tags,omitempty"`
+	Icon      *string   `json:"icon,omitempty"`
+	StartDate *string   `json:"start_date,omitempty"`
+	EndDate   *string   `json:"end_date,omitempty"`
+
+	// delete：cascade（默认）或 promote
+	Mode string `json:"mode,omitempty" validate:"omitempty,oneof=cascade promote"`
+
+	// move：为空表示移动到根级别
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+// 批量操作请求
+type BatchTasksRequest struct {
+	Operations []BatchTaskOperationRequest `json:"operations" validate:"required,min=1,dive"`
+	Atomic     bool                        `json:"atomic"` // 任一任务失败时回滚整个批次
+}
+
+// 调整子任务顺序请求
+type ReorderTasksRequest struct {
+	ParentID string   `json:"parent_id"`                                        // 为空表示调整根任务的顺序
+	TaskIDs  []string `json:"task_ids" validate:"required,min=1,dive,required"` // 按期望顺序排列，可以只包含部分子任务
+}
+
+// 任务看板范围（获取看板时为查询参数，移动卡片时在请求体中）：root_id 与时间段二选一
+type TaskBoardScopeRequest struct {
+	RootID     string `json:"root_id" query:"root_id"` // 该任务的所有后代
+	PeriodType string `json:"period_type" query:"period_type" validate:"omitempty,oneof=day week month quarter year"`
+	StartDate  string `json:"start_date" query:"start_date"` // YYYY-MM-DD
+	EndDate    string `json:"end_date" query:"end_date"`     // YYYY-MM-DD
+	Match      string `json:"match" query:"match" validate:"omitempty,oneof=contained intersecting starting_within"`
+}
+
+// 移动看板卡片请求
+type MoveBoardCardRequest struct {
+	TaskBoardScopeRequest
+	Status           string `json:"status" validate:"required,oneof=not_started in_progress completed cancelled"`
+	Position         *int   `json:"position,omitempty" validate:"omitempty,min=0"` // 在目标列中的位置，为空时放到最后
+	OverrideBlockers bool   `json:"override_blockers,omitempty"`
+}
+
+// 任务模板节点请求
+type TaskTemplateNodeRequest struct {
+	Title      string                    `json:"title" validate:"required"`
+	PeriodType string                    `json:"period_type" validate:"required,oneof=day week month quarter year"`
+	Offset     int                       `json:"offset" validate:"min=0"` // 父任务时间段内的第几个时间段，从0开始
+	Tags       []string                  `json:"tags"`
+	Icon       string                    `json:"icon"`
+	Priority   string                    `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
+	Children   []TaskTemplateNodeRequest `json:"children,omitempty" validate:"dive"`
+}
+
+// 创建任务模板请求
+type CreateTaskTemplateRequest struct {
+	Name        string                  `json:"name" validate:"required"`
+	Description string                  `json:"description"`
+	Root        TaskTemplateNodeRequest `json:"root" validate:"required"`
+}
+
+// 从已有任务树保存模板请求
+type CaptureTaskTemplateRequest struct {
+	TaskID      string `json:"task_id" validate:"required"`
+	Name        string `json:"name"` // 为空时使用任务标题
+	Description string `json:"description"`
+}
+
+// 实例化任务模板请求
+type InstantiateTaskTemplateRequest struct {
+	Date     string `json:"date" validate:"required"` // 参考日期，YYYY-MM-DD
+	ParentID string `json:"parent_id,omitempty"`      // 可选：挂到已有任务下
+}
+
+// 创建标签请求
+type CreateTagRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Color       string `json:"color"` // #RRGGBB，可选
+	Description string `json:"description"`
+}
+
+// 更新标签请求，只修改传递了的字段
+type UpdateTagRequest struct {
+	Name        *string `json:"name,omitempty"` // 重命名，使用该标签的任务一起更新
+	Color       *string `json:"color,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// 合并标签请求
+type MergeTagsRequest struct {
+	TargetID string `json:"target_id" validate:"required"`
+}
+
+// 按标签表达式查询任务请求（查询参数）
+type ListTasksByTagsRequest struct {
+	Expr     string `query:"expr" validate:"required"`                     // 例如：工作 AND (紧急 OR 重要) AND NOT 已归档
+	Page     int    `query:"page" validate:"omitempty,min=1"`              // 页码，默认1
+	PageSize int    `query:"page_size" validate:"omitempty,min=1,max=100"` // 每页大小，默认20
+}
+
+// 检查任务树请求（管理员）
+type CheckTaskTreesRequest struct {
+	UserID string `json:"user_id"` // 为空时检查所有用户
+	Fix    bool   `json:"fix"`     // 是否在同一事务中修复发现的问题
 }
 
 func PeriodTypeFromString(s string) (biz.PeriodType, error) {
@@ -152,6 +432,22 @@ func PeriodTypeFromString(s string) (biz.PeriodType, error) {
 	}
 }
 
+// PeriodMatchFromString 解析时间段匹配方式，为空时使用 defaultMatch
+func PeriodMatchFromString(s string, defaultMatch biz.PeriodMatch) (biz.PeriodMatch, error) {
+	switch s {
+	case "":
+		return defaultMatch, nil
+	case "contained":
+		return biz.PeriodMatchContained, nil
+	case "intersecting":
+		return biz.PeriodMatchIntersecting, nil
+	case "starting_within":
+		return biz.PeriodMatchStartingWithin, nil
+	default:
+		return 0, fmt.Errorf("unknown period match: %s", s)
+	}
+}
+
 func TaskStatusFromString(s string) (biz.TaskStatus, error) {
 	switch s {
 	case "not_started":
@@ -167,6 +463,19 @@ func TaskStatusFromString(s string) (biz.TaskStatus, error) {
 	}
 }
 
+func ScoreRollupModeFromString(s string) (biz.ScoreRollupMode, error) {
+	switch s {
+	case "sum":
+		return biz.ScoreRollupSum, nil
+	case "average":
+		return biz.ScoreRollupAverage, nil
+	case "weighted":
+		return biz.ScoreRollupWeighted, nil
+	default:
+		return 0, fmt.Errorf("unknown score rollup mode: %s", s)
+	}
+}
+
 func TaskPriorityFromString(s string) (biz.TaskPriority, error) {
 	switch s {
 	case "low":
@@ -181,3 +490,111 @@ func TaskPriorityFromString(s string) (biz.TaskPriority, error) {
 		return 0, fmt.Errorf("unknown task priority: %s", s)
 	}
 }
+
+func TaskDeleteModeFromString(s string) (biz.TaskDeleteMode, error) {
+	switch s {
+	case "cascade":
+		return biz.TaskDeleteModeCascade, nil
+	case "promote":
+		return biz.TaskDeleteModePromote, nil
+	default:
+		return 0, fmt.Errorf("unknown task delete mode: %s", s)
+	}
+}
+
+func CompletionPropagationModeFromString(s string) (biz.CompletionPropagationMode, error) {
+	switch s {
+	case "auto":
+		return biz.CompletionPropagationAuto, nil
+	case "flag":
+		return biz.CompletionPropagationFlag, nil
+	default:
+		return 0, fmt.Errorf("unknown completion propagation mode: %s", s)
+	}
+}
+
+func RolloverModeFromString(s string) (biz.RolloverMode, error) {
+	switch s {
+	case "off":
+		return biz.RolloverModeOff, nil
+	case "move":
+		return biz.RolloverModeMove, nil
+	case "clone":
+		return biz.RolloverModeClone, nil
+	default:
+		return 0, fmt.Errorf("unknown rollover mode: %s", s)
+	}
+}
+
+func EstimateUnitFromString(s string) (biz.EstimateUnit, error) {
+	switch s {
+	case "minutes":
+		return biz.EstimateUnitMinutes, nil
+	case "points":
+		return biz.EstimateUnitPoints, nil
+	default:
+		return 0, fmt.Errorf("unknown estimate unit: %s", s)
+	}
+}
+
+func CronFrequencyFromString(s string) (biz.CronFrequency, error) {
+	switch s {
+	case "daily":
+		return biz.CronFrequencyDaily, nil
+	case "weekly":
+		return biz.CronFrequencyWeekly, nil
+	case "monthly":
+		return biz.CronFrequencyMonthly, nil
+	case "workdays":
+		return biz.CronFrequencyWorkdays, nil
+	case "cron":
+		return biz.CronFrequencyCron, nil
+	default:
+		return 0, fmt.Errorf("unknown cron frequency: %s", s)
+	}
+}
+
+func ChecklistItemInputsFromRequest(items []ChecklistItemRequest) []biz.ChecklistItemInput {
+	inputs := make([]biz.ChecklistItemInput, len(items))
+	for i, item := range items {
+		inputs[i] = biz.ChecklistItemInput{Text: item.Text, Done: item.Done}
+	}
+	return inputs
+}
+
+// KeyResultInputFromRequest 关键结果请求转换为业务层参数，请求为空时返回 nil
+func KeyResultInputFromRequest(req *KeyResultRequest) *biz.KeyResultInput {
+	if req == nil {
+		return nil
+	}
+	return &biz.KeyResultInput{StartValue: req.StartValue, TargetValue: req.TargetValue, Unit: req.Unit}
+}
+
+func TaskTemplateNodeFromRequest(req TaskTemplateNodeRequest) (*biz.TaskTemplateNode, error) {
+	periodType, err := PeriodTypeFromString(req.PeriodType)
+	if err != nil {
+		return nil, err
+	}
+	priority := biz.TaskPriorityLow
+	if req.Priority != "" {
+		if priority, err = TaskPriorityFromString(req.Priority); err != nil {
+			return nil, err
+		}
+	}
+	node := &biz.TaskTemplateNode{
+		Title:    req.Title,
+		TaskType: periodType,
+		Offset:   req.Offset,
+		Tags:     req.Tags,
+		Icon:     req.Icon,
+		Priority: priority,
+	}
+	for _, childReq := range req.Children {
+		child, err := TaskTemplateNodeFromRequest(childReq)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
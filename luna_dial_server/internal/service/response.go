@@ -1,6 +1,9 @@
 package service
 
-import "time"
+import (
+	"luna_dial/internal/biz"
+	"time"
+)
 
 // Response 通用响应结构体
 type Response struct {
@@ -18,6 +21,25 @@ type LoginResponse struct {
 	ExpiresIn int64  `json:"expires_in"` // 会话过期时间（秒）
 }
 
+// 批量操作中单个任务的结果
+type BatchTaskItemResponse struct {
+	Operation int                 `json:"operation"`       // 操作在请求中的下标
+	TaskID    string              `json:"task_id"`         // 任务ID
+	Status    biz.BatchItemStatus `json:"status"`          // succeeded | failed | rolled_back | skipped
+	Code      int                 `json:"code,omitempty"`  // 失败时的错误码，与单个操作接口一致
+	Error     string              `json:"error,omitempty"` // 失败原因
+	Task      *biz.Task           `json:"task,omitempty"`  // 修改、移动成功后的任务
+}
+
+// 批量操作响应
+type BatchTasksResponse struct {
+	Atomic     bool                    `json:"atomic"`      // 是否为全部成功模式
+	RolledBack bool                    `json:"rolled_back"` // 整个批次是否被回滚
+	Succeeded  int                     `json:"succeeded"`   // 成功的任务数
+	Failed     int                     `json:"failed"`      // 失败的任务数
+	Items      []BatchTaskItemResponse `json:"items"`       // 每个任务的结果
+}
+
 // PaginatedData 通用分页数据结构 (嵌套在 Response.Data 中)
 type PaginatedData struct {
 	Items      interface{} `json:"items"`      // 数据列表
@@ -101,6 +123,13 @@ func NewErrorResponse(code int, message string) *Response {
 	}
 }
 
+// NewErrorResponseWithData 创建带详细信息的错误响应，例如冲突的子任务列表
+func NewErrorResponseWithData(code int, message string, data interface{}) *Response {
+	response := NewErrorResponse(code, message)
+	response.Data = data
+	return response
+}
+
 // WithRequestID 添加请求ID
 func (r *Response) WithRequestID(requestID string) *Response {
 	r.RequestID = requestID
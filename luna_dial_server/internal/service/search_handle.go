@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"luna_dial/internal/biz"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 全文搜索任务和日志，结果按相关度排序并分页
+func (s *Service) handleSearch(c echo.Context) error {
+	var req SearchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	param := biz.SearchParam{
+		UserID:   userID,
+		Query:    req.Query,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+	for _, kind := range req.Kind {
+		param.Kinds = append(param.Kinds, biz.SearchEntityKind(kind))
+	}
+	if req.PeriodType != "" {
+		periodType, err := PeriodTypeFromString(req.PeriodType)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid period type: %s", req.PeriodType)))
+		}
+		param.PeriodType = &periodType
+	}
+	if req.StartDate != "" {
+		startDate, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, "Invalid start_date format, expected YYYY-MM-DD"))
+		}
+		param.StartDate = &startDate
+	}
+	if req.EndDate != "" {
+		endDate, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, "Invalid end_date format, expected YYYY-MM-DD"))
+		}
+		param.EndDate = &endDate
+	}
+	for _, statusStr := range req.Status {
+		status, err := TaskStatusFromString(statusStr)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid status: %s", statusStr)))
+		}
+		param.Statuses = append(param.Statuses, status)
+	}
+
+	hits, total, err := s.searchUsecase.Search(c.Request().Context(), param)
+	if err != nil {
+		if errors.Is(err, biz.ErrInvalidInput) || errors.Is(err, biz.ErrInvalidPeriod) {
+			return c.JSON(400, NewErrorResponse(400, err.Error()))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to search"))
+	}
+	return c.JSON(200, NewPaginatedResponse(hits, req.Page, req.PageSize, total))
+}
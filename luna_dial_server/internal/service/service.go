@@ -3,11 +3,19 @@ package service
 import (
 	"context"
 	"luna_dial/internal/biz"
+	"luna_dial/internal/config"
 	"luna_dial/internal/data"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+// 周期任务生成器的执行间隔
+const cronTaskGenerateInterval = time.Hour
+
+// 自动顺延每小时检查一次，跨过零点后的第一次执行完成前一天的顺延
+const taskRolloverInterval = time.Hour
+
 type Service struct {
 	e *echo.Echo
 
@@ -17,6 +25,14 @@ type Service struct {
 	userUsecase    *biz.UserUsecase
 	taskUsecase    *biz.TaskUsecase
 	planUsecase    *biz.PlanUsecase
+
+	settingsUsecase *biz.UserSettingsUsecase
+	cronTaskUsecase *biz.CronTaskUsecase
+	trashUsecase    *biz.TrashUsecase
+
+	taskTemplateUsecase *biz.TaskTemplateUsecase
+	searchUsecase       *biz.SearchUsecase
+	tagUsecase          *biz.TagUsecase
 }
 
 func NewService(ctx context.Context, e *echo.Echo, dataInstance *data.Data) *Service {
@@ -24,19 +40,66 @@ func NewService(ctx context.Context, e *echo.Echo, dataInstance *data.Data) *Ser
 	taskRepo := data.NewTaskRepo(dataInstance.DB)
 	journalRepo := data.NewJournalRepo(dataInstance.DB)
 	userRepo := data.NewUserRepo(dataInstance.DB)
+	settingsRepo := data.NewUserSettingsRepo(dataInstance.DB)
+	cronTaskRepo := data.NewCronTaskRepo(dataInstance.DB)
+	historyRepo := data.NewChangeHistoryRepo(dataInstance.DB)
+	taskTemplateRepo := data.NewTaskTemplateRepo(dataInstance.DB)
+	searchRepo := data.NewSearchRepo(dataInstance.DB)
+	tagRepo := data.NewTagRepo(dataInstance.DB)
 
 	s := &Service{
 		e:              e,
 		systemConfig:   dataInstance.SystemConfig,
 		sessionManager: dataInstance.SessionManager,
-		journalUsecase: biz.NewJournalUsecase(journalRepo),
+		journalUsecase: biz.NewJournalUsecase(journalRepo, historyRepo),
 		userUsecase:    biz.NewUserUsecase(userRepo),
-		taskUsecase:    biz.NewTaskUsecase(taskRepo),
+		taskUsecase:    biz.NewTaskUsecase(taskRepo, settingsRepo, historyRepo),
+
+		settingsUsecase: biz.NewUserSettingsUsecase(settingsRepo),
+	}
+	if config.Cfg != nil {
+		s.taskUsecase.SetMaxTreeDepth(config.Cfg.Task.MaxTreeDepth)
 	}
 	s.planUsecase = biz.NewPlanUsecase(s.taskUsecase, s.journalUsecase)
+	s.cronTaskUsecase = biz.NewCronTaskUsecase(cronTaskRepo, s.taskUsecase)
+	s.taskTemplateUsecase = biz.NewTaskTemplateUsecase(taskTemplateRepo, s.taskUsecase)
+	s.searchUsecase = biz.NewSearchUsecase(searchRepo)
+	s.tagUsecase = biz.NewTagUsecase(tagRepo, s.taskUsecase)
+
+	// 启动周期任务生成协程，随数据层清理一起停止
+	cronTaskGenerator := biz.NewCronTaskGenerator(s.cronTaskUsecase, cronTaskGenerateInterval)
+	cronTaskGenerator.Start()
+	dataInstance.AddCleanup(cronTaskGenerator.Stop)
+
+	// 启动未完成任务自动顺延协程
+	taskRolloverJob := biz.NewTaskRolloverJob(s.taskUsecase, taskRolloverInterval)
+	taskRolloverJob.Start()
+	dataInstance.AddCleanup(taskRolloverJob.Stop)
+
+	// 启动回收站清理协程
+	s.trashUsecase = biz.NewTrashUsecase(s.taskUsecase, s.journalUsecase)
+	retention, purgeInterval := trashPurgeSettings()
+	trashPurger := biz.NewTrashPurger(s.trashUsecase, retention, purgeInterval)
+	trashPurger.Start()
+	dataInstance.AddCleanup(trashPurger.Stop)
 	return s
 }
 
+// trashPurgeSettings 从配置读取回收站保留期和清理间隔，未配置时使用默认值
+func trashPurgeSettings() (time.Duration, time.Duration) {
+	retentionDays := config.DefaultTrashRetentionDays
+	intervalHour := config.DefaultTrashPurgeIntervalHour
+	if config.Cfg != nil {
+		if config.Cfg.Trash.RetentionDays > 0 {
+			retentionDays = config.Cfg.Trash.RetentionDays
+		}
+		if config.Cfg.Trash.PurgeIntervalHour > 0 {
+			intervalHour = config.Cfg.Trash.PurgeIntervalHour
+		}
+	}
+	return time.Duration(retentionDays) * 24 * time.Hour, time.Duration(intervalHour) * time.Hour
+}
+
 func (s *Service) SetupRouter() {
 	// 设置Session相关路由
 	s.setupSessionRoutes()
@@ -72,12 +135,15 @@ func (s *Service) setupSessionRoutes() {
 	// 其他业务接口...
 	userGroup := protected.Group("/users")
 	userGroup.GET("/me", s.handleGetCurrentUser)
+	userGroup.GET("/me/settings", s.handleGetUserSettings)
+	userGroup.PUT("/me/settings", s.handleUpdateUserSettings)
 
 	journalGroup := protected.Group("/journals")
 	journalGroup.GET("", s.handleListJournalsByPeriod)
 	journalGroup.POST("", s.handleCreateJournal)
 	journalGroup.PUT("/:journal_id", s.handleUpdateJournal)
 	journalGroup.DELETE("/:journal_id", s.handleDeleteJournal)
+	journalGroup.GET("/:journal_id/history", s.handleListJournalHistory)
 	// 阶段五新增：分页查询日志
 	journalGroup.GET("/paginated", s.handleListJournalsWithPagination)
 
@@ -93,11 +159,85 @@ func (s *Service) setupSessionRoutes() {
 	taskGroup.GET("/roots", s.handleListRootTasks)                   // 分页查询根任务
 	taskGroup.GET("/tree", s.handleListGlobalTaskTree)               // 全局任务树视图（分页）
 	taskGroup.GET("/:task_id/tree", s.handleGetTaskTree)             // 获取指定任务的完整任务树
+	taskGroup.GET("/:task_id/subtree", s.handleGetTaskSubtree)       // 获取指定任务的子树（任意层级）
 	taskGroup.GET("/:task_id/parents", s.handleGetTaskParents)       // 获取任务的父任务链
 	taskGroup.PUT("/:task_id/move", s.handleMoveTask)                // 移动任务
 	taskGroup.POST("/optimized", s.handleCreateTaskWithOptimization) // 使用优化的任务创建
+	taskGroup.POST("/rollover", s.handleRolloverTasks)               // 顺延未完成任务到下一个时间段
+	taskGroup.POST("/batch", s.handleBatchTasks)                     // 批量修改、删除、移动任务
+	taskGroup.GET("/by-tags", s.handleListTasksByTags)               // 按标签表达式查询任务
+	taskGroup.PUT("/reorder", s.handleReorderTasks)                  // 调整同级任务的顺序
+	// 任务描述与清单
+	taskGroup.PUT("/:task_id/description", s.handleSetTaskDescription)
+	taskGroup.GET("/:task_id/checklist", s.handleListChecklistItems)
+	taskGroup.POST("/:task_id/checklist", s.handleAddChecklistItem)
+	taskGroup.PUT("/:task_id/checklist/:item_id", s.handleUpdateChecklistItem)
+	taskGroup.DELETE("/:task_id/checklist/:item_id", s.handleDeleteChecklistItem)
+	// 任务依赖（阻塞关系）
+	taskGroup.GET("/critical-path", s.handleGetCriticalPath)
+	taskGroup.GET("/:task_id/blockers", s.handleListTaskBlockers)
+	taskGroup.POST("/:task_id/blockers", s.handleAddTaskDependency)
+	taskGroup.DELETE("/:task_id/blockers/:blocker_id", s.handleRemoveTaskDependency)
+	taskGroup.GET("/:task_id/dependents", s.handleListTaskDependents)
+
+	// 工时记录
+	taskGroup.GET("/:task_id/time-entries", s.handleListTimeEntries)
+	taskGroup.POST("/:task_id/time-entries", s.handleAddTimeEntry)
+	taskGroup.POST("/:task_id/timer/start", s.handleStartTimer)
+
+	// 关键结果打卡
+	taskGroup.GET("/:task_id/check-ins", s.handleListKeyResultCheckIns)
+	taskGroup.POST("/:task_id/check-ins", s.handleCheckInKeyResult)
+
+	// 变更历史
+	taskGroup.GET("/:task_id/history", s.handleListTaskHistory)
+
+	boardGroup := protected.Group("/boards")
+	boardGroup.GET("/tasks", s.handleGetTaskBoard)
+	boardGroup.POST("/tasks/:task_id/move", s.handleMoveBoardCard)
+
+	timeEntryGroup := protected.Group("/time-entries")
+	timeEntryGroup.GET("/running", s.handleGetRunningTimer)
+	timeEntryGroup.POST("/stop", s.handleStopTimer)
+	timeEntryGroup.DELETE("/:entry_id", s.handleDeleteTimeEntry)
+
+	cronTaskGroup := protected.Group("/cron-tasks")
+	cronTaskGroup.GET("", s.handleListCronTasks)
+	cronTaskGroup.POST("", s.handleCreateCronTask)
+	cronTaskGroup.GET("/:cron_task_id", s.handleGetCronTask)
+	cronTaskGroup.PUT("/:cron_task_id", s.handleUpdateCronTask)
+	cronTaskGroup.DELETE("/:cron_task_id", s.handleDeleteCronTask)
+
+	protected.GET("/search", s.handleSearch)
+
+	tagGroup := protected.Group("/tags")
+	tagGroup.GET("", s.handleListTags)
+	tagGroup.POST("", s.handleCreateTag)
+	tagGroup.PUT("/:tag_id", s.handleUpdateTag)
+	tagGroup.DELETE("/:tag_id", s.handleDeleteTag)
+	tagGroup.POST("/:tag_id/merge", s.handleMergeTags) // 合并到 target_id 指定的标签
+
+	taskTemplateGroup := protected.Group("/task-templates")
+	taskTemplateGroup.GET("", s.handleListTaskTemplates)
+	taskTemplateGroup.POST("", s.handleCreateTaskTemplate)
+	taskTemplateGroup.POST("/capture", s.handleCaptureTaskTemplate) // 以已有任务为根保存模板
+	taskTemplateGroup.GET("/:template_id", s.handleGetTaskTemplate)
+	taskTemplateGroup.DELETE("/:template_id", s.handleDeleteTaskTemplate)
+	taskTemplateGroup.POST("/:template_id/instantiate", s.handleInstantiateTaskTemplate)
+
+	trashGroup := protected.Group("/trash")
+	trashGroup.GET("", s.handleListTrash)
+	trashGroup.POST("/tasks/:task_id/restore", s.handleRestoreTask)
+	trashGroup.POST("/journals/:journal_id/restore", s.handleRestoreJournal)
 
 	planGroup := protected.Group("/plans")
 	planGroup.GET("", s.handleListPlans)
 	planGroup.GET("/stats", s.handleGetPlanStats)
+	planGroup.GET("/effort", s.handleGetPlanEffort)
+
+	protected.GET("/agenda", s.handleGetAgenda)
+
+	// 管理接口，只允许管理员账号访问
+	adminGroup := protected.Group("/admin")
+	adminGroup.POST("/tree/fsck", s.handleCheckTaskTrees)
 }
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"luna_dial/internal/biz"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 获取当前用户的全部标签及使用次数
+func (s *Service) handleListTags(c echo.Context) error {
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	tags, err := s.tagUsecase.ListTags(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(500, NewErrorResponse(500, "Failed to list tags"))
+	}
+	return c.JSON(200, NewSuccessResponse(tags))
+}
+
+// 创建标签
+func (s *Service) handleCreateTag(c echo.Context) error {
+	var req CreateTagRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	tag, err := s.tagUsecase.CreateTag(c.Request().Context(), biz.CreateTagParam{
+		UserID:      userID,
+		Name:        req.Name,
+		Color:       req.Color,
+		Description: req.Description,
+	})
+	if err != nil {
+		return tagErrorResponse(c, err, "Failed to create tag")
+	}
+	return c.JSON(201, NewSuccessResponse(tag))
+}
+
+// 更新标签（重命名、颜色、描述）
+func (s *Service) handleUpdateTag(c echo.Context) error {
+	tagID := c.Param("tag_id")
+	if tagID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Tag ID is required"))
+	}
+
+	var req UpdateTagRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	tag, err := s.tagUsecase.UpdateTag(c.Request().Context(), biz.UpdateTagParam{
+		TagID:       tagID,
+		UserID:      userID,
+		Name:        req.Name,
+		Color:       req.Color,
+		Description: req.Description,
+	})
+	if err != nil {
+		return tagErrorResponse(c, err, "Failed to update tag")
+	}
+	return c.JSON(200, NewSuccessResponse(tag))
+}
+
+// 把路径中的标签合并到目标标签，返回目标标签
+func (s *Service) handleMergeTags(c echo.Context) error {
+	tagID := c.Param("tag_id")
+	if tagID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Tag ID is required"))
+	}
+
+	var req MergeTagsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	tag, err := s.tagUsecase.MergeTags(c.Request().Context(), biz.MergeTagsParam{
+		UserID:   userID,
+		SourceID: tagID,
+		TargetID: req.TargetID,
+	})
+	if err != nil {
+		return tagErrorResponse(c, err, "Failed to merge tags")
+	}
+	return c.JSON(200, NewSuccessResponse(tag))
+}
+
+// 删除标签，同时从所有任务上移除
+func (s *Service) handleDeleteTag(c echo.Context) error {
+	tagID := c.Param("tag_id")
+	if tagID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Tag ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	err = s.tagUsecase.DeleteTag(c.Request().Context(), biz.GetTagParam{
+		TagID:  tagID,
+		UserID: userID,
+	})
+	if err != nil {
+		return tagErrorResponse(c, err, "Failed to delete tag")
+	}
+	return c.NoContent(204)
+}
+
+// 按标签表达式分页查询任务
+func (s *Service) handleListTasksByTags(c echo.Context) error {
+	var req ListTasksByTagsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	tasks, total, err := s.tagUsecase.ListTasksByTags(c.Request().Context(), biz.ListTasksByTagsParam{
+		UserID:   userID,
+		Expr:     req.Expr,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	})
+	if err != nil {
+		return tagErrorResponse(c, err, "Failed to list tasks by tags")
+	}
+	return c.JSON(200, NewPaginatedResponse(tasks, req.Page, req.PageSize, total))
+}
+
+// tagErrorResponse 把标签业务错误映射为 HTTP 响应
+func tagErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, biz.ErrTagNotFound):
+		return c.JSON(404, NewErrorResponse(404, "Tag not found"))
+	case errors.Is(err, biz.ErrTagNameExists):
+		return c.JSON(409, NewErrorResponse(409, err.Error()))
+	case errors.Is(err, biz.ErrInvalidInput),
+		errors.Is(err, biz.ErrTagNameInvalid),
+		errors.Is(err, biz.ErrTagColorInvalid),
+		errors.Is(err, biz.ErrTagExprInvalid):
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	default:
+		return c.JSON(500, NewErrorResponse(500, fallback))
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"luna_dial/internal/biz"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 批量修改、删除、移动任务
+func (s *Service) handleBatchTasks(c echo.Context) error {
+	var req BatchTasksRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	param := biz.BatchTaskParam{
+		UserID:     userID,
+		Operations: make([]biz.BatchTaskOperation, len(req.Operations)),
+		Atomic:     req.Atomic,
+	}
+	for i, opReq := range req.Operations {
+		op, err := batchTaskOperationFromRequest(opReq)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid operation %d: %v", i, err)))
+		}
+		param.Operations[i] = op
+	}
+
+	result, err := s.taskUsecase.BatchTasks(c.Request().Context(), param)
+	if err != nil {
+		if errors.Is(err, biz.ErrInvalidInput) {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid batch: at most %d tasks, update operations need at least one field", biz.MaxBatchTaskItems)))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to run batch operations"))
+	}
+
+	resp := BatchTasksResponse{
+		Atomic:     result.Atomic,
+		RolledBack: result.RolledBack,
+		Succeeded:  result.Succeeded,
+		Failed:     result.Failed,
+		Items:      make([]BatchTaskItemResponse, len(result.Items)),
+	}
+	for i, item := range result.Items {
+		resp.Items[i] = BatchTaskItemResponse{
+			Operation: item.Operation,
+			TaskID:    item.TaskID,
+			Status:    item.Status,
+			Task:      item.Task,
+		}
+		if item.Err != nil {
+			resp.Items[i].Code, resp.Items[i].Error = batchItemError(item.Err)
+		}
+	}
+	return c.JSON(200, NewSuccessResponse(resp))
+}
+
+func batchTaskOperationFromRequest(req BatchTaskOperationRequest) (biz.BatchTaskOperation, error) {
+	op := biz.BatchTaskOperation{TaskIDs: req.TaskIDs}
+	switch req.Action {
+	case "delete":
+		op.Action = biz.BatchTaskActionDelete
+		op.DeleteMode = biz.TaskDeleteModeCascade
+		if req.Mode != "" {
+			mode, err := TaskDeleteModeFromString(req.Mode)
+			if err != nil {
+				return op, err
+			}
+			op.DeleteMode = mode
+		}
+		return op, nil
+	case "move":
+		op.Action = biz.BatchTaskActionMove
+		op.NewParentID = req.ParentID
+		return op, nil
+	}
+
+	op.Action = biz.BatchTaskActionUpdate
+	if req.Status != "" {
+		status, err := TaskStatusFromString(req.Status)
+		if err != nil {
+			return op, err
+		}
+		op.Status = &status
+	}
+	if req.Priority != "" {
+		priority, err := TaskPriorityFromString(req.Priority)
+		if err != nil {
+			return op, err
+		}
+		op.Priority = &priority
+	}
+	if req.Icon != nil && *req.Icon != "" && !IsIcon(*req.Icon) {
+		return op, errors.New("invalid icon format")
+	}
+	op.Icon = req.Icon
+	op.Tags = req.Tags
+	if (req.StartDate == nil) != (req.EndDate == nil) {
+		return op, errors.New("start_date and end_date must be set together")
+	}
+	if req.StartDate != nil {
+		startDate, err := time.Parse("2006-01-02", *req.StartDate)
+		if err != nil {
+			return op, errors.New("invalid start_date format, expected YYYY-MM-DD")
+		}
+		endDate, err := time.Parse("2006-01-02", *req.EndDate)
+		if err != nil {
+			return op, errors.New("invalid end_date format, expected YYYY-MM-DD")
+		}
+		op.Period = &biz.Period{Start: startDate, End: endDate}
+	}
+	return op, nil
+}
+
+// batchItemError 单个任务失败时的错误码和原因，与单个操作接口的映射保持一致，内部错误不向客户端暴露细节
+func batchItemError(err error) (int, string) {
+	switch {
+	case errors.Is(err, biz.ErrTaskNotFound):
+		return 404, "Task not found"
+	case errors.Is(err, biz.ErrTaskBlocked),
+		errors.Is(err, biz.ErrTaskChildrenConflict),
+		errors.Is(err, biz.ErrTaskStatusTransition):
+		return 409, err.Error()
+	case errors.Is(err, biz.ErrInvalidInput),
+		errors.Is(err, biz.ErrTaskMoveCycle),
+		errors.Is(err, biz.ErrTaskTreeTooDeep),
+		errors.Is(err, biz.ErrSubTaskTypeInvalid),
+		errors.Is(err, biz.ErrSubTaskPeriodInvalid),
+		errors.Is(err, biz.ErrTagNameInvalid):
+		return 400, err.Error()
+	default:
+		return 500, "Internal error"
+	}
+}
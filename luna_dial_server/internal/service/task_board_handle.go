@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"luna_dial/internal/biz"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 获取任务看板：时间段内或子树中的任务按状态分列
+func (s *Service) handleGetTaskBoard(c echo.Context) error {
+	var req TaskBoardScopeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	scope, err := boardScopeFromRequest(req)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	board, err := s.taskUsecase.GetTaskBoard(c.Request().Context(), biz.GetTaskBoardParam{UserID: userID, Scope: scope})
+	if err != nil {
+		return taskBoardErrorResponse(c, err)
+	}
+	return c.JSON(200, NewSuccessResponse(board))
+}
+
+// 移动看板卡片：修改状态并放到目标列的指定位置，返回移动后的看板
+func (s *Service) handleMoveBoardCard(c echo.Context) error {
+	var req MoveBoardCardRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	scope, err := boardScopeFromRequest(req.TaskBoardScopeRequest)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+	status, err := TaskStatusFromString(req.Status)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	board, err := s.taskUsecase.MoveBoardCard(c.Request().Context(), biz.MoveBoardCardParam{
+		UserID:           userID,
+		TaskID:           c.Param("task_id"),
+		Scope:            scope,
+		Status:           status,
+		Position:         req.Position,
+		OverrideBlockers: req.OverrideBlockers,
+	})
+	if err != nil {
+		return taskBoardErrorResponse(c, err)
+	}
+	return c.JSON(200, NewSuccessResponse(board))
+}
+
+// boardScopeFromRequest 解析看板范围：指定 root_id 时为子树，否则需要完整的时间段
+func boardScopeFromRequest(req TaskBoardScopeRequest) (biz.BoardScope, error) {
+	if req.RootID != "" {
+		return biz.BoardScope{RootTaskID: req.RootID}, nil
+	}
+	if req.PeriodType == "" || req.StartDate == "" || req.EndDate == "" {
+		return biz.BoardScope{}, errors.New("root_id or period_type, start_date and end_date are required")
+	}
+	periodType, err := PeriodTypeFromString(req.PeriodType)
+	if err != nil {
+		return biz.BoardScope{}, err
+	}
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return biz.BoardScope{}, errors.New("invalid start_date format, expected YYYY-MM-DD")
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return biz.BoardScope{}, errors.New("invalid end_date format, expected YYYY-MM-DD")
+	}
+	match, err := PeriodMatchFromString(req.Match, biz.PeriodMatchContained)
+	if err != nil {
+		return biz.BoardScope{}, err
+	}
+	return biz.BoardScope{PeriodType: periodType, Period: biz.Period{Start: startDate, End: endDate}, Match: match}, nil
+}
+
+// taskBoardErrorResponse 看板接口的错误映射，状态相关的冲突与更新任务接口一致
+func taskBoardErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, biz.ErrTaskNotFound):
+		return c.JSON(404, NewErrorResponse(404, "Task not found"))
+	case errors.Is(err, biz.ErrTaskNotOnBoard):
+		return c.JSON(404, NewErrorResponse(404, err.Error()))
+	case errors.Is(err, biz.ErrWIPLimitExceeded),
+		errors.Is(err, biz.ErrTaskBlocked),
+		errors.Is(err, biz.ErrTaskStatusTransition):
+		return c.JSON(409, NewErrorResponse(409, err.Error()))
+	case errors.Is(err, biz.ErrInvalidInput):
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	default:
+		c.Logger().Error("Failed to handle task board:", err)
+		return c.JSON(500, NewErrorResponse(500, "Failed to handle task board"))
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"luna_dial/internal/biz"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 设置任务描述（markdown）
+func (s *Service) handleSetTaskDescription(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	var req SetTaskDescriptionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	task, err := s.taskUsecase.SetTaskDescription(c.Request().Context(), biz.SetTaskDescriptionParam{
+		TaskID:      taskID,
+		UserID:      userID,
+		Description: req.Description,
+	})
+	if err != nil {
+		return checklistErrorResponse(c, err, "Failed to set task description")
+	}
+	return c.JSON(200, NewSuccessResponse(task))
+}
+
+// 获取任务清单
+func (s *Service) handleListChecklistItems(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	items, err := s.taskUsecase.ListChecklistItems(c.Request().Context(), biz.ListChecklistItemsParam{
+		TaskID: taskID,
+		UserID: userID,
+	})
+	if err != nil {
+		return checklistErrorResponse(c, err, "Failed to list checklist items")
+	}
+	return c.JSON(200, NewSuccessResponse(items))
+}
+
+// 添加清单项
+func (s *Service) handleAddChecklistItem(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	var req AddChecklistItemRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	task, err := s.taskUsecase.AddChecklistItem(c.Request().Context(), biz.AddChecklistItemParam{
+		TaskID:   taskID,
+		UserID:   userID,
+		Text:     req.Text,
+		Position: req.Position,
+	})
+	if err != nil {
+		return checklistErrorResponse(c, err, "Failed to add checklist item")
+	}
+	return c.JSON(201, NewSuccessResponse(task))
+}
+
+// 更新清单项（文本、完成状态、位置）
+func (s *Service) handleUpdateChecklistItem(c echo.Context) error {
+	taskID := c.Param("task_id")
+	itemID := c.Param("item_id")
+	if taskID == "" || itemID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID and item ID are required"))
+	}
+
+	var req UpdateChecklistItemRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+	if req.Text == nil && req.Done == nil && req.Position == nil {
+		return c.JSON(400, NewErrorResponse(400, "At least one field must be provided for update"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	task, err := s.taskUsecase.UpdateChecklistItem(c.Request().Context(), biz.UpdateChecklistItemParam{
+		TaskID:   taskID,
+		UserID:   userID,
+		ItemID:   itemID,
+		Text:     req.Text,
+		Done:     req.Done,
+		Position: req.Position,
+	})
+	if err != nil {
+		return checklistErrorResponse(c, err, "Failed to update checklist item")
+	}
+	return c.JSON(200, NewSuccessResponse(task))
+}
+
+// 删除清单项
+func (s *Service) handleDeleteChecklistItem(c echo.Context) error {
+	taskID := c.Param("task_id")
+	itemID := c.Param("item_id")
+	if taskID == "" || itemID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID and item ID are required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	task, err := s.taskUsecase.DeleteChecklistItem(c.Request().Context(), biz.DeleteChecklistItemParam{
+		TaskID: taskID,
+		UserID: userID,
+		ItemID: itemID,
+	})
+	if err != nil {
+		return checklistErrorResponse(c, err, "Failed to delete checklist item")
+	}
+	return c.JSON(200, NewSuccessResponse(task))
+}
+
+// checklistErrorResponse 将描述/清单相关的业务错误映射为 HTTP 响应
+func checklistErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, biz.ErrTaskNotFound):
+		return c.JSON(404, NewErrorResponse(404, "Task not found"))
+	case errors.Is(err, biz.ErrChecklistItemNotFound):
+		return c.JSON(404, NewErrorResponse(404, "Checklist item not found"))
+	case errors.Is(err, biz.ErrChecklistItemTextEmpty), errors.Is(err, biz.ErrInvalidInput):
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	default:
+		return c.JSON(500, NewErrorResponse(500, fallback))
+	}
+}
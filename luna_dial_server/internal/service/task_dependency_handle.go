@@ -0,0 +1,160 @@
+package service
+
+import (
+	"errors"
+	"luna_dial/internal/biz"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 添加依赖：当前任务被 blocked_by_task_id 阻塞
+func (s *Service) handleAddTaskDependency(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	var req AddTaskDependencyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	dependency, err := s.taskUsecase.AddTaskDependency(c.Request().Context(), biz.AddTaskDependencyParam{
+		TaskID:          taskID,
+		BlockedByTaskID: req.BlockedByTaskID,
+		UserID:          userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, biz.ErrTaskNotFound):
+			return c.JSON(404, NewErrorResponse(404, "Task not found"))
+		case errors.Is(err, biz.ErrTaskDependencyCycle), errors.Is(err, biz.ErrTaskDependencyExists):
+			return c.JSON(409, NewErrorResponse(409, err.Error()))
+		case errors.Is(err, biz.ErrInvalidInput):
+			return c.JSON(400, NewErrorResponse(400, err.Error()))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to add task dependency"))
+	}
+	return c.JSON(201, NewSuccessResponse(dependency))
+}
+
+// 删除依赖
+func (s *Service) handleRemoveTaskDependency(c echo.Context) error {
+	taskID := c.Param("task_id")
+	blockerID := c.Param("blocker_id")
+	if taskID == "" || blockerID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID and blocker ID are required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	err = s.taskUsecase.RemoveTaskDependency(c.Request().Context(), biz.RemoveTaskDependencyParam{
+		TaskID:          taskID,
+		BlockedByTaskID: blockerID,
+		UserID:          userID,
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrTaskDependencyNotFound) {
+			return c.JSON(404, NewErrorResponse(404, "Task dependency not found"))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to remove task dependency"))
+	}
+	return c.NoContent(204)
+}
+
+// 获取阻塞当前任务的任务
+func (s *Service) handleListTaskBlockers(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	blockers, err := s.taskUsecase.ListTaskBlockers(c.Request().Context(), biz.ListTaskDependencyParam{
+		TaskID: taskID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrTaskNotFound) {
+			return c.JSON(404, NewErrorResponse(404, "Task not found"))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to list task blockers"))
+	}
+	return c.JSON(200, NewSuccessResponse(blockers))
+}
+
+// 获取被当前任务阻塞的任务
+func (s *Service) handleListTaskDependents(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	dependents, err := s.taskUsecase.ListTaskDependents(c.Request().Context(), biz.ListTaskDependencyParam{
+		TaskID: taskID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrTaskNotFound) {
+			return c.JSON(404, NewErrorResponse(404, "Task not found"))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to list task dependents"))
+	}
+	return c.JSON(200, NewSuccessResponse(dependents))
+}
+
+// 获取时间段内的关键路径（最长的依赖链）
+func (s *Service) handleGetCriticalPath(c echo.Context) error {
+	startDateStr := c.QueryParam("start_date")
+	endDateStr := c.QueryParam("end_date")
+	if startDateStr == "" || endDateStr == "" {
+		return c.JSON(400, NewErrorResponse(400, "start_date and end_date are required"))
+	}
+
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid start_date format, expected YYYY-MM-DD"))
+	}
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid end_date format, expected YYYY-MM-DD"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	path, err := s.taskUsecase.GetCriticalPath(c.Request().Context(), biz.GetCriticalPathParam{
+		UserID: userID,
+		Period: biz.Period{Start: startDate, End: endDate},
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrInvalidPeriod) {
+			return c.JSON(400, NewErrorResponse(400, err.Error()))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to get critical path"))
+	}
+	return c.JSON(200, NewSuccessResponse(path))
+}
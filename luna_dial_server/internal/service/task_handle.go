@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"luna_dial/internal/biz"
 	"regexp"
@@ -58,12 +59,17 @@ func (s *Service) handleListTasks(c echo.Context) error {
 	if err != nil {
 		return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid period type: %s", periodType)))
 	}
+	match, err := PeriodMatchFromString(c.QueryParam("match"), biz.PeriodMatchContained)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid match, expected contained, intersecting or starting_within"))
+	}
 
 	// 调用业务层获取任务列表
 	tasks, err := s.taskUsecase.ListTaskByPeriod(c.Request().Context(), biz.ListTaskByPeriodParam{
 		UserID:  userId,
 		Period:  period,
 		GroupBy: periodTypeEnum,
+		Match:   match,
 	})
 	if err != nil {
 		c.Logger().Error("Failed to get tasks:", err)
@@ -122,11 +128,19 @@ func (s *Service) handleCreateTask(c echo.Context) error {
 			Start: startDate,
 			End:   endDate,
 		},
-		Icon:     req.Icon,
-		Tags:     req.Tags,
-		Priority: priority,
+		Icon:        req.Icon,
+		Tags:        req.Tags,
+		Priority:    priority,
+		Description: req.Description,
+		Checklist:   ChecklistItemInputsFromRequest(req.Checklist),
+		KeyResult:   KeyResultInputFromRequest(req.KeyResult),
+		Estimate:    req.Estimate,
 	})
 	if err != nil {
+		if errors.Is(err, biz.ErrTagNameInvalid) || errors.Is(err, biz.ErrKeyResultInvalid) ||
+			errors.Is(err, biz.ErrChecklistItemTextEmpty) {
+			return c.JSON(400, NewErrorResponse(400, err.Error()))
+		}
 		return c.JSON(500, NewErrorResponse(500, "Failed to create task"))
 	}
 	return c.JSON(200, NewSuccessResponseWithMessage("create task endpoint", task))
@@ -186,11 +200,19 @@ func (s *Service) handleCreateSubTask(c echo.Context) error {
 			Start: startDate,
 			End:   endDate,
 		},
-		Icon:     req.Icon,
-		Priority: priority,
-		Tags:     req.Tags,
+		Icon:        req.Icon,
+		Priority:    priority,
+		Tags:        req.Tags,
+		Description: req.Description,
+		Checklist:   ChecklistItemInputsFromRequest(req.Checklist),
+		KeyResult:   KeyResultInputFromRequest(req.KeyResult),
+		Estimate:    req.Estimate,
 	})
 	if err != nil {
+		if errors.Is(err, biz.ErrTagNameInvalid) || errors.Is(err, biz.ErrKeyResultInvalid) ||
+			errors.Is(err, biz.ErrTaskTreeTooDeep) || errors.Is(err, biz.ErrChecklistItemTextEmpty) {
+			return c.JSON(400, NewErrorResponse(400, err.Error()))
+		}
 		return c.JSON(500, NewErrorResponse(500, fmt.Sprintf("Failed to create subtask: %v", err)))
 	}
 	return c.JSON(200, NewSuccessResponseWithMessage("create subtask endpoint", subTask))
@@ -246,6 +268,13 @@ func (s *Service) handleUpdateTask(c echo.Context) error {
 			End:   endDate,
 		}
 	}
+	if req.PeriodType != nil {
+		periodType, err := PeriodTypeFromString(*req.PeriodType)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid period type: %s", *req.PeriodType)))
+		}
+		updateParam.Type = &periodType
+	}
 	if req.Status != "" {
 		status, err := TaskStatusFromString(req.Status)
 		if err != nil {
@@ -253,6 +282,7 @@ func (s *Service) handleUpdateTask(c echo.Context) error {
 		}
 		updateParam.Status = &status
 	}
+	updateParam.CancelReason = req.CancelReason
 	if req.Priority != "" {
 		priority, err := TaskPriorityFromString(req.Priority)
 		if err != nil {
@@ -260,15 +290,47 @@ func (s *Service) handleUpdateTask(c echo.Context) error {
 		}
 		updateParam.Priority = &priority
 	}
+	if req.ScoreRollup != nil {
+		mode, err := ScoreRollupModeFromString(*req.ScoreRollup)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid score rollup mode: %s", *req.ScoreRollup)))
+		}
+		updateParam.ScoreRollup = &mode
+	}
+	updateParam.RollupCompletedOnly = req.RollupCompletedOnly
+	updateParam.Weight = req.Weight
+	updateParam.Estimate = req.Estimate
+	updateParam.KeyResult = KeyResultInputFromRequest(req.KeyResult)
+	updateParam.RemoveKeyResult = req.RemoveKeyResult
 	if req.Icon != nil {
 		updateParam.Icon = req.Icon
 	}
 	if req.Tags != nil {
 		updateParam.Tags = req.Tags
 	}
+	if req.Description != nil {
+		updateParam.Description = req.Description
+	}
+	if req.Checklist != nil {
+		checklist := ChecklistItemInputsFromRequest(*req.Checklist)
+		updateParam.Checklist = &checklist
+	}
+	updateParam.OverrideBlockers = req.OverrideBlockers
 
 	task, err := s.taskUsecase.UpdateTask(c.Request().Context(), updateParam)
 	if err != nil {
+		var conflictErr *biz.TaskChildrenConflictError
+		if errors.As(err, &conflictErr) {
+			return c.JSON(409, NewErrorResponseWithData(409, err.Error(), conflictErr.Conflicts))
+		}
+		if errors.Is(err, biz.ErrTaskBlocked) || errors.Is(err, biz.ErrTaskStatusTransition) {
+			return c.JSON(409, NewErrorResponse(409, err.Error()))
+		}
+		if errors.Is(err, biz.ErrChecklistItemTextEmpty) || errors.Is(err, biz.ErrTagNameInvalid) ||
+			errors.Is(err, biz.ErrSubTaskTypeInvalid) || errors.Is(err, biz.ErrSubTaskPeriodInvalid) ||
+			errors.Is(err, biz.ErrKeyResultInvalid) || errors.Is(err, biz.ErrInvalidInput) {
+			return c.JSON(400, NewErrorResponse(400, err.Error()))
+		}
 		return c.JSON(500, NewErrorResponse(500, "Failed to update task"))
 	}
 	return c.JSON(200, NewSuccessResponseWithMessage("update task endpoint", task))
@@ -291,15 +353,20 @@ func (s *Service) handleCompleteTask(c echo.Context) error {
 	}
 
 	status := biz.TaskStatusCompleted
-	_, err = s.taskUsecase.UpdateTask(c.Request().Context(), biz.UpdateTaskParam{
-		TaskID: taskID,
-		UserID: userID,
-		Status: &status,
+	task, err := s.taskUsecase.UpdateTask(c.Request().Context(), biz.UpdateTaskParam{
+		TaskID:           taskID,
+		UserID:           userID,
+		Status:           &status,
+		OverrideBlockers: c.QueryParam("override_blockers") == "true",
 	})
 	if err != nil {
+		if errors.Is(err, biz.ErrTaskBlocked) || errors.Is(err, biz.ErrTaskStatusTransition) {
+			return c.JSON(409, NewErrorResponse(409, err.Error()))
+		}
 		return c.JSON(500, NewErrorResponse(500, "Failed to complete task"))
 	}
-	return c.JSON(200, NewSuccessResponseWithMessage("complete task endpoint", nil))
+	// 返回的任务中 changed_ancestors 列出了因完成状态传播而变化的祖先任务
+	return c.JSON(200, NewSuccessResponseWithMessage("complete task endpoint", task))
 }
 
 // 更新任务分数
@@ -351,11 +418,28 @@ func (s *Service) handleDeleteTask(c echo.Context) error {
 		return c.JSON(401, NewErrorResponse(401, "User not found"))
 	}
 
+	// 子任务处理方式：cascade（默认，级联删除）或 promote（子任务上移）
+	mode := biz.TaskDeleteModeCascade
+	if modeStr := c.QueryParam("mode"); modeStr != "" {
+		mode, err = TaskDeleteModeFromString(modeStr)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid delete mode: %s", modeStr)))
+		}
+	}
+
 	err = s.taskUsecase.DeleteTask(c.Request().Context(), biz.DeleteTaskParam{
 		TaskID: taskID,
 		UserID: userID,
+		Mode:   mode,
 	})
 	if err != nil {
+		if errors.Is(err, biz.ErrTaskNotFound) {
+			return c.JSON(404, NewErrorResponse(404, "Task not found"))
+		}
+		var conflictErr *biz.TaskChildrenConflictError
+		if errors.As(err, &conflictErr) {
+			return c.JSON(409, NewErrorResponseWithData(409, err.Error(), conflictErr.Conflicts))
+		}
 		return c.JSON(500, NewErrorResponse(500, "Failed to delete task"))
 	}
 	return c.JSON(200, NewSuccessResponseWithMessage("delete task endpoint", nil))
@@ -551,6 +635,45 @@ func (s *Service) handleGetTaskTree(c echo.Context) error {
 	return c.JSON(200, NewSuccessResponse(taskTree))
 }
 
+// 获取任意层级任务的子树（任务自身及其所有后代）
+func (s *Service) handleGetTaskSubtree(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	// 获取当前用户ID
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	// 可选的状态过滤，同完整任务树
+	statusFilters := []biz.TaskStatus{}
+	if statusParam := c.QueryParam("status"); statusParam != "" {
+		for _, statusStr := range statusSeparatorRegex.Split(statusParam, -1) {
+			status, err := TaskStatusFromString(statusStr)
+			if err != nil {
+				return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid status: %s", statusStr)))
+			}
+			statusFilters = append(statusFilters, status)
+		}
+	}
+
+	subtree, err := s.taskUsecase.GetTaskSubtree(c.Request().Context(), biz.GetTaskSubtreeParam{
+		UserID:        userID,
+		TaskID:        taskID,
+		IncludeStatus: statusFilters,
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrTaskNotFound) {
+			return c.JSON(404, NewErrorResponse(404, "Task not found"))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to get task subtree"))
+	}
+	return c.JSON(200, NewSuccessResponse(subtree))
+}
+
 // 获取任务的父任务链
 func (s *Service) handleGetTaskParents(c echo.Context) error {
 	taskID := c.Param("task_id")
@@ -579,20 +702,82 @@ func (s *Service) handleGetTaskParents(c echo.Context) error {
 
 // 移动任务
 func (s *Service) handleMoveTask(c echo.Context) error {
+	// 路径参数为唯一任务ID来源
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
 	var req MoveTaskRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
 	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
 
 	// 获取当前用户ID
-	_, _, err := GetUserFromContext(c)
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	task, err := s.taskUsecase.MoveTask(c.Request().Context(), biz.MoveTaskParam{
+		TaskID:      taskID,
+		UserID:      userID,
+		NewParentID: req.NewParentID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, biz.ErrTaskNotFound):
+			return c.JSON(404, NewErrorResponse(404, "Task or new parent task not found"))
+		case errors.Is(err, biz.ErrTaskMoveCycle),
+			errors.Is(err, biz.ErrTaskTreeTooDeep),
+			errors.Is(err, biz.ErrSubTaskTypeInvalid),
+			errors.Is(err, biz.ErrSubTaskPeriodInvalid),
+			errors.Is(err, biz.ErrInvalidInput):
+			return c.JSON(400, NewErrorResponse(400, err.Error()))
+		default:
+			c.Logger().Error("Failed to move task:", err)
+			return c.JSON(500, NewErrorResponse(500, "Failed to move task"))
+		}
+	}
+	return c.JSON(200, NewSuccessResponseWithMessage("move task endpoint", task))
+}
+
+// 调整同一父任务下子任务的顺序，返回调整后的全部子任务
+func (s *Service) handleReorderTasks(c echo.Context) error {
+	var req ReorderTasksRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
 	if err != nil {
 		return c.JSON(401, NewErrorResponse(401, "User not found"))
 	}
 
-	// TODO: 实现任务移动逻辑（当前业务层尚未实现MoveTask方法）
-	// 这里先返回一个提示信息
-	return c.JSON(501, NewErrorResponse(501, "Task move functionality is not yet implemented"))
+	tasks, err := s.taskUsecase.ReorderTasks(c.Request().Context(), biz.ReorderTasksParam{
+		UserID:   userID,
+		ParentID: req.ParentID,
+		TaskIDs:  req.TaskIDs,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, biz.ErrTaskNotFound):
+			return c.JSON(404, NewErrorResponse(404, "Parent task not found"))
+		case errors.Is(err, biz.ErrTaskReorderInvalid),
+			errors.Is(err, biz.ErrInvalidInput):
+			return c.JSON(400, NewErrorResponse(400, err.Error()))
+		default:
+			c.Logger().Error("Failed to reorder tasks:", err)
+			return c.JSON(500, NewErrorResponse(500, "Failed to reorder tasks"))
+		}
+	}
+	return c.JSON(200, NewSuccessResponse(tasks))
 }
 
 // 使用优化的任务创建方法
@@ -643,11 +828,19 @@ func (s *Service) handleCreateTaskWithOptimization(c echo.Context) error {
 			Start: startDate,
 			End:   endDate,
 		},
-		Icon:     req.Icon,
-		Tags:     req.Tags,
-		Priority: priority,
+		Icon:        req.Icon,
+		Tags:        req.Tags,
+		Priority:    priority,
+		Description: req.Description,
+		Checklist:   ChecklistItemInputsFromRequest(req.Checklist),
+		KeyResult:   KeyResultInputFromRequest(req.KeyResult),
+		Estimate:    req.Estimate,
 	})
 	if err != nil {
+		if errors.Is(err, biz.ErrTagNameInvalid) || errors.Is(err, biz.ErrKeyResultInvalid) ||
+			errors.Is(err, biz.ErrChecklistItemTextEmpty) {
+			return c.JSON(400, NewErrorResponse(400, err.Error()))
+		}
 		return c.JSON(500, NewErrorResponse(500, "Failed to create task"))
 	}
 	return c.JSON(200, NewSuccessResponseWithMessage("Task created with tree optimization", task))
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"luna_dial/internal/biz"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 把指定时间段内未完成的任务顺延到下一个时间段
+func (s *Service) handleRolloverTasks(c echo.Context) error {
+	var req RolloverTasksRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	pType, err := PeriodTypeFromString(req.PeriodType)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid period type: %s", req.PeriodType)))
+	}
+	mode, err := RolloverModeFromString(req.Mode)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid mode: %s", req.Mode)))
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid date format, expected YYYY-MM-DD"))
+	}
+
+	result, err := s.taskUsecase.RolloverTasks(c.Request().Context(), biz.RolloverTasksParam{
+		UserID: userID,
+		Type:   pType,
+		Period: biz.NewPeriodFromPeriodType(pType, date),
+		Mode:   mode,
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrRolloverModeInvalid) || errors.Is(err, biz.ErrInvalidPeriod) {
+			return c.JSON(400, NewErrorResponse(400, err.Error()))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to roll over tasks"))
+	}
+	return c.JSON(200, NewSuccessResponse(result))
+}
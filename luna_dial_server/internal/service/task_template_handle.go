@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"luna_dial/internal/biz"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 获取当前用户的任务模板列表
+func (s *Service) handleListTaskTemplates(c echo.Context) error {
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	templates, err := s.taskTemplateUsecase.ListTaskTemplates(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(500, NewErrorResponse(500, "Failed to list task templates"))
+	}
+	return c.JSON(200, NewSuccessResponse(templates))
+}
+
+// 获取任务模板详情
+func (s *Service) handleGetTaskTemplate(c echo.Context) error {
+	templateID := c.Param("template_id")
+	if templateID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Template ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	template, err := s.taskTemplateUsecase.GetTaskTemplate(c.Request().Context(), biz.GetTaskTemplateParam{
+		TemplateID: templateID,
+		UserID:     userID,
+	})
+	if err != nil {
+		return taskTemplateErrorResponse(c, err, "Failed to get task template")
+	}
+	return c.JSON(200, NewSuccessResponse(template))
+}
+
+// 创建任务模板
+func (s *Service) handleCreateTaskTemplate(c echo.Context) error {
+	var req CreateTaskTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	root, err := TaskTemplateNodeFromRequest(req.Root)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid template node: %v", err)))
+	}
+
+	template, err := s.taskTemplateUsecase.CreateTaskTemplate(c.Request().Context(), biz.CreateTaskTemplateParam{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Root:        root,
+	})
+	if err != nil {
+		return taskTemplateErrorResponse(c, err, "Failed to create task template")
+	}
+	return c.JSON(201, NewSuccessResponse(template))
+}
+
+// 以已有任务为根保存任务模板
+func (s *Service) handleCaptureTaskTemplate(c echo.Context) error {
+	var req CaptureTaskTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	template, err := s.taskTemplateUsecase.CaptureTaskTemplate(c.Request().Context(), biz.CaptureTaskTemplateParam{
+		UserID:      userID,
+		TaskID:      req.TaskID,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		return taskTemplateErrorResponse(c, err, "Failed to capture task template")
+	}
+	return c.JSON(201, NewSuccessResponse(template))
+}
+
+// 删除任务模板
+func (s *Service) handleDeleteTaskTemplate(c echo.Context) error {
+	templateID := c.Param("template_id")
+	if templateID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Template ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	err = s.taskTemplateUsecase.DeleteTaskTemplate(c.Request().Context(), biz.GetTaskTemplateParam{
+		TemplateID: templateID,
+		UserID:     userID,
+	})
+	if err != nil {
+		return taskTemplateErrorResponse(c, err, "Failed to delete task template")
+	}
+	return c.NoContent(204)
+}
+
+// 在参考日期实例化任务模板，返回创建的任务树
+func (s *Service) handleInstantiateTaskTemplate(c echo.Context) error {
+	templateID := c.Param("template_id")
+	if templateID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Template ID is required"))
+	}
+
+	var req InstantiateTaskTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid date format, expected YYYY-MM-DD"))
+	}
+
+	task, err := s.taskTemplateUsecase.InstantiateTaskTemplate(c.Request().Context(), biz.InstantiateTaskTemplateParam{
+		TemplateID:    templateID,
+		UserID:        userID,
+		ReferenceDate: date,
+		ParentID:      req.ParentID,
+	})
+	if err != nil {
+		return taskTemplateErrorResponse(c, err, "Failed to instantiate task template")
+	}
+	return c.JSON(201, NewSuccessResponse(task))
+}
+
+func taskTemplateErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, biz.ErrTaskTemplateNotFound):
+		return c.JSON(404, NewErrorResponse(404, "Task template not found"))
+	case errors.Is(err, biz.ErrTaskNotFound):
+		return c.JSON(404, NewErrorResponse(404, "Task not found"))
+	case errors.Is(err, biz.ErrInvalidInput),
+		errors.Is(err, biz.ErrTaskTemplateInvalid),
+		errors.Is(err, biz.ErrTaskTreeTooDeep),
+		errors.Is(err, biz.ErrSubTaskTypeInvalid),
+		errors.Is(err, biz.ErrSubTaskPeriodInvalid):
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	default:
+		return c.JSON(500, NewErrorResponse(500, fallback))
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"errors"
+	"luna_dial/internal/biz"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 开始计时
+func (s *Service) handleStartTimer(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	var req StartTimerRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	entry, err := s.taskUsecase.StartTimer(c.Request().Context(), biz.StartTimerParam{
+		TaskID:      taskID,
+		UserID:      userID,
+		Note:        req.Note,
+		StopRunning: req.StopRunning,
+	})
+	if err != nil {
+		return timeEntryErrorResponse(c, err, "Failed to start timer")
+	}
+	return c.JSON(201, NewSuccessResponse(entry))
+}
+
+// 停止当前正在运行的计时器
+func (s *Service) handleStopTimer(c echo.Context) error {
+	var req StopTimerRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	entry, err := s.taskUsecase.StopTimer(c.Request().Context(), biz.StopTimerParam{
+		UserID: userID,
+		Note:   req.Note,
+	})
+	if err != nil {
+		return timeEntryErrorResponse(c, err, "Failed to stop timer")
+	}
+	return c.JSON(200, NewSuccessResponse(entry))
+}
+
+// 获取当前正在运行的计时器，没有时 data 为 null
+func (s *Service) handleGetRunningTimer(c echo.Context) error {
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	entry, err := s.taskUsecase.GetRunningTimer(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(500, NewErrorResponse(500, "Failed to get running timer"))
+	}
+	return c.JSON(200, NewSuccessResponse(entry))
+}
+
+// 手动补录工时
+func (s *Service) handleAddTimeEntry(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	var req AddTimeEntryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	entry, err := s.taskUsecase.AddTimeEntry(c.Request().Context(), biz.AddTimeEntryParam{
+		TaskID:    taskID,
+		UserID:    userID,
+		StartedAt: req.StartedAt,
+		EndedAt:   req.EndedAt,
+		Note:      req.Note,
+	})
+	if err != nil {
+		return timeEntryErrorResponse(c, err, "Failed to add time entry")
+	}
+	return c.JSON(201, NewSuccessResponse(entry))
+}
+
+// 获取任务的工时记录
+func (s *Service) handleListTimeEntries(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	entries, err := s.taskUsecase.ListTimeEntries(c.Request().Context(), biz.ListTimeEntriesParam{
+		TaskID: taskID,
+		UserID: userID,
+	})
+	if err != nil {
+		return timeEntryErrorResponse(c, err, "Failed to list time entries")
+	}
+	return c.JSON(200, NewSuccessResponse(entries))
+}
+
+// 删除工时记录
+func (s *Service) handleDeleteTimeEntry(c echo.Context) error {
+	entryID := c.Param("entry_id")
+	if entryID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Time entry ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	if err := s.taskUsecase.DeleteTimeEntry(c.Request().Context(), biz.DeleteTimeEntryParam{
+		EntryID: entryID,
+		UserID:  userID,
+	}); err != nil {
+		return timeEntryErrorResponse(c, err, "Failed to delete time entry")
+	}
+	return c.NoContent(204)
+}
+
+// timeEntryErrorResponse 将计时/工时相关的业务错误映射为 HTTP 响应
+func timeEntryErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, biz.ErrTaskNotFound):
+		return c.JSON(404, NewErrorResponse(404, "Task not found"))
+	case errors.Is(err, biz.ErrTimeEntryNotFound):
+		return c.JSON(404, NewErrorResponse(404, "Time entry not found"))
+	case errors.Is(err, biz.ErrNoRunningTimer):
+		return c.JSON(404, NewErrorResponse(404, err.Error()))
+	case errors.Is(err, biz.ErrTimerAlreadyRunning):
+		return c.JSON(409, NewErrorResponse(409, err.Error()))
+	case errors.Is(err, biz.ErrTimeEntryInvalid), errors.Is(err, biz.ErrInvalidInput):
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	default:
+		return c.JSON(500, NewErrorResponse(500, fallback))
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"luna_dial/internal/biz"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 获取回收站中的任务和日志
+func (s *Service) handleListTrash(c echo.Context) error {
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	trash, err := s.trashUsecase.ListTrash(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(500, NewErrorResponse(500, "Failed to list trash"))
+	}
+	return c.JSON(200, NewSuccessResponse(trash))
+}
+
+// 从回收站恢复任务（连同一起删除的子任务）
+func (s *Service) handleRestoreTask(c echo.Context) error {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Task ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	task, err := s.taskUsecase.RestoreTask(c.Request().Context(), biz.RestoreTaskParam{
+		TaskID: taskID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrTaskNotFound) {
+			return c.JSON(404, NewErrorResponse(404, "Task not found in trash"))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to restore task"))
+	}
+	return c.JSON(200, NewSuccessResponse(task))
+}
+
+// 从回收站恢复日志
+func (s *Service) handleRestoreJournal(c echo.Context) error {
+	journalID := c.Param("journal_id")
+	if journalID == "" {
+		return c.JSON(400, NewErrorResponse(400, "Journal ID is required"))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	journal, err := s.journalUsecase.RestoreJournal(c.Request().Context(), biz.RestoreJournalParam{
+		JournalID: journalID,
+		UserID:    userID,
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrJournalNotFound) {
+			return c.JSON(404, NewErrorResponse(404, "Journal not found in trash"))
+		}
+		return c.JSON(500, NewErrorResponse(500, "Failed to restore journal"))
+	}
+	return c.JSON(200, NewSuccessResponse(journal))
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"luna_dial/internal/biz"
 	"time"
@@ -58,3 +59,79 @@ func (s *Service) handleGetCurrentUser(c echo.Context) error {
 		Timestamp: time.Now().Unix(),
 	})
 }
+
+// handleGetUserSettings 获取当前用户设置
+func (s *Service) handleGetUserSettings(c echo.Context) error {
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	settings, err := s.settingsUsecase.GetUserSettings(c.Request().Context(), userID)
+	if err != nil {
+		c.Logger().Error("Failed to get user settings:", err)
+		return c.JSON(500, NewErrorResponse(500, "Failed to get user settings"))
+	}
+	return c.JSON(200, NewSuccessResponse(settings))
+}
+
+// handleUpdateUserSettings 更新当前用户设置
+func (s *Service) handleUpdateUserSettings(c echo.Context) error {
+	var req UpdateUserSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, "Invalid request data"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(400, NewErrorResponse(400, err.Error()))
+	}
+
+	userID, _, err := GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(401, NewErrorResponse(401, "User not found"))
+	}
+
+	param := biz.UpdateUserSettingsParam{UserID: userID}
+	if req.CompletionPropagation != nil {
+		mode, err := CompletionPropagationModeFromString(*req.CompletionPropagation)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid completion_propagation: %s", *req.CompletionPropagation)))
+		}
+		param.CompletionPropagation = &mode
+	}
+	if req.AutoRollover != nil {
+		mode, err := RolloverModeFromString(*req.AutoRollover)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid auto_rollover: %s", *req.AutoRollover)))
+		}
+		param.AutoRollover = &mode
+	}
+	if req.EstimateUnit != nil {
+		unit, err := EstimateUnitFromString(*req.EstimateUnit)
+		if err != nil {
+			return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid estimate_unit: %s", *req.EstimateUnit)))
+		}
+		param.EstimateUnit = &unit
+	}
+	param.DailyCapacity = req.DailyCapacity
+	param.WeeklyCapacity = req.WeeklyCapacity
+	if len(req.WIPLimits) > 0 {
+		param.WIPLimits = make(map[biz.TaskStatus]int, len(req.WIPLimits))
+		for key, limit := range req.WIPLimits {
+			status, err := TaskStatusFromString(key)
+			if err != nil {
+				return c.JSON(400, NewErrorResponse(400, fmt.Sprintf("Invalid wip_limits status: %s", key)))
+			}
+			param.WIPLimits[status] = limit
+		}
+	}
+
+	settings, err := s.settingsUsecase.UpdateUserSettings(c.Request().Context(), param)
+	if err != nil {
+		if errors.Is(err, biz.ErrInvalidInput) || errors.Is(err, biz.ErrRolloverModeInvalid) {
+			return c.JSON(400, NewErrorResponse(400, err.Error()))
+		}
+		c.Logger().Error("Failed to update user settings:", err)
+		return c.JSON(500, NewErrorResponse(500, "Failed to update user settings"))
+	}
+	return c.JSON(200, NewSuccessResponse(settings))
+}
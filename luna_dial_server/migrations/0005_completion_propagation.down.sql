@@ -0,0 +1,3 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS ready_to_complete;
+
+DROP TABLE IF EXISTS user_settings;
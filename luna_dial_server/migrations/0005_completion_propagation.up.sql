@@ -0,0 +1,11 @@
+-- 用户设置表
+-- completion_propagation：子任务全部完成后父任务的处理方式，0=自动完成, 1=仅标记为可完成
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id VARCHAR(36) PRIMARY KEY,
+    completion_propagation INT DEFAULT 0 NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 任务"可完成"标记：所有未取消的子任务都已完成，等待用户确认
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS ready_to_complete BOOLEAN DEFAULT FALSE;
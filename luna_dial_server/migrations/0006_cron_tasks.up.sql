@@ -0,0 +1,25 @@
+-- 周期任务规则表
+-- frequency：0=每天, 1=每周指定星期, 2=每月指定日期, 3=工作日, 4=cron表达式
+-- last_generated_date：最后一次生成任务的日期，后台生成器据此续跑，避免重启后重复生成
+CREATE TABLE IF NOT EXISTS cron_tasks (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    frequency INT NOT NULL,
+    weekdays VARCHAR(20),
+    month_day INT DEFAULT 0,
+    cron_expr VARCHAR(100),
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP,
+    task_type INT NOT NULL,
+    tags TEXT,
+    icon VARCHAR(10),
+    priority INT DEFAULT 0 NOT NULL,
+    parent_id VARCHAR(36),
+    last_generated_date TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_cron_tasks_user_id ON cron_tasks(user_id);
+CREATE INDEX IF NOT EXISTS idx_cron_tasks_end_date ON cron_tasks(end_date);
@@ -0,0 +1,13 @@
+-- 任务依赖表：task_id 被 blocked_by_task_id 阻塞，与 parent_id 层级无关，可以跨任务树
+-- 任务删除时依赖边随之删除
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id VARCHAR(36) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    blocked_by_task_id VARCHAR(36) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, blocked_by_task_id),
+    CHECK (task_id <> blocked_by_task_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocked_by ON task_dependencies(blocked_by_task_id);
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_user_id ON task_dependencies(user_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS task_checklist_items;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS checklist_done;
+ALTER TABLE tasks DROP COLUMN IF EXISTS checklist_total;
+ALTER TABLE tasks DROP COLUMN IF EXISTS description;
@@ -0,0 +1,20 @@
+-- 任务描述（markdown）
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS description TEXT;
+
+-- 清单统计冗余字段：列表查询时直接计算完成比例
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS checklist_total INT DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS checklist_done INT DEFAULT 0;
+
+-- 任务清单项：比子任务更轻量，按 position 排序，任务删除时一起删除
+CREATE TABLE IF NOT EXISTS task_checklist_items (
+    id VARCHAR(36) PRIMARY KEY,
+    task_id VARCHAR(36) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id VARCHAR(36) NOT NULL,
+    text VARCHAR(500) NOT NULL,
+    done BOOLEAN DEFAULT FALSE,
+    position INT DEFAULT 0 NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_checklist_items_task_position ON task_checklist_items(task_id, position);
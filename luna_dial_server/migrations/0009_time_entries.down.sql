@@ -0,0 +1,4 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS tree_time_spent;
+ALTER TABLE tasks DROP COLUMN IF EXISTS time_spent;
+
+DROP TABLE IF EXISTS time_entries;
@@ -0,0 +1,23 @@
+-- 工时记录：ended_at 为空表示计时器正在运行，duration 以秒为单位
+CREATE TABLE IF NOT EXISTS time_entries (
+    id VARCHAR(36) PRIMARY KEY,
+    task_id VARCHAR(36) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id VARCHAR(36) NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP,
+    duration BIGINT DEFAULT 0 NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_time_entries_task_id ON time_entries(task_id);
+CREATE INDEX IF NOT EXISTS idx_time_entries_user_started ON time_entries(user_id, started_at);
+
+-- 每个用户同一时间最多一个运行中的计时器
+CREATE UNIQUE INDEX IF NOT EXISTS idx_time_entries_user_running ON time_entries(user_id) WHERE ended_at IS NULL;
+
+-- 任务耗时冗余字段（秒）：time_spent 为任务自身耗时，tree_time_spent 只在根任务上维护整棵树的耗时
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS time_spent BIGINT DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tree_time_spent BIGINT DEFAULT 0;
@@ -0,0 +1,9 @@
+-- 回收站中的数据在回滚时永久删除
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+DELETE FROM journals WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_journals_deleted_at;
+DROP INDEX IF EXISTS idx_tasks_deleted_at;
+
+ALTER TABLE journals DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,6 @@
+-- 软删除：删除任务和日志时只记录 deleted_at，进入回收站，超过保留期后由后台清理永久删除
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE journals ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_journals_deleted_at ON journals(deleted_at);
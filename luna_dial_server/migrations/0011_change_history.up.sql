@@ -0,0 +1,17 @@
+-- 任务和日志的变更历史：每条记录对应一个字段的一次变更
+-- 实体被永久删除后历史仍然保留，因此不设外键
+CREATE TABLE IF NOT EXISTS change_history (
+    id VARCHAR(36) PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    actor_id VARCHAR(36) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    field VARCHAR(50) NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_change_history_entity ON change_history(entity_type, entity_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_change_history_user_id ON change_history(user_id);
@@ -0,0 +1,5 @@
+ALTER TABLE user_settings DROP COLUMN IF EXISTS auto_rollover;
+
+DROP INDEX IF EXISTS idx_tasks_carried_over_from_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS carry_over_count;
+ALTER TABLE tasks DROP COLUMN IF EXISTS carried_over_from_id;
@@ -0,0 +1,9 @@
+-- 未完成任务顺延
+-- carried_over_from_id：复制模式下顺延出的任务指向原任务；carry_over_count：任务被顺延的次数
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS carried_over_from_id VARCHAR(36);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS carry_over_count INT DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_carried_over_from_id ON tasks(carried_over_from_id);
+
+-- 自动顺延设置：0=不顺延, 1=移动到下一个时间段, 2=复制到下一个时间段
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS auto_rollover INT DEFAULT 0 NOT NULL;
@@ -0,0 +1,12 @@
+-- 任务模板：root 保存整棵模板树（JSON），节点的时间段相对于实例化时的参考日期
+CREATE TABLE IF NOT EXISTS task_templates (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    root TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_templates_user_id ON task_templates(user_id);
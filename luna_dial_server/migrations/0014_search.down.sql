@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_journals_content_trgm;
+DROP INDEX IF EXISTS idx_journals_title_trgm;
+DROP INDEX IF EXISTS idx_tasks_tags_trgm;
+DROP INDEX IF EXISTS idx_tasks_title_trgm;
+DROP INDEX IF EXISTS idx_journals_search_vector;
+DROP INDEX IF EXISTS idx_tasks_search_vector;
+
+ALTER TABLE journals DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE tasks DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,24 @@
+-- 全文搜索：任务搜索标题和标签，日志搜索标题和内容
+-- 使用 simple 分词配置（不做词干化），中文等没有空格分词的内容由 pg_trgm 的子串匹配兜底
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', replace(coalesce(tags, ''), ',', ' ')), 'B')
+    ) STORED;
+
+ALTER TABLE journals ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(content, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON tasks USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_journals_search_vector ON journals USING GIN (search_vector);
+
+-- 子串匹配（ILIKE）使用的三元组索引
+CREATE INDEX IF NOT EXISTS idx_tasks_title_trgm ON tasks USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_tasks_tags_trgm ON tasks USING GIN (tags gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_journals_title_trgm ON journals USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_journals_content_trgm ON journals USING GIN (content gin_trgm_ops);
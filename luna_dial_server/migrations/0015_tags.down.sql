@@ -0,0 +1,5 @@
+-- 冗余文本恢复为逗号分隔，包含逗号的标签会被拆开
+UPDATE tasks SET tags = replace(tags, E'\n', ',') WHERE tags IS NOT NULL AND tags <> '';
+
+DROP TABLE IF EXISTS task_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,52 @@
+-- 标签独立成表，任务通过 task_tags 关联标签
+-- tasks.tags 保留为标签名的冗余文本（改为换行分隔，标签名可以包含逗号），用于读取和全文搜索，以 task_tags 为准
+CREATE TABLE IF NOT EXISTS tags (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '',
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name ON tags(user_id, name);
+
+CREATE TABLE IF NOT EXISTS task_tags (
+    task_id VARCHAR(36) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    tag_id VARCHAR(36) NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (task_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag_id ON task_tags(tag_id);
+
+-- 迁移已有数据：拆分逗号分隔的标签，去除空白和重复，保留原有顺序
+CREATE TEMPORARY TABLE tag_backfill AS
+SELECT task_id, user_id, name, MIN(ord) AS ord
+FROM (
+    SELECT t.id AS task_id, t.user_id, left(btrim(s.name), 50) AS name, s.ord
+    FROM tasks t, unnest(string_to_array(t.tags, ',')) WITH ORDINALITY AS s(name, ord)
+    WHERE t.tags IS NOT NULL AND t.tags <> ''
+) split
+WHERE name <> ''
+GROUP BY task_id, user_id, name;
+
+INSERT INTO tags (id, user_id, name)
+SELECT md5(user_id || ':' || name), user_id, name
+FROM (SELECT DISTINCT user_id, name FROM tag_backfill) names
+ON CONFLICT (user_id, name) DO NOTHING;
+
+INSERT INTO task_tags (task_id, tag_id, position)
+SELECT b.task_id, g.id, b.ord - 1
+FROM tag_backfill b JOIN tags g ON g.user_id = b.user_id AND g.name = b.name
+ON CONFLICT (task_id, tag_id) DO NOTHING;
+
+DROP TABLE tag_backfill;
+
+UPDATE tasks SET tags = COALESCE((
+    SELECT string_agg(g.name, E'\n' ORDER BY tt.position, g.name)
+    FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+    WHERE tt.task_id = tasks.id
+), '')
+WHERE tags IS NOT NULL AND tags <> '';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_user_parent_sort_rank;
+ALTER TABLE tasks DROP COLUMN IF EXISTS sort_rank;
@@ -0,0 +1,15 @@
+-- 同级任务的手动排序：按间隔分配排序位置，调整顺序时只需修改被移动的任务
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS sort_rank BIGINT NOT NULL DEFAULT 0;
+
+-- 保持原有顺序：子任务按创建时间正序，根任务按创建时间倒序
+UPDATE tasks SET sort_rank = ranked.sort_rank
+FROM (
+    SELECT id, ROW_NUMBER() OVER (
+        PARTITION BY user_id, COALESCE(parent_id, '')
+        ORDER BY CASE WHEN COALESCE(parent_id, '') = '' THEN -EXTRACT(EPOCH FROM created_at) ELSE EXTRACT(EPOCH FROM created_at) END, id
+    ) * 1024 AS sort_rank
+    FROM tasks
+) ranked
+WHERE tasks.id = ranked.id;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_user_parent_sort_rank ON tasks(user_id, parent_id, sort_rank);
@@ -0,0 +1,5 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS progress;
+ALTER TABLE tasks DROP COLUMN IF EXISTS rollup_score;
+ALTER TABLE tasks DROP COLUMN IF EXISTS weight;
+ALTER TABLE tasks DROP COLUMN IF EXISTS rollup_completed_only;
+ALTER TABLE tasks DROP COLUMN IF EXISTS score_rollup;
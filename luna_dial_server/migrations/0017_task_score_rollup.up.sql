@@ -0,0 +1,30 @@
+-- 分数汇总：非叶子任务由子任务汇总分数和进度，汇总方式和权重可按任务配置
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS score_rollup INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS rollup_completed_only BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS weight DOUBLE PRECISION NOT NULL DEFAULT 1;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS rollup_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS progress DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- 先按叶子任务初始化：汇总分数为自身分数，已完成为 100
+UPDATE tasks SET
+    rollup_score = score,
+    progress = CASE WHEN status = 2 THEN 100 ELSE 0 END;
+
+-- 再从最深的一层开始向上汇总（已有任务都使用默认的求和方式），已取消和已删除的子任务不参与计算
+DO $$
+DECLARE
+    depth INTEGER;
+BEGIN
+    FOR depth IN SELECT DISTINCT tree_depth FROM tasks WHERE deleted_at IS NULL ORDER BY tree_depth DESC LOOP
+        UPDATE tasks SET
+            rollup_score = agg.score_sum,
+            progress = CASE WHEN tasks.status = 2 THEN 100 ELSE ROUND(agg.progress_avg::numeric, 4) END
+        FROM (
+            SELECT parent_id, user_id, SUM(rollup_score) AS score_sum, AVG(progress) AS progress_avg
+            FROM tasks
+            WHERE deleted_at IS NULL AND status <> 3 AND COALESCE(parent_id, '') <> ''
+            GROUP BY parent_id, user_id
+        ) agg
+        WHERE tasks.id = agg.parent_id AND tasks.user_id = agg.user_id AND tasks.tree_depth = depth;
+    END LOOP;
+END $$;
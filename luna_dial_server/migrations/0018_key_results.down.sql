@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS key_result_check_ins;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS key_result_unit;
+ALTER TABLE tasks DROP COLUMN IF EXISTS key_result_current;
+ALTER TABLE tasks DROP COLUMN IF EXISTS key_result_target;
+ALTER TABLE tasks DROP COLUMN IF EXISTS key_result_start;
+ALTER TABLE tasks DROP COLUMN IF EXISTS is_key_result;
@@ -0,0 +1,21 @@
+-- 关键结果：任务可以设置起始值、目标值、当前值和单位，进度由达成率 (current - start) / (target - start) 决定
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS is_key_result BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS key_result_start DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS key_result_target DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS key_result_current DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS key_result_unit VARCHAR(32);
+
+-- 关键结果打卡：每次更新当前值都留下一条记录
+CREATE TABLE IF NOT EXISTS key_result_check_ins (
+    id VARCHAR(36) PRIMARY KEY,
+    task_id VARCHAR(36) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id VARCHAR(36) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    previous_value DOUBLE PRECISION NOT NULL,
+    note TEXT,
+    checked_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_key_result_check_ins_task_checked ON key_result_check_ins(task_id, checked_at);
+CREATE INDEX IF NOT EXISTS idx_key_result_check_ins_user_id ON key_result_check_ins(user_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_completed_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS cancel_reason;
+ALTER TABLE tasks DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS completed_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS started_at;
@@ -0,0 +1,13 @@
+-- 任务状态时间：第一次开始、最近一次完成、取消的时间，以及可选的取消原因
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS started_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS cancel_reason TEXT;
+
+-- 已有任务没有记录状态变化的时间，用最后更新时间近似
+UPDATE tasks SET started_at = updated_at WHERE status = 1 AND started_at IS NULL;
+UPDATE tasks SET completed_at = updated_at WHERE status = 2 AND completed_at IS NULL;
+UPDATE tasks SET cancelled_at = updated_at WHERE status = 3 AND cancelled_at IS NULL;
+
+-- 按完成时间统计按时完成和逾期完成
+CREATE INDEX IF NOT EXISTS idx_tasks_completed_at ON tasks(completed_at);
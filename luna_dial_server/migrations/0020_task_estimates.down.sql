@@ -0,0 +1,5 @@
+ALTER TABLE user_settings DROP COLUMN IF EXISTS weekly_capacity;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS daily_capacity;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS estimate_unit;
+ALTER TABLE tasks DROP COLUMN IF EXISTS tree_estimate;
+ALTER TABLE tasks DROP COLUMN IF EXISTS estimate;
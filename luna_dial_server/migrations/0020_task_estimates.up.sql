@@ -0,0 +1,8 @@
+-- 工作量估算：任务自身的估算，以及根任务上维护的整树估算（已有任务没有估算，默认为0）
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS estimate INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tree_estimate INTEGER NOT NULL DEFAULT 0;
+
+-- 估算单位（0=分钟, 1=点数）和每天、每周的容量，容量为0时不检查
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS estimate_unit INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS daily_capacity INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS weekly_capacity INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+ALTER TABLE user_settings DROP COLUMN IF EXISTS wip_limit_cancelled;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS wip_limit_completed;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS wip_limit_in_progress;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS wip_limit_not_started;
+ALTER TABLE tasks DROP COLUMN IF EXISTS board_rank;
@@ -0,0 +1,8 @@
+-- 任务看板：卡片在看板列中的排序位置，已有任务的位置相同，按创建时间排列
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS board_rank BIGINT NOT NULL DEFAULT 0;
+
+-- 看板每一列（任务状态）的在制品上限，0 表示不限制
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS wip_limit_not_started INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS wip_limit_in_progress INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS wip_limit_completed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS wip_limit_cancelled INTEGER NOT NULL DEFAULT 0;
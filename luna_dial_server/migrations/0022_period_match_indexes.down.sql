@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_journals_user_period_end;
+DROP INDEX IF EXISTS idx_tasks_user_period_end;
+DROP INDEX IF EXISTS idx_journals_user_type_period_end;
+DROP INDEX IF EXISTS idx_journals_user_type_period_start;
+DROP INDEX IF EXISTS idx_tasks_user_type_period_end;
+DROP INDEX IF EXISTS idx_tasks_user_type_period_start;
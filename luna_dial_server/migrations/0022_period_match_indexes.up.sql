@@ -0,0 +1,11 @@
+-- 按时间段查询的三种匹配方式：
+-- contained / starting_within 按 period_start 做范围扫描，intersecting（period_end > 开始 AND period_start < 结束）按 period_end 做范围扫描
+-- 列表接口总是同时按类型过滤，把类型放在用户之后
+CREATE INDEX IF NOT EXISTS idx_tasks_user_type_period_start ON tasks (user_id, task_type, period_start);
+CREATE INDEX IF NOT EXISTS idx_tasks_user_type_period_end ON tasks (user_id, task_type, period_end);
+CREATE INDEX IF NOT EXISTS idx_journals_user_type_period_start ON journals (user_id, journal_type, period_start);
+CREATE INDEX IF NOT EXISTS idx_journals_user_type_period_end ON journals (user_id, journal_type, period_end);
+
+-- 不按类型过滤的查询（日程、分页日志列表）使用的交集扫描
+CREATE INDEX IF NOT EXISTS idx_tasks_user_period_end ON tasks (user_id, period_end);
+CREATE INDEX IF NOT EXISTS idx_journals_user_period_end ON journals (user_id, period_end);